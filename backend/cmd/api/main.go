@@ -1,14 +1,30 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
+	"tp06-testing/internal/contentfilter"
 	"tp06-testing/internal/database"
+	"tp06-testing/internal/events"
 	"tp06-testing/internal/handlers"
+	"tp06-testing/internal/mail"
+	"tp06-testing/internal/realtime"
 	"tp06-testing/internal/repository"
 	"tp06-testing/internal/router"
 	"tp06-testing/internal/services"
+	"tp06-testing/internal/storage"
 )
 
 func main() {
@@ -22,21 +38,268 @@ func main() {
 	// Crear repositorios
 	userRepo := repository.NewSQLiteUserRepository(db)
 	postRepo := repository.NewSQLitePostRepository(db)
+	attachmentRepo := repository.NewSQLiteAttachmentRepository(db)
+	reactionRepo := repository.NewSQLiteReactionRepository(db)
+	bookmarkRepo := repository.NewSQLiteBookmarkRepository(db)
+	followRepo := repository.NewSQLiteFollowRepository(db)
+	notificationRepo := repository.NewSQLiteNotificationRepository(db)
+	mentionRepo := repository.NewSQLiteMentionRepository(db)
+	webhookRepo := repository.NewSQLiteWebhookRepository(db)
+	federationRepo := repository.NewSQLiteFederationRepository(db)
+	digestRepo := repository.NewSQLiteDigestRepository(db)
+	blockRepo := repository.NewSQLiteBlockRepository(db)
+	reportRepo := repository.NewSQLiteReportRepository(db)
+	contentFilterRepo := repository.NewSQLiteContentFilterRepository(db)
+	suspensionRepo := repository.NewSQLiteSuspensionRepository(db)
+
+	// Envío de emails
+	mailer, err := newMailer()
+	if err != nil {
+		log.Fatal("Error al configurar el envío de emails:", err)
+	}
+
+	// Almacenamiento de archivos adjuntos
+	blobStore, err := newBlobStore()
+	if err != nil {
+		log.Fatal("Error al inicializar el almacenamiento de archivos:", err)
+	}
 
 	// Crear servicios
 	authService := services.NewAuthService(userRepo)
+	authService.SetSuspensionRepository(suspensionRepo)
+	notificationService := services.NewNotificationService(notificationRepo, postRepo)
 	postService := services.NewPostService(postRepo, userRepo)
+	postService.SetAttachmentRepository(attachmentRepo)
+	postService.SetReactionRepository(reactionRepo)
+	postService.SetBookmarkRepository(bookmarkRepo)
+	postService.SetMentionRepository(mentionRepo)
+	postService.SetBlockRepository(blockRepo)
+	postService.SetFollowRepository(followRepo)
+	postService.SetSuspensionRepository(suspensionRepo)
+	postService.SetNotificationService(notificationService)
+	trashRetention := time.Duration(getEnvInt("TRASH_RETENTION_DAYS", 30)) * 24 * time.Hour
+	postService.SetTrashRetention(trashRetention)
+	broker := events.NewBroker(1000, 64)
+	postService.SetEventBroker(broker)
+	attachmentService := services.NewAttachmentService(attachmentRepo, postRepo, userRepo, blobStore)
+	reactionService := services.NewReactionService(reactionRepo, postRepo, userRepo)
+	followService := services.NewFollowService(followRepo, userRepo)
+	blockService := services.NewBlockService(blockRepo, userRepo)
+	moderationService := services.NewModerationService(reportRepo, suspensionRepo, postRepo, userRepo, getEnvInt("REPORT_HIDE_THRESHOLD", 3))
+	moderationService.SetFollowRepository(followRepo)
+	attachmentService.SetFollowRepository(followRepo)
+	reactionService.SetFollowRepository(followRepo)
+	followService.SetNotificationService(notificationService)
+
+	// Filtros de contenido: lo retenido va a la cola de moderación y las
+	// decisiones de los moderadores entrenan el clasificador de spam
+	contentFilter, spamClassifier, err := newContentFilter(contentFilterRepo)
+	if err != nil {
+		log.Fatal("Error al configurar los filtros de contenido:", err)
+	}
+	postService.SetContentFilter(contentFilter)
+	postService.SetModerationService(moderationService)
+	moderationService.SetSpamTrainer(spamClassifier)
+
+	webhookService := services.NewWebhookService(webhookRepo, services.RealClock{})
+	sitemapService := services.NewSitemapService(postRepo)
+	federationService := services.NewFederationService(federationRepo, userRepo, postService, followService, services.NewPublicHTTPClient(10*time.Second), getEnv("FEDERATION_URL", getEnv("API_URL", "http://localhost:8080")), getEnv("SITE_URL", "http://localhost:3000"))
+	secret, err := unsubscribeSecret()
+	if err != nil {
+		log.Fatal("Error al configurar los links para desuscribirse:", err)
+	}
+	digestService := services.NewDigestService(digestRepo, services.RealClock{}, secret, getEnv("API_URL", "http://localhost:8080"))
+
+	// El contexto se cancela con SIGINT/SIGTERM: frena las tareas en
+	// segundo plano y corta los streams abiertos
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	federationService.SetContext(ctx)
+
+	// Eliminar definitivamente lo que venció en la papelera
+	trashPurger := services.NewTrashPurger(postRepo, services.RealClock{}, trashRetention, time.Hour)
+	go trashPurger.Start(ctx)
+
+	// Versiones reducidas de las imágenes subidas
+	imageProcessor := services.NewImageProcessor(attachmentRepo, blobStore, time.Minute)
+	attachmentService.SetImageProcessor(imageProcessor)
+	go imageProcessor.Start(ctx)
+
+	// Entregas de webhooks: los eventos se encolan en la base y se envían
+	// con reintentos
+	webhookDispatcher := services.NewWebhookDispatcher(webhookRepo, services.RealClock{}, services.NewPublicHTTPClient(10*time.Second), 15*time.Second)
+	webhookService.SetDispatcher(webhookDispatcher)
+	go webhookDispatcher.Listen(ctx, broker)
+	go webhookDispatcher.Start(ctx)
+
+	// Los posts nuevos y borrados se envían a los seguidores remotos
+	go federationService.Listen(ctx, broker)
+
+	// Publicar posts programados en segundo plano (después de lanzar los
+	// listeners del broker, ya que cada post publicado se anuncia)
+	scheduler := services.NewPostScheduler(postService, services.RealClock{}, 30*time.Second)
+	go scheduler.Start(ctx)
+
+	// Resúmenes diarios y semanales por email
+	digestSender := services.NewDigestSender(digestService, digestRepo, userRepo, mailer, services.RealClock{}, 10*time.Minute, getEnv("SITE_URL", "http://localhost:3000"))
+	go digestSender.Start(ctx)
 
 	// Crear handlers
 	authHandler := handlers.NewAuthHandler(authService)
 	postHandler := handlers.NewPostHandler(postService)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService)
+	reactionHandler := handlers.NewReactionHandler(reactionService)
+	userHandler := handlers.NewUserHandler(followService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	streamHandler := handlers.NewStreamHandler(broker, 15*time.Second)
+	hub := realtime.NewHub(broker, realtime.DefaultConfig)
+	postService.SetRoomPublisher(hub)
+	moderationService.SetSessionCloser(hub)
+	liveHandler := handlers.NewLiveHandler(hub, authService, postService, followService, allowedOrigins())
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	feedHandler := handlers.NewFeedHandler(postService, followService, getEnv("SITE_URL", "http://localhost:3000"))
+	sitemapHandler := handlers.NewSitemapHandler(sitemapService, getEnv("SITE_URL", "http://localhost:3000"), handlers.RobotsConfig{
+		DisallowAll: os.Getenv("ROBOTS_DISALLOW_ALL") == "true",
+		Disallow:    strings.Split(getEnv("ROBOTS_DISALLOW", "/api/"), ","),
+	})
+
+	activityPubHandler := handlers.NewActivityPubHandler(federationService)
+	digestHandler := handlers.NewDigestHandler(digestService)
+	blockHandler := handlers.NewBlockHandler(blockService)
+	moderationHandler := handlers.NewModerationHandler(moderationService)
 
 	// Configurar rutas
-	r := router.Setup(authHandler, postHandler)
+	r := router.Setup(authHandler, postHandler, attachmentHandler, reactionHandler, userHandler, notificationHandler, streamHandler, liveHandler, webhookHandler, feedHandler, sitemapHandler, activityPubHandler, digestHandler, blockHandler, moderationHandler)
+
+	// Iniciar servidor. Los requests heredan ctx para que los streams SSE
+	// terminen al apagarse.
+	server := &http.Server{
+		Addr:        ":8080",
+		Handler:     r,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		log.Println("🚀 Servidor corriendo en http://localhost:8080")
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Error al iniciar el servidor:", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Apagando el servidor...")
+
+	// Se dejan terminar los requests en curso y se cierran los WebSocket
+	// (Shutdown no espera las conexiones que ya no son HTTP)
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelShutdown()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Println("Error al apagar el servidor:", err)
+	}
+	hub.Shutdown()
+}
+
+// allowedOrigins lee de ALLOWED_ORIGINS (separados por coma) los orígenes
+// desde los que el frontend puede abrir un WebSocket
+func allowedOrigins() []string {
+	return strings.Split(getEnv("ALLOWED_ORIGINS", "http://localhost:3000"), ",")
+}
+
+// newBlobStore elige dónde guardar los adjuntos según BLOB_STORE:
+// "local" (por defecto, en UPLOADS_DIR) o "s3" (S3_ENDPOINT, S3_BUCKET,
+// S3_REGION, S3_ACCESS_KEY y S3_SECRET_KEY)
+func newBlobStore() (storage.BlobStore, error) {
+	if getEnv("BLOB_STORE", "local") == "s3" {
+		return storage.NewS3Store(storage.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    os.Getenv("S3_REGION"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+		}, nil)
+	}
+	return storage.NewLocalStore(getEnv("UPLOADS_DIR", "./uploads"))
+}
+
+// newMailer elige cómo se envían los emails según MAILER: "file" (por
+// defecto, se guardan en MAIL_OUTBOX_DIR) o "smtp" (SMTP_HOST, SMTP_PORT,
+// SMTP_USERNAME y SMTP_PASSWORD). El remitente es MAIL_FROM.
+func newMailer() (mail.Mailer, error) {
+	from := getEnv("MAIL_FROM", "Blog <no-reply@localhost>")
+	if getEnv("MAILER", "file") == "smtp" {
+		port, _ := strconv.Atoi(os.Getenv("SMTP_PORT"))
+		return mail.NewSMTPMailer(mail.SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		})
+	}
+	return mail.NewFileMailer(getEnv("MAIL_OUTBOX_DIR", "./outbox"), from)
+}
+
+// newContentFilter arma la cadena de filtros de contenido:
+//   - palabras prohibidas: las de CONTENT_REJECT_WORDS_FILE rechazan y las
+//     de CONTENT_HOLD_WORDS_FILE retienen (una por línea, opcionales)
+//   - links: las cuentas con menos de NEW_ACCOUNT_DAYS días (7) no pueden
+//     publicar más de NEW_ACCOUNT_MAX_LINKS links (2) sin revisión
+//   - repetidos: el mismo texto del mismo autor dentro de
+//     DUPLICATE_WINDOW_HOURS horas (24)
+//   - clasificador de spam, que se devuelve aparte para entrenarlo
+func newContentFilter(repo repository.ContentFilterRepository) (*contentfilter.Chain, *contentfilter.SpamClassifier, error) {
+	rejectWords, err := contentfilter.LoadWordList(os.Getenv("CONTENT_REJECT_WORDS_FILE"))
+	if err != nil {
+		return nil, nil, err
+	}
+	holdWords, err := contentfilter.LoadWordList(os.Getenv("CONTENT_HOLD_WORDS_FILE"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	classifier := contentfilter.NewSpamClassifier(repo, 0.9, 0.99, 10)
+	chain := contentfilter.NewChain(
+		contentfilter.NewWordFilter(rejectWords, holdWords),
+		contentfilter.NewLinkFilter(getEnvInt("NEW_ACCOUNT_MAX_LINKS", 2), time.Duration(getEnvInt("NEW_ACCOUNT_DAYS", 7))*24*time.Hour),
+		contentfilter.NewDuplicateFilter(repo, time.Duration(getEnvInt("DUPLICATE_WINDOW_HOURS", 24))*time.Hour, 20),
+		classifier,
+	)
+	return chain, classifier, nil
+}
+
+// unsubscribeSecret lee UNSUBSCRIBE_SECRET, con el que se firman los links
+// para desuscribirse. Con MAILER=smtp es obligatorio: un secreto al azar
+// invalidaría en cada reinicio los links de los emails ya enviados. Con el
+// mailer de archivos (desarrollo) se genera uno al azar y se avisa.
+func unsubscribeSecret() (string, error) {
+	if secret := os.Getenv("UNSUBSCRIBE_SECRET"); secret != "" {
+		return secret, nil
+	}
+	if getEnv("MAILER", "file") == "smtp" {
+		return "", errors.New("UNSUBSCRIBE_SECRET es obligatorio con MAILER=smtp")
+	}
+
+	log.Println("⚠️  UNSUBSCRIBE_SECRET no está configurado: se usa un secreto al azar y los links para desuscribirse no sobreviven a un reinicio")
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(random), nil
+}
+
+// getEnvInt devuelve la variable de entorno como número o el valor por
+// defecto si no está configurada o no es un número válido
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
 
-	// Iniciar servidor
-	log.Println("🚀 Servidor corriendo en http://localhost:8080")
-	if err := http.ListenAndServe(":8080", r); err != nil {
-		log.Fatal("Error al iniciar el servidor:", err)
+// getEnv devuelve la variable de entorno o el valor por defecto
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
 	}
+	return fallback
 }
@@ -0,0 +1,52 @@
+// Comando para dar o quitar el rol de moderador a un usuario. Los
+// moderadores atienden los reportes en /api/admin.
+//
+// Uso: go run ./cmd/moderator -db ./database.db -user ana [-revoke]
+package main
+
+import (
+	"flag"
+	"log"
+
+	"tp06-testing/internal/database"
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/repository"
+)
+
+func main() {
+	dbPath := flag.String("db", "./database.db", "ruta de la base de datos SQLite")
+	username := flag.String("user", "", "nombre del usuario")
+	revoke := flag.Bool("revoke", false, "quitar el rol de moderador en lugar de darlo")
+	flag.Parse()
+
+	if *username == "" {
+		log.Fatal("Indica el usuario con -user")
+	}
+
+	db, err := database.InitDB(*dbPath)
+	if err != nil {
+		log.Fatal("Error al inicializar la base de datos:", err)
+	}
+	defer db.Close()
+
+	userRepo := repository.NewSQLiteUserRepository(db)
+
+	user, err := userRepo.FindByUsername(*username)
+	if err != nil {
+		log.Fatal("Error al buscar el usuario:", err)
+	}
+	if user == nil {
+		log.Fatalf("No existe el usuario %q", *username)
+	}
+
+	role := models.RoleModerator
+	if *revoke {
+		role = models.RoleUser
+	}
+
+	if err := userRepo.SetRole(user.ID, role); err != nil {
+		log.Fatal("Error al cambiar el rol:", err)
+	}
+
+	log.Printf("%s (ID %d) ahora tiene el rol %s", user.Username, user.ID, role)
+}
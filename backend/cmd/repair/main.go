@@ -0,0 +1,33 @@
+// Comando de mantenimiento que recalcula los datos desnormalizados de la
+// base (contadores de comentarios de cada post).
+//
+// Uso: go run ./cmd/repair -db ./database.db
+package main
+
+import (
+	"flag"
+	"log"
+
+	"tp06-testing/internal/database"
+	"tp06-testing/internal/repository"
+)
+
+func main() {
+	dbPath := flag.String("db", "./database.db", "ruta de la base de datos SQLite")
+	flag.Parse()
+
+	db, err := database.InitDB(*dbPath)
+	if err != nil {
+		log.Fatal("Error al inicializar la base de datos:", err)
+	}
+	defer db.Close()
+
+	postRepo := repository.NewSQLitePostRepository(db)
+
+	fixed, err := postRepo.RecountComments()
+	if err != nil {
+		log.Fatal("Error al recalcular los contadores de comentarios:", err)
+	}
+
+	log.Printf("Contadores de comentarios recalculados: %d posts corregidos", fixed)
+}
@@ -0,0 +1,179 @@
+// Package activitypub arma los documentos de ActivityPub (actores,
+// objetos, actividades y colecciones) y firma y verifica los requests
+// entre servidores con HTTP Signatures, como lo espera Mastodon.
+package activitypub
+
+import "encoding/json"
+
+// ContentType es el Content-Type de los documentos de ActivityPub
+const ContentType = "application/activity+json"
+
+// Accept es lo que se pide al buscar un documento en otro servidor
+const Accept = `application/activity+json, application/ld+json; profile="https://www.w3.org/ns/activitystreams"`
+
+// Public es la audiencia que hace visible una actividad para cualquiera
+const Public = "https://www.w3.org/ns/activitystreams#Public"
+
+// Contextos JSON-LD
+const (
+	ContextActivityStreams = "https://www.w3.org/ns/activitystreams"
+	ContextSecurity        = "https://w3id.org/security/v1"
+)
+
+// Tipos de actividad y de objeto que se usan
+const (
+	TypeAccept  = "Accept"
+	TypeCreate  = "Create"
+	TypeDelete  = "Delete"
+	TypeFollow  = "Follow"
+	TypeUndo    = "Undo"
+	TypePerson  = "Person"
+	TypeArticle = "Article"
+	TypeNote    = "Note"
+
+	TypeTombstone             = "Tombstone"
+	TypeOrderedCollection     = "OrderedCollection"
+	TypeOrderedCollectionPage = "OrderedCollectionPage"
+)
+
+// PublicKey es la clave con la que un actor firma sus requests
+type PublicKey struct {
+	ID           string `json:"id"`
+	Owner        string `json:"owner"`
+	PublicKeyPem string `json:"publicKeyPem"`
+}
+
+// Endpoints son URLs adicionales de un actor
+type Endpoints struct {
+	SharedInbox string `json:"sharedInbox,omitempty"`
+}
+
+// Actor es el documento de un usuario
+type Actor struct {
+	Context           interface{} `json:"@context,omitempty"`
+	ID                string      `json:"id"`
+	Type              string      `json:"type"`
+	PreferredUsername string      `json:"preferredUsername"`
+	Name              string      `json:"name,omitempty"`
+	URL               string      `json:"url,omitempty"`
+	Inbox             string      `json:"inbox"`
+	Outbox            string      `json:"outbox,omitempty"`
+	Followers         string      `json:"followers,omitempty"`
+	Published         string      `json:"published,omitempty"`
+	Endpoints         *Endpoints  `json:"endpoints,omitempty"`
+	PublicKey         PublicKey   `json:"publicKey"`
+}
+
+// Object es un post (Article), una respuesta (Note) o un Tombstone
+type Object struct {
+	Context      interface{} `json:"@context,omitempty"`
+	ID           string      `json:"id"`
+	Type         string      `json:"type"`
+	AttributedTo string      `json:"attributedTo,omitempty"`
+	InReplyTo    string      `json:"inReplyTo,omitempty"`
+	Name         string      `json:"name,omitempty"`
+	Content      string      `json:"content,omitempty"`
+	URL          string      `json:"url,omitempty"`
+	Published    string      `json:"published,omitempty"`
+	Updated      string      `json:"updated,omitempty"`
+	To           []string    `json:"to,omitempty"`
+	Cc           []string    `json:"cc,omitempty"`
+}
+
+// Activity es una actividad saliente. Object puede ser un *Object, otra
+// actividad o el ID de un objeto.
+type Activity struct {
+	Context   interface{} `json:"@context,omitempty"`
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	Actor     string      `json:"actor"`
+	Object    interface{} `json:"object"`
+	Published string      `json:"published,omitempty"`
+	To        []string    `json:"to,omitempty"`
+	Cc        []string    `json:"cc,omitempty"`
+}
+
+// IncomingActivity es una actividad recibida en un inbox. Solo se leen
+// los campos que se usan; el objeto se decodifica según el tipo.
+type IncomingActivity struct {
+	ID     string          `json:"id"`
+	Type   string          `json:"type"`
+	Actor  string          `json:"actor"`
+	Object json.RawMessage `json:"object"`
+}
+
+// IncomingObject son los campos que se leen del objeto de una actividad
+// recibida (una Note, un Follow embebido, un Tombstone...)
+type IncomingObject struct {
+	ID           string `json:"id"`
+	Type         string `json:"type"`
+	Actor        string `json:"actor"`
+	Object       string `json:"object"`
+	AttributedTo string `json:"attributedTo"`
+	InReplyTo    string `json:"inReplyTo"`
+	Content      string `json:"content"`
+}
+
+// ParseObject decodifica el objeto de una actividad, que puede venir
+// embebido o como un string con su ID
+func ParseObject(raw json.RawMessage) (*IncomingObject, error) {
+	var id string
+	if err := json.Unmarshal(raw, &id); err == nil {
+		return &IncomingObject{ID: id}, nil
+	}
+
+	object := &IncomingObject{}
+	if err := json.Unmarshal(raw, object); err != nil {
+		// Hay implementaciones que mandan campos como inReplyTo u object
+		// embebidos; solo se conserva lo que se pueda leer como ID
+		var loose map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &loose); err != nil {
+			return nil, err
+		}
+		object = &IncomingObject{
+			ID:           stringField(loose["id"]),
+			Type:         stringField(loose["type"]),
+			Actor:        stringField(loose["actor"]),
+			Object:       stringField(loose["object"]),
+			AttributedTo: stringField(loose["attributedTo"]),
+			InReplyTo:    stringField(loose["inReplyTo"]),
+			Content:      stringField(loose["content"]),
+		}
+	}
+	return object, nil
+}
+
+// stringField lee un campo que puede ser un string o un objeto con id
+func stringField(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var ref struct {
+		ID string `json:"id"`
+	}
+	if json.Unmarshal(raw, &ref) == nil {
+		return ref.ID
+	}
+	return ""
+}
+
+// OrderedCollection es una colección paginada (outbox, seguidores)
+type OrderedCollection struct {
+	Context    interface{} `json:"@context,omitempty"`
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	TotalItems int         `json:"totalItems"`
+	First      string      `json:"first,omitempty"`
+}
+
+// OrderedCollectionPage es una página de una OrderedCollection
+type OrderedCollectionPage struct {
+	Context      interface{}   `json:"@context,omitempty"`
+	ID           string        `json:"id"`
+	Type         string        `json:"type"`
+	PartOf       string        `json:"partOf"`
+	Next         string        `json:"next,omitempty"`
+	Prev         string        `json:"prev,omitempty"`
+	OrderedItems []interface{} `json:"orderedItems"`
+}
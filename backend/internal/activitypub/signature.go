@@ -0,0 +1,176 @@
+package activitypub
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Errores de verificación de firmas
+var (
+	ErrMissingSignature = errors.New("falta el header Signature")
+	ErrInvalidSignature = errors.New("firma HTTP inválida")
+	ErrDigestMismatch   = errors.New("el Digest no coincide con el cuerpo")
+	ErrExpiredSignature = errors.New("la fecha de la firma está fuera de rango")
+)
+
+const (
+	// SignatureMaxAge es la antigüedad máxima del header Date de un request firmado
+	SignatureMaxAge = 12 * time.Hour
+
+	// signatureMaxSkew es cuánto puede adelantar el reloj del otro servidor
+	signatureMaxSkew = time.Hour
+
+	// requestTarget es el pseudo-header con el método y la ruta
+	requestTarget = "(request-target)"
+)
+
+// Signature es el contenido de un header Signature
+type Signature struct {
+	KeyID     string
+	Algorithm string
+	Headers   []string
+	Value     []byte
+}
+
+// Digest calcula el header Digest de un cuerpo
+func Digest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// SignRequest firma un request con rsa-sha256 (draft-cavage-http-signatures,
+// lo que usa Mastodon). Completa Date y, si hay cuerpo, Digest; body debe
+// ser el mismo que se envía.
+func SignRequest(req *http.Request, keyID string, key *rsa.PrivateKey, body []byte, now time.Time) error {
+	req.Header.Set("Date", now.UTC().Format(http.TimeFormat))
+	headers := []string{requestTarget, "host", "date"}
+	if body != nil {
+		req.Header.Set("Digest", Digest(body))
+		headers = append(headers, "digest")
+	}
+
+	hashed := sha256.Sum256([]byte(signingString(req, headers)))
+	value, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Signature", fmt.Sprintf(`keyId="%s",algorithm="rsa-sha256",headers="%s",signature="%s"`,
+		keyID, strings.Join(headers, " "), base64.StdEncoding.EncodeToString(value)))
+	return nil
+}
+
+// ParseSignature lee el header Signature de un request
+func ParseSignature(req *http.Request) (*Signature, error) {
+	header := req.Header.Get("Signature")
+	if header == "" {
+		return nil, ErrMissingSignature
+	}
+
+	sig := &Signature{Headers: []string{"date"}} // Valor por defecto de la especificación
+	for _, part := range strings.Split(header, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, ErrInvalidSignature
+		}
+		value = strings.Trim(value, `"`)
+
+		switch name {
+		case "keyId":
+			sig.KeyID = value
+		case "algorithm":
+			sig.Algorithm = value
+		case "headers":
+			sig.Headers = strings.Fields(strings.ToLower(value))
+		case "signature":
+			decoded, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return nil, ErrInvalidSignature
+			}
+			sig.Value = decoded
+		}
+	}
+
+	if sig.KeyID == "" || sig.Value == nil {
+		return nil, ErrInvalidSignature
+	}
+	return sig, nil
+}
+
+// Verify comprueba la firma de un request recibido con la clave pública
+// del actor. Exige que se hayan firmado (request-target), host y date,
+// y digest si el request tiene cuerpo; el Digest tiene que coincidir con
+// body y la fecha no puede ser muy vieja ni del futuro.
+func (sig *Signature) Verify(req *http.Request, key *rsa.PublicKey, body []byte, now time.Time) error {
+	if sig.Algorithm != "" && sig.Algorithm != "rsa-sha256" && sig.Algorithm != "hs2019" {
+		return ErrInvalidSignature
+	}
+
+	required := []string{requestTarget, "host", "date"}
+	if len(body) > 0 {
+		required = append(required, "digest")
+	}
+	for _, name := range required {
+		if !contains(sig.Headers, name) {
+			return ErrInvalidSignature
+		}
+	}
+
+	if len(body) > 0 && req.Header.Get("Digest") != Digest(body) {
+		return ErrDigestMismatch
+	}
+
+	date, err := http.ParseTime(req.Header.Get("Date"))
+	if err != nil {
+		return ErrExpiredSignature
+	}
+	if date.Before(now.Add(-SignatureMaxAge)) || date.After(now.Add(signatureMaxSkew)) {
+		return ErrExpiredSignature
+	}
+
+	hashed := sha256.Sum256([]byte(signingString(req, sig.Headers)))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hashed[:], sig.Value); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// signingString arma el texto que se firma: una línea "nombre: valor" por
+// cada header, en el orden indicado
+func signingString(req *http.Request, headers []string) string {
+	lines := make([]string, len(headers))
+	for i, name := range headers {
+		var value string
+		switch name {
+		case requestTarget:
+			value = strings.ToLower(req.Method) + " " + req.URL.RequestURI()
+		case "host":
+			// En el servidor Go saca Host de los headers y lo deja en req.Host
+			value = req.Host
+			if value == "" {
+				value = req.URL.Host
+			}
+		default:
+			value = strings.Join(req.Header.Values(name), ", ")
+		}
+		lines[i] = name + ": " + value
+	}
+	return strings.Join(lines, "\n")
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package activitypub
+
+import "strings"
+
+// ContentTypeJRD es el Content-Type de las respuestas de WebFinger
+const ContentTypeJRD = "application/jrd+json"
+
+// JRD es la respuesta de WebFinger (RFC 7033)
+type JRD struct {
+	Subject string    `json:"subject"`
+	Aliases []string  `json:"aliases,omitempty"`
+	Links   []JRDLink `json:"links"`
+}
+
+// JRDLink es un link de una respuesta de WebFinger
+type JRDLink struct {
+	Rel  string `json:"rel"`
+	Type string `json:"type,omitempty"`
+	Href string `json:"href"`
+}
+
+// ParseAcct separa un recurso "acct:usuario@dominio" (el "acct:" es
+// opcional). ok es false si no tiene esa forma.
+func ParseAcct(resource string) (username string, domain string, ok bool) {
+	resource = strings.TrimPrefix(resource, "acct:")
+	resource = strings.TrimPrefix(resource, "@")
+
+	i := strings.LastIndexByte(resource, '@')
+	if i <= 0 || i == len(resource)-1 {
+		return "", "", false
+	}
+	return resource[:i], resource[i+1:], true
+}
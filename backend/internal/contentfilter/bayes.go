@@ -0,0 +1,127 @@
+package contentfilter
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"tp06-testing/internal/repository"
+)
+
+const (
+	// interestingTokens es cuántas palabras (las que más se inclinan hacia
+	// un lado) se usan para calcular la probabilidad
+	interestingTokens = 15
+	// maxTrainingTokens limita las palabras de un documento
+	maxTrainingTokens = 500
+)
+
+// SpamClassifier es un clasificador bayesiano ingenuo. Aprende de las
+// decisiones de los moderadores (Train) y guarda en el repositorio en
+// cuántos documentos de spam y de ham apareció cada palabra. No decide
+// nada hasta haber visto al menos MinDocuments de cada clase.
+type SpamClassifier struct {
+	repo         repository.ContentFilterRepository
+	HoldAt       float64
+	RejectAt     float64
+	MinDocuments int
+}
+
+// NewSpamClassifier crea el clasificador
+func NewSpamClassifier(repo repository.ContentFilterRepository, holdAt float64, rejectAt float64, minDocuments int) *SpamClassifier {
+	return &SpamClassifier{
+		repo:         repo,
+		HoldAt:       holdAt,
+		RejectAt:     rejectAt,
+		MinDocuments: minDocuments,
+	}
+}
+
+// Name identifica al filtro
+func (c *SpamClassifier) Name() string {
+	return "bayes"
+}
+
+// Check retiene o rechaza el contenido según la probabilidad de spam
+func (c *SpamClassifier) Check(content *Content) (*Decision, error) {
+	probability, trained, err := c.SpamProbability(content.Text)
+	if err != nil || !trained {
+		return nil, err
+	}
+
+	reason := fmt.Sprintf("probabilidad de spam del %.0f%%", probability*100)
+	switch {
+	case probability >= c.RejectAt:
+		return &Decision{Verdict: Reject, Reason: reason}, nil
+	case probability >= c.HoldAt:
+		return &Decision{Verdict: Hold, Reason: reason}, nil
+	}
+	return nil, nil
+}
+
+// SpamProbability calcula la probabilidad de que el texto sea spam.
+// trained es false si todavía no hay documentos suficientes para opinar.
+func (c *SpamClassifier) SpamProbability(text string) (probability float64, trained bool, err error) {
+	docs, err := c.repo.FindDocumentCounts()
+	if err != nil {
+		return 0, false, err
+	}
+	if docs.Spam < c.MinDocuments || docs.Ham < c.MinDocuments {
+		return 0, false, nil
+	}
+
+	counts, err := c.repo.FindTokenCounts(classifierTokens(text))
+	if err != nil {
+		return 0, false, err
+	}
+
+	// Log del cociente P(palabra|spam) / P(palabra|ham) de cada palabra
+	// conocida, con suavizado de Laplace para las que solo se vieron de
+	// un lado
+	ratios := make([]float64, 0, len(counts))
+	for _, count := range counts {
+		pSpam := float64(count.Spam+1) / float64(docs.Spam+2)
+		pHam := float64(count.Ham+1) / float64(docs.Ham+2)
+		ratios = append(ratios, math.Log(pSpam/pHam))
+	}
+
+	sort.Slice(ratios, func(i, j int) bool {
+		if math.Abs(ratios[i]) != math.Abs(ratios[j]) {
+			return math.Abs(ratios[i]) > math.Abs(ratios[j])
+		}
+		return ratios[i] > ratios[j]
+	})
+	if len(ratios) > interestingTokens {
+		ratios = ratios[:interestingTokens]
+	}
+
+	logOdds := math.Log(float64(docs.Spam) / float64(docs.Ham))
+	for _, ratio := range ratios {
+		logOdds += ratio
+	}
+
+	return 1 / (1 + math.Exp(-logOdds)), true, nil
+}
+
+// Train suma el texto como ejemplo de spam o de ham
+func (c *SpamClassifier) Train(text string, spam bool) error {
+	return c.repo.Train(classifierTokens(text), spam)
+}
+
+// classifierTokens devuelve las palabras normalizadas del texto, sin
+// repetir y descartando las de una letra
+func classifierTokens(text string) []string {
+	seen := make(map[string]bool)
+	var tokens []string
+	for _, token := range Tokens(text) {
+		if len([]rune(token)) < 2 || seen[token] {
+			continue
+		}
+		seen[token] = true
+		tokens = append(tokens, token)
+		if len(tokens) == maxTrainingTokens {
+			break
+		}
+	}
+	return tokens
+}
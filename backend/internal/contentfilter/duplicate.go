@@ -0,0 +1,62 @@
+package contentfilter
+
+import (
+	"time"
+
+	"tp06-testing/internal/repository"
+)
+
+// recentContentLimit es cuántos textos recientes del autor se comparan
+const recentContentLimit = 50
+
+// DuplicateFilter rechaza el contenido que el mismo autor ya publicó hace
+// poco (dentro de Window), comparando el texto normalizado. Los textos
+// cortos (menos de MinLength caracteres) no cuentan: "gracias!" se repite
+// sin ser spam.
+type DuplicateFilter struct {
+	repo      repository.ContentFilterRepository
+	Window    time.Duration
+	MinLength int
+	Now       func() time.Time
+}
+
+// NewDuplicateFilter crea el filtro
+func NewDuplicateFilter(repo repository.ContentFilterRepository, window time.Duration, minLength int) *DuplicateFilter {
+	return &DuplicateFilter{
+		repo:      repo,
+		Window:    window,
+		MinLength: minLength,
+		Now:       time.Now,
+	}
+}
+
+// Name identifica al filtro
+func (f *DuplicateFilter) Name() string {
+	return "duplicates"
+}
+
+// Check busca el mismo texto entre lo último que escribió el autor
+func (f *DuplicateFilter) Check(content *Content) (*Decision, error) {
+	normalized := Normalize(content.Text)
+	if len([]rune(normalized)) < f.MinLength {
+		return nil, nil
+	}
+
+	// Un post que se edita no se compara consigo mismo
+	editing := 0
+	if content.Kind == KindPost {
+		editing = content.PostID
+	}
+
+	texts, err := f.repo.FindRecentContent(content.AuthorID, f.Now().Add(-f.Window), editing, recentContentLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, text := range texts {
+		if Normalize(text) == normalized {
+			return &Decision{Verdict: Reject, Reason: "ya publicaste este mismo contenido"}, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,95 @@
+// Package contentfilter revisa el contenido nuevo o editado (posts y
+// comentarios) antes de guardarlo: palabras prohibidas, cantidad de links
+// de las cuentas nuevas, contenido repetido y un clasificador bayesiano de
+// spam que aprende de las decisiones de los moderadores.
+//
+// Cada filtro devuelve una decisión (publicar, retener para revisión o
+// rechazar) y Chain los combina: el primer rechazo corta la revisión; si
+// ninguno rechaza, gana la primera retención.
+package contentfilter
+
+import "time"
+
+// Decisiones posibles, de menor a mayor gravedad
+const (
+	Allow  = "allow"  // Se publica normalmente
+	Hold   = "hold"   // Se guarda oculto hasta que lo revise un moderador
+	Reject = "reject" // No se guarda
+)
+
+// Tipos de contenido
+const (
+	KindPost    = "post"
+	KindComment = "comment"
+)
+
+// Content es lo que se revisa: el texto (título y contenido de un post, o
+// el comentario) y los datos del autor que necesitan los filtros
+type Content struct {
+	Kind            string
+	AuthorID        int
+	AuthorCreatedAt time.Time
+	PostID          int // Post que se edita o en el que se comenta (0 si es un post nuevo)
+	Text            string
+}
+
+// Decision es el resultado de revisar un contenido
+type Decision struct {
+	Verdict string `json:"verdict"`
+	Filter  string `json:"filter,omitempty"` // Filtro que decidió
+	Reason  string `json:"reason,omitempty"` // Motivo legible
+}
+
+// ContentFilter es un filtro de la cadena. Check devuelve nil si el
+// contenido le parece bien.
+type ContentFilter interface {
+	Name() string
+	Check(content *Content) (*Decision, error)
+}
+
+// Chain corre varios filtros en orden
+type Chain struct {
+	filters []ContentFilter
+}
+
+// NewChain crea una cadena con los filtros dados
+func NewChain(filters ...ContentFilter) *Chain {
+	return &Chain{filters: filters}
+}
+
+// Name identifica a la cadena (Chain también es un ContentFilter)
+func (c *Chain) Name() string {
+	return "chain"
+}
+
+// Check corre los filtros hasta el primer rechazo. Si ninguno rechaza
+// devuelve la primera retención; si nadie objeta, Allow. Nunca devuelve
+// nil.
+func (c *Chain) Check(content *Content) (*Decision, error) {
+	var held *Decision
+
+	for _, filter := range c.filters {
+		decision, err := filter.Check(content)
+		if err != nil {
+			return nil, err
+		}
+		if decision == nil || decision.Verdict == Allow {
+			continue
+		}
+
+		if decision.Filter == "" {
+			decision.Filter = filter.Name()
+		}
+		if decision.Verdict == Reject {
+			return decision, nil
+		}
+		if held == nil {
+			held = decision
+		}
+	}
+
+	if held != nil {
+		return held, nil
+	}
+	return &Decision{Verdict: Allow}, nil
+}
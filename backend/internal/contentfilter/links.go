@@ -0,0 +1,54 @@
+package contentfilter
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// linkRe reconoce links con esquema, que empiezan con www. o que son un
+// dominio suelto con una terminación común (ejemplo.com/oferta)
+var linkRe = regexp.MustCompile(`(?i)\bhttps?://\S+|\bwww\.\S+|\b[a-z0-9-]+\.(?:com|net|org|info|biz|io|xyz|ru|top|shop)\b\S*`)
+
+// LinkFilter retiene el contenido de las cuentas nuevas (creadas hace
+// menos de NewAccountAge) que tenga más de MaxLinks links: es la forma más
+// común de spam en un blog.
+type LinkFilter struct {
+	MaxLinks      int
+	NewAccountAge time.Duration
+	Now           func() time.Time
+}
+
+// NewLinkFilter crea el filtro
+func NewLinkFilter(maxLinks int, newAccountAge time.Duration) *LinkFilter {
+	return &LinkFilter{
+		MaxLinks:      maxLinks,
+		NewAccountAge: newAccountAge,
+		Now:           time.Now,
+	}
+}
+
+// CountLinks cuenta los links del texto
+func CountLinks(text string) int {
+	return len(linkRe.FindAllStringIndex(text, -1))
+}
+
+// Name identifica al filtro
+func (f *LinkFilter) Name() string {
+	return "links"
+}
+
+// Check retiene el contenido si la cuenta es nueva y se pasa de links
+func (f *LinkFilter) Check(content *Content) (*Decision, error) {
+	if f.Now().Sub(content.AuthorCreatedAt) >= f.NewAccountAge {
+		return nil, nil
+	}
+
+	if links := CountLinks(content.Text); links > f.MaxLinks {
+		return &Decision{
+			Verdict: Hold,
+			Reason:  fmt.Sprintf("cuenta nueva con %d links (máximo %d)", links, f.MaxLinks),
+		}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,131 @@
+package contentfilter
+
+import (
+	"strings"
+	"unicode"
+)
+
+// foldedRunes lleva letras con tilde o de otros alfabetos que se ven
+// iguales a una latina (homoglifos) a su letra base. Las tildes escritas
+// como carácter combinante aparte se descartan directamente.
+var foldedRunes = map[rune]rune{
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a', 'ā': 'a', 'ą': 'a',
+	'ç': 'c', 'ć': 'c', 'č': 'c',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e', 'ē': 'e', 'ę': 'e', 'ě': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i', 'ī': 'i', 'ı': 'i',
+	'ñ': 'n', 'ń': 'n', 'ň': 'n',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o', 'ø': 'o', 'ō': 'o',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u', 'ū': 'u', 'ů': 'u',
+	'ý': 'y', 'ÿ': 'y',
+	'ł': 'l', 'ś': 's', 'š': 's', 'ź': 'z', 'ż': 'z', 'ž': 'z', 'ř': 'r', 'ß': 's',
+	// Cirílico
+	'а': 'a', 'в': 'b', 'е': 'e', 'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o',
+	'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'і': 'i', 'ј': 'j', 'ѕ': 's',
+	// Griego
+	'α': 'a', 'β': 'b', 'ε': 'e', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o', 'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x',
+}
+
+// leetRunes son los números y símbolos que se usan en lugar de letras
+// ("v14gr4", "$pam"). Solo se reemplazan dentro de palabras que tienen
+// alguna letra, así "2024" sigue siendo un número.
+var leetRunes = map[rune]rune{
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '@': 'a', '$': 's',
+}
+
+// Normalize lleva un texto a una forma canónica para comparar palabras
+// aunque se escriban con variantes: minúsculas, sin tildes, sin
+// caracteres invisibles, con los homoglifos y el "leet" reemplazados por
+// su letra, sin letras repetidas ("spaaam" = "spam") y con las letras
+// sueltas unidas ("v i a g r a" = "viagra"). Devuelve las palabras
+// separadas por un espacio.
+func Normalize(text string) string {
+	return strings.Join(Tokens(text), " ")
+}
+
+// Tokens devuelve las palabras normalizadas del texto (ver Normalize)
+func Tokens(text string) []string {
+	var tokens []string
+	for _, field := range strings.FieldsFunc(text, isSeparator) {
+		if token := normalizeWord(field); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return joinSingleLetters(tokens)
+}
+
+// isSeparator indica si el carácter separa palabras. Los símbolos del
+// leet no separan para poder reemplazarlos después.
+func isSeparator(r rune) bool {
+	if _, ok := leetRunes[r]; ok {
+		return false
+	}
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r) && !unicode.Is(unicode.Cf, r)
+}
+
+// normalizeWord normaliza una palabra ya separada del texto
+func normalizeWord(word string) string {
+	hasLetter := strings.IndexFunc(word, unicode.IsLetter) >= 0
+
+	var b strings.Builder
+	var last rune
+	for _, r := range word {
+		// Tildes combinantes y caracteres invisibles (zero-width, etc.)
+		if unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Cf, r) {
+			continue
+		}
+		// Formas de ancho completo (ＳＰＡＭ)
+		if r >= 0xFF01 && r <= 0xFF5E {
+			r -= 0xFEE0
+		}
+		r = unicode.ToLower(r)
+		if folded, ok := foldedRunes[r]; ok {
+			r = folded
+		}
+		if leet, ok := leetRunes[r]; ok {
+			if !hasLetter {
+				if r == '@' || r == '$' {
+					continue
+				}
+			} else {
+				r = leet
+			}
+		}
+		if r == last && unicode.IsLetter(r) {
+			continue
+		}
+		b.WriteRune(r)
+		last = r
+	}
+	return b.String()
+}
+
+// joinSingleLetters une las rachas de tres o más letras sueltas
+func joinSingleLetters(tokens []string) []string {
+	joined := tokens[:0:0]
+	run := ""
+	runLength := 0
+
+	flush := func() {
+		if runLength >= 3 {
+			joined = append(joined, normalizeWord(run))
+		} else {
+			for _, r := range run {
+				joined = append(joined, string(r))
+			}
+		}
+		run, runLength = "", 0
+	}
+
+	for _, token := range tokens {
+		if len([]rune(token)) == 1 && unicode.IsLetter([]rune(token)[0]) {
+			run += token
+			runLength++
+			continue
+		}
+		flush()
+		joined = append(joined, token)
+	}
+	flush()
+
+	return joined
+}
@@ -0,0 +1,94 @@
+package contentfilter
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// WordFilter busca palabras o frases prohibidas. Las de reject hacen
+// rechazar el contenido y las de hold lo retienen para revisión. Listas y
+// texto se comparan normalizados (ver Normalize), así que "V14GR4" coincide
+// con "viagra"; las frases deben aparecer completas y en orden.
+type WordFilter struct {
+	reject []string
+	hold   []string
+}
+
+// NewWordFilter crea el filtro con las dos listas
+func NewWordFilter(reject []string, hold []string) *WordFilter {
+	return &WordFilter{
+		reject: normalizeList(reject),
+		hold:   normalizeList(hold),
+	}
+}
+
+// normalizeList normaliza las entradas y descarta las vacías
+func normalizeList(words []string) []string {
+	var normalized []string
+	for _, word := range words {
+		if word = Normalize(word); word != "" {
+			normalized = append(normalized, word)
+		}
+	}
+	return normalized
+}
+
+// LoadWordList lee una lista de palabras o frases, una por línea. Ignora
+// las líneas vacías y las que empiezan con #. Una ruta vacía devuelve una
+// lista vacía.
+func LoadWordList(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			words = append(words, line)
+		}
+	}
+
+	return words, scanner.Err()
+}
+
+// Name identifica al filtro
+func (f *WordFilter) Name() string {
+	return "words"
+}
+
+// Check busca primero las palabras que rechazan y después las que retienen
+func (f *WordFilter) Check(content *Content) (*Decision, error) {
+	if len(f.reject) == 0 && len(f.hold) == 0 {
+		return nil, nil
+	}
+
+	text := " " + Normalize(content.Text) + " "
+
+	if findWord(text, f.reject) != "" {
+		return &Decision{Verdict: Reject, Reason: "contiene palabras no permitidas"}, nil
+	}
+	if word := findWord(text, f.hold); word != "" {
+		return &Decision{Verdict: Hold, Reason: "contiene la palabra \"" + word + "\""}, nil
+	}
+	return nil, nil
+}
+
+// findWord devuelve la primera palabra de la lista que aparece completa en
+// text (normalizado y rodeado de espacios)
+func findWord(text string, words []string) string {
+	for _, word := range words {
+		if strings.Contains(text, " "+word+" ") {
+			return word
+		}
+	}
+	return ""
+}
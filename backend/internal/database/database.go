@@ -2,15 +2,22 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
 // InitDB inicializa la base de datos SQLite
 func InitDB(filepath string) (*sql.DB, error) {
-	// Abrir conexión a SQLite
-	db, err := sql.Open("sqlite3", filepath)
+	// Abrir conexión a SQLite. SQLite no aplica las FOREIGN KEY (ni sus
+	// ON DELETE CASCADE) salvo que se active en cada conexión.
+	dsn := filepath + "?_foreign_keys=on"
+	if strings.Contains(filepath, "?") {
+		dsn = filepath + "&_foreign_keys=on"
+	}
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, err
 	}
@@ -38,6 +45,7 @@ func createTables(db *sql.DB) error {
 		email TEXT UNIQUE NOT NULL,
 		password TEXT NOT NULL,
 		username TEXT NOT NULL,
+		role TEXT NOT NULL DEFAULT 'user',
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 	);
 
@@ -47,7 +55,17 @@ func createTables(db *sql.DB) error {
 		title TEXT NOT NULL,
 		content TEXT NOT NULL,
 		user_id INTEGER NOT NULL,
+		slug TEXT,
+		status TEXT NOT NULL DEFAULT 'published',
+		visibility TEXT NOT NULL DEFAULT 'public',
+		published_at DATETIME,
+		comment_count INTEGER NOT NULL DEFAULT 0,
+		last_comment_at DATETIME,
+		hidden_at DATETIME,
+		deleted_at DATETIME,
+		deleted_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 	);
 
@@ -57,17 +75,485 @@ func createTables(db *sql.DB) error {
 		post_id INTEGER NOT NULL,
 		user_id INTEGER NOT NULL,
 		content TEXT NOT NULL,
+		hidden_at DATETIME,
+		deleted_at DATETIME,
+		deleted_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	-- Slugs anteriores de un post (se mantienen como redirecciones)
+	CREATE TABLE IF NOT EXISTS post_slug_redirects (
+		slug TEXT PRIMARY KEY,
+		post_id INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+	);
+
+	-- Archivos adjuntos (el contenido se guarda en el BlobStore)
+	CREATE TABLE IF NOT EXISTS attachments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		post_id INTEGER,
+		user_id INTEGER NOT NULL,
+		storage_key TEXT UNIQUE NOT NULL,
+		filename TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		width INTEGER NOT NULL DEFAULT 0,
+		height INTEGER NOT NULL DEFAULT 0,
+		processing_status TEXT NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS attachment_variants (
+		attachment_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		storage_key TEXT UNIQUE NOT NULL,
+		content_type TEXT NOT NULL,
+		width INTEGER NOT NULL,
+		height INTEGER NOT NULL,
+		size INTEGER NOT NULL,
+		PRIMARY KEY (attachment_id, name),
+		FOREIGN KEY (attachment_id) REFERENCES attachments(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS post_reactions (
+		post_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (post_id, user_id, type),
+		FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS comment_reactions (
+		comment_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (comment_id, user_id, type),
+		FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS bookmarks (
+		user_id INTEGER NOT NULL,
+		post_id INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, post_id),
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS follows (
+		follower_id INTEGER NOT NULL,
+		followee_id INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (follower_id, followee_id),
+		CHECK (follower_id != followee_id),
+		FOREIGN KEY (follower_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (followee_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	-- Menciones @usuario en posts (comment_id NULL) y comentarios
+	CREATE TABLE IF NOT EXISTS mentions (
+		post_id INTEGER NOT NULL,
+		comment_id INTEGER,
+		user_id INTEGER NOT NULL,
+		char_offset INTEGER NOT NULL,
+		char_length INTEGER NOT NULL,
+		FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+		FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	-- Notificaciones: las sin leer del mismo tipo y post se agrupan (count)
+	CREATE TABLE IF NOT EXISTS notifications (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		actor_id INTEGER NOT NULL,
+		post_id INTEGER,
+		comment_id INTEGER,
+		count INTEGER NOT NULL DEFAULT 1,
+		read_at DATETIME,
 		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (actor_id) REFERENCES users(id) ON DELETE CASCADE,
 		FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+		FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE SET NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS notification_preferences (
+		user_id INTEGER PRIMARY KEY,
+		comments INTEGER NOT NULL DEFAULT 1,
+		replies INTEGER NOT NULL DEFAULT 1,
+		mentions INTEGER NOT NULL DEFAULT 1,
+		follows INTEGER NOT NULL DEFAULT 1,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	-- Webhooks: events son los tipos suscriptos, separados por coma
+	CREATE TABLE IF NOT EXISTS webhooks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		events TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	-- Cola persistente de entregas de webhooks y registro de resultados
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		webhook_id INTEGER NOT NULL,
+		event_type TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at DATETIME,
+		response_status INTEGER,
+		last_error TEXT NOT NULL DEFAULT '',
+		redelivery_of INTEGER,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		delivered_at DATETIME,
+		FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE,
+		FOREIGN KEY (redelivery_of) REFERENCES webhook_deliveries(id) ON DELETE SET NULL
+	);
+
+	-- Claves RSA con las que los usuarios firman sus actividades de ActivityPub
+	CREATE TABLE IF NOT EXISTS actor_keys (
+		user_id INTEGER PRIMARY KEY,
+		private_key_pem TEXT NOT NULL,
+		public_key_pem TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 	);
 
+	-- Actores de otros servidores; cada uno tiene un usuario local (sin
+	-- contraseña) con el que sigue autores y comenta
+	CREATE TABLE IF NOT EXISTS remote_actors (
+		user_id INTEGER PRIMARY KEY,
+		uri TEXT UNIQUE NOT NULL,
+		key_id TEXT NOT NULL,
+		public_key_pem TEXT NOT NULL,
+		inbox TEXT NOT NULL,
+		shared_inbox TEXT NOT NULL DEFAULT '',
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	-- Respuestas recibidas por ActivityPub que se importaron como comentarios
+	CREATE TABLE IF NOT EXISTS remote_comments (
+		object_uri TEXT PRIMARY KEY,
+		comment_id INTEGER NOT NULL,
+		post_id INTEGER NOT NULL,
+		FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE,
+		FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+	);
+
+	-- Resumen por email: frecuencia y hasta dónde cubrió el último enviado
+	CREATE TABLE IF NOT EXISTS digest_settings (
+		user_id INTEGER PRIMARY KEY,
+		frequency TEXT NOT NULL DEFAULT 'off',
+		last_digest_at DATETIME,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	-- Bloqueos: el bloqueado no puede comentar los posts ni mencionar a
+	-- quien lo bloqueó, y tampoco se le muestra
+	CREATE TABLE IF NOT EXISTS user_blocks (
+		user_id INTEGER NOT NULL,
+		blocked_id INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, blocked_id),
+		CHECK (user_id != blocked_id),
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (blocked_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	-- Silenciados: sus posts y comentarios no se le muestran a quien los silenció
+	CREATE TABLE IF NOT EXISTS user_mutes (
+		user_id INTEGER NOT NULL,
+		muted_id INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, muted_id),
+		CHECK (user_id != muted_id),
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (muted_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	-- Reportes de posts y comentarios (comment_id = 0 si se reporta el
+	-- post). post_id y comment_id no son claves foráneas para que el
+	-- reporte y su historial sobrevivan si el contenido se borra; content
+	-- guarda el texto tal como estaba al reportarlo. reporter_id es NULL
+	-- en los reportes automáticos del filtro de contenido.
+	CREATE TABLE IF NOT EXISTS reports (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		reporter_id INTEGER,
+		post_id INTEGER NOT NULL,
+		comment_id INTEGER NOT NULL DEFAULT 0,
+		author_id INTEGER NOT NULL,
+		reason TEXT NOT NULL,
+		details TEXT NOT NULL DEFAULT '',
+		content TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'open',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		resolved_at DATETIME,
+		FOREIGN KEY (reporter_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE
+	);
+
+	-- Acciones sobre cada reporte (moderator_id NULL = automática)
+	CREATE TABLE IF NOT EXISTS report_actions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		report_id INTEGER NOT NULL,
+		moderator_id INTEGER,
+		action TEXT NOT NULL,
+		note TEXT NOT NULL DEFAULT '',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (report_id) REFERENCES reports(id) ON DELETE CASCADE,
+		FOREIGN KEY (moderator_id) REFERENCES users(id) ON DELETE SET NULL
+	);
+
+	-- Suspensiones de usuarios, con su historial. Una suspensión está
+	-- vigente mientras no se levante (lifted_at) ni venza (expires_at,
+	-- NULL si es permanente).
+	CREATE TABLE IF NOT EXISTS suspensions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		moderator_id INTEGER,
+		reason TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		expires_at DATETIME,
+		lifted_at DATETIME,
+		lifted_by INTEGER,
+		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+		FOREIGN KEY (moderator_id) REFERENCES users(id) ON DELETE SET NULL,
+		FOREIGN KEY (lifted_by) REFERENCES users(id) ON DELETE SET NULL
+	);
+
+	-- Clasificador bayesiano de spam: en cuántos documentos de cada clase
+	-- apareció cada palabra, y cuántos documentos de cada clase se vieron
+	CREATE TABLE IF NOT EXISTS spam_tokens (
+		token TEXT PRIMARY KEY,
+		spam_count INTEGER NOT NULL DEFAULT 0,
+		ham_count INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS spam_documents (
+		label TEXT PRIMARY KEY,
+		count INTEGER NOT NULL DEFAULT 0
+	);
+
 	-- Índices para mejorar rendimiento
+	CREATE INDEX IF NOT EXISTS idx_suspensions_user ON suspensions(user_id, created_at);
 	CREATE INDEX IF NOT EXISTS idx_posts_user_id ON posts(user_id);
 	CREATE INDEX IF NOT EXISTS idx_comments_post_id ON comments(post_id);
 	CREATE INDEX IF NOT EXISTS idx_comments_user_id ON comments(user_id);
+	CREATE INDEX IF NOT EXISTS idx_post_slug_redirects_post_id ON post_slug_redirects(post_id);
+	CREATE INDEX IF NOT EXISTS idx_attachments_post_id ON attachments(post_id);
+	CREATE INDEX IF NOT EXISTS idx_attachments_user_id ON attachments(user_id);
+	CREATE INDEX IF NOT EXISTS idx_post_reactions_user_id ON post_reactions(user_id);
+	CREATE INDEX IF NOT EXISTS idx_comment_reactions_user_id ON comment_reactions(user_id);
+	CREATE INDEX IF NOT EXISTS idx_bookmarks_user_created ON bookmarks(user_id, created_at DESC, post_id DESC);
+	CREATE INDEX IF NOT EXISTS idx_bookmarks_post_id ON bookmarks(post_id);
+	CREATE INDEX IF NOT EXISTS idx_follows_followee ON follows(followee_id, created_at DESC, follower_id DESC);
+	CREATE INDEX IF NOT EXISTS idx_follows_follower ON follows(follower_id, created_at DESC, followee_id DESC);
+	CREATE INDEX IF NOT EXISTS idx_mentions_post ON mentions(post_id, comment_id);
+	CREATE INDEX IF NOT EXISTS idx_mentions_comment ON mentions(comment_id);
+	CREATE INDEX IF NOT EXISTS idx_mentions_user ON mentions(user_id);
+	CREATE INDEX IF NOT EXISTS idx_notifications_user_updated ON notifications(user_id, updated_at DESC, id DESC);
+	CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id, type, post_id) WHERE read_at IS NULL;
+	CREATE INDEX IF NOT EXISTS idx_webhooks_user_id ON webhooks(user_id);
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at DESC, id DESC);
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+	CREATE INDEX IF NOT EXISTS idx_remote_comments_comment ON remote_comments(comment_id);
+	CREATE INDEX IF NOT EXISTS idx_user_blocks_blocked ON user_blocks(blocked_id);
+	CREATE INDEX IF NOT EXISTS idx_user_mutes_muted ON user_mutes(muted_id);
+	CREATE INDEX IF NOT EXISTS idx_digest_settings_due ON digest_settings(last_digest_at) WHERE frequency != 'off';
+	CREATE INDEX IF NOT EXISTS idx_comments_post_created ON comments(post_id, created_at);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_reports_open_reporter ON reports(post_id, comment_id, reporter_id) WHERE status = 'open';
+	CREATE INDEX IF NOT EXISTS idx_reports_status_created ON reports(status, created_at, id);
+	CREATE INDEX IF NOT EXISTS idx_report_actions_report ON report_actions(report_id, id);
 	`
 
-	_, err := db.Exec(schema)
-	return err
+	if _, err := db.Exec(schema); err != nil {
+		return err
+	}
+
+	return migrate(db)
+}
+
+// migrate agrega a una base existente las columnas nuevas que
+// CREATE TABLE IF NOT EXISTS no modifica
+func migrate(db *sql.DB) error {
+	if _, err := addColumnIfMissing(db, "posts", "slug", "TEXT"); err != nil {
+		return err
+	}
+
+	// Los posts anteriores a los slugs reciben uno basado en su ID
+	if _, err := db.Exec(`UPDATE posts SET slug = 'post-' || id WHERE slug IS NULL OR slug = ''`); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_posts_slug ON posts(slug)`); err != nil {
+		return err
+	}
+
+	if _, err := addColumnIfMissing(db, "posts", "status", "TEXT NOT NULL DEFAULT 'published'"); err != nil {
+		return err
+	}
+	if _, err := addColumnIfMissing(db, "posts", "published_at", "DATETIME"); err != nil {
+		return err
+	}
+
+	// Los posts que ya eran públicos se consideran publicados al crearse
+	if _, err := db.Exec(`UPDATE posts SET published_at = created_at WHERE status = 'published' AND published_at IS NULL`); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_posts_status_published_at ON posts(status, published_at)`); err != nil {
+		return err
+	}
+	// Para el feed: los posts publicados de cada autor seguido, en orden
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_posts_user_feed ON posts(user_id, status, published_at DESC, id DESC)`); err != nil {
+		return err
+	}
+
+	// Contadores desnormalizados de comentarios
+	addedCount, err := addColumnIfMissing(db, "posts", "comment_count", "INTEGER NOT NULL DEFAULT 0")
+	if err != nil {
+		return err
+	}
+	if _, err := addColumnIfMissing(db, "posts", "last_comment_at", "DATETIME"); err != nil {
+		return err
+	}
+
+	// Fecha de la última edición (para los feeds); los posts anteriores
+	// toman la de creación
+	if _, err := addColumnIfMissing(db, "posts", "updated_at", "DATETIME"); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`UPDATE posts SET updated_at = created_at WHERE updated_at IS NULL`); err != nil {
+		return err
+	}
+
+	// Dimensiones y estado del procesamiento de imágenes adjuntas
+	if _, err := addColumnIfMissing(db, "attachments", "width", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if _, err := addColumnIfMissing(db, "attachments", "height", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if _, err := addColumnIfMissing(db, "attachments", "processing_status", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_attachments_processing ON attachments(processing_status)`); err != nil {
+		return err
+	}
+
+	// Moderación: rol de los usuarios y contenido oculto por reportes
+	if _, err := addColumnIfMissing(db, "users", "role", "TEXT NOT NULL DEFAULT 'user'"); err != nil {
+		return err
+	}
+	if _, err := addColumnIfMissing(db, "posts", "hidden_at", "DATETIME"); err != nil {
+		return err
+	}
+	if _, err := addColumnIfMissing(db, "comments", "hidden_at", "DATETIME"); err != nil {
+		return err
+	}
+
+	// Papelera: los posts y comentarios borrados se conservan hasta que
+	// vence el plazo de restauración
+	for _, table := range []string{"posts", "comments"} {
+		if _, err := addColumnIfMissing(db, table, "deleted_at", "DATETIME"); err != nil {
+			return err
+		}
+		if _, err := addColumnIfMissing(db, table, "deleted_by", "INTEGER REFERENCES users(id) ON DELETE SET NULL"); err != nil {
+			return err
+		}
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_posts_deleted ON posts(deleted_at) WHERE deleted_at IS NOT NULL`); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_comments_deleted ON comments(deleted_at) WHERE deleted_at IS NOT NULL`); err != nil {
+		return err
+	}
+
+	if addedCount {
+		// Primera vez que hay contadores: se calculan a partir de los
+		// comentarios existentes con el mismo criterio que
+		// SQLitePostRepository.RecountComments (sin ocultos ni borrados), por
+		// eso va después de agregar hidden_at y deleted_at
+		if _, err := db.Exec(`
+			UPDATE posts SET
+				comment_count = (SELECT COUNT(*) FROM comments c WHERE c.post_id = posts.id AND c.hidden_at IS NULL AND c.deleted_at IS NULL),
+				last_comment_at = (SELECT MAX(c.created_at) FROM comments c WHERE c.post_id = posts.id AND c.hidden_at IS NULL AND c.deleted_at IS NULL)
+		`); err != nil {
+			return err
+		}
+	}
+
+	// Visibilidad de los posts: los existentes quedan públicos
+	if _, err := addColumnIfMissing(db, "posts", "visibility", "TEXT NOT NULL DEFAULT 'public'"); err != nil {
+		return err
+	}
+
+	// Los nombres de usuario son únicos sin distinguir mayúsculas (así los
+	// buscan las menciones y WebFinger). Si ya había repetidos, el más
+	// antiguo conserva el nombre, que es al que apuntaban las menciones, y
+	// los demás reciben su ID como sufijo.
+	if _, err := db.Exec(`
+		UPDATE users SET username = username || '_' || id
+		WHERE id NOT IN (SELECT MIN(id) FROM users GROUP BY username COLLATE NOCASE)
+	`); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users(username COLLATE NOCASE)`); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// addColumnIfMissing ejecuta ALTER TABLE solo si la columna no existe.
+// Devuelve true si la columna se agregó.
+func addColumnIfMissing(db *sql.DB, table, column, definition string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return false, nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return false, err
+	}
+	return true, nil
 }
@@ -0,0 +1,166 @@
+// Package events distribuye en tiempo real lo que pasa en el blog (posts
+// y comentarios creados o borrados) a los clientes conectados por
+// streaming.
+package events
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Tipos de evento
+const (
+	PostCreated    = "post.created"
+	PostDeleted    = "post.deleted"
+	CommentCreated = "comment.created"
+	CommentDeleted = "comment.deleted"
+)
+
+// Event es un evento ya serializado, listo para enviar
+type Event struct {
+	ID     uint64
+	Type   string
+	PostID int    // Post al que se refiere (para filtrar por tema)
+	Data   []byte // Payload en JSON
+}
+
+// Broker recibe los eventos publicados y los reparte entre las
+// suscripciones. Guarda los últimos en un buffer circular para que un
+// cliente que se reconecta pueda retomar desde el último que recibió.
+//
+// Los IDs son crecientes y arrancan en la hora de inicio en nanosegundos:
+// un ID de antes de reiniciar el servidor siempre es menor que los del
+// buffer, así que se detecta que no se puede retomar.
+type Broker struct {
+	mu      sync.Mutex
+	lastID  uint64
+	history []*Event // Buffer circular
+	next    int      // Posición donde se escribe el próximo evento
+	count   int      // Cantidad de eventos guardados (hasta len(history))
+
+	subscriberBuffer int
+	subscribers      map[*Subscription]struct{}
+}
+
+// NewBroker crea un broker que recuerda los últimos historySize eventos
+// y deja acumular hasta subscriberBuffer eventos sin leer por suscripción
+func NewBroker(historySize int, subscriberBuffer int) *Broker {
+	if historySize <= 0 {
+		historySize = 1
+	}
+	if subscriberBuffer <= 0 {
+		subscriberBuffer = 1
+	}
+	return &Broker{
+		lastID:           uint64(time.Now().UnixNano()),
+		history:          make([]*Event, historySize),
+		subscriberBuffer: subscriberBuffer,
+		subscribers:      make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscription recibe los eventos de los posts indicados (de todos si no
+// se indicó ninguno). Si el cliente no lee a tiempo y se llena su buffer,
+// el broker cierra el canal: el cliente debe reconectarse y retomar desde
+// el último ID que procesó.
+type Subscription struct {
+	C       <-chan *Event
+	ch      chan *Event
+	postIDs map[int]bool
+	broker  *Broker
+}
+
+// matches indica si el evento corresponde a los temas de la suscripción
+func (s *Subscription) matches(event *Event) bool {
+	return len(s.postIDs) == 0 || s.postIDs[event.PostID]
+}
+
+// Close da de baja la suscripción. Se puede llamar más de una vez.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.remove(s)
+}
+
+// remove saca la suscripción y cierra su canal (con el lock tomado)
+func (b *Broker) remove(sub *Subscription) {
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.ch)
+	}
+}
+
+// Publish serializa data y envía el evento a las suscripciones
+// interesadas. Nunca se bloquea esperando a un cliente lento.
+func (b *Broker) Publish(eventType string, postID int, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event := &Event{ID: b.lastID, Type: eventType, PostID: postID, Data: payload}
+
+	b.history[b.next] = event
+	b.next = (b.next + 1) % len(b.history)
+	if b.count < len(b.history) {
+		b.count++
+	}
+
+	for sub := range b.subscribers {
+		if !sub.matches(event) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			// Cliente lento: se lo desconecta en lugar de frenar al resto
+			b.remove(sub)
+		}
+	}
+
+	return nil
+}
+
+// Subscribe crea una suscripción a los posts indicados. Si lastEventID no
+// es cero devuelve además los eventos posteriores que siguen en el
+// buffer. resumed es false si algunos ya se perdieron: en ese caso no
+// hay historial y el cliente tiene que volver a cargar los datos.
+func (b *Broker) Subscribe(postIDs []int, lastEventID uint64) (sub *Subscription, backlog []*Event, resumed bool) {
+	ch := make(chan *Event, b.subscriberBuffer)
+	sub = &Subscription{C: ch, ch: ch, postIDs: make(map[int]bool), broker: b}
+	for _, id := range postIDs {
+		sub.postIDs[id] = true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	resumed = true
+	if lastEventID != 0 {
+		oldest := b.lastID - uint64(b.count) + 1
+		resumed = lastEventID+1 >= oldest && lastEventID <= b.lastID
+
+		// El historial se envía en orden, del más viejo al más nuevo
+		for i := 0; resumed && i < b.count; i++ {
+			event := b.history[(b.next-b.count+i+len(b.history))%len(b.history)]
+			if event.ID > lastEventID && sub.matches(event) {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+
+	b.subscribers[sub] = struct{}{}
+	return sub, backlog, resumed
+}
+
+// SubscriberCount devuelve la cantidad de suscripciones activas
+func (b *Broker) SubscriberCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers)
+}
@@ -0,0 +1,58 @@
+// Package feeds genera los feeds de sindicación del blog en RSS 2.0,
+// Atom 1.0 y JSON Feed 1.1 a partir de una misma descripción.
+package feeds
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Feed es un feed independiente del formato
+type Feed struct {
+	ID          string // Identificador permanente (Atom id)
+	Title       string
+	Description string
+	Link        string    // Página del sitio que representa el feed
+	SelfURL     string    // URL del propio feed en el formato que se genera
+	Updated     time.Time // Última modificación de cualquiera de las entradas
+	Items       []*Item
+}
+
+// Item es una entrada del feed
+type Item struct {
+	ID        string // GUID: no cambia aunque se edite el post
+	Title     string
+	Link      string
+	Author    string
+	Summary   string // Texto plano
+	Content   string // HTML; cada formato lo escapa como corresponde
+	Published time.Time
+	Updated   time.Time
+}
+
+// TagURI arma un identificador tag: (RFC 4151) para una entidad del sitio,
+// por ejemplo tag:blog.example.com,2025-10-01:post-12. Depende solo del
+// dominio, la fecha de creación y el nombre, así que no cambia al editar.
+func TagURI(siteURL string, created time.Time, specific string) string {
+	host := siteURL
+	if u, err := url.Parse(siteURL); err == nil && u.Host != "" {
+		host = u.Hostname()
+	}
+	return fmt.Sprintf("tag:%s,%s:%s", host, created.UTC().Format("2006-01-02"), specific)
+}
+
+// LatestUpdate devuelve la fecha más reciente de las entradas, o fallback
+// si no hay ninguna
+func LatestUpdate(items []*Item, fallback time.Time) time.Time {
+	latest := time.Time{}
+	for _, item := range items {
+		if item.Updated.After(latest) {
+			latest = item.Updated
+		}
+	}
+	if latest.IsZero() {
+		return fallback
+	}
+	return latest
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"tp06-testing/internal/activitypub"
+	"tp06-testing/internal/services"
+
+	"github.com/gorilla/mux"
+)
+
+// maxInboxSize es el tamaño máximo de una actividad recibida
+const maxInboxSize = 1 << 20
+
+// ActivityPubHandler expone a los usuarios y sus posts por ActivityPub
+type ActivityPubHandler struct {
+	federationService *services.FederationService
+}
+
+// NewActivityPubHandler crea una nueva instancia
+func NewActivityPubHandler(federationService *services.FederationService) *ActivityPubHandler {
+	return &ActivityPubHandler{
+		federationService: federationService,
+	}
+}
+
+// WebFinger maneja GET /.well-known/webfinger?resource=acct:usuario@dominio
+func (h *ActivityPubHandler) WebFinger(w http.ResponseWriter, r *http.Request) {
+	resource := r.URL.Query().Get("resource")
+	if resource == "" {
+		respondWithError(w, http.StatusBadRequest, "falta el parámetro resource")
+		return
+	}
+
+	jrd, err := h.federationService.WebFinger(resource)
+	if err != nil {
+		respondWithFederationError(w, err)
+		return
+	}
+
+	respondWithContentType(w, http.StatusOK, activitypub.ContentTypeJRD, jrd)
+}
+
+// GetActor maneja GET /ap/users/{id}
+func (h *ActivityPubHandler) GetActor(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	actor, err := h.federationService.GetActor(userID)
+	if err != nil {
+		respondWithFederationError(w, err)
+		return
+	}
+
+	respondWithContentType(w, http.StatusOK, activitypub.ContentType, actor)
+}
+
+// GetOutbox maneja GET /ap/users/{id}/outbox y, con ?page=N, sus páginas
+func (h *ActivityPubHandler) GetOutbox(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	var payload interface{}
+	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
+		page, convErr := strconv.Atoi(pageStr)
+		if convErr != nil {
+			respondWithError(w, http.StatusBadRequest, services.ErrInvalidPage)
+			return
+		}
+		payload, err = h.federationService.GetOutboxPage(userID, page)
+	} else {
+		payload, err = h.federationService.GetOutbox(userID)
+	}
+	if err != nil {
+		respondWithFederationError(w, err)
+		return
+	}
+
+	respondWithContentType(w, http.StatusOK, activitypub.ContentType, payload)
+}
+
+// GetFollowers maneja GET /ap/users/{id}/followers
+func (h *ActivityPubHandler) GetFollowers(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	followers, err := h.federationService.GetFollowers(userID)
+	if err != nil {
+		respondWithFederationError(w, err)
+		return
+	}
+
+	respondWithContentType(w, http.StatusOK, activitypub.ContentType, followers)
+}
+
+// PostInbox maneja POST /ap/users/{id}/inbox
+// La actividad tiene que venir firmada (HTTP Signatures) por su actor.
+func (h *ActivityPubHandler) PostInbox(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxInboxSize))
+	if err != nil {
+		respondWithError(w, http.StatusRequestEntityTooLarge, "la actividad es demasiado grande")
+		return
+	}
+
+	if err := h.federationService.HandleInbox(userID, r, body); err != nil {
+		respondWithFederationError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// GetPostObject maneja GET /ap/posts/{id}
+func (h *ActivityPubHandler) GetPostObject(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	object, err := h.federationService.GetPostObject(postID)
+	if err != nil {
+		respondWithFederationError(w, err)
+		return
+	}
+
+	respondWithContentType(w, http.StatusOK, activitypub.ContentType, object)
+}
+
+// respondWithContentType responde JSON con un Content-Type propio
+func respondWithContentType(w http.ResponseWriter, code int, contentType string, payload interface{}) {
+	response, _ := json.Marshal(payload)
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(code)
+	w.Write(response)
+}
+
+// respondWithFederationError traduce los errores de FederationService
+func respondWithFederationError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case services.ErrActorNotFound, services.ErrPostNotFound:
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case services.ErrInvalidSignature:
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+	case services.ErrInvalidActivity, services.ErrInvalidPage:
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+	}
+}
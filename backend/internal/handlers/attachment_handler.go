@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"tp06-testing/internal/services"
+
+	"github.com/gorilla/mux"
+)
+
+// multipartMemory es la parte del formulario que se mantiene en memoria;
+// el resto del archivo se guarda temporalmente en disco
+const multipartMemory = 1 << 20
+
+// AttachmentHandler maneja las peticiones HTTP de archivos adjuntos
+type AttachmentHandler struct {
+	attachmentService *services.AttachmentService
+}
+
+// NewAttachmentHandler crea una nueva instancia
+func NewAttachmentHandler(attachmentService *services.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+	}
+}
+
+// Upload maneja POST /api/uploads (multipart/form-data)
+// Campos: "file" (requerido) y "post_id" (opcional)
+func (h *AttachmentHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	// El límite incluye un margen para los demás campos del formulario
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxUploadSize+multipartMemory)
+	if err := r.ParseMultipartForm(multipartMemory); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, services.ErrFileTooLarge)
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, "formulario inválido")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "el archivo es requerido")
+		return
+	}
+	defer file.Close()
+
+	postID := 0
+	if value := r.FormValue("post_id"); value != "" {
+		postID, err = strconv.Atoi(value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+			return
+		}
+	}
+
+	attachment, err := h.attachmentService.Upload(r.Context(), userID, postID, header.Filename, header.Size, file)
+	if err != nil {
+		if err.Error() == services.ErrFileTooLarge {
+			respondWithError(w, http.StatusRequestEntityTooLarge, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, attachment)
+}
+
+// Download maneja GET /api/attachments/{id}
+// Soporta pedidos parciales (Range) y caché condicional (If-Modified-Since)
+func (h *AttachmentHandler) Download(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	attachment, content, err := h.attachmentService.Open(r.Context(), id, userID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	defer content.Close()
+
+	serveAttachment(w, r, attachment.Filename, attachment.ContentType, attachment.CreatedAt, content)
+}
+
+// DownloadVariant maneja GET /api/attachments/{id}/variants/{name}
+func (h *AttachmentHandler) DownloadVariant(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	variant, content, err := h.attachmentService.OpenVariant(r.Context(), id, vars["name"], userID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	defer content.Close()
+
+	// Las versiones no cambian una vez generadas
+	filename := vars["name"] + path.Ext(variant.StorageKey)
+	serveAttachment(w, r, filename, variant.ContentType, time.Time{}, content)
+}
+
+// GetByPost maneja GET /api/posts/{id}/attachments
+func (h *AttachmentHandler) GetByPost(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	attachments, err := h.attachmentService.GetByPostID(postID, viewerID(r))
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, attachments)
+}
+
+// serveAttachment envía el archivo con los headers de seguridad. Las
+// imágenes se muestran en el navegador; el resto se descarga.
+func serveAttachment(w http.ResponseWriter, r *http.Request, filename, contentType string, modified time.Time, content io.ReadSeeker) {
+	disposition := "attachment"
+	if strings.HasPrefix(contentType, "image/") {
+		disposition = "inline"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+
+	http.ServeContent(w, r, filename, modified, content)
+}
+
+// Delete maneja DELETE /api/attachments/{id}
+func (h *AttachmentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.attachmentService.Delete(r.Context(), id, userID); err != nil {
+		respondWithError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Archivo eliminado"})
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"tp06-testing/internal/models"
@@ -24,8 +25,8 @@ func NewAuthHandler(authService *services.AuthService) *AuthHandler {
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	// Decodificar el body JSON
 	var req models.RegisterRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, "JSON inválido")
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondWithDecodeError(w, err)
 		return
 	}
 
@@ -44,14 +45,19 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	// Decodificar el body JSON
 	var creds models.Credentials
-	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
-		respondWithError(w, http.StatusBadRequest, "JSON inválido")
+	if err := decodeJSON(w, r, &creds); err != nil {
+		respondWithDecodeError(w, err)
 		return
 	}
 
 	// Llamar al servicio
 	user, err := h.authService.Login(&creds)
 	if err != nil {
+		var suspended *services.SuspendedError
+		if errors.As(err, &suspended) {
+			respondWithError(w, http.StatusForbidden, err.Error())
+			return
+		}
 		respondWithError(w, http.StatusUnauthorized, err.Error())
 		return
 	}
@@ -60,6 +66,32 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, user)
 }
 
+// RejectSuspended es un middleware que corta las peticiones de usuarios
+// suspendidos con 403 y el motivo: el X-User-ID que guardó el frontend
+// deja de servir apenas se suspende la cuenta, sin esperar a que vuelva a
+// iniciar sesión.
+func (h *AuthHandler) RejectSuspended(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := viewerID(r)
+		if userID == 0 || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if err := h.authService.CheckSession(userID); err != nil {
+			var suspended *services.SuspendedError
+			if errors.As(err, &suspended) {
+				respondWithError(w, http.StatusForbidden, err.Error())
+				return
+			}
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 // Funciones auxiliares para responder JSON
 
 func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
@@ -72,3 +104,24 @@ func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 func respondWithError(w http.ResponseWriter, code int, message string) {
 	respondWithJSON(w, code, map[string]string{"error": message})
 }
+
+// maxRequestBody es el tamaño máximo del cuerpo JSON de una petición. El
+// contenido de posts y comentarios tiene además su propio límite en el
+// servicio; este evita leer cuerpos enormes antes de poder validarlo.
+const maxRequestBody = 1 << 20
+
+// decodeJSON lee el cuerpo JSON de la petición sin pasar de maxRequestBody
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	return json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody)).Decode(v)
+}
+
+// respondWithDecodeError responde 413 si el cuerpo superó el límite y 400
+// si no es JSON válido
+func respondWithDecodeError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		respondWithError(w, http.StatusRequestEntityTooLarge, ErrBodyTooLarge)
+		return
+	}
+	respondWithError(w, http.StatusBadRequest, ErrInvalidJSON)
+}
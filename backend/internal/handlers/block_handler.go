@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+
+	"github.com/gorilla/mux"
+)
+
+// BlockHandler maneja las peticiones HTTP de bloqueos y silenciados
+type BlockHandler struct {
+	blockService *services.BlockService
+}
+
+// NewBlockHandler crea una nueva instancia
+func NewBlockHandler(blockService *services.BlockService) *BlockHandler {
+	return &BlockHandler{
+		blockService: blockService,
+	}
+}
+
+// Block maneja POST /api/users/{id}/block
+func (h *BlockHandler) Block(w http.ResponseWriter, r *http.Request) {
+	h.change(w, r, h.blockService.Block)
+}
+
+// Unblock maneja DELETE /api/users/{id}/block
+func (h *BlockHandler) Unblock(w http.ResponseWriter, r *http.Request) {
+	h.change(w, r, h.blockService.Unblock)
+}
+
+// Mute maneja POST /api/users/{id}/mute
+func (h *BlockHandler) Mute(w http.ResponseWriter, r *http.Request) {
+	h.change(w, r, h.blockService.Mute)
+}
+
+// Unmute maneja DELETE /api/users/{id}/mute
+func (h *BlockHandler) Unmute(w http.ResponseWriter, r *http.Request) {
+	h.change(w, r, h.blockService.Unmute)
+}
+
+// GetBlocked maneja GET /api/users/me/blocks
+func (h *BlockHandler) GetBlocked(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, h.blockService.GetBlocked)
+}
+
+// GetMuted maneja GET /api/users/me/mutes
+func (h *BlockHandler) GetMuted(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, h.blockService.GetMuted)
+}
+
+// change resuelve los parámetros comunes de bloquear y silenciar
+func (h *BlockHandler) change(w http.ResponseWriter, r *http.Request, change func(userID int, otherID int) (*models.BlockStatus, error)) {
+	otherID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := change(userID, otherID)
+	if err != nil {
+		switch err.Error() {
+		case services.ErrUserNotFound:
+			respondWithError(w, http.StatusNotFound, err.Error())
+		case services.ErrCannotBlockSelf, services.ErrCannotMuteSelf:
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, status)
+}
+
+// list resuelve los listados de bloqueados y silenciados
+func (h *BlockHandler) list(w http.ResponseWriter, r *http.Request, list func(userID int) ([]*models.BlockedUser, error)) {
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	users, err := list(userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, users)
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+
+	"github.com/gorilla/mux"
+)
+
+// BookmarkPost maneja PUT /api/posts/{id}/bookmark
+func (h *PostHandler) BookmarkPost(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.postService.BookmarkPost(postID, userID); err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]bool{"bookmarked": true})
+}
+
+// UnbookmarkPost maneja DELETE /api/posts/{id}/bookmark
+func (h *PostHandler) UnbookmarkPost(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.postService.UnbookmarkPost(postID, userID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]bool{"bookmarked": false})
+}
+
+// GetBookmarks maneja GET /api/users/me/bookmarks?cursor=&limit=
+func (h *PostHandler) GetBookmarks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	cursor, limit, ok := pageParams(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.postService.GetBookmarks(userID, cursor, limit)
+	if err != nil {
+		respondWithPageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// GetFeed maneja GET /api/feed?cursor=&limit=
+func (h *PostHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	cursor, limit, ok := pageParams(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.postService.GetFeed(userID, cursor, limit)
+	if err != nil {
+		respondWithPageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// pageParams lee ?cursor= y ?limit= de un listado paginado. Si limit no
+// es un número responde 400 y devuelve false.
+func pageParams(w http.ResponseWriter, r *http.Request) (string, int, bool) {
+	query := r.URL.Query()
+
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, services.ErrInvalidLimit)
+			return "", 0, false
+		}
+	}
+
+	return query.Get("cursor"), limit, true
+}
+
+// respondWithPageError responde 400 para parámetros de paginación inválidos
+func respondWithPageError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case services.ErrInvalidLimit, models.ErrInvalidCursor:
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+)
+
+// unsubscribePage es la página que se muestra al abrir el link para
+// desuscribirse y después de confirmar
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="es">
+<head><meta charset="utf-8"><title>Resumen por email</title></head>
+<body style="font-family:Arial,Helvetica,sans-serif;max-width:480px;margin:48px auto;color:#222">
+{{- if .Done}}
+<p>Listo, ya no vas a recibir el resumen por email.</p>
+{{- else}}
+<p>¿Dejar de recibir el resumen por email?</p>
+<form method="post" action="{{.Action}}"><button type="submit">Desuscribirme</button></form>
+{{- end}}
+</body>
+</html>
+`))
+
+// DigestHandler maneja la suscripción al resumen por email
+type DigestHandler struct {
+	digestService *services.DigestService
+}
+
+// NewDigestHandler crea una nueva instancia
+func NewDigestHandler(digestService *services.DigestService) *DigestHandler {
+	return &DigestHandler{
+		digestService: digestService,
+	}
+}
+
+// GetSettings maneja GET /api/users/me/digest
+func (h *DigestHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	settings, err := h.digestService.GetSettings(userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, settings)
+}
+
+// UpdateSettings maneja PUT /api/users/me/digest
+func (h *DigestHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	var req models.UpdateDigestSettingsRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondWithDecodeError(w, err)
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	settings, err := h.digestService.UpdateSettings(userID, &req)
+	if err != nil {
+		if err.Error() == services.ErrInvalidDigestFrequency {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, settings)
+}
+
+// UnsubscribePage maneja GET /api/digest/unsubscribe?token=...
+// Solo pide confirmación: los antivirus de correo abren los links de los
+// emails, así que un GET no puede desuscribir.
+func (h *DigestHandler) UnsubscribePage(w http.ResponseWriter, r *http.Request) {
+	userID, err := h.digestService.VerifyUnsubscribeToken(r.URL.Query().Get("token"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// El formulario apunta al mismo link del email, armado de nuevo con el
+	// token firmado, en lugar de reenviar lo que llegó en la URL
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	unsubscribePage.Execute(w, map[string]interface{}{"Action": h.digestService.UnsubscribeURL(userID)})
+}
+
+// Unsubscribe maneja POST /api/digest/unsubscribe?token=...
+// Lo usa el formulario de UnsubscribePage y también el botón de los
+// clientes de correo (List-Unsubscribe-Post, RFC 8058), por eso no pide
+// autenticación: alcanza con el token firmado.
+func (h *DigestHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	if err := h.digestService.Unsubscribe(r.URL.Query().Get("token")); err != nil {
+		if err.Error() == services.ErrInvalidUnsubscribeToken {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	unsubscribePage.Execute(w, map[string]interface{}{"Done": true})
+}
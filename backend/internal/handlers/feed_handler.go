@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"tp06-testing/internal/feeds"
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+
+	"github.com/gorilla/mux"
+)
+
+// feedSize es la cantidad de posts que incluye cada feed
+const feedSize = 20
+
+// FeedHandler genera los feeds RSS, Atom y JSON Feed del blog
+type FeedHandler struct {
+	postService   *services.PostService
+	followService *services.FollowService
+	siteURL       string
+}
+
+// NewFeedHandler crea una nueva instancia. siteURL es la dirección pública
+// del frontend, a la que apuntan los links de los posts.
+func NewFeedHandler(postService *services.PostService, followService *services.FollowService, siteURL string) *FeedHandler {
+	return &FeedHandler{
+		postService:   postService,
+		followService: followService,
+		siteURL:       strings.TrimRight(siteURL, "/"),
+	}
+}
+
+// feedFormats asocia cada extensión con su generador y Content-Type
+var feedFormats = map[string]struct {
+	contentType string
+	render      func(*feeds.Feed) ([]byte, error)
+}{
+	"xml":  {feeds.ContentTypeRSS, feeds.RSS},
+	"atom": {feeds.ContentTypeAtom, feeds.Atom},
+	"json": {feeds.ContentTypeJSON, feeds.JSON},
+}
+
+// GetFeed maneja GET /feed.{xml|atom|json} y GET /users/{id}/feed.{xml|atom|json}
+// Responde 304 si el cliente ya tiene la versión actual (If-None-Match o
+// If-Modified-Since).
+func (h *FeedHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	format, ok := feedFormats[vars["format"]]
+	if !ok {
+		respondWithError(w, http.StatusNotFound, "formato de feed desconocido")
+		return
+	}
+
+	feed := &feeds.Feed{
+		Title:       "Blog",
+		Description: "Los últimos posts del blog",
+		Link:        h.siteURL + "/",
+	}
+	fallback := time.Unix(0, 0)
+
+	idStr, byAuthor := vars["id"]
+	userID := 0
+	if byAuthor {
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+			return
+		}
+
+		profile, err := h.followService.GetProfile(id, 0)
+		if err != nil {
+			respondWithFollowError(w, err)
+			return
+		}
+
+		userID = id
+		feed.Title = "Posts de " + profile.Username
+		feed.Description = "Los últimos posts de " + profile.Username
+		feed.Link = h.siteURL + "/users/" + idStr
+		fallback = profile.CreatedAt
+	}
+
+	var posts []*models.Post
+	var err error
+	if byAuthor {
+		posts, err = h.postService.GetPublicPostsByUser(userID, 0, feedSize)
+	} else {
+		posts, err = h.postService.GetLatestPublicPosts(feedSize)
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	feed.ID = feed.Link
+	feed.SelfURL = requestURL(r)
+	for _, post := range posts {
+		feed.Items = append(feed.Items, h.feedItem(post))
+	}
+	feed.Updated = feeds.LatestUpdate(feed.Items, fallback)
+
+	body, err := format.render(feed)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	http.ServeContent(w, r, "", feed.Updated, bytes.NewReader(body))
+}
+
+// feedItem convierte un post publicado en una entrada del feed. El ID
+// depende solo del ID y la fecha de creación, así que no cambia aunque se
+// edite el título (y con él el slug).
+func (h *FeedHandler) feedItem(post *models.Post) *feeds.Item {
+	published := post.CreatedAt
+	if post.PublishedAt != nil {
+		published = *post.PublishedAt
+	}
+	// Un borrador editado antes de publicarse se considera actualizado al publicarse
+	updated := post.UpdatedAt
+	if updated.Before(published) {
+		updated = published
+	}
+
+	return &feeds.Item{
+		ID:        feeds.TagURI(h.siteURL, post.CreatedAt, "post-"+strconv.Itoa(post.ID)),
+		Title:     post.Title,
+		Link:      h.siteURL + "/posts/" + post.Slug,
+		Author:    post.Username,
+		Summary:   post.Excerpt,
+		Content:   post.ContentHTML,
+		Published: published,
+		Updated:   updated,
+	}
+}
+
+// requestURL reconstruye la URL pública del request
+func requestURL(r *http.Request) string {
+	return requestBaseURL(r) + r.URL.Path
+}
+
+// requestBaseURL devuelve esquema y host con los que se llegó al servidor
+// (detrás de un proxy usa X-Forwarded-Proto)
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// postListOmittedFields no se envían en GET /api/posts salvo que el cliente
+// los pida explícitamente con ?fields= (el listado usa el excerpt)
+var postListOmittedFields = []string{"content", "content_html"}
+
+// respondWithFields responde como respondWithJSON pero aplicando el
+// parámetro ?fields=campo1,campo2 (sparse fieldset). Sin el parámetro se
+// envían todos los campos del modelo salvo los indicados en omitted.
+// payload debe ser un struct, un puntero a struct o un slice de ellos.
+func respondWithFields(w http.ResponseWriter, r *http.Request, code int, payload interface{}, omitted []string) {
+	available := jsonFieldNames(reflect.TypeOf(payload))
+
+	selected, err := parseFields(r.URL.Query().Get("fields"), available, omitted)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var out bytes.Buffer
+	if bytes.HasPrefix(raw, []byte("[")) {
+		var items []map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		out.WriteByte('[')
+		for i, item := range items {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			writeFields(&out, item, selected)
+		}
+		out.WriteByte(']')
+	} else {
+		var item map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &item); err != nil {
+			respondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeFields(&out, item, selected)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(out.Bytes())
+}
+
+// parseFields valida los campos pedidos contra los disponibles
+func parseFields(param string, available []string, omitted []string) ([]string, error) {
+	if strings.TrimSpace(param) == "" {
+		var fields []string
+		for _, f := range available {
+			if !containsString(omitted, f) {
+				fields = append(fields, f)
+			}
+		}
+		return fields, nil
+	}
+
+	requested := map[string]bool{}
+	for _, f := range strings.Split(param, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if !containsString(available, f) {
+			return nil, errors.New("campo desconocido en fields: " + f)
+		}
+		requested[f] = true
+	}
+
+	// Se respeta el orden del modelo, no el del parámetro
+	var fields []string
+	for _, f := range available {
+		if requested[f] {
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
+
+// writeFields escribe un objeto JSON con los campos seleccionados en orden
+func writeFields(out *bytes.Buffer, item map[string]json.RawMessage, fields []string) {
+	out.WriteByte('{')
+	first := true
+	for _, f := range fields {
+		value, ok := item[f]
+		if !ok {
+			continue
+		}
+		if !first {
+			out.WriteByte(',')
+		}
+		first = false
+		key, _ := json.Marshal(f)
+		out.Write(key)
+		out.WriteByte(':')
+		out.Write(value)
+	}
+	out.WriteByte('}')
+}
+
+// jsonFieldNames devuelve los nombres JSON de los campos del struct
+// (atravesando punteros y slices)
+func jsonFieldNames(t reflect.Type) []string {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"tp06-testing/internal/realtime"
+	"tp06-testing/internal/services"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+// LiveHandler maneja las conexiones WebSocket de los hilos de comentarios
+type LiveHandler struct {
+	hub           *realtime.Hub
+	authService   *services.AuthService
+	postService   *services.PostService
+	followService *services.FollowService
+	upgrader      websocket.Upgrader
+}
+
+// NewLiveHandler crea una nueva instancia. allowedOrigins son los
+// orígenes (esquema://host:puerto) desde los que un navegador puede
+// conectarse; sin ninguno solo se acepta el mismo host del servidor.
+func NewLiveHandler(hub *realtime.Hub, authService *services.AuthService, postService *services.PostService, followService *services.FollowService, allowedOrigins []string) *LiveHandler {
+	h := &LiveHandler{
+		hub:           hub,
+		authService:   authService,
+		postService:   postService,
+		followService: followService,
+	}
+	h.upgrader = websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     checkOrigin(allowedOrigins),
+	}
+	return h
+}
+
+// Live maneja GET /api/posts/{id}/live (WebSocket)
+// El navegador no puede mandar headers propios al abrir un WebSocket, así
+// que el usuario puede venir en ?user_id= además de en X-User-ID.
+func (h *LiveHandler) Live(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	if r.Header.Get(HeaderUserID) == "" && r.URL.Query().Get("user_id") != "" {
+		r.Header.Set(HeaderUserID, r.URL.Query().Get("user_id"))
+	}
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	// RejectSuspended ya corrió sin ver el ?user_id=, así que se vuelve a
+	// controlar acá
+	if err := h.authService.CheckSession(userID); err != nil {
+		var suspended *services.SuspendedError
+		if errors.As(err, &suspended) {
+			respondWithError(w, http.StatusForbidden, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	profile, err := h.followService.GetProfile(userID, 0)
+	if err != nil {
+		if err.Error() == services.ErrUserNotFound {
+			respondWithError(w, http.StatusUnauthorized, ErrUserNotAuthenticated)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if _, err := h.postService.GetPostByID(postID, userID); err != nil {
+		if err.Error() == services.ErrPostNotFound {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Si falla, Upgrade ya respondió el error
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	user := realtime.Typer{UserID: profile.ID, Username: profile.Username}
+	if err := h.hub.Join(postID, user, conn); err != nil {
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, err.Error()))
+		conn.Close()
+		log.Printf("No se pudo entrar a la sala del post %d: %v", postID, err)
+	}
+}
+
+// checkOrigin acepta las conexiones sin Origin (clientes que no son
+// navegadores), las del mismo host y las de los orígenes permitidos
+func checkOrigin(allowedOrigins []string) func(r *http.Request) bool {
+	allowed := make(map[string]bool)
+	for _, origin := range allowedOrigins {
+		allowed[strings.ToLower(strings.TrimRight(strings.TrimSpace(origin), "/"))] = true
+	}
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		if allowed[strings.ToLower(origin)] {
+			return true
+		}
+
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+
+	"github.com/gorilla/mux"
+)
+
+// ModerationHandler maneja los reportes y la cola de moderación
+type ModerationHandler struct {
+	moderationService *services.ModerationService
+}
+
+// NewModerationHandler crea una nueva instancia
+func NewModerationHandler(moderationService *services.ModerationService) *ModerationHandler {
+	return &ModerationHandler{
+		moderationService: moderationService,
+	}
+}
+
+// ReportPost maneja POST /api/posts/{id}/report
+func (h *ModerationHandler) ReportPost(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CreateReportRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondWithDecodeError(w, err)
+		return
+	}
+
+	report, err := h.moderationService.ReportPost(postID, &req, userID)
+	if err != nil {
+		respondWithModerationError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, report)
+}
+
+// ReportComment maneja POST /api/posts/{postId}/comments/{commentId}/report
+func (h *ModerationHandler) ReportComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postID, err := strconv.Atoi(vars["postId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+	commentID, err := strconv.Atoi(vars["commentId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CreateReportRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondWithDecodeError(w, err)
+		return
+	}
+
+	report, err := h.moderationService.ReportComment(postID, commentID, &req, userID)
+	if err != nil {
+		respondWithModerationError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, report)
+}
+
+// GetReports maneja GET /api/admin/reports?status=&cursor=&limit=
+func (h *ModerationHandler) GetReports(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	cursor, limit, ok := pageParams(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.moderationService.GetReports(userID, r.URL.Query().Get("status"), cursor, limit)
+	if err != nil {
+		respondWithModerationError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// GetReport maneja GET /api/admin/reports/{id}
+func (h *ModerationHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	reportID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := h.moderationService.GetReport(userID, reportID)
+	if err != nil {
+		respondWithModerationError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, report)
+}
+
+// Resolve maneja POST /api/admin/reports/{id}/resolve
+func (h *ModerationHandler) Resolve(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, h.moderationService.Resolve)
+}
+
+// Dismiss maneja POST /api/admin/reports/{id}/dismiss
+func (h *ModerationHandler) Dismiss(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, h.moderationService.Dismiss)
+}
+
+// Act maneja POST /api/admin/reports/{id}/actions
+func (h *ModerationHandler) Act(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, h.moderationService.Act)
+}
+
+// SuspendUser maneja POST /api/admin/users/{id}/suspend
+// Body: {"reason": "...", "expires_at": "..."} (sin expires_at es permanente)
+func (h *ModerationHandler) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	targetID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.SuspendUserRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondWithDecodeError(w, err)
+		return
+	}
+
+	suspension, err := h.moderationService.SuspendUser(userID, targetID, &req)
+	if err != nil {
+		respondWithModerationError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, suspension)
+}
+
+// LiftSuspension maneja DELETE /api/admin/users/{id}/suspend
+func (h *ModerationHandler) LiftSuspension(w http.ResponseWriter, r *http.Request) {
+	h.suspensions(w, r, h.moderationService.LiftSuspension)
+}
+
+// GetSuspensions maneja GET /api/admin/users/{id}/suspensions
+func (h *ModerationHandler) GetSuspensions(w http.ResponseWriter, r *http.Request) {
+	h.suspensions(w, r, h.moderationService.GetSuspensions)
+}
+
+// suspensions resuelve id y moderador y responde el historial de
+// suspensiones que devuelve la operación
+func (h *ModerationHandler) suspensions(w http.ResponseWriter, r *http.Request, operation func(moderatorID int, userID int) ([]*models.Suspension, error)) {
+	targetID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	suspensions, err := operation(userID, targetID)
+	if err != nil {
+		respondWithModerationError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, suspensions)
+}
+
+// moderate resuelve los parámetros comunes de las decisiones sobre un
+// reporte. El cuerpo es opcional salvo en Act, que necesita la acción.
+func (h *ModerationHandler) moderate(w http.ResponseWriter, r *http.Request, moderate func(moderatorID int, reportID int, req *models.ModerateReportRequest) (*models.Report, error)) {
+	reportID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ModerateReportRequest
+	if err := decodeJSON(w, r, &req); err != nil && !errors.Is(err, io.EOF) {
+		respondWithDecodeError(w, err)
+		return
+	}
+
+	report, err := moderate(userID, reportID, &req)
+	if err != nil {
+		respondWithModerationError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, report)
+}
+
+// respondWithModerationError traduce los errores de ModerationService a
+// códigos HTTP
+func respondWithModerationError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case services.ErrPostNotFound, services.ErrCommentNotFound, services.ErrReportNotFound, services.ErrUserNotFound:
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case services.ErrNotModerator:
+		respondWithError(w, http.StatusForbidden, err.Error())
+	case services.ErrAlreadyReported, services.ErrReportClosed, services.ErrUserNotSuspended:
+		respondWithError(w, http.StatusConflict, err.Error())
+	case services.ErrInvalidReportReason, services.ErrReportDetailsRequired, services.ErrReportDetailsTooLong,
+		services.ErrCannotReportOwn, services.ErrInvalidReportAction, services.ErrInvalidReportStatus,
+		services.ErrSuspensionReasonRequired, services.ErrSuspensionReasonTooLong, services.ErrSuspensionExpiresInPast,
+		services.ErrCannotSuspendModerator:
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondWithPageError(w, err)
+	}
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+
+	"github.com/gorilla/mux"
+)
+
+// NotificationHandler maneja las peticiones HTTP de notificaciones
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+}
+
+// NewNotificationHandler crea una nueva instancia
+func NewNotificationHandler(notificationService *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// GetNotifications maneja GET /api/notifications?unread=true&cursor=&limit=
+func (h *NotificationHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	cursor, limit, ok := pageParams(w, r)
+	if !ok {
+		return
+	}
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	page, err := h.notificationService.GetNotifications(userID, unreadOnly, cursor, limit)
+	if err != nil {
+		respondWithPageError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// GetUnreadCount maneja GET /api/notifications/unread-count
+func (h *NotificationHandler) GetUnreadCount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	count, err := h.notificationService.GetUnreadCount(userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]int{"unread_count": count})
+}
+
+// MarkRead maneja POST /api/notifications/{id}/read
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	notificationID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.notificationService.MarkRead(userID, notificationID); err != nil {
+		if err.Error() == services.ErrNotificationNotFound {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]bool{"read": true})
+}
+
+// MarkAllRead maneja POST /api/notifications/read-all
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	count, err := h.notificationService.MarkAllRead(userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]int{"marked": count})
+}
+
+// GetPreferences maneja GET /api/notifications/preferences
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	prefs, err := h.notificationService.GetPreferences(userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, prefs)
+}
+
+// UpdatePreferences maneja PUT /api/notifications/preferences
+func (h *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	var req models.UpdateNotificationPreferencesRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondWithDecodeError(w, err)
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	prefs, err := h.notificationService.UpdatePreferences(userID, &req)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, prefs)
+}
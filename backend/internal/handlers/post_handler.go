@@ -1,8 +1,9 @@
 package handlers
 
 import (
-	"encoding/json"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"tp06-testing/internal/models"
@@ -18,6 +19,7 @@ const (
 	ErrInvalidUserID        = "User ID inválido"
 	ErrInvalidID            = "ID inválido"
 	ErrInvalidJSON          = "JSON inválido"
+	ErrBodyTooLarge         = "El cuerpo de la petición es demasiado grande"
 )
 
 // PostHandler maneja las peticiones HTTP de posts
@@ -35,8 +37,8 @@ func NewPostHandler(postService *services.PostService) *PostHandler {
 // CreatePost maneja POST /api/posts
 func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 	var req models.CreatePostRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, ErrInvalidJSON)
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondWithDecodeError(w, err)
 		return
 	}
 
@@ -54,6 +56,14 @@ func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 
 	post, err := h.postService.CreatePost(&req, userID)
 	if err != nil {
+		if err.Error() == services.ErrUserSuspended {
+			respondWithError(w, http.StatusForbidden, err.Error())
+			return
+		}
+		if err.Error() == services.ErrSlugConflict {
+			respondWithError(w, http.StatusConflict, err.Error())
+			return
+		}
 		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
@@ -62,14 +72,20 @@ func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetAllPosts maneja GET /api/posts
+// Devuelve el excerpt en lugar del contenido completo; ?fields= permite elegir los campos
+// y ?sort=new|top el orden
 func (h *PostHandler) GetAllPosts(w http.ResponseWriter, r *http.Request) {
-	posts, err := h.postService.GetAllPosts()
+	posts, err := h.postService.GetAllPosts(viewerID(r), r.URL.Query().Get("sort"))
 	if err != nil {
+		if err.Error() == services.ErrInvalidSort {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, posts)
+	respondWithFields(w, r, http.StatusOK, posts, postListOmittedFields)
 }
 
 // GetPostByID maneja GET /api/posts/{id}
@@ -81,12 +97,154 @@ func (h *PostHandler) GetPostByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	post, err := h.postService.GetPostByID(id)
+	post, err := h.postService.GetPostByID(id, viewerID(r))
 	if err != nil {
 		respondWithError(w, http.StatusNotFound, err.Error())
 		return
 	}
 
+	respondWithFields(w, r, http.StatusOK, post, nil)
+}
+
+// GetPostBySlug maneja GET /api/posts/by-slug/{slug}
+// Si el slug es uno anterior responde 301 hacia el permalink actual
+func (h *PostHandler) GetPostBySlug(w http.ResponseWriter, r *http.Request) {
+	slug := mux.Vars(r)["slug"]
+
+	post, err := h.postService.GetPostBySlug(slug, viewerID(r))
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if post.Slug != slug {
+		http.Redirect(w, r, "/api/posts/by-slug/"+url.PathEscape(post.Slug), http.StatusMovedPermanently)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, post)
+}
+
+// UpdatePost maneja PUT /api/posts/{id}
+func (h *PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	var req models.UpdatePostRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondWithDecodeError(w, err)
+		return
+	}
+
+	userIDStr := r.Header.Get(HeaderUserID)
+	if userIDStr == "" {
+		respondWithError(w, http.StatusUnauthorized, ErrUserNotAuthenticated)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidUserID)
+		return
+	}
+
+	post, err := h.postService.UpdatePost(id, &req, userID)
+	if err != nil {
+		if err.Error() == services.ErrUserSuspended {
+			respondWithError(w, http.StatusForbidden, err.Error())
+			return
+		}
+		if err.Error() == services.ErrSlugConflict {
+			respondWithError(w, http.StatusConflict, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, post)
+}
+
+// PublishPost maneja POST /api/posts/{id}/publish
+// El body es opcional: {"publish_at": "..."} programa la publicación
+func (h *PostHandler) PublishPost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	var req models.PublishPostRequest
+	if r.ContentLength != 0 {
+		if err := decodeJSON(w, r, &req); err != nil && err != io.EOF {
+			respondWithDecodeError(w, err)
+			return
+		}
+	}
+
+	userIDStr := r.Header.Get(HeaderUserID)
+	if userIDStr == "" {
+		respondWithError(w, http.StatusUnauthorized, ErrUserNotAuthenticated)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidUserID)
+		return
+	}
+
+	post, err := h.postService.PublishPost(id, &req, userID)
+	if err != nil {
+		respondWithError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, post)
+}
+
+// UnpublishPost maneja POST /api/posts/{id}/unpublish
+func (h *PostHandler) UnpublishPost(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.postService.UnpublishPost)
+}
+
+// ArchivePost maneja POST /api/posts/{id}/archive
+func (h *PostHandler) ArchivePost(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.postService.ArchivePost)
+}
+
+// changeStatus resuelve id y usuario y aplica el cambio de estado indicado
+func (h *PostHandler) changeStatus(w http.ResponseWriter, r *http.Request, change func(postID int, userID int) (*models.Post, error)) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	userIDStr := r.Header.Get(HeaderUserID)
+	if userIDStr == "" {
+		respondWithError(w, http.StatusUnauthorized, ErrUserNotAuthenticated)
+		return
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidUserID)
+		return
+	}
+
+	post, err := change(id, userID)
+	if err != nil {
+		respondWithError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
 	respondWithJSON(w, http.StatusOK, post)
 }
 
@@ -130,8 +288,8 @@ func (h *PostHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req models.CreateCommentRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondWithError(w, http.StatusBadRequest, ErrInvalidJSON)
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondWithDecodeError(w, err)
 		return
 	}
 
@@ -149,6 +307,10 @@ func (h *PostHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 
 	comment, err := h.postService.CreateComment(postID, &req, userID)
 	if err != nil {
+		if err.Error() == services.ErrBlockedByAuthor || err.Error() == services.ErrUserSuspended {
+			respondWithError(w, http.StatusForbidden, err.Error())
+			return
+		}
 		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
@@ -156,7 +318,7 @@ func (h *PostHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusCreated, comment)
 }
 
-// GetComments maneja GET /api/posts/{id}/comments
+// GetComments maneja GET /api/posts/{id}/comments (?sort=new|top)
 func (h *PostHandler) GetComments(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	postID, err := strconv.Atoi(vars["id"])
@@ -165,8 +327,12 @@ func (h *PostHandler) GetComments(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	comments, err := h.postService.GetCommentsByPostID(postID)
+	comments, err := h.postService.GetCommentsByPostID(postID, viewerID(r), r.URL.Query().Get("sort"))
 	if err != nil {
+		if err.Error() == services.ErrInvalidSort {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		respondWithError(w, http.StatusNotFound, err.Error())
 		return
 	}
@@ -207,3 +373,31 @@ func (h *PostHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 
 	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Comentario eliminado"})
 }
+
+// viewerID devuelve el usuario que hace la petición, o 0 si es anónima.
+// Se usa en lecturas donde la autenticación es opcional.
+func viewerID(r *http.Request) int {
+	id, err := strconv.Atoi(r.Header.Get(HeaderUserID))
+	if err != nil || id <= 0 {
+		return 0
+	}
+	return id
+}
+
+// authenticatedUserID obtiene el usuario del header X-User-ID. Si falta o
+// es inválido responde el error correspondiente y devuelve false.
+func authenticatedUserID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	userIDStr := r.Header.Get(HeaderUserID)
+	if userIDStr == "" {
+		respondWithError(w, http.StatusUnauthorized, ErrUserNotAuthenticated)
+		return 0, false
+	}
+
+	userID, err := strconv.Atoi(userIDStr)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidUserID)
+		return 0, false
+	}
+
+	return userID, true
+}
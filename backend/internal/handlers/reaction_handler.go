@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+
+	"github.com/gorilla/mux"
+)
+
+// ReactionHandler maneja las peticiones HTTP de reacciones
+type ReactionHandler struct {
+	reactionService *services.ReactionService
+}
+
+// NewReactionHandler crea una nueva instancia
+func NewReactionHandler(reactionService *services.ReactionService) *ReactionHandler {
+	return &ReactionHandler{
+		reactionService: reactionService,
+	}
+}
+
+// ReactToPost maneja PUT /api/posts/{id}/reactions/{type}
+func (h *ReactionHandler) ReactToPost(w http.ResponseWriter, r *http.Request) {
+	h.postReaction(w, r, h.reactionService.ReactToPost)
+}
+
+// UnreactToPost maneja DELETE /api/posts/{id}/reactions/{type}
+func (h *ReactionHandler) UnreactToPost(w http.ResponseWriter, r *http.Request) {
+	h.postReaction(w, r, h.reactionService.RemovePostReaction)
+}
+
+// ReactToComment maneja PUT /api/posts/{postId}/comments/{commentId}/reactions/{type}
+func (h *ReactionHandler) ReactToComment(w http.ResponseWriter, r *http.Request) {
+	h.commentReaction(w, r, h.reactionService.ReactToComment)
+}
+
+// UnreactToComment maneja DELETE /api/posts/{postId}/comments/{commentId}/reactions/{type}
+func (h *ReactionHandler) UnreactToComment(w http.ResponseWriter, r *http.Request) {
+	h.commentReaction(w, r, h.reactionService.RemoveCommentReaction)
+}
+
+// postReaction resuelve los parámetros comunes de las rutas de posts
+func (h *ReactionHandler) postReaction(w http.ResponseWriter, r *http.Request, apply func(postID int, userID int, reactionType string) (*models.ReactionSummary, error)) {
+	vars := mux.Vars(r)
+	postID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	summary, err := apply(postID, userID, vars["type"])
+	if err != nil {
+		respondWithReactionError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, summary)
+}
+
+// commentReaction resuelve los parámetros comunes de las rutas de comentarios
+func (h *ReactionHandler) commentReaction(w http.ResponseWriter, r *http.Request, apply func(postID int, commentID int, userID int, reactionType string) (*models.ReactionSummary, error)) {
+	vars := mux.Vars(r)
+	postID, err := strconv.Atoi(vars["postId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Post ID inválido")
+		return
+	}
+	commentID, err := strconv.Atoi(vars["commentId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Comment ID inválido")
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	summary, err := apply(postID, commentID, userID, vars["type"])
+	if err != nil {
+		respondWithReactionError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, summary)
+}
+
+// respondWithReactionError elige el código según el error del service
+func respondWithReactionError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case services.ErrInvalidReaction:
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	case services.ErrPostNotFound, services.ErrCommentNotFound, services.ErrUserNotFound:
+		respondWithError(w, http.StatusNotFound, err.Error())
+	default:
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+
+	"github.com/gorilla/mux"
+)
+
+// sitemapNS es el espacio de nombres del protocolo de sitemaps
+const sitemapNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// RobotsConfig configura el contenido de /robots.txt
+type RobotsConfig struct {
+	DisallowAll bool     // No indexar nada (entornos de prueba)
+	Disallow    []string // Rutas que los buscadores no deben recorrer
+}
+
+// SitemapHandler sirve los sitemaps de los posts publicados y robots.txt
+type SitemapHandler struct {
+	sitemapService *services.SitemapService
+	siteURL        string
+	robots         RobotsConfig
+}
+
+// NewSitemapHandler crea una nueva instancia. siteURL es la dirección
+// pública del frontend, a la que apuntan las URLs de los posts.
+func NewSitemapHandler(sitemapService *services.SitemapService, siteURL string, robots RobotsConfig) *SitemapHandler {
+	return &SitemapHandler{
+		sitemapService: sitemapService,
+		siteURL:        strings.TrimRight(siteURL, "/"),
+		robots:         robots,
+	}
+}
+
+// GetSitemap maneja GET /sitemap.xml
+// Si los posts no entran en un solo sitemap responde un índice que apunta
+// a /sitemap-1.xml, /sitemap-2.xml, ...
+func (h *SitemapHandler) GetSitemap(w http.ResponseWriter, r *http.Request) {
+	pages, err := h.sitemapService.PageCount()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if pages == 1 {
+		h.writeURLSet(w, 1)
+		return
+	}
+
+	base := requestBaseURL(r)
+	out := startXML(w)
+	fmt.Fprintf(out, "<sitemapindex xmlns=%q>\n", sitemapNS)
+	for page := 1; page <= pages; page++ {
+		fmt.Fprintf(out, "  <sitemap><loc>%s/sitemap-%d.xml</loc></sitemap>\n", escapeXML(base), page)
+	}
+	fmt.Fprint(out, "</sitemapindex>\n")
+	out.Flush()
+}
+
+// GetSitemapPage maneja GET /sitemap-{page}.xml
+func (h *SitemapHandler) GetSitemapPage(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(mux.Vars(r)["page"])
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, services.ErrSitemapNotFound)
+		return
+	}
+	h.writeURLSet(w, page)
+}
+
+// writeURLSet escribe un sitemap a medida que se leen los posts. El
+// encabezado se escribe con la primera URL (o al final), así un error
+// antes de empezar todavía puede responderse con su código.
+func (h *SitemapHandler) writeURLSet(w http.ResponseWriter, page int) {
+	var out *bufio.Writer
+	start := func() {
+		if out == nil {
+			out = startXML(w)
+			fmt.Fprintf(out, "<urlset xmlns=%q>\n", sitemapNS)
+		}
+	}
+
+	err := h.sitemapService.EachEntry(page, func(entry *models.SitemapEntry) error {
+		start()
+		_, err := fmt.Fprintf(out, "  <url><loc>%s/posts/%s</loc><lastmod>%s</lastmod></url>\n",
+			escapeXML(h.siteURL), escapeXML(url.PathEscape(entry.Slug)), entry.LastModified.UTC().Format(time.RFC3339))
+		return err
+	})
+
+	if err != nil && out == nil {
+		if err.Error() == services.ErrSitemapNotFound {
+			respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err != nil {
+		// Ya se envió parte del sitemap: solo queda cortarlo
+		log.Printf("Error al generar el sitemap %d: %v", page, err)
+		return
+	}
+
+	start()
+	fmt.Fprint(out, "</urlset>\n")
+	out.Flush()
+}
+
+// startXML envía los headers y la declaración XML
+func startXML(w http.ResponseWriter) *bufio.Writer {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.WriteHeader(http.StatusOK)
+
+	out := bufio.NewWriter(w)
+	io.WriteString(out, xml.Header)
+	return out
+}
+
+// escapeXML escapa un texto para incluirlo en un documento XML
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// GetRobots maneja GET /robots.txt
+func (h *SitemapHandler) GetRobots(w http.ResponseWriter, r *http.Request) {
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+
+	if h.robots.DisallowAll {
+		b.WriteString("Disallow: /\n")
+	} else {
+		for _, path := range h.robots.Disallow {
+			if path = strings.TrimSpace(path); path != "" {
+				b.WriteString("Disallow: " + path + "\n")
+			}
+		}
+		b.WriteString("\nSitemap: " + requestBaseURL(r) + "/sitemap.xml\n")
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, b.String())
+}
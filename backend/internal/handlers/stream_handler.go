@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tp06-testing/internal/events"
+)
+
+// streamRetry es cuánto espera el navegador (en ms) antes de reconectarse
+const streamRetry = 3000
+
+// StreamHandler envía los eventos en tiempo real con Server-Sent Events
+type StreamHandler struct {
+	broker    *events.Broker
+	heartbeat time.Duration
+}
+
+// NewStreamHandler crea una nueva instancia. Cada heartbeat se envía un
+// comentario vacío para que proxies y navegadores no corten la conexión.
+func NewStreamHandler(broker *events.Broker, heartbeat time.Duration) *StreamHandler {
+	return &StreamHandler{
+		broker:    broker,
+		heartbeat: heartbeat,
+	}
+}
+
+// Stream maneja GET /api/stream?post_id=1&post_id=2
+// Sin post_id recibe los eventos de todos los posts. Al reconectarse, el
+// navegador manda Last-Event-ID (o ?last_event_id=) y se reenvían los
+// eventos que se perdió; si ya no están en memoria se envía
+// "stream.reset" para que vuelva a cargar los datos.
+func (h *StreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondWithError(w, http.StatusInternalServerError, "streaming no soportado")
+		return
+	}
+
+	postIDs, ok := streamPostIDs(w, r)
+	if !ok {
+		return
+	}
+
+	lastEventID, ok := streamLastEventID(w, r)
+	if !ok {
+		return
+	}
+
+	sub, backlog, resumed := h.broker.Subscribe(postIDs, lastEventID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Sin buffer en nginx
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry)
+	if !resumed {
+		fmt.Fprint(w, "event: stream.reset\ndata: {}\n\n")
+	}
+	for _, event := range backlog {
+		if err := writeEvent(w, event); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, open := <-sub.C:
+			if !open {
+				// El cliente no leía a tiempo: se corta y al reconectarse
+				// retoma desde el último evento que recibió
+				return
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent escribe un evento en formato SSE (el JSON no tiene saltos de línea)
+func writeEvent(w io.Writer, event *events.Event) error {
+	_, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+	return err
+}
+
+// streamPostIDs lee los ?post_id= de la suscripción
+func streamPostIDs(w http.ResponseWriter, r *http.Request) ([]int, bool) {
+	var postIDs []int
+	for _, value := range r.URL.Query()["post_id"] {
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+			return nil, false
+		}
+		postIDs = append(postIDs, id)
+	}
+	return postIDs, true
+}
+
+// streamLastEventID lee el último evento recibido por el cliente (0 si es
+// una conexión nueva)
+func streamLastEventID(w http.ResponseWriter, r *http.Request) (uint64, bool) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return 0, true
+	}
+
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Last-Event-ID inválido")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"tp06-testing/internal/services"
+
+	"github.com/gorilla/mux"
+)
+
+// GetTrash maneja GET /api/users/me/trash
+func (h *PostHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	trash, err := h.postService.GetTrash(userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, trash)
+}
+
+// RestorePost maneja POST /api/posts/{id}/restore
+func (h *PostHandler) RestorePost(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	post, err := h.postService.RestorePost(postID, userID)
+	if err != nil {
+		respondWithRestoreError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, post)
+}
+
+// RestoreComment maneja POST /api/posts/{postId}/comments/{commentId}/restore
+func (h *PostHandler) RestoreComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postID, err := strconv.Atoi(vars["postId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Post ID inválido")
+		return
+	}
+	commentID, err := strconv.Atoi(vars["commentId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Comment ID inválido")
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	comment, err := h.postService.RestoreComment(postID, commentID, userID)
+	if err != nil {
+		respondWithRestoreError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, comment)
+}
+
+// respondWithRestoreError traduce los errores de restaurar de la papelera
+func respondWithRestoreError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case services.ErrPostNotInTrash, services.ErrCommentNotInTrash, services.ErrPostNotFound:
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case services.ErrUserSuspended:
+		respondWithError(w, http.StatusForbidden, err.Error())
+	default:
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+
+	"github.com/gorilla/mux"
+)
+
+// UserHandler maneja las peticiones HTTP de perfiles y seguidores
+type UserHandler struct {
+	followService *services.FollowService
+}
+
+// NewUserHandler crea una nueva instancia
+func NewUserHandler(followService *services.FollowService) *UserHandler {
+	return &UserHandler{
+		followService: followService,
+	}
+}
+
+// GetProfile maneja GET /api/users/{id}
+func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	profile, err := h.followService.GetProfile(userID, viewerID(r))
+	if err != nil {
+		respondWithFollowError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, profile)
+}
+
+// Follow maneja POST /api/users/{id}/follow
+func (h *UserHandler) Follow(w http.ResponseWriter, r *http.Request) {
+	h.changeFollow(w, r, h.followService.Follow)
+}
+
+// Unfollow maneja DELETE /api/users/{id}/follow
+func (h *UserHandler) Unfollow(w http.ResponseWriter, r *http.Request) {
+	h.changeFollow(w, r, h.followService.Unfollow)
+}
+
+// GetFollowers maneja GET /api/users/{id}/followers?cursor=&limit=
+func (h *UserHandler) GetFollowers(w http.ResponseWriter, r *http.Request) {
+	h.listUsers(w, r, h.followService.GetFollowers)
+}
+
+// GetFollowing maneja GET /api/users/{id}/following?cursor=&limit=
+func (h *UserHandler) GetFollowing(w http.ResponseWriter, r *http.Request) {
+	h.listUsers(w, r, h.followService.GetFollowing)
+}
+
+// changeFollow resuelve los parámetros comunes de seguir y dejar de seguir
+func (h *UserHandler) changeFollow(w http.ResponseWriter, r *http.Request, change func(followerID int, followeeID int) (*models.UserProfile, error)) {
+	followeeID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	profile, err := change(userID, followeeID)
+	if err != nil {
+		respondWithFollowError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, profile)
+}
+
+// listUsers resuelve los parámetros comunes de los listados de usuarios
+func (h *UserHandler) listUsers(w http.ResponseWriter, r *http.Request, list func(userID int, viewerID int, cursor string, limit int) (*models.UserPage, error)) {
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	cursor, limit, ok := pageParams(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := list(userID, viewerID(r), cursor, limit)
+	if err != nil {
+		respondWithFollowError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// respondWithFollowError elige el código según el error del service
+func respondWithFollowError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case services.ErrUserNotFound:
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case services.ErrCannotFollowSelf:
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondWithPageError(w, err)
+	}
+}
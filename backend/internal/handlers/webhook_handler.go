@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+
+	"github.com/gorilla/mux"
+)
+
+// WebhookHandler maneja las peticiones HTTP de webhooks
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+}
+
+// NewWebhookHandler crea una nueva instancia
+func NewWebhookHandler(webhookService *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// CreateWebhook maneja POST /api/webhooks
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CreateWebhookRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		respondWithDecodeError(w, err)
+		return
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(&req, userID)
+	if err != nil {
+		respondWithWebhookError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, webhook)
+}
+
+// GetWebhooks maneja GET /api/webhooks
+func (h *WebhookHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	webhooks, err := h.webhookService.GetWebhooks(userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, webhooks)
+}
+
+// DeleteWebhook maneja DELETE /api/webhooks/{id}
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteWebhook(webhookID, userID); err != nil {
+		respondWithWebhookError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Webhook eliminado"})
+}
+
+// GetDeliveries maneja GET /api/webhooks/{id}/deliveries?cursor=&limit=
+func (h *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	cursor, limit, ok := pageParams(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.webhookService.GetDeliveries(webhookID, userID, cursor, limit)
+	if err != nil {
+		respondWithWebhookError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// Redeliver maneja POST /api/webhooks/{id}/deliveries/{deliveryId}/redeliver
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	webhookID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+	deliveryID, err := strconv.Atoi(vars["deliveryId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, ErrInvalidID)
+		return
+	}
+
+	userID, ok := authenticatedUserID(w, r)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(webhookID, deliveryID, userID)
+	if err != nil {
+		respondWithWebhookError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, delivery)
+}
+
+// respondWithWebhookError traduce los errores de WebhookService a códigos HTTP
+func respondWithWebhookError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case services.ErrWebhookNotFound, services.ErrDeliveryNotFound:
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case services.ErrInvalidWebhookURL, services.ErrInvalidWebhookEvents, services.ErrPrivateAddress:
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		respondWithPageError(w, err)
+	}
+}
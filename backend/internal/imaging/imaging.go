@@ -0,0 +1,99 @@
+// Package imaging procesa las imágenes subidas usando solo la biblioteca
+// estándar (image/jpeg, image/png, image/gif): valida sus dimensiones,
+// elimina los metadatos (EXIF, GPS, XMP) y genera versiones reducidas.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+
+	// Registra el decodificador de GIF para image.Decode
+	_ "image/gif"
+)
+
+// MaxPixels es la cantidad máxima de píxeles que se acepta decodificar
+// (40 megapíxeles). Protege contra "decompression bombs": archivos chicos
+// que declaran dimensiones enormes y agotan la memoria al decodificarlos.
+const MaxPixels = 40_000_000
+
+// jpegQuality es la calidad con la que se codifican los JPEG generados
+const jpegQuality = 85
+
+var (
+	// ErrTooManyPixels indica que la imagen supera MaxPixels
+	ErrTooManyPixels = errors.New("la imagen supera el tamaño máximo de 40 megapíxeles")
+
+	// ErrInvalidImage indica que el archivo no se pudo decodificar
+	ErrInvalidImage = errors.New("la imagen está dañada o no es válida")
+)
+
+// Decodable indica si el tipo se puede decodificar (y por lo tanto
+// generar versiones reducidas) con la biblioteca estándar
+func Decodable(contentType string) bool {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+		return true
+	}
+	return false
+}
+
+// Probe lee solo el encabezado de la imagen y devuelve sus dimensiones,
+// rechazando las que superan MaxPixels antes de decodificar nada
+func Probe(data []byte) (width int, height int, err error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, ErrInvalidImage
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return 0, 0, ErrInvalidImage
+	}
+	if int64(config.Width)*int64(config.Height) > MaxPixels {
+		return 0, 0, ErrTooManyPixels
+	}
+	return config.Width, config.Height, nil
+}
+
+// Decode valida las dimensiones, decodifica la imagen (el primer cuadro
+// en los GIF animados) y aplica la orientación EXIF de los JPEG
+func Decode(data []byte) (*image.RGBA, error) {
+	if _, _, err := Probe(data); err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	return orient(toRGBA(img), Orientation(data)), nil
+}
+
+// Encode codifica la imagen en el formato indicado. Los formatos sin
+// codificador propio (GIF) se guardan como PNG para no perder calidad.
+// Devuelve el contenido y el tipo resultante.
+func Encode(img image.Image, contentType string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	if contentType == "image/jpeg" {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/jpeg", nil
+	}
+
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "image/png", nil
+}
+
+// toRGBA copia la imagen a un *image.RGBA con origen en (0, 0)
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+	return dst
+}
@@ -0,0 +1,276 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+)
+
+// StripMetadata elimina los metadatos que pueden revelar datos personales
+// (EXIF con coordenadas GPS y modelo de cámara, XMP, IPTC, comentarios)
+// sin volver a codificar la imagen. Los JPEG rotados por EXIF son la
+// excepción: se re-codifican ya girados para no perder la orientación.
+// Los tipos sin metadatos conocidos se devuelven sin cambios.
+func StripMetadata(contentType string, data []byte) ([]byte, error) {
+	switch contentType {
+	case "image/jpeg":
+		if Orientation(data) > 1 {
+			img, err := Decode(data)
+			if err != nil {
+				return nil, err
+			}
+			out, _, err := Encode(img, "image/jpeg")
+			return out, err
+		}
+		return stripJPEG(data)
+	case "image/png":
+		return stripPNG(data)
+	case "image/webp":
+		return stripWebP(data)
+	}
+	return data, nil
+}
+
+// Marcadores JPEG relevantes
+const (
+	markerSOI  = 0xD8
+	markerEOI  = 0xD9
+	markerSOS  = 0xDA
+	markerAPP1 = 0xE1 // EXIF y XMP
+	markerAPPD = 0xED // Photoshop / IPTC
+	markerCOM  = 0xFE // comentario
+)
+
+// stripJPEG copia los segmentos del encabezado salvo los de metadatos.
+// Desde SOS (inicio de los datos comprimidos) se copia todo sin cambios.
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != markerSOI {
+		return nil, ErrInvalidImage
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return nil, ErrInvalidImage
+		}
+		marker := data[pos+1]
+		if marker == 0xFF { // relleno entre segmentos
+			pos++
+			continue
+		}
+		if marker == markerSOS || marker == markerEOI {
+			return append(out, data[pos:]...), nil
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, ErrInvalidImage
+		}
+
+		switch marker {
+		case markerAPP1, markerAPPD, markerCOM:
+			// se descarta
+		default:
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+
+	return nil, ErrInvalidImage
+}
+
+// pngMetadataChunks son los chunks PNG que solo llevan metadatos
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+// stripPNG copia los chunks salvo los de metadatos. Cada chunk tiene su
+// propio CRC, así que no hace falta recalcular nada.
+func stripPNG(data []byte) ([]byte, error) {
+	const signatureLen = 8
+	if len(data) < signatureLen || string(data[:signatureLen]) != "\x89PNG\r\n\x1a\n" {
+		return nil, ErrInvalidImage
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:signatureLen]...)
+
+	pos := signatureLen
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		chunkType := string(data[pos+4 : pos+8])
+		end := pos + 12 + length // largo + tipo + datos + CRC
+		if length < 0 || end > len(data) {
+			return nil, ErrInvalidImage
+		}
+
+		if !pngMetadataChunks[chunkType] {
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+
+		if chunkType == "IEND" {
+			return out, nil
+		}
+	}
+
+	return nil, ErrInvalidImage
+}
+
+// Flags del chunk VP8X que indican la presencia de metadatos
+const (
+	vp8xFlagEXIF = 0x08
+	vp8xFlagXMP  = 0x04
+)
+
+// stripWebP quita los chunks EXIF y XMP del contenedor RIFF y apaga los
+// flags correspondientes en VP8X
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrInvalidImage
+	}
+
+	out := make([]byte, 12, len(data))
+	copy(out, data[:12])
+
+	pos := 12
+	for pos+8 <= len(data) {
+		chunkType := string(data[pos : pos+4])
+		length := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + length + length%2 // los chunks se alinean a 2 bytes
+		if end > len(data) {
+			return nil, ErrInvalidImage
+		}
+
+		switch chunkType {
+		case "EXIF", "XMP ":
+			// se descarta
+		case "VP8X":
+			start := len(out)
+			out = append(out, data[pos:end]...)
+			if length > 0 {
+				out[start+8] &^= vp8xFlagEXIF | vp8xFlagXMP
+			}
+		default:
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+	}
+
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
+
+// Orientation devuelve la orientación EXIF (1 a 8) de un JPEG, o 1 si no
+// tiene o no se puede leer
+func Orientation(data []byte) int {
+	exif := jpegEXIF(data)
+	if len(exif) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(exif[:4]) {
+	case "II*\x00":
+		order = binary.LittleEndian
+	case "MM\x00*":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(exif[4:]))
+	if ifd+2 > len(exif) {
+		return 1
+	}
+	count := int(order.Uint16(exif[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(exif) {
+			return 1
+		}
+		if order.Uint16(exif[entry:]) == 0x0112 { // tag Orientation (SHORT)
+			value := int(order.Uint16(exif[entry+8:]))
+			if value >= 1 && value <= 8 {
+				return value
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// jpegEXIF devuelve el bloque TIFF del segmento APP1 "Exif" de un JPEG
+func jpegEXIF(data []byte) []byte {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != markerSOI {
+		return nil
+	}
+
+	pos := 2
+	for pos+4 <= len(data) && data[pos] == 0xFF {
+		marker := data[pos+1]
+		if marker == markerSOS || marker == markerEOI {
+			return nil
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil
+		}
+		segment := data[pos+4 : end]
+		if marker == markerAPP1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:]
+		}
+		pos = end
+	}
+	return nil
+}
+
+// orient gira o espeja la imagen según la orientación EXIF para que se
+// vea derecha sin depender de los metadatos
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 { // las orientaciones 5 a 8 intercambian ancho y alto
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // espejo horizontal
+				dx, dy = w-1-x, y
+			case 3: // 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // espejo vertical
+				dx, dy = x, h-1-y
+			case 5: // transpuesta
+				dx, dy = y, x
+			case 6: // 90° horario
+				dx, dy = h-1-y, x
+			case 7: // transversa
+				dx, dy = h-1-y, w-1-x
+			case 8: // 90° antihorario
+				dx, dy = y, w-1-x
+			}
+			si := y*src.Stride + x*4
+			di := dy*dst.Stride + dx*4
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,109 @@
+package imaging
+
+import (
+	"image"
+	"math"
+)
+
+// Fit reduce la imagen para que entre en maxWidth x maxHeight manteniendo
+// la proporción. Nunca agranda: si ya entra, devuelve la misma imagen.
+// Usa un promedio por área (box filter), que da buenos resultados al
+// reducir y evita el "aliasing" de tomar un píxel de cada tanto.
+func Fit(src *image.RGBA, maxWidth, maxHeight int) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	if w <= maxWidth && h <= maxHeight {
+		return src
+	}
+
+	scale := math.Min(float64(maxWidth)/float64(w), float64(maxHeight)/float64(h))
+	dw := max(1, int(math.Round(float64(w)*scale)))
+	dh := max(1, int(math.Round(float64(h)*scale)))
+
+	return resample(src, dw, dh)
+}
+
+// contribution son los píxeles de origen que aportan a un píxel de destino
+type contribution struct {
+	start   int
+	weights []float32
+}
+
+// contributions calcula, para cada posición de destino, qué fracción de
+// cada píxel de origen cubre
+func contributions(srcLen, dstLen int) []contribution {
+	scale := float64(srcLen) / float64(dstLen)
+	result := make([]contribution, dstLen)
+
+	for i := range result {
+		from := float64(i) * scale
+		to := from + scale
+		start := int(from)
+		end := min(srcLen, int(math.Ceil(to)))
+
+		weights := make([]float32, 0, end-start)
+		for j := start; j < end; j++ {
+			overlap := math.Min(to, float64(j+1)) - math.Max(from, float64(j))
+			weights = append(weights, float32(overlap/scale))
+		}
+		result[i] = contribution{start: start, weights: weights}
+	}
+	return result
+}
+
+// resample reduce en dos pasadas (horizontal y vertical). Trabaja sobre
+// valores premultiplicados por alfa, así los bordes transparentes no se
+// oscurecen.
+func resample(src *image.RGBA, dw, dh int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	cols := contributions(sw, dw)
+	rows := contributions(sh, dh)
+
+	// Pasada horizontal: sh filas de dw píxeles
+	tmp := make([]float32, sh*dw*4)
+	for y := 0; y < sh; y++ {
+		srcRow := src.Pix[y*src.Stride:]
+		for x, c := range cols {
+			var r, g, b, a float32
+			for k, weight := range c.weights {
+				p := (c.start + k) * 4
+				r += float32(srcRow[p]) * weight
+				g += float32(srcRow[p+1]) * weight
+				b += float32(srcRow[p+2]) * weight
+				a += float32(srcRow[p+3]) * weight
+			}
+			t := (y*dw + x) * 4
+			tmp[t], tmp[t+1], tmp[t+2], tmp[t+3] = r, g, b, a
+		}
+	}
+
+	// Pasada vertical
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y, c := range rows {
+		for x := 0; x < dw; x++ {
+			var r, g, b, a float32
+			for k, weight := range c.weights {
+				t := ((c.start+k)*dw + x) * 4
+				r += tmp[t] * weight
+				g += tmp[t+1] * weight
+				b += tmp[t+2] * weight
+				a += tmp[t+3] * weight
+			}
+			d := y*dst.Stride + x*4
+			dst.Pix[d] = clampByte(r)
+			dst.Pix[d+1] = clampByte(g)
+			dst.Pix[d+2] = clampByte(b)
+			dst.Pix[d+3] = clampByte(a)
+		}
+	}
+	return dst
+}
+
+func clampByte(v float32) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
@@ -0,0 +1,52 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer no envía nada: guarda cada email como un archivo .eml en un
+// directorio (la "bandeja de salida"), que se puede abrir con cualquier
+// cliente de correo. Sirve para desarrollo y para los tests.
+type FileMailer struct {
+	dir  string
+	from string
+	now  func() time.Time
+}
+
+// NewFileMailer crea el directorio de salida si no existe
+func NewFileMailer(dir string, from string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileMailer{dir: dir, from: from, now: time.Now}, nil
+}
+
+// Send escribe el email de forma atómica (archivo temporal + rename) para
+// que quien lea el directorio nunca encuentre uno a medio escribir
+func (m *FileMailer) Send(ctx context.Context, msg *Message) error {
+	now := m.now()
+	raw, err := Encode(m.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(m.dir, ".mail-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(raw)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	name := now.UTC().Format("20060102-150405") + "-" + randomHex(4) + ".eml"
+	return os.Rename(tmp.Name(), filepath.Join(m.dir, name))
+}
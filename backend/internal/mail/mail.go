@@ -0,0 +1,149 @@
+// Package mail arma y envía emails con versión en texto y en HTML detrás
+// de la interfaz Mailer, con una implementación que los deja como
+// archivos .eml en un directorio (desarrollo y tests) y otra por SMTP.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInvalidMessage se devuelve si falta el destinatario o algún header
+// trae saltos de línea (que permitirían inyectar otros headers)
+var ErrInvalidMessage = errors.New("email inválido")
+
+// Message es un email a un solo destinatario. Headers son headers
+// adicionales, por ejemplo List-Unsubscribe.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	Headers map[string]string
+}
+
+// Mailer define cómo se envían los emails
+// INTERFACE: permite cambiar SMTP por archivos o usar mocks en los tests
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Encode arma el email en formato MIME: multipart/alternative con la
+// parte de texto primero y la de HTML después (la que prefieren los
+// clientes que la soportan). Si el mensaje no tiene HTML se envía solo
+// el texto.
+func Encode(from string, msg *Message, now time.Time) ([]byte, error) {
+	if err := validate(from, msg); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeHeader := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+
+	writeHeader("From", from)
+	writeHeader("To", msg.To)
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	writeHeader("Date", now.Format(time.RFC1123Z))
+	writeHeader("Message-ID", "<"+randomHex(16)+"@"+domainOf(from)+">")
+	writeHeader("MIME-Version", "1.0")
+
+	names := make([]string, 0, len(msg.Headers))
+	for name := range msg.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeHeader(textproto.CanonicalMIMEHeaderKey(name), msg.Headers[name])
+	}
+
+	if msg.HTML == "" {
+		writeHeader("Content-Type", `text/plain; charset="utf-8"`)
+		writeHeader("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, msg.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	boundary := "alt-" + randomHex(12)
+	writeHeader("Content-Type", `multipart/alternative; boundary="`+boundary+`"`)
+	buf.WriteString("\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain", msg.Text},
+		{"text/html", msg.HTML},
+	} {
+		buf.WriteString("--" + boundary + "\r\n")
+		writeHeader("Content-Type", part.contentType+`; charset="utf-8"`)
+		writeHeader("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, part.body); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+
+	return buf.Bytes(), nil
+}
+
+// validate rechaza mensajes sin destinatario y headers con saltos de línea
+func validate(from string, msg *Message) error {
+	if strings.TrimSpace(msg.To) == "" || strings.TrimSpace(from) == "" {
+		return ErrInvalidMessage
+	}
+
+	values := []string{from, msg.To, msg.Subject}
+	for name, value := range msg.Headers {
+		values = append(values, name, value)
+	}
+	for _, value := range values {
+		if strings.ContainsAny(value, "\r\n") {
+			return ErrInvalidMessage
+		}
+	}
+	return nil
+}
+
+// writeQuotedPrintable codifica el cuerpo con saltos de línea CRLF
+func writeQuotedPrintable(buf *bytes.Buffer, body string) error {
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	w := quotedprintable.NewWriter(buf)
+	if _, err := w.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n"))); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// domainOf devuelve el dominio de una dirección ("Blog <no-reply@blog.com>"
+// → "blog.com"), o "localhost" si no lo tiene
+func domainOf(address string) string {
+	address = strings.TrimSuffix(strings.TrimSpace(address), ">")
+	if at := strings.LastIndex(address, "@"); at >= 0 && at < len(address)-1 {
+		return address[at+1:]
+	}
+	return "localhost"
+}
+
+// randomHex genera n bytes aleatorios en hexadecimal
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
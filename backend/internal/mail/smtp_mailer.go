@@ -0,0 +1,112 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPConfig son los datos de conexión al servidor SMTP
+type SMTPConfig struct {
+	Host     string
+	Port     int    // 587 por defecto
+	Username string // Sin usuario no se autentica
+	Password string
+	From     string // Ejemplo: "Blog <no-reply@blog.com>"
+}
+
+// SMTPMailer envía los emails por SMTP. Si el servidor ofrece STARTTLS
+// la conexión se cifra antes de autenticarse.
+type SMTPMailer struct {
+	config SMTPConfig
+	sender string // Dirección de From sin el nombre, para MAIL FROM
+	now    func() time.Time
+}
+
+// NewSMTPMailer crea una nueva instancia
+func NewSMTPMailer(config SMTPConfig) (*SMTPMailer, error) {
+	if config.Host == "" || config.From == "" {
+		return nil, errors.New("SMTP requiere host y remitente")
+	}
+	if config.Port == 0 {
+		config.Port = 587
+	}
+
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return nil, errors.New("remitente de email inválido")
+	}
+
+	return &SMTPMailer{config: config, sender: from.Address, now: time.Now}, nil
+}
+
+// Send abre una conexión por email; el envío se corta si se cancela ctx
+// o pasa un minuto
+func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
+	raw, err := Encode(m.config.From, msg, m.now())
+	if err != nil {
+		return err
+	}
+
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return ErrInvalidMessage
+	}
+
+	addr := net.JoinHostPort(m.config.Host, strconv.Itoa(m.config.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(time.Minute)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.config.Host}); err != nil {
+			return err
+		}
+	}
+	if m.config.Username != "" {
+		// PlainAuth se niega a mandar la contraseña sin TLS salvo a localhost
+		auth := smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(m.sender); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(raw); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
@@ -0,0 +1,76 @@
+package markdown
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"strconv"
+	"sync"
+)
+
+// Renderer convierte Markdown en HTML guardando el resultado por revisión
+// del contenido (hash del texto + Version), para no volver a procesar el
+// mismo texto en cada GetAllPosts. Es seguro para uso concurrente.
+type Renderer struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[[sha256.Size]byte]*list.Element
+	order    *list.List // el más usado recientemente adelante
+}
+
+type cacheEntry struct {
+	key  [sha256.Size]byte
+	html string
+}
+
+// NewRenderer crea un renderer que guarda hasta capacity revisiones
+func NewRenderer(capacity int) *Renderer {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &Renderer{
+		capacity: capacity,
+		entries:  make(map[[sha256.Size]byte]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Render devuelve el HTML sanitizado del contenido, usando el cache si
+// esa revisión ya se había renderizado
+func (r *Renderer) Render(content string) string {
+	key := sha256.Sum256([]byte(strconv.Itoa(Version) + "\x00" + content))
+
+	r.mu.Lock()
+	if el, ok := r.entries[key]; ok {
+		r.order.MoveToFront(el)
+		r.mu.Unlock()
+		return el.Value.(*cacheEntry).html
+	}
+	r.mu.Unlock()
+
+	// Se renderiza fuera del lock para no bloquear a otras peticiones
+	rendered := ToHTML(content)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if el, ok := r.entries[key]; ok {
+		r.order.MoveToFront(el)
+		return rendered
+	}
+
+	r.entries[key] = r.order.PushFront(&cacheEntry{key: key, html: rendered})
+	if r.order.Len() > r.capacity {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.entries, oldest.Value.(*cacheEntry).key)
+	}
+
+	return rendered
+}
+
+// Len devuelve la cantidad de revisiones guardadas
+func (r *Renderer) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.order.Len()
+}
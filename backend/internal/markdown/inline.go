@@ -0,0 +1,632 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	uriAutolinkRe   = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.-]{1,31}:[^\s<>]*)>`)
+	emailAutolinkRe = regexp.MustCompile(`^<([A-Za-z0-9.!#$%&'*+/=?^_{|}~-]+@[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?(?:\.[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?)*)>`)
+)
+
+// inlineNode es una pieza del texto inline: texto plano (ya escapado),
+// HTML generado o una corrida de delimitadores de énfasis (*, _, ~)
+type inlineNode struct {
+	html string
+
+	delim    byte
+	count    int // delimitadores todavía sin emparejar
+	orig     int // largo original de la corrida
+	canOpen  bool
+	canClose bool
+	active   bool
+	opens    string // tags de apertura a emitir después de la corrida
+	closes   string // tags de cierre a emitir antes de la corrida
+}
+
+// maxLinkParens es la cantidad máxima de paréntesis anidados en el destino
+// de un link, el mismo límite que usa la implementación de referencia de
+// CommonMark. Sin límite, un texto con muchos "[a](" sin cerrar obliga a
+// recorrer el resto del contenido por cada uno.
+const maxLinkParens = 32
+
+var entityRe = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+
+// inlineParser interpreta el texto de un bloque. Recuerda lo que ya buscó
+// sin éxito (cierres de corchetes, de código y de títulos) para que el
+// tiempo de interpretación crezca en forma lineal aunque el texto tenga
+// muchas aperturas sin cerrar.
+type inlineParser struct {
+	s string
+
+	// inLink indica que el texto es el de un link: no se arman links
+	// anidados, como en CommonMark
+	inLink bool
+
+	// brackets tiene la posición del ] que cierra cada [ (se calcula
+	// la primera vez que hace falta)
+	brackets map[int]int
+
+	// noCodeCloser guarda, por largo de la corrida de backticks, desde qué
+	// posición ya se sabe que no hay cierre
+	noCodeCloser map[int]int
+
+	// noCloser guarda, por carácter de cierre de un título (", ' o ")"), desde qué
+	// posición ya se sabe que no aparece sin escapar
+	noCloser map[byte]int
+}
+
+func newInlineParser(s string, inLink bool) *inlineParser {
+	return &inlineParser{
+		s:            s,
+		inLink:       inLink,
+		noCodeCloser: map[int]int{},
+		noCloser:     map[byte]int{},
+	}
+}
+
+// renderInline convierte el texto de un bloque en HTML
+func renderInline(s string) string {
+	return newInlineParser(s, false).render()
+}
+
+// render arma el HTML a partir de los nodos ya emparejados
+func (p *inlineParser) render() string {
+	nodes := p.parse()
+	processEmphasis(nodes)
+
+	var b strings.Builder
+	for _, n := range nodes {
+		if n.delim == 0 {
+			b.WriteString(n.html)
+			continue
+		}
+		b.WriteString(n.closes)
+		b.WriteString(strings.Repeat(string(n.delim), n.count))
+		b.WriteString(n.opens)
+	}
+	return b.String()
+}
+
+// parse recorre el texto y arma la lista de nodos
+func (p *inlineParser) parse() []*inlineNode {
+	s := p.s
+	var nodes []*inlineNode
+	var text strings.Builder
+
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, &inlineNode{html: text.String()})
+			text.Reset()
+		}
+	}
+	emit := func(h string) {
+		flush()
+		nodes = append(nodes, &inlineNode{html: h})
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch c {
+		case '\\':
+			if i+1 < len(s) && s[i+1] == '\n' {
+				emit("<br />\n")
+				i = skipSpaces(s, i+2)
+				continue
+			}
+			if i+1 < len(s) && isASCIIPunct(s[i+1]) {
+				text.WriteString(html.EscapeString(s[i+1 : i+2]))
+				i += 2
+				continue
+			}
+			text.WriteByte('\\')
+			i++
+
+		case '`':
+			if code, next, ok := p.parseCodeSpan(i); ok {
+				emit(code)
+				i = next
+				continue
+			}
+			n := runLength(s, i, '`')
+			text.WriteString(s[i : i+n])
+			i += n
+
+		case '*', '_', '~':
+			n := runLength(s, i, c)
+			if c == '~' && n > 2 {
+				text.WriteString(s[i : i+n])
+				i += n
+				continue
+			}
+			flush()
+			nodes = append(nodes, newDelimNode(s, i, n))
+			i += n
+
+		case '!':
+			if i+1 < len(s) && s[i+1] == '[' {
+				if h, next, ok := p.parseLink(i+1, true); ok {
+					emit(h)
+					i = next
+					continue
+				}
+			}
+			text.WriteByte('!')
+			i++
+
+		case '[':
+			if !p.inLink {
+				if h, next, ok := p.parseLink(i, false); ok {
+					emit(h)
+					i = next
+					continue
+				}
+			}
+			text.WriteByte('[')
+			i++
+
+		case '&':
+			// Las entidades válidas (&amp;, &#233;, ...) pasan tal cual
+			if m := entityRe.FindString(s[i:]); m != "" && validEntity(m) {
+				text.WriteString(m)
+				i += len(m)
+				continue
+			}
+			text.WriteString("&amp;")
+			i++
+
+		case '<':
+			if m := uriAutolinkRe.FindStringSubmatch(s[i:]); m != nil {
+				if href, ok := safeURL(m[1], false); ok {
+					emit(`<a href="` + html.EscapeString(href) + `">` + html.EscapeString(m[1]) + `</a>`)
+					i += len(m[0])
+					continue
+				}
+			}
+			if m := emailAutolinkRe.FindStringSubmatch(s[i:]); m != nil {
+				emit(`<a href="mailto:` + html.EscapeString(m[1]) + `">` + html.EscapeString(m[1]) + `</a>`)
+				i += len(m[0])
+				continue
+			}
+			text.WriteString("&lt;")
+			i++
+
+		case '\n':
+			// Dos o más espacios antes del salto de línea generan <br />
+			current := text.String()
+			trimmed := strings.TrimRight(current, " ")
+			text.Reset()
+			text.WriteString(trimmed)
+			if len(current)-len(trimmed) >= 2 {
+				emit("<br />\n")
+			} else {
+				text.WriteByte('\n')
+			}
+			i = skipSpaces(s, i+1)
+
+		default:
+			j := i + 1
+			for j < len(s) && !strings.ContainsRune("\\`*_~![&<\n", rune(s[j])) {
+				j++
+			}
+			text.WriteString(html.EscapeString(s[i:j]))
+			i = j
+		}
+	}
+	flush()
+
+	return nodes
+}
+
+// validEntity indica si la referencia corresponde a un carácter: un nombre
+// conocido de HTML o un código numérico válido
+func validEntity(ref string) bool {
+	name := ref[1 : len(ref)-1]
+	if name[0] != '#' {
+		return html.UnescapeString(ref) != ref
+	}
+
+	var code int64
+	var err error
+	if name[1] == 'x' || name[1] == 'X' {
+		code, err = strconv.ParseInt(name[2:], 16, 32)
+	} else {
+		code, err = strconv.ParseInt(name[1:], 10, 32)
+	}
+	return err == nil && code > 0 && code <= unicode.MaxRune && !(code >= 0xD800 && code <= 0xDFFF)
+}
+
+// newDelimNode calcula si la corrida puede abrir o cerrar énfasis según
+// las reglas de "flanking" de CommonMark
+func newDelimNode(s string, i, n int) *inlineNode {
+	before, after := ' ', ' '
+	if i > 0 {
+		before, _ = utf8.DecodeLastRuneInString(s[:i])
+	}
+	if i+n < len(s) {
+		after, _ = utf8.DecodeRuneInString(s[i+n:])
+	}
+
+	leftFlanking := !unicode.IsSpace(after) &&
+		(!isPunct(after) || unicode.IsSpace(before) || isPunct(before))
+	rightFlanking := !unicode.IsSpace(before) &&
+		(!isPunct(before) || unicode.IsSpace(after) || isPunct(after))
+
+	node := &inlineNode{delim: s[i], count: n, orig: n, active: true}
+	switch s[i] {
+	case '_':
+		node.canOpen = leftFlanking && (!rightFlanking || isPunct(before))
+		node.canClose = rightFlanking && (!leftFlanking || isPunct(after))
+	default:
+		node.canOpen = leftFlanking
+		node.canClose = rightFlanking
+	}
+	return node
+}
+
+// openersKey agrupa los cierres que buscan apertura con las mismas reglas
+type openersKey struct {
+	delim   byte
+	canOpen bool
+	orig    int
+}
+
+// processEmphasis empareja delimitadores de apertura y cierre y agrega
+// los tags <em>, <strong> y <del> correspondientes. Sigue el algoritmo de
+// CommonMark: los delimitadores forman una lista de la que se quitan los
+// ya usados, y para cada tipo de cierre se recuerda hasta dónde no hay
+// aperturas posibles, así nunca se vuelve a recorrer lo ya revisado.
+func processEmphasis(nodes []*inlineNode) {
+	var delims []*inlineNode
+	for _, n := range nodes {
+		if n.delim != 0 {
+			delims = append(delims, n)
+		}
+	}
+
+	prev := make([]int, len(delims))
+	next := make([]int, len(delims))
+	for d := range delims {
+		prev[d] = d - 1
+		next[d] = d + 1
+	}
+	remove := func(d int) {
+		if prev[d] >= 0 {
+			next[prev[d]] = next[d]
+		}
+		if next[d] < len(delims) {
+			prev[next[d]] = prev[d]
+		}
+	}
+
+	bottom := map[openersKey]int{}
+
+	for c, closer := range delims {
+		if !closer.active || !closer.canClose {
+			continue
+		}
+
+		key := openersKey{delim: closer.delim, canOpen: closer.canOpen, orig: closer.orig % 3}
+		if closer.delim == '~' {
+			key.orig = closer.orig
+		}
+		floor, ok := bottom[key]
+		if !ok {
+			floor = -1
+		}
+
+		for closer.count > 0 {
+			o := prev[c]
+			for o > floor && !matchesOpener(delims[o], closer) {
+				o = prev[o]
+			}
+			if o <= floor {
+				// No hay apertura para este tipo de cierre antes de c
+				bottom[key] = c - 1
+				break
+			}
+			opener := delims[o]
+
+			use := 1
+			if opener.count >= 2 && closer.count >= 2 {
+				use = 2
+			}
+
+			var open, close string
+			switch {
+			case opener.delim == '~':
+				open, close = "<del>", "</del>"
+			case use == 2:
+				open, close = "<strong>", "</strong>"
+			default:
+				open, close = "<em>", "</em>"
+			}
+
+			// Los tags nuevos envuelven a los que ya estaban
+			opener.opens = open + opener.opens
+			closer.closes = closer.closes + close
+			opener.count -= use
+			closer.count -= use
+
+			// Los delimitadores entre apertura y cierre quedan como texto
+			for k := next[o]; k < c; k = next[k] {
+				delims[k].active = false
+			}
+			next[o] = c
+			prev[c] = o
+
+			if opener.count == 0 {
+				opener.active = false
+				remove(o)
+			}
+		}
+
+		// Si no puede abrir otro énfasis ya no hace falta en la lista
+		if closer.count == 0 || !closer.canOpen {
+			closer.active = false
+			remove(c)
+		}
+	}
+}
+
+// matchesOpener indica si el delimitador puede abrir el cierre
+func matchesOpener(opener, closer *inlineNode) bool {
+	if opener.delim != closer.delim || !opener.active || !opener.canOpen || opener.count == 0 {
+		return false
+	}
+	if closer.delim == '~' {
+		return opener.count == closer.count
+	}
+	// Regla del "múltiplo de 3" de CommonMark
+	return !((opener.canClose || closer.canOpen) &&
+		(opener.orig+closer.orig)%3 == 0 && !(opener.orig%3 == 0 && closer.orig%3 == 0))
+}
+
+// parseCodeSpan busca el cierre de una corrida de backticks del mismo largo
+func (p *inlineParser) parseCodeSpan(i int) (string, int, bool) {
+	s := p.s
+	n := runLength(s, i, '`')
+	if from, ok := p.noCodeCloser[n]; ok && i >= from {
+		return "", 0, false
+	}
+
+	for j := i + n; j < len(s); {
+		k := strings.IndexByte(s[j:], '`')
+		if k < 0 {
+			break
+		}
+		j += k
+		m := runLength(s, j, '`')
+		if m == n {
+			code := strings.ReplaceAll(s[i+n:j], "\n", " ")
+			if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+				code = code[1 : len(code)-1]
+			}
+			return "<code>" + html.EscapeString(code) + "</code>", j + m, true
+		}
+		j += m
+	}
+
+	p.noCodeCloser[n] = i
+	return "", 0, false
+}
+
+// parseLink interpreta [texto](destino "título") a partir del corchete en i.
+// Con image genera un <img> usando el texto como alt.
+func (p *inlineParser) parseLink(i int, image bool) (string, int, bool) {
+	s := p.s
+	closeBracket := p.closingBracket(i)
+	if closeBracket < 0 || closeBracket+1 >= len(s) || s[closeBracket+1] != '(' {
+		return "", 0, false
+	}
+
+	dest, title, next, ok := p.parseLinkTarget(closeBracket + 2)
+	if !ok {
+		return "", 0, false
+	}
+
+	label := s[i+1 : closeBracket]
+	href, safe := safeURL(unescapeBackslashes(dest), image)
+
+	if image {
+		alt := html.EscapeString(plainText(label))
+		if !safe {
+			return alt, next, true
+		}
+		h := `<img src="` + html.EscapeString(href) + `" alt="` + alt + `"`
+		if title != "" {
+			h += ` title="` + html.EscapeString(unescapeBackslashes(title)) + `"`
+		}
+		return h + " />", next, true
+	}
+
+	content := newInlineParser(label, true).render()
+	if !safe {
+		return content, next, true
+	}
+	h := `<a href="` + html.EscapeString(href) + `"`
+	if title != "" {
+		h += ` title="` + html.EscapeString(unescapeBackslashes(title)) + `"`
+	}
+	return h + ">" + content + "</a>", next, true
+}
+
+// closingBracket devuelve el ] que cierra el [ en i, o -1. La primera vez
+// empareja todos los corchetes del texto de una sola pasada, respetando
+// anidamiento, escapes y código inline.
+func (p *inlineParser) closingBracket(i int) int {
+	if p.brackets == nil {
+		s := p.s
+		p.brackets = map[int]int{}
+		var open []int
+		for j := 0; j < len(s); j++ {
+			switch s[j] {
+			case '\\':
+				j++
+			case '`':
+				if _, next, ok := p.parseCodeSpan(j); ok {
+					j = next - 1
+				} else {
+					j += runLength(s, j, '`') - 1
+				}
+			case '[':
+				open = append(open, j)
+			case ']':
+				if len(open) > 0 {
+					p.brackets[open[len(open)-1]] = j
+					open = open[:len(open)-1]
+				}
+			}
+		}
+	}
+
+	if j, ok := p.brackets[i]; ok {
+		return j
+	}
+	return -1
+}
+
+// indexUnescaped busca desde i la primera aparición de c que no esté
+// escapada con \. Si no hay, lo recuerda: tampoco la habrá más adelante.
+func (p *inlineParser) indexUnescaped(i int, c byte) int {
+	if from, ok := p.noCloser[c]; ok && i >= from {
+		return -1
+	}
+	for j := i; j < len(p.s); j++ {
+		switch p.s[j] {
+		case '\\':
+			j++
+		case c:
+			return j
+		}
+	}
+	p.noCloser[c] = i
+	return -1
+}
+
+// parseLinkTarget lee el destino y el título opcional hasta el ) de cierre
+func (p *inlineParser) parseLinkTarget(i int) (dest, title string, next int, ok bool) {
+	s := p.s
+	i = skipWhitespace(s, i)
+	if i >= len(s) {
+		return "", "", 0, false
+	}
+
+	if s[i] == '<' {
+		// El destino entre <> no puede tener saltos de línea ni otro <,
+		// así que la búsqueda termina en el próximo de ellos
+		end := i + 1
+		for ; end < len(s) && s[end] != '>'; end++ {
+			if s[end] == '\n' || s[end] == '<' {
+				return "", "", 0, false
+			}
+			if s[end] == '\\' {
+				end++
+			}
+		}
+		if end >= len(s) {
+			return "", "", 0, false
+		}
+		dest = s[i+1 : end]
+		i = end + 1
+	} else {
+		depth := 0
+		start := i
+		for ; i < len(s); i++ {
+			c := s[i]
+			if c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]) {
+				i++
+				continue
+			}
+			if c == '(' {
+				depth++
+				if depth > maxLinkParens {
+					return "", "", 0, false
+				}
+			} else if c == ')' {
+				if depth == 0 {
+					break
+				}
+				depth--
+			} else if c == ' ' || c == '\n' || c < 0x20 {
+				break
+			}
+		}
+		dest = s[start:i]
+	}
+
+	j := skipWhitespace(s, i)
+	if j < len(s) && j > i && (s[j] == '"' || s[j] == '\'' || s[j] == '(') {
+		closing := s[j]
+		if closing == '(' {
+			closing = ')'
+		}
+		end := p.indexUnescaped(j+1, closing)
+		if end < 0 {
+			return "", "", 0, false
+		}
+		title = s[j+1 : end]
+		j = skipWhitespace(s, end+1)
+	}
+
+	if j >= len(s) || s[j] != ')' {
+		return "", "", 0, false
+	}
+	return dest, title, j + 1, true
+}
+
+// plainText quita la sintaxis de énfasis y código para usar el texto como alt
+func plainText(s string) string {
+	return strings.NewReplacer("*", "", "_", "", "`", "", "~~", "", "[", "", "]", "").Replace(unescapeBackslashes(s))
+}
+
+// unescapeBackslashes resuelve los escapes \x de puntuación ASCII
+func unescapeBackslashes(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func runLength(s string, i int, c byte) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == c {
+		n++
+	}
+	return n
+}
+
+func skipSpaces(s string, i int) int {
+	for i < len(s) && s[i] == ' ' {
+		i++
+	}
+	return i
+}
+
+func skipWhitespace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isPunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
@@ -0,0 +1,490 @@
+// Package markdown convierte el contenido de posts y comentarios (Markdown)
+// en HTML seguro para mostrar en el frontend.
+//
+// Soporta el subconjunto de CommonMark que se usa en el blog (títulos,
+// párrafos, énfasis, links, imágenes, listas, citas, código y separadores)
+// más las extensiones de GitHub para tablas y texto tachado. El HTML
+// escrito a mano en el contenido no se interpreta: se escapa como texto.
+// Todo el resultado pasa además por Sanitize antes de devolverse.
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version identifica la versión del renderer. Se incluye en la clave del
+// cache para que un cambio en el renderer invalide el HTML ya generado.
+const Version = 2
+
+var (
+	atxHeadingRe    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	thematicBreakRe = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	fenceOpenRe     = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`]*?)[ \t]*$")
+	setextH1Re      = regexp.MustCompile(`^ {0,3}=+[ \t]*$`)
+	setextH2Re      = regexp.MustCompile(`^ {0,3}-+[ \t]*$`)
+	listItemRe      = regexp.MustCompile(`^( {0,3})([-+*]|\d{1,9}[.)])([ \t]+|$)`)
+	blockquoteRe    = regexp.MustCompile(`^ {0,3}> ?`)
+	tableDelimRe    = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	languageRe      = regexp.MustCompile(`^[A-Za-z0-9_+#.-]+$`)
+)
+
+// maxBlockDepth es cuántas citas y listas se pueden anidar. Más adentro
+// los marcadores quedan como texto: cada nivel vuelve a procesar sus
+// líneas, así que sin límite un contenido como "> > > ..." tarda un
+// tiempo cuadrático en renderizarse.
+const maxBlockDepth = 16
+
+// ToHTML convierte Markdown en HTML sanitizado, sin usar cache
+func ToHTML(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+	lines := strings.Split(expandTabs(src), "\n")
+
+	var b strings.Builder
+	renderBlocks(&b, lines, false, 0)
+
+	return Sanitize(strings.TrimSuffix(b.String(), "\n"))
+}
+
+// expandTabs reemplaza tabs iniciales por espacios (tab stop de 4)
+func expandTabs(src string) string {
+	if !strings.Contains(src, "\t") {
+		return src
+	}
+
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		var b strings.Builder
+		col := 0
+		for j, r := range line {
+			if r == '\t' {
+				n := 4 - col%4
+				b.WriteString(strings.Repeat(" ", n))
+				col += n
+				continue
+			}
+			if r != ' ' {
+				b.WriteString(line[j:])
+				break
+			}
+			b.WriteRune(r)
+			col++
+		}
+		lines[i] = b.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderBlocks procesa líneas de bloque. Con tight los párrafos se emiten
+// sin <p> (ítems de una lista compacta). depth es el nivel de anidamiento
+// dentro de citas y listas.
+func renderBlocks(b *strings.Builder, lines []string, tight bool, depth int) {
+	nested := depth < maxBlockDepth
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case isBlank(line):
+			i++
+
+		case fenceOpenRe.MatchString(line):
+			i = renderFencedCode(b, lines, i)
+
+		case atxHeadingRe.MatchString(line):
+			m := atxHeadingRe.FindStringSubmatch(line)
+			level := len(m[1])
+			b.WriteString("<h" + strconv.Itoa(level) + ">" + renderInline(strings.TrimSpace(m[2])) + "</h" + strconv.Itoa(level) + ">\n")
+			i++
+
+		case thematicBreakRe.MatchString(line):
+			b.WriteString("<hr />\n")
+			i++
+
+		case nested && blockquoteRe.MatchString(line):
+			i = renderBlockquote(b, lines, i, depth)
+
+		case nested && listItemRe.MatchString(line):
+			i = renderList(b, lines, i, depth)
+
+		case indentOf(line) >= 4:
+			i = renderIndentedCode(b, lines, i)
+
+		case isTableStart(lines, i):
+			i = renderTable(b, lines, i)
+
+		default:
+			i = renderParagraph(b, lines, i, tight)
+		}
+	}
+}
+
+// renderFencedCode emite un bloque ``` o ~~~ con la clase del lenguaje
+func renderFencedCode(b *strings.Builder, lines []string, start int) int {
+	m := fenceOpenRe.FindStringSubmatch(lines[start])
+	indent := len(m[1])
+	fence := m[2]
+	lang := strings.Fields(m[3] + " ")
+
+	var code []string
+	i := start + 1
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimLeft(lines[i], " ")
+		if indentOf(lines[i]) < 4 && strings.HasPrefix(trimmed, fence[:1]) &&
+			strings.TrimRight(trimmed, fence[:1]+" ") == "" && len(strings.TrimRight(trimmed, " ")) >= len(fence) {
+			i++
+			break
+		}
+		code = append(code, trimIndent(lines[i], indent))
+	}
+
+	b.WriteString("<pre><code")
+	if len(lang) > 0 && languageRe.MatchString(lang[0]) {
+		b.WriteString(` class="language-` + html.EscapeString(strings.ToLower(lang[0])) + `"`)
+	}
+	b.WriteString(">")
+	for _, c := range code {
+		b.WriteString(html.EscapeString(c) + "\n")
+	}
+	b.WriteString("</code></pre>\n")
+
+	return i
+}
+
+// renderIndentedCode emite un bloque de código indentado con 4 espacios
+func renderIndentedCode(b *strings.Builder, lines []string, start int) int {
+	var code []string
+	i := start
+	for ; i < len(lines); i++ {
+		if !isBlank(lines[i]) && indentOf(lines[i]) < 4 {
+			break
+		}
+		code = append(code, trimIndent(lines[i], 4))
+	}
+
+	// Las líneas en blanco finales no forman parte del bloque
+	for len(code) > 0 && isBlank(code[len(code)-1]) {
+		code = code[:len(code)-1]
+	}
+
+	b.WriteString("<pre><code>")
+	for _, c := range code {
+		b.WriteString(html.EscapeString(c) + "\n")
+	}
+	b.WriteString("</code></pre>\n")
+
+	return i
+}
+
+// renderBlockquote agrupa las líneas con > (y las de continuación perezosa)
+// y las procesa recursivamente
+func renderBlockquote(b *strings.Builder, lines []string, start int, depth int) int {
+	var inner []string
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if blockquoteRe.MatchString(line) {
+			inner = append(inner, blockquoteRe.ReplaceAllString(line, ""))
+			continue
+		}
+		// Continuación perezosa: una línea de texto que sigue al párrafo citado
+		if isBlank(line) || len(inner) == 0 || isBlank(inner[len(inner)-1]) || startsBlock(line) {
+			break
+		}
+		inner = append(inner, line)
+	}
+
+	b.WriteString("<blockquote>\n")
+	renderBlocks(b, inner, false, depth+1)
+	b.WriteString("</blockquote>\n")
+
+	return i
+}
+
+// listItem es un ítem de lista con sus líneas ya desindentadas
+type listItem struct {
+	lines []string
+}
+
+// renderList agrupa ítems consecutivos del mismo tipo de lista
+func renderList(b *strings.Builder, lines []string, start int, depth int) int {
+	first := listItemRe.FindStringSubmatch(lines[start])
+	ordered := !strings.ContainsAny(first[2], "-+*")
+	delimiter := first[2][len(first[2])-1:]
+
+	var items []listItem
+	loose := false
+	i := start
+
+	for i < len(lines) {
+		m := listItemRe.FindStringSubmatch(lines[i])
+		if m == nil || !sameListType(m[2], ordered, delimiter) {
+			break
+		}
+
+		// Ancho del marcador: el contenido del ítem queda alineado después
+		padding := len(m[3])
+		if padding > 4 || padding == 0 {
+			padding = 1
+		}
+		contentIndent := len(m[1]) + len(m[2]) + padding
+
+		item := listItem{lines: []string{strings.TrimPrefix(lines[i][min(len(lines[i]), len(m[1])+len(m[2])):], strings.Repeat(" ", padding))}}
+		i++
+
+		for i < len(lines) {
+			line := lines[i]
+			if isBlank(line) {
+				item.lines = append(item.lines, "")
+				i++
+				continue
+			}
+			if indentOf(line) >= contentIndent {
+				item.lines = append(item.lines, trimIndent(line, contentIndent))
+				i++
+				continue
+			}
+			// Continuación perezosa del párrafo del ítem
+			prev := item.lines[len(item.lines)-1]
+			if !isBlank(prev) && !startsBlock(line) {
+				item.lines = append(item.lines, strings.TrimLeft(line, " "))
+				i++
+				continue
+			}
+			break
+		}
+
+		// Un blanco entre ítems o entre bloques del ítem hace la lista "loose"
+		trailing := 0
+		for len(item.lines) > 0 && isBlank(item.lines[len(item.lines)-1]) {
+			item.lines = item.lines[:len(item.lines)-1]
+			trailing++
+		}
+		if trailing > 0 && i < len(lines) && listItemRe.MatchString(lines[i]) {
+			loose = true
+		}
+		for _, l := range item.lines {
+			if isBlank(l) {
+				loose = true
+			}
+		}
+
+		items = append(items, item)
+
+		// Un blanco seguido de algo que no es ítem termina la lista
+		if trailing > 0 && (i >= len(lines) || !listItemRe.MatchString(lines[i])) {
+			break
+		}
+	}
+
+	if ordered {
+		n, _ := strconv.Atoi(first[2][:len(first[2])-1])
+		if n != 1 {
+			b.WriteString(`<ol start="` + strconv.Itoa(n) + `">` + "\n")
+		} else {
+			b.WriteString("<ol>\n")
+		}
+	} else {
+		b.WriteString("<ul>\n")
+	}
+
+	for _, item := range items {
+		b.WriteString("<li>")
+		var inner strings.Builder
+		renderBlocks(&inner, item.lines, !loose, depth+1)
+		content := inner.String()
+		if !loose {
+			content = strings.TrimSuffix(content, "\n")
+		} else if content != "" {
+			b.WriteString("\n")
+		}
+		b.WriteString(content)
+		b.WriteString("</li>\n")
+	}
+
+	if ordered {
+		b.WriteString("</ol>\n")
+	} else {
+		b.WriteString("</ul>\n")
+	}
+
+	return i
+}
+
+// sameListType indica si el marcador continúa la lista actual
+func sameListType(marker string, ordered bool, delimiter string) bool {
+	isOrdered := !strings.ContainsAny(marker, "-+*")
+	if isOrdered != ordered {
+		return false
+	}
+	return marker[len(marker)-1:] == delimiter
+}
+
+// isTableStart detecta una tabla GFM: fila de encabezado + fila delimitadora
+func isTableStart(lines []string, i int) bool {
+	if i+1 >= len(lines) || !strings.Contains(lines[i], "|") || !tableDelimRe.MatchString(lines[i+1]) {
+		return false
+	}
+	return len(splitTableRow(lines[i])) == len(splitTableRow(lines[i+1]))
+}
+
+// renderTable emite una tabla con la alineación de cada columna
+func renderTable(b *strings.Builder, lines []string, start int) int {
+	header := splitTableRow(lines[start])
+	delims := splitTableRow(lines[start+1])
+
+	aligns := make([]string, len(delims))
+	for j, d := range delims {
+		d = strings.TrimSpace(d)
+		left, right := strings.HasPrefix(d, ":"), strings.HasSuffix(d, ":")
+		switch {
+		case left && right:
+			aligns[j] = "center"
+		case right:
+			aligns[j] = "right"
+		case left:
+			aligns[j] = "left"
+		}
+	}
+
+	writeRow := func(cells []string, tag string) {
+		b.WriteString("<tr>\n")
+		for j := range aligns {
+			cell := ""
+			if j < len(cells) {
+				cell = cells[j]
+			}
+			b.WriteString("<" + tag)
+			if aligns[j] != "" {
+				b.WriteString(` align="` + aligns[j] + `"`)
+			}
+			b.WriteString(">" + renderInline(strings.TrimSpace(cell)) + "</" + tag + ">\n")
+		}
+		b.WriteString("</tr>\n")
+	}
+
+	b.WriteString("<table>\n<thead>\n")
+	writeRow(header, "th")
+	b.WriteString("</thead>\n")
+
+	i := start + 2
+	if i < len(lines) && !isBlank(lines[i]) && !startsBlock(lines[i]) {
+		b.WriteString("<tbody>\n")
+		for ; i < len(lines) && !isBlank(lines[i]) && !startsBlock(lines[i]); i++ {
+			writeRow(splitTableRow(lines[i]), "td")
+		}
+		b.WriteString("</tbody>\n")
+	}
+	b.WriteString("</table>\n")
+
+	return i
+}
+
+// splitTableRow separa las celdas de una fila respetando \| y código
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	inCode := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case c == '`':
+			inCode = !inCode
+			cell.WriteByte(c)
+		case c == '|' && !inCode:
+			cells = append(cells, cell.String())
+			cell.Reset()
+		default:
+			cell.WriteByte(c)
+		}
+	}
+	return append(cells, cell.String())
+}
+
+// renderParagraph junta líneas de texto hasta un blanco o el inicio de otro
+// bloque. Detecta también los títulos setext (subrayados con = o -).
+func renderParagraph(b *strings.Builder, lines []string, start int, tight bool) int {
+	var text []string
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) {
+			break
+		}
+		if len(text) > 0 {
+			if setextH1Re.MatchString(line) {
+				b.WriteString("<h1>" + renderInline(strings.Join(text, "\n")) + "</h1>\n")
+				return i + 1
+			}
+			if setextH2Re.MatchString(line) {
+				b.WriteString("<h2>" + renderInline(strings.Join(text, "\n")) + "</h2>\n")
+				return i + 1
+			}
+			if interruptsParagraph(line) {
+				break
+			}
+		}
+		text = append(text, strings.TrimLeft(line, " "))
+	}
+
+	content := renderInline(strings.TrimRight(strings.Join(text, "\n"), " "))
+	if tight {
+		b.WriteString(content + "\n")
+	} else {
+		b.WriteString("<p>" + content + "</p>\n")
+	}
+
+	return i
+}
+
+// startsBlock indica si la línea abre un bloque distinto de un párrafo
+func startsBlock(line string) bool {
+	return fenceOpenRe.MatchString(line) || atxHeadingRe.MatchString(line) ||
+		thematicBreakRe.MatchString(line) || blockquoteRe.MatchString(line) ||
+		listItemRe.MatchString(line)
+}
+
+// interruptsParagraph aplica la regla de CommonMark: una lista ordenada solo
+// corta un párrafo si empieza en 1 y ningún ítem vacío lo hace
+func interruptsParagraph(line string) bool {
+	if m := listItemRe.FindStringSubmatch(line); m != nil {
+		if strings.TrimSpace(line[len(m[0]):]) == "" {
+			return false
+		}
+		if !strings.ContainsAny(m[2], "-+*") {
+			return strings.HasPrefix(m[2], "1") && len(m[2]) == 2
+		}
+		return true
+	}
+	return startsBlock(line)
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// indentOf cuenta los espacios iniciales de la línea
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// trimIndent quita hasta n espacios iniciales
+func trimIndent(line string, n int) string {
+	i := 0
+	for i < n && i < len(line) && line[i] == ' ' {
+		i++
+	}
+	return line[i:]
+}
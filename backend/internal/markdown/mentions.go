@@ -0,0 +1,160 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Mention es un @usuario encontrado en el contenido. Offset y Length se
+// cuentan en caracteres (code points) sobre el texto original e incluyen
+// la @.
+type Mention struct {
+	Username string
+	Offset   int
+	Length   int
+}
+
+// mentionRe reconoce @usuario: letras, números y _, con . o - solo en el medio
+var mentionRe = regexp.MustCompile(`@([\p{L}\p{N}_](?:[\p{L}\p{N}_.-]*[\p{L}\p{N}_])?)`)
+
+// FindMentions devuelve los @usuario del contenido en orden de aparición.
+// Ignora los que están dentro de bloques de código (``` / ~~~ o
+// indentados), de código inline, los escapados (\@usuario) y los que son
+// parte de otra palabra, un email o una URL.
+func FindMentions(src string) []Mention {
+	var mentions []Mention
+
+	fence := ""       // Marcador del bloque ``` abierto, vacío si no hay
+	prevBlank := true // Un bloque indentado no puede cortar un párrafo
+	indented := false // Dentro de un bloque de código indentado
+	runes, scanned := 0, 0
+
+	for start := 0; start <= len(src); {
+		end := strings.IndexByte(src[start:], '\n')
+		if end < 0 {
+			end = len(src)
+		} else {
+			end += start
+		}
+		line := strings.TrimSuffix(src[start:end], "\r")
+		expanded := expandTabs(line)
+
+		switch {
+		case fence != "":
+			if closesFence(expanded, fence) {
+				fence = ""
+			}
+		case fenceOpenRe.MatchString(expanded):
+			fence = fenceOpenRe.FindStringSubmatch(expanded)[2]
+		case !isBlank(line) && indentOf(expanded) >= 4 && (prevBlank || indented):
+			indented = true
+		default:
+			if !isBlank(line) {
+				indented = false
+			}
+			for _, m := range lineMentions(line) {
+				// Los offsets se pasan de bytes a caracteres de forma incremental
+				runes += utf8.RuneCountInString(src[scanned : start+m.Offset])
+				scanned = start + m.Offset
+				mentions = append(mentions, Mention{
+					Username: m.Username,
+					Offset:   runes,
+					Length:   utf8.RuneCountInString(m.Username) + 1,
+				})
+			}
+		}
+
+		prevBlank = isBlank(line)
+		start = end + 1
+	}
+
+	return mentions
+}
+
+// closesFence indica si la línea cierra el bloque abierto con fence
+func closesFence(line string, fence string) bool {
+	trimmed := strings.TrimLeft(line, " ")
+	return indentOf(line) < 4 && strings.HasPrefix(trimmed, fence[:1]) &&
+		strings.TrimRight(trimmed, fence[:1]+" ") == "" && len(strings.TrimRight(trimmed, " ")) >= len(fence)
+}
+
+// lineMentions busca las menciones de una línea fuera del código inline.
+// Offset queda en bytes relativo a la línea.
+func lineMentions(line string) []Mention {
+	if !strings.Contains(line, "@") {
+		return nil
+	}
+
+	code := codeSpans(line)
+	inCode := func(i int) bool {
+		for _, span := range code {
+			if i >= span[0] && i < span[1] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var mentions []Mention
+	for _, m := range mentionRe.FindAllStringSubmatchIndex(line, -1) {
+		at, end := m[0], m[1]
+		if inCode(at) {
+			continue
+		}
+		if prev, _ := utf8.DecodeLastRuneInString(line[:at]); at > 0 && !canPrecedeMention(prev) {
+			continue
+		}
+		// @usuario@instancia es una cuenta de otro servidor
+		if end < len(line) && line[end] == '@' {
+			continue
+		}
+		mentions = append(mentions, Mention{Username: line[m[2]:m[3]], Offset: at})
+	}
+	return mentions
+}
+
+// canPrecedeMention indica si el carácter anterior a la @ permite que sea
+// una mención (y no un email, una URL o un escape)
+func canPrecedeMention(r rune) bool {
+	if unicode.IsLetter(r) || unicode.IsNumber(r) {
+		return false
+	}
+	return !strings.ContainsRune(`_@/.\-+=`+"`", r)
+}
+
+// codeSpans devuelve los rangos [inicio, fin) en bytes del código inline
+// de la línea. Una secuencia de ` sin cierre del mismo largo es texto.
+func codeSpans(line string) [][2]int {
+	var spans [][2]int
+	for i := 0; i < len(line); {
+		if line[i] != '`' {
+			i++
+			continue
+		}
+
+		n := runLength(line, i, '`')
+		closing := -1
+		for j := i + n; j < len(line); {
+			if line[j] != '`' {
+				j++
+				continue
+			}
+			m := runLength(line, j, '`')
+			if m == n {
+				closing = j
+				break
+			}
+			j += m
+		}
+
+		if closing < 0 {
+			i += n
+			continue
+		}
+		spans = append(spans, [2]int{i, closing + n})
+		i = closing + n
+	}
+	return spans
+}
@@ -0,0 +1,195 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// allowedTags son los únicos tags que pueden aparecer en el HTML final,
+// con los atributos permitidos para cada uno
+var allowedTags = map[string][]string{
+	"a":          {"href", "title"},
+	"blockquote": nil,
+	"br":         nil,
+	"code":       {"class"},
+	"del":        nil,
+	"em":         nil,
+	"h1":         nil,
+	"h2":         nil,
+	"h3":         nil,
+	"h4":         nil,
+	"h5":         nil,
+	"h6":         nil,
+	"hr":         nil,
+	"img":        {"src", "alt", "title"},
+	"li":         nil,
+	"ol":         {"start"},
+	"p":          nil,
+	"pre":        nil,
+	"strong":     nil,
+	"table":      nil,
+	"tbody":      nil,
+	"td":         {"align"},
+	"th":         {"align"},
+	"thead":      nil,
+	"tr":         nil,
+	"ul":         nil,
+}
+
+var (
+	tagRe       = regexp.MustCompile(`^<(/?)([A-Za-z][A-Za-z0-9]*)((?:\s+[^\s"'>/=]+(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s"'=<>` + "`" + `]+))?)*)\s*(/?)>`)
+	attrRe      = regexp.MustCompile(`([^\s"'>/=]+)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'=<>` + "`" + `]+)))?`)
+	classRe     = regexp.MustCompile(`^language-[a-z0-9_+#.-]+$`)
+	numberRe    = regexp.MustCompile(`^[0-9]{1,9}$`)
+	alignValues = map[string]bool{"left": true, "center": true, "right": true}
+)
+
+// Sanitize filtra HTML contra la lista de tags y atributos permitidos.
+// Los tags no permitidos se escapan (quedan visibles como texto), los
+// comentarios se eliminan (un "<!--" sin cerrar también se escapa, para no
+// perder el texto que sigue) y los atributos desconocidos o con URLs
+// peligrosas (javascript:, data:, ...) se descartan.
+func Sanitize(input string) string {
+	var b strings.Builder
+	b.Grow(len(input))
+
+	for i := 0; i < len(input); {
+		lt := strings.IndexByte(input[i:], '<')
+		if lt < 0 {
+			b.WriteString(escapeText(input[i:]))
+			break
+		}
+		b.WriteString(escapeText(input[i : i+lt]))
+		i += lt
+
+		if strings.HasPrefix(input[i:], "<!--") {
+			end := strings.Index(input[i+4:], "-->")
+			if end < 0 {
+				b.WriteString("&lt;")
+				i++
+				continue
+			}
+			i += 4 + end + 3
+			continue
+		}
+
+		m := tagRe.FindStringSubmatch(input[i:])
+		if m == nil {
+			b.WriteString("&lt;")
+			i++
+			continue
+		}
+
+		name := strings.ToLower(m[2])
+		allowed, ok := allowedTags[name]
+		if !ok {
+			b.WriteString(html.EscapeString(m[0]))
+			i += len(m[0])
+			continue
+		}
+
+		if m[1] == "/" {
+			b.WriteString("</" + name + ">")
+		} else {
+			b.WriteString("<" + name + sanitizeAttributes(name, m[3], allowed))
+			if name == "br" || name == "hr" || name == "img" {
+				b.WriteString(" />")
+			} else {
+				b.WriteString(">")
+			}
+		}
+		i += len(m[0])
+	}
+
+	return b.String()
+}
+
+// sanitizeAttributes conserva solo los atributos permitidos con valores válidos
+func sanitizeAttributes(tag, raw string, allowed []string) string {
+	var b strings.Builder
+	seen := map[string]bool{}
+
+	for _, m := range attrRe.FindAllStringSubmatch(raw, -1) {
+		name := strings.ToLower(m[1])
+		value := html.UnescapeString(m[2] + m[3] + m[4])
+
+		if seen[name] || !contains(allowed, name) {
+			continue
+		}
+
+		switch name {
+		case "href", "src":
+			safe, ok := safeURL(value, name == "src")
+			if !ok {
+				continue
+			}
+			value = safe
+		case "class":
+			if !classRe.MatchString(value) {
+				continue
+			}
+		case "align":
+			if !alignValues[value] {
+				continue
+			}
+		case "start":
+			if !numberRe.MatchString(value) {
+				continue
+			}
+		}
+
+		seen[name] = true
+		b.WriteString(" " + name + `="` + html.EscapeString(value) + `"`)
+	}
+
+	// Los links externos no deben dar acceso a window.opener
+	if tag == "a" && seen["href"] {
+		b.WriteString(` rel="nofollow noopener noreferrer"`)
+	}
+
+	return b.String()
+}
+
+// safeURL acepta URLs relativas y los esquemas http, https y mailto
+// (solo http y https para imágenes)
+func safeURL(raw string, image bool) (string, bool) {
+	u := strings.TrimSpace(raw)
+
+	// Los navegadores ignoran caracteres de control y espacios dentro del
+	// esquema ("java\tscript:"), así que se evalúa una versión compacta
+	compact := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, strings.ToLower(u))
+
+	colon := strings.IndexByte(compact, ':')
+	if colon < 0 || strings.ContainsAny(compact[:colon], "/?#") {
+		return u, true
+	}
+
+	switch compact[:colon] {
+	case "http", "https":
+		return u, true
+	case "mailto":
+		return u, !image
+	default:
+		return "", false
+	}
+}
+
+// escapeText escapa < y > sueltos sin tocar las entidades ya escapadas
+func escapeText(s string) string {
+	return strings.NewReplacer("<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	preBlockRe  = regexp.MustCompile(`(?s)<pre>.*?</pre>`)
+	headingRe   = regexp.MustCompile(`(?s)<h[1-6]>.*?</h[1-6]>`)
+	blockEndRe  = regexp.MustCompile(`</(?:p|h[1-6]|li|blockquote|tr|td|th)>|<br />|<hr />`)
+	anyTagRe    = regexp.MustCompile(`<[^>]*>`)
+	sentenceEnd = regexp.MustCompile(`[.!?…]["')\]»]?(\s|$)`)
+)
+
+// TextFromHTML obtiene el texto legible del HTML generado por ToHTML.
+// Los bloques de código se omiten porque no aportan a un resumen.
+func TextFromHTML(h string) string {
+	h = preBlockRe.ReplaceAllString(h, " ")
+	h = blockEndRe.ReplaceAllStringFunc(h, func(tag string) string { return tag + " " })
+	h = anyTagRe.ReplaceAllString(h, "")
+	return strings.Join(strings.Fields(html.UnescapeString(h)), " ")
+}
+
+// WordCount cuenta las palabras de un texto plano
+func WordCount(text string) int {
+	return len(strings.FieldsFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r)
+	}))
+}
+
+// ExcerptFromHTML arma el resumen de un contenido ya renderizado. Los
+// títulos se omiten para que el resumen empiece por el primer párrafo.
+func ExcerptFromHTML(h string, maxRunes int) string {
+	return Excerpt(TextFromHTML(headingRe.ReplaceAllString(h, " ")), maxRunes)
+}
+
+// Excerpt recorta el texto en el último fin de oración que entra en
+// maxRunes caracteres. Si la primera oración ya es más larga, corta en
+// el último espacio y agrega "…".
+func Excerpt(text string, maxRunes int) string {
+	if utf8.RuneCountInString(text) <= maxRunes {
+		return text
+	}
+
+	limit := byteOffset(text, maxRunes)
+	cut := -1
+	for _, loc := range sentenceEnd.FindAllStringSubmatchIndex(text[:limit+1], -1) {
+		end := loc[2] // fin de la puntuación, antes del espacio
+		if end <= limit {
+			cut = end
+		}
+	}
+	if cut > 0 {
+		return strings.TrimSpace(text[:cut])
+	}
+
+	truncated := text[:limit]
+	if space := strings.LastIndexFunc(truncated, unicode.IsSpace); space > 0 {
+		truncated = truncated[:space]
+	}
+	return strings.TrimRightFunc(truncated, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + "…"
+}
+
+// byteOffset devuelve la posición en bytes del carácter número n
+func byteOffset(s string, n int) int {
+	i := 0
+	for pos := range s {
+		if i == n {
+			return pos
+		}
+		i++
+	}
+	return len(s)
+}
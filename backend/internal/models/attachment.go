@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Estados del procesamiento de imágenes (versiones reducidas). Los
+// adjuntos que no son imágenes decodificables no tienen estado.
+const (
+	AttachmentProcessingPending = "pending"
+	AttachmentProcessingReady   = "ready"
+	AttachmentProcessingFailed  = "failed"
+)
+
+// Nombres de las versiones reducidas que se generan de cada imagen
+const (
+	VariantThumbnail = "thumbnail"
+	VariantMedium    = "medium"
+)
+
+// Attachment representa un archivo subido y, opcionalmente, asociado a un post
+type Attachment struct {
+	ID               int                           `json:"id"`
+	PostID           *int                          `json:"post_id"` // nil mientras no esté asociado a un post
+	UserID           int                           `json:"user_id"`
+	StorageKey       string                        `json:"-"` // Clave interna en el BlobStore
+	Filename         string                        `json:"filename"`
+	ContentType      string                        `json:"content_type"`
+	Size             int64                         `json:"size"`
+	Width            int                           `json:"width,omitempty"`  // Solo imágenes
+	Height           int                           `json:"height,omitempty"` // Solo imágenes
+	ProcessingStatus string                        `json:"processing_status,omitempty"`
+	Variants         map[string]*AttachmentVariant `json:"variants,omitempty"` // Por nombre: thumbnail, medium
+	URL              string                        `json:"url"`                // Ruta de descarga
+	CreatedAt        time.Time                     `json:"created_at"`
+}
+
+// AttachmentVariant es una versión reducida de una imagen adjunta
+type AttachmentVariant struct {
+	Name        string `json:"-"`
+	StorageKey  string `json:"-"`
+	ContentType string `json:"content_type"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Size        int64  `json:"size"`
+	URL         string `json:"url"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// BlockStatus indica si el usuario autenticado bloqueó o silenció a otro
+type BlockStatus struct {
+	UserID  int  `json:"user_id"`
+	Blocked bool `json:"blocked"` // No puede comentar sus posts ni mencionarlo
+	Muted   bool `json:"muted"`   // Sus posts y comentarios no se le muestran
+}
+
+// BlockedUser es un usuario bloqueado o silenciado, con la fecha desde
+// la que lo está
+type BlockedUser struct {
+	ID        int       `json:"id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Frecuencias del resumen por email
+const (
+	DigestOff    = "off"
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// DigestSettings indica cada cuánto quiere recibir el usuario el resumen
+// por email. LastDigestAt es hasta dónde cubrió el último resumen (o
+// cuándo se activó); el próximo incluye lo que pasó desde entonces.
+type DigestSettings struct {
+	UserID       int        `json:"-"`
+	Frequency    string     `json:"frequency"`
+	LastDigestAt *time.Time `json:"last_digest_at"`
+}
+
+// DigestPeriod devuelve cada cuánto se envía el resumen con esa
+// frecuencia, o 0 si no es una frecuencia válida o está desactivado
+func DigestPeriod(frequency string) time.Duration {
+	switch frequency {
+	case DigestDaily:
+		return 24 * time.Hour
+	case DigestWeekly:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// UpdateDigestSettingsRequest se usa para activar o desactivar el resumen
+type UpdateDigestSettingsRequest struct {
+	Frequency string `json:"frequency"` // "daily", "weekly" u "off"
+}
+
+// DigestReply es un comentario en un post del usuario, con los datos del
+// post para armar el link
+type DigestReply struct {
+	CommentID int
+	PostID    int
+	PostTitle string
+	PostSlug  string
+	Username  string // Autor del comentario
+	Content   string // Markdown del comentario
+	CreatedAt time.Time
+}
@@ -0,0 +1,40 @@
+package models
+
+// ActorKey es el par de claves RSA con el que un usuario local firma sus
+// actividades de ActivityPub
+type ActorKey struct {
+	UserID        int
+	PrivateKeyPEM string
+	PublicKeyPEM  string
+}
+
+// RemoteActor es un usuario de otro servidor (por ejemplo de Mastodon).
+// Se representa con un usuario local sin contraseña para que pueda
+// seguir a los autores y comentar como cualquier otro.
+type RemoteActor struct {
+	UserID       int    // Usuario local que lo representa
+	URI          string // ID del actor en ActivityPub
+	Username     string // usuario@dominio
+	KeyID        string // ID de la clave con la que firma
+	PublicKeyPEM string
+	Inbox        string
+	SharedInbox  string // Vacío si el servidor no tiene inbox compartido
+}
+
+// DeliveryInbox devuelve el inbox al que conviene enviarle actividades:
+// el compartido si existe, así un servidor recibe una sola copia
+func (a *RemoteActor) DeliveryInbox() string {
+	if a.SharedInbox != "" {
+		return a.SharedInbox
+	}
+	return a.Inbox
+}
+
+// RemoteComment asocia una respuesta recibida por ActivityPub con el
+// comentario que se creó a partir de ella
+type RemoteComment struct {
+	ObjectURI string
+	CommentID int
+	PostID    int
+	UserID    int // Autor del comentario (solo al leerlo)
+}
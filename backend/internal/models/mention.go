@@ -0,0 +1,11 @@
+package models
+
+// Mention es un @usuario resuelto dentro del contenido de un post o
+// comentario. Offset y Length indican, en caracteres (code points) sobre
+// Content, el texto "@usuario" para que el frontend lo convierta en link.
+type Mention struct {
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+	Offset   int    `json:"offset"`
+	Length   int    `json:"length"`
+}
@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// Tipos de notificación
+const (
+	NotificationComment = "comment" // Comentaron en un post propio
+	NotificationReply   = "reply"   // Comentaron en un post donde el usuario ya había comentado
+	NotificationMention = "mention" // Mencionaron al usuario con @usuario
+	NotificationFollow  = "follow"  // Empezaron a seguir al usuario
+)
+
+// Notification es un aviso para un usuario. Las notificaciones sin leer
+// del mismo tipo y sobre el mismo post se agrupan en una sola: Count dice
+// cuántos eventos reúne y Actor es el autor del último.
+type Notification struct {
+	ID            int        `json:"id"`
+	UserID        int        `json:"user_id"` // Destinatario
+	Type          string     `json:"type"`
+	ActorID       int        `json:"actor_id"`
+	ActorUsername string     `json:"actor_username"`
+	PostID        *int       `json:"post_id"` // nil en las de tipo follow
+	PostTitle     string     `json:"post_title,omitempty"`
+	PostSlug      string     `json:"post_slug,omitempty"`
+	CommentID     *int       `json:"comment_id"`
+	Count         int        `json:"count"`
+	Message       string     `json:"message"` // Texto listo para mostrar
+	Read          bool       `json:"read"`
+	ReadAt        *time.Time `json:"read_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"` // Último evento agrupado
+}
+
+// NotificationPage es una página de notificaciones, las más recientes
+// primero, junto con la cantidad total sin leer
+type NotificationPage struct {
+	Notifications []*Notification `json:"notifications"`
+	NextCursor    string          `json:"next_cursor,omitempty"`
+	UnreadCount   int             `json:"unread_count"`
+}
+
+// NotificationPreferences indica qué tipos de notificación quiere recibir
+// el usuario. Por defecto están todos activados.
+type NotificationPreferences struct {
+	UserID   int  `json:"-"`
+	Comments bool `json:"comments"`
+	Replies  bool `json:"replies"`
+	Mentions bool `json:"mentions"`
+	Follows  bool `json:"follows"`
+}
+
+// DefaultNotificationPreferences devuelve las preferencias de un usuario
+// que todavía no las configuró
+func DefaultNotificationPreferences(userID int) *NotificationPreferences {
+	return &NotificationPreferences{
+		UserID:   userID,
+		Comments: true,
+		Replies:  true,
+		Mentions: true,
+		Follows:  true,
+	}
+}
+
+// Allows indica si el usuario quiere recibir notificaciones de ese tipo
+func (p *NotificationPreferences) Allows(notificationType string) bool {
+	switch notificationType {
+	case NotificationComment:
+		return p.Comments
+	case NotificationReply:
+		return p.Replies
+	case NotificationMention:
+		return p.Mentions
+	case NotificationFollow:
+		return p.Follows
+	default:
+		return false
+	}
+}
+
+// UpdateNotificationPreferencesRequest se usa para cambiar las
+// preferencias; los campos que no vienen no se modifican
+type UpdateNotificationPreferencesRequest struct {
+	Comments *bool `json:"comments"`
+	Replies  *bool `json:"replies"`
+	Mentions *bool `json:"mentions"`
+	Follows  *bool `json:"follows"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidCursor se devuelve cuando ?cursor= no es un cursor válido
+const ErrInvalidCursor = "cursor inválido"
+
+// PostPage es una página de posts con paginación por cursor. NextCursor
+// viene vacío cuando no hay más resultados.
+type PostPage struct {
+	Posts      []*Post `json:"posts"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+// Cursor marca la última fila de una página: la fecha por la que se
+// ordena y el ID que desempata. Para el cliente es un texto opaco.
+type Cursor struct {
+	Time time.Time
+	ID   int
+}
+
+// String codifica el cursor para enviarlo en la respuesta
+func (c Cursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", c.Time.Unix(), c.ID)))
+}
+
+// ParseCursor decodifica un cursor recibido en ?cursor=. Un texto vacío
+// significa "desde el principio" y devuelve nil.
+func ParseCursor(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New(ErrInvalidCursor)
+	}
+
+	var unix int64
+	var id int
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &unix, &id); err != nil || id <= 0 {
+		return nil, errors.New(ErrInvalidCursor)
+	}
+
+	return &Cursor{Time: time.Unix(unix, 0).UTC(), ID: id}, nil
+}
@@ -2,33 +2,139 @@ package models
 
 import "time"
 
+// Estados posibles de un post
+const (
+	PostStatusDraft     = "draft"     // Solo lo ve el autor
+	PostStatusScheduled = "scheduled" // Se publica automáticamente en PublishedAt
+	PostStatusPublished = "published" // Visible para todos
+	PostStatusArchived  = "archived"  // Retirado del listado, solo lo ve el autor
+)
+
+// Visibilidades de un post publicado (el autor siempre lo ve)
+const (
+	PostVisibilityPublic    = "public"    // Lo ve cualquiera y aparece en los listados
+	PostVisibilityUnlisted  = "unlisted"  // Lo ve cualquiera con el link, no aparece en los listados
+	PostVisibilityFollowers = "followers" // Solo lo ven los seguidores del autor
+	PostVisibilityPrivate   = "private"   // Solo lo ve el autor
+)
+
 // Post representa una publicación
 type Post struct {
-	ID        int       `json:"id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	UserID    int       `json:"user_id"`
-	Username  string    `json:"username"` // Para mostrar quién publicó
-	CreatedAt time.Time `json:"created_at"`
+	ID          int        `json:"id"`
+	Title       string     `json:"title"`
+	Content     string     `json:"content"`      // Markdown tal como lo escribió el autor
+	ContentHTML string     `json:"content_html"` // HTML sanitizado generado a partir de Content
+	Excerpt     string     `json:"excerpt"`      // Resumen en texto plano para los listados
+	WordCount   int        `json:"word_count"`
+	ReadingTime int        `json:"reading_time_minutes"`
+	Slug        string     `json:"slug"` // Identificador legible para permalinks
+	Status      string     `json:"status"`
+	Visibility  string     `json:"visibility"`
+	PublishedAt *time.Time `json:"published_at"` // nil mientras sea borrador
+	// Contadores desnormalizados, se actualizan al crear o borrar comentarios
+	CommentCount  int        `json:"comment_count"`
+	LastCommentAt *time.Time `json:"last_comment_at"`
+	UserID        int        `json:"user_id"`
+	Username      string     `json:"username"` // Para mostrar quién publicó
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"` // Última edición del título o contenido
+	// Ocultado por moderación: solo lo ve su autor
+	HiddenAt *time.Time `json:"hidden_at,omitempty"`
+	// Reacciones por tipo, el total y las que puso el usuario que consulta
+	Reactions     map[string]int `json:"reactions"`
+	ReactionCount int            `json:"reaction_count"`
+	MyReactions   []string       `json:"my_reactions"`
+	// Usuarios mencionados con @usuario en Content
+	Mentions []Mention `json:"mentions"`
+	// Si el usuario que consulta guardó el post (y cuándo, en sus guardados)
+	Bookmarked   bool       `json:"bookmarked"`
+	BookmarkedAt *time.Time `json:"bookmarked_at,omitempty"`
+	// Solo se completa al obtener un post individual
+	Attachments []*Attachment `json:"attachments,omitempty"`
+	// Solo en la papelera: cuándo se borró y cuándo se elimina para siempre
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	PurgeAt   *time.Time `json:"purge_at,omitempty"`
+}
+
+// IsPublished indica si el post es visible para cualquier usuario
+func (p *Post) IsPublished() bool {
+	return p.Status == PostStatusPublished
+}
+
+// IsPublic indica si el post está publicado para todos y aparece en los
+// listados. Una visibilidad vacía equivale a "public". Un post oculto por
+// moderación (o retenido por los filtros) nunca es público.
+func (p *Post) IsPublic() bool {
+	return p.IsPublished() && !p.IsHidden() && (p.Visibility == "" || p.Visibility == PostVisibilityPublic)
+}
+
+// IsHidden indica si moderación ocultó el post
+func (p *Post) IsHidden() bool {
+	return p.HiddenAt != nil
 }
 
 // CreatePostRequest se usa para crear un post
+// Status es opcional ("published" por defecto); "scheduled" requiere PublishAt.
+// Visibility es opcional ("public" por defecto).
 type CreatePostRequest struct {
-	Title   string `json:"title"`
-	Content string `json:"content"`
+	Title      string     `json:"title"`
+	Content    string     `json:"content"`
+	Status     string     `json:"status,omitempty"`
+	PublishAt  *time.Time `json:"publish_at,omitempty"`
+	Visibility string     `json:"visibility,omitempty"`
+}
+
+// PublishPostRequest se usa para publicar un post. Si PublishAt es
+// una fecha futura el post queda programado en lugar de publicarse.
+type PublishPostRequest struct {
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+}
+
+// UpdatePostRequest se usa para editar un post
+// Visibility es opcional: si está vacío se conserva la actual.
+type UpdatePostRequest struct {
+	Title      string `json:"title"`
+	Content    string `json:"content"`
+	Visibility string `json:"visibility,omitempty"`
 }
 
 // Comment representa un comentario en un post
 type Comment struct {
-	ID        int       `json:"id"`
-	PostID    int       `json:"post_id"`
-	UserID    int       `json:"user_id"`
-	Username  string    `json:"username"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          int       `json:"id"`
+	PostID      int       `json:"post_id"`
+	UserID      int       `json:"user_id"`
+	Username    string    `json:"username"`
+	Content     string    `json:"content"`      // Markdown tal como lo escribió el autor
+	ContentHTML string    `json:"content_html"` // HTML sanitizado generado a partir de Content
+	CreatedAt   time.Time `json:"created_at"`
+	// Ocultado por moderación: solo lo ve su autor
+	HiddenAt *time.Time `json:"hidden_at,omitempty"`
+	// Reacciones por tipo, el total y las que puso el usuario que consulta
+	Reactions     map[string]int `json:"reactions"`
+	ReactionCount int            `json:"reaction_count"`
+	MyReactions   []string       `json:"my_reactions"`
+	// Usuarios mencionados con @usuario en Content
+	Mentions []Mention `json:"mentions"`
+	// Solo en la papelera: cuándo se borró y cuándo se elimina para siempre
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	PurgeAt   *time.Time `json:"purge_at,omitempty"`
 }
 
 // CreateCommentRequest se usa para crear un comentario
 type CreateCommentRequest struct {
 	Content string `json:"content"`
 }
+
+// Trash es la papelera de un usuario: los posts y comentarios que borró
+// y todavía puede restaurar, los borrados más recientemente primero
+type Trash struct {
+	Posts         []*Post    `json:"posts"`
+	Comments      []*Comment `json:"comments"`
+	RetentionDays int        `json:"retention_days"`
+}
+
+// SitemapEntry es un post publicado tal como aparece en el sitemap
+type SitemapEntry struct {
+	Slug         string
+	LastModified time.Time // La más reciente entre publicación y última edición
+}
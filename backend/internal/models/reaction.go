@@ -0,0 +1,43 @@
+package models
+
+// Tipos de reacción permitidos: "me gusta" más un conjunto fijo de emojis
+const (
+	ReactionLike      = "like"      // 👍
+	ReactionLove      = "love"      // ❤️
+	ReactionLaugh     = "laugh"     // 😂
+	ReactionWow       = "wow"       // 😮
+	ReactionSad       = "sad"       // 😢
+	ReactionCelebrate = "celebrate" // 🎉
+)
+
+// ReactionTypes son los tipos válidos, en el orden en que se muestran
+var ReactionTypes = []string{
+	ReactionLike,
+	ReactionLove,
+	ReactionLaugh,
+	ReactionWow,
+	ReactionSad,
+	ReactionCelebrate,
+}
+
+// IsValidReaction indica si el tipo de reacción está permitido
+func IsValidReaction(reactionType string) bool {
+	for _, t := range ReactionTypes {
+		if t == reactionType {
+			return true
+		}
+	}
+	return false
+}
+
+// ReactionSummary resume las reacciones de un post o comentario
+type ReactionSummary struct {
+	Counts map[string]int `json:"reactions"`      // Cantidad por tipo (solo los que tienen alguna)
+	Total  int            `json:"reaction_count"` // Suma de todos los tipos
+	Mine   []string       `json:"my_reactions"`   // Las que puso el usuario que consulta
+}
+
+// NewReactionSummary crea un resumen vacío
+func NewReactionSummary() *ReactionSummary {
+	return &ReactionSummary{Counts: map[string]int{}, Mine: []string{}}
+}
@@ -0,0 +1,119 @@
+package models
+
+import "time"
+
+// Motivos por los que se puede reportar un post o comentario
+const (
+	ReportSpam           = "spam"
+	ReportHarassment     = "harassment"
+	ReportHate           = "hate"
+	ReportViolence       = "violence"
+	ReportSexual         = "sexual"
+	ReportMisinformation = "misinformation"
+	ReportOther          = "other" // Requiere Details
+
+	// ReportFilter es el motivo de los reportes que crea el filtro de
+	// contenido al retener algo para revisión; no lo elige un usuario
+	ReportFilter = "filter"
+)
+
+// ReportReasons son los motivos válidos, en el orden en que se muestran
+var ReportReasons = []string{
+	ReportSpam,
+	ReportHarassment,
+	ReportHate,
+	ReportViolence,
+	ReportSexual,
+	ReportMisinformation,
+	ReportOther,
+}
+
+// IsValidReportReason indica si el motivo de reporte está permitido
+func IsValidReportReason(reason string) bool {
+	for _, r := range ReportReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// Estados de un reporte
+const (
+	ReportOpen      = "open"      // En la cola de moderación
+	ReportResolved  = "resolved"  // Un moderador lo atendió
+	ReportDismissed = "dismissed" // Un moderador lo descartó: el contenido queda visible
+)
+
+// Acciones que quedan registradas en el historial de un reporte
+const (
+	ReportActionHide    = "hide"    // Se ocultó el contenido
+	ReportActionUnhide  = "unhide"  // Se volvió a mostrar al descartar el reporte
+	ReportActionDelete  = "delete"  // Se borró el contenido
+	ReportActionBan     = "ban"     // Se expulsó al autor
+	ReportActionResolve = "resolve" // Se cerró sin tocar el contenido
+	ReportActionDismiss = "dismiss" // Se descartó
+)
+
+// Estados del contenido reportado, tal como está ahora
+const (
+	ReportTargetVisible = "visible"
+	ReportTargetHidden  = "hidden"
+	ReportTargetDeleted = "deleted"
+)
+
+// Report es el reporte de un usuario sobre un post o un comentario
+type Report struct {
+	ID         int    `json:"id"`
+	ReporterID int    `json:"reporter_id,omitempty"` // 0 si lo creó el filtro de contenido
+	PostID     int    `json:"post_id"`
+	CommentID  int    `json:"comment_id,omitempty"` // 0 si se reportó el post
+	AuthorID   int    `json:"author_id"`            // Autor del contenido reportado
+	Reason     string `json:"reason"`
+	Details    string `json:"details,omitempty"`
+	// Texto del contenido al momento de reportarlo
+	Content    string     `json:"content"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+
+	// Solo para moderadores: cómo está el contenido ahora, cuántos
+	// reportes abiertos tiene y el historial de acciones (en el detalle)
+	TargetStatus string          `json:"target_status,omitempty"`
+	OpenReports  int             `json:"open_reports,omitempty"`
+	Actions      []*ReportAction `json:"actions,omitempty"`
+}
+
+// IsComment indica si el reporte es sobre un comentario
+func (r *Report) IsComment() bool {
+	return r.CommentID != 0
+}
+
+// ReportAction es una acción registrada sobre un reporte
+type ReportAction struct {
+	ID          int       `json:"id"`
+	ReportID    int       `json:"report_id"`
+	ModeratorID *int      `json:"moderator_id"` // nil si fue automática
+	Action      string    `json:"action"`
+	Note        string    `json:"note,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CreateReportRequest se usa para reportar un post o comentario
+type CreateReportRequest struct {
+	Reason  string `json:"reason"`
+	Details string `json:"details"`
+}
+
+// ModerateReportRequest se usa para resolver, descartar o actuar sobre un
+// reporte. Action solo se usa en POST /api/admin/reports/{id}/actions.
+type ModerateReportRequest struct {
+	Action string `json:"action,omitempty"`
+	Note   string `json:"note"`
+}
+
+// ReportPage es una página de la cola de moderación
+type ReportPage struct {
+	Reports    []*Report `json:"reports"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,8 @@
+package models
+
+// SpamCounts cuenta en cuántos documentos de spam y de no spam (ham)
+// apareció una palabra, o cuántos documentos de cada clase se vieron
+type SpamCounts struct {
+	Spam int
+	Ham  int
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Suspension es la suspensión de un usuario por un moderador. Mientras
+// está vigente el usuario no puede iniciar sesión ni publicar.
+type Suspension struct {
+	ID          int        `json:"id"`
+	UserID      int        `json:"user_id"`
+	ModeratorID *int       `json:"moderator_id"` // nil si el moderador ya no existe o es una expulsión migrada
+	Reason      string     `json:"reason"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time `json:"expires_at"` // nil si es permanente
+	LiftedAt    *time.Time `json:"lifted_at,omitempty"`
+	LiftedBy    *int       `json:"lifted_by,omitempty"`
+}
+
+// IsActive indica si la suspensión sigue vigente en now
+func (s *Suspension) IsActive(now time.Time) bool {
+	return s.LiftedAt == nil && (s.ExpiresAt == nil || now.Before(*s.ExpiresAt))
+}
+
+// SuspendUserRequest se usa para suspender a un usuario. Sin expires_at
+// la suspensión es permanente (expulsión).
+type SuspendUserRequest struct {
+	Reason    string     `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
@@ -2,15 +2,27 @@ package models
 
 import "time"
 
+// Roles de los usuarios
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator" // Atiende los reportes en /api/admin
+)
+
 // User representa un usuario del sistema
 type User struct {
 	ID        int       `json:"id"`
 	Email     string    `json:"email"`
 	Password  string    `json:"-"` // No se serializa en JSON (por seguridad)
 	Username  string    `json:"username"`
+	Role      string    `json:"role"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// IsModerator indica si el usuario puede atender reportes
+func (u *User) IsModerator() bool {
+	return u.Role == RoleModerator
+}
+
 // Credentials se usa para login
 type Credentials struct {
 	Email    string `json:"email"`
@@ -23,3 +35,22 @@ type RegisterRequest struct {
 	Password string `json:"password"`
 	Username string `json:"username"`
 }
+
+// UserProfile es la vista pública de un usuario (sin email), con sus
+// contadores de seguidores
+type UserProfile struct {
+	ID             int       `json:"id"`
+	Username       string    `json:"username"`
+	CreatedAt      time.Time `json:"created_at"`
+	FollowerCount  int       `json:"follower_count"`
+	FollowingCount int       `json:"following_count"`
+	FollowedByMe   bool      `json:"followed_by_me"` // Si el usuario que consulta lo sigue
+	// Solo en los listados de seguidores/seguidos: desde cuándo
+	FollowedAt *time.Time `json:"followed_at,omitempty"`
+}
+
+// UserPage es una página de usuarios con paginación por cursor
+type UserPage struct {
+	Users      []*UserProfile `json:"users"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
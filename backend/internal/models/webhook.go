@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// Estados de una entrega de webhook
+const (
+	DeliveryPending   = "pending"   // Espera su próximo intento
+	DeliveryDelivered = "delivered" // El receptor respondió 2xx
+	DeliveryDead      = "dead"      // Agotó los reintentos
+)
+
+// Webhook es una suscripción de un usuario a eventos del blog. Cada evento
+// se envía por POST a URL firmado con Secret.
+type Webhook struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"` // Solo se muestra al crearlo
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Subscribed indica si el webhook recibe ese tipo de evento
+func (w *Webhook) Subscribed(eventType string) bool {
+	for _, event := range w.Events {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateWebhookRequest representa el request para crear un webhook. Sin
+// Secret se genera uno al azar.
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+}
+
+// WebhookDelivery es el envío de un evento a un webhook, con el resultado
+// del último intento
+type WebhookDelivery struct {
+	ID             int        `json:"id"`
+	WebhookID      int        `json:"webhook_id"`
+	EventType      string     `json:"event_type"`
+	Payload        string     `json:"payload"` // Cuerpo JSON que se envía
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at"` // nil si ya no se reintenta
+	ResponseStatus *int       `json:"response_status"` // nil si no hubo respuesta
+	LastError      string     `json:"last_error,omitempty"`
+	RedeliveryOf   *int       `json:"redelivery_of"` // Entrega original si es un reenvío
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+
+	// Datos del webhook para enviarla (solo en las entregas pendientes)
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+// WebhookDeliveryPage es una página del registro de entregas, las más
+// recientes primero
+type WebhookDeliveryPage struct {
+	Deliveries []*WebhookDelivery `json:"deliveries"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,109 @@
+package realtime
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// maxMessageSize es el tamaño máximo de un mensaje del cliente (solo
+// manda avisos de escritura)
+const maxMessageSize = 512
+
+// incoming es un mensaje recibido del cliente
+type incoming struct {
+	Type string `json:"type"`
+}
+
+// client es una conexión WebSocket dentro de una sala. readPump y
+// writePump corren en sus propias goroutines: son la única lectura y la
+// única escritura de la conexión.
+type client struct {
+	conn *websocket.Conn
+	room *room
+	user Typer
+
+	// send lo cierra la sala al sacar al cliente; closeCode se fija antes
+	// y dice con qué código cerrar la conexión
+	send      chan []byte
+	closeCode int
+}
+
+// readPump lee los avisos de escritura del cliente y controla que siga
+// respondiendo los pings. Al cortarse la conexión saca al cliente de la sala.
+func (c *client) readPump(config Config) {
+	defer func() {
+		select {
+		case c.room.leave <- c:
+		case <-c.room.done:
+		}
+	}()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(config.PongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(config.PongWait))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var msg incoming
+		if json.Unmarshal(data, &msg) != nil {
+			continue
+		}
+
+		var update typingUpdate
+		switch msg.Type {
+		case MessageTyping:
+			update = typingUpdate{client: c, typing: true}
+		case MessageStopTyping:
+			update = typingUpdate{client: c, typing: false}
+		default:
+			// Los mensajes desconocidos se ignoran
+			continue
+		}
+
+		select {
+		case c.room.typing <- update:
+		case <-c.room.done:
+			return
+		}
+	}
+}
+
+// writePump envía los mensajes de la sala y los pings. Cuando la sala
+// cierra send, manda el mensaje de cierre y cierra la conexión.
+func (c *client) writePump(config Config, wg *sync.WaitGroup) {
+	ticker := time.NewTicker(config.PingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+		wg.Done()
+	}()
+
+	for {
+		select {
+		case message, open := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(config.WriteWait))
+			if !open {
+				c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(c.closeCode, ""))
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(config.WriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
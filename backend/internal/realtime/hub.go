@@ -0,0 +1,447 @@
+// Package realtime mantiene las salas WebSocket de cada post: los
+// clientes conectados reciben al instante los comentarios nuevos y se
+// avisan entre ellos quién está escribiendo.
+package realtime
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"tp06-testing/internal/events"
+
+	"github.com/gorilla/websocket"
+)
+
+// Tipos de mensaje propios de la sala (los de comentarios y posts son los
+// de events)
+const (
+	MessageTyping     = "typing"      // Cliente → servidor: estoy escribiendo
+	MessageStopTyping = "typing.stop" // Cliente → servidor: dejé de escribir
+	MessagePresence   = "presence"    // Servidor → cliente: quiénes escriben
+)
+
+// ErrHubClosed se devuelve al unirse a una sala cuando el servidor se está apagando
+var ErrHubClosed = errors.New("el servidor se está apagando")
+
+// Message es lo que el servidor envía a los clientes
+type Message struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Typer es un usuario que está escribiendo un comentario
+type Typer struct {
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// Config ajusta los tiempos de la sala. Los valores en cero toman los de
+// DefaultConfig.
+type Config struct {
+	TypingTTL  time.Duration // Cuánto dura "escribiendo" sin un nuevo aviso
+	PingPeriod time.Duration // Cada cuánto se envía un ping al cliente
+	PongWait   time.Duration // Cuánto se espera el pong antes de cortar
+	WriteWait  time.Duration // Tiempo máximo para escribir un mensaje
+	SendBuffer int           // Mensajes sin enviar antes de cortar a un cliente lento
+}
+
+// DefaultConfig es la configuración que usa el servidor
+var DefaultConfig = Config{
+	TypingTTL:  5 * time.Second,
+	PingPeriod: 30 * time.Second,
+	PongWait:   40 * time.Second,
+	WriteWait:  10 * time.Second,
+	SendBuffer: 32,
+}
+
+// Hub reparte a los clientes en una sala por post. Cada sala tiene su
+// propia goroutine, que se crea con el primer cliente y termina cuando se
+// va el último.
+type Hub struct {
+	broker *events.Broker
+	config Config
+
+	mu     sync.Mutex
+	rooms  map[int]*room
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewHub crea un hub que toma los eventos de cada post del broker
+func NewHub(broker *events.Broker, config Config) *Hub {
+	if config.TypingTTL <= 0 {
+		config.TypingTTL = DefaultConfig.TypingTTL
+	}
+	if config.PingPeriod <= 0 {
+		config.PingPeriod = DefaultConfig.PingPeriod
+	}
+	if config.PongWait <= 0 {
+		config.PongWait = DefaultConfig.PongWait
+	}
+	if config.WriteWait <= 0 {
+		config.WriteWait = DefaultConfig.WriteWait
+	}
+	if config.SendBuffer <= 0 {
+		config.SendBuffer = DefaultConfig.SendBuffer
+	}
+	return &Hub{
+		broker: broker,
+		config: config,
+		rooms:  make(map[int]*room),
+	}
+}
+
+// Join suma la conexión a la sala del post y arranca su lectura y
+// escritura. Desde ese momento el hub es dueño de la conexión y la cierra
+// cuando el cliente se va.
+func (h *Hub) Join(postID int, user Typer, conn *websocket.Conn) error {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return ErrHubClosed
+	}
+	r, ok := h.rooms[postID]
+	if !ok {
+		r = newRoom(h, postID)
+		h.rooms[postID] = r
+		h.wg.Add(1)
+		go r.run()
+	}
+	// Se cuenta antes de entrar para que la sala no se cierre entretanto
+	r.members++
+	h.wg.Add(1) // La escritura del cliente, para que Shutdown espere el cierre
+	h.mu.Unlock()
+
+	c := &client{
+		conn: conn,
+		room: r,
+		user: user,
+		send: make(chan []byte, h.config.SendBuffer),
+	}
+
+	select {
+	case r.join <- c:
+	case <-r.done:
+		// El hub se apagó mientras entraba
+		h.wg.Done()
+		return ErrHubClosed
+	}
+
+	go c.writePump(h.config, &h.wg)
+	go c.readPump(h.config)
+	return nil
+}
+
+// Publish envía un evento solo a la sala del post, sin pasar por el
+// broker. Es el camino de los comentarios de posts que no son públicos:
+// al broker lo escucha cualquiera, pero a la sala solo entra quien pasó
+// el control de visibilidad del post. Si no hay sala no hace nada.
+func (h *Hub) Publish(eventType string, postID int, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	r, ok := h.rooms[postID]
+	h.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	select {
+	case r.direct <- &events.Event{Type: eventType, PostID: postID, Data: payload}:
+	case <-r.done:
+		// La sala se cerró entretanto: no queda nadie a quien avisar
+	}
+	return nil
+}
+
+// Disconnect saca al usuario de todas las salas cerrando sus conexiones
+// con 1008 (por ejemplo, al suspenderlo)
+func (h *Hub) Disconnect(userID int) {
+	h.mu.Lock()
+	rooms := make([]*room, 0, len(h.rooms))
+	for _, r := range h.rooms {
+		rooms = append(rooms, r)
+	}
+	h.mu.Unlock()
+
+	for _, r := range rooms {
+		select {
+		case r.kick <- userID:
+		case <-r.done:
+		}
+	}
+}
+
+// RoomCount devuelve la cantidad de salas activas
+func (h *Hub) RoomCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.rooms)
+}
+
+// Shutdown cierra todas las salas avisando a los clientes que el servidor
+// se apaga y espera a que terminen. Después de llamarlo Join falla.
+func (h *Hub) Shutdown() {
+	h.mu.Lock()
+	if !h.closed {
+		h.closed = true
+		for _, r := range h.rooms {
+			close(r.quit)
+		}
+	}
+	h.mu.Unlock()
+
+	h.wg.Wait()
+}
+
+// leaveRoom descuenta un miembro. Si era el último, saca la sala del hub
+// y devuelve true para que su goroutine termine.
+func (h *Hub) leaveRoom(r *room) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r.members--
+	if r.members > 0 {
+		return false
+	}
+	if h.rooms[r.postID] == r {
+		delete(h.rooms, r.postID)
+	}
+	return true
+}
+
+// typingUpdate es un aviso de un cliente de que empezó o dejó de escribir
+type typingUpdate struct {
+	client *client
+	typing bool
+}
+
+// typer es el estado de un usuario que está escribiendo
+type typer struct {
+	username string
+	expires  time.Time
+}
+
+// room es la sala de un post. Todo su estado lo maneja la goroutine run.
+type room struct {
+	hub    *Hub
+	postID int
+
+	members int  // Clientes conectados más los que están entrando (con hub.mu)
+	empty   bool // La sala se quedó sin clientes y run tiene que terminar
+
+	join   chan *client
+	leave  chan *client
+	typing chan typingUpdate
+	direct chan *events.Event // Eventos de Hub.Publish
+	kick   chan int           // Usuarios a desconectar (Hub.Disconnect)
+	quit   chan struct{}      // Se cierra para apagar la sala
+	done   chan struct{}      // Se cierra cuando run terminó
+
+	clients map[*client]bool
+	typers  map[int]*typer
+}
+
+func newRoom(h *Hub, postID int) *room {
+	return &room{
+		hub:     h,
+		postID:  postID,
+		join:    make(chan *client),
+		leave:   make(chan *client),
+		typing:  make(chan typingUpdate),
+		direct:  make(chan *events.Event),
+		kick:    make(chan int),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+		clients: make(map[*client]bool),
+		typers:  make(map[int]*typer),
+	}
+}
+
+// run atiende la sala hasta que se va el último cliente o se apaga el hub
+func (r *room) run() {
+	defer r.hub.wg.Done()
+	defer close(r.done)
+
+	sub, _, _ := r.hub.broker.Subscribe([]int{r.postID}, 0)
+	defer func() { sub.Close() }()
+
+	ticker := time.NewTicker(r.hub.config.TypingTTL / 5)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case c := <-r.join:
+			r.clients[c] = true
+			select {
+			case c.send <- r.presenceMessage():
+			default:
+			}
+
+		case c := <-r.leave:
+			r.remove(c, websocket.CloseNormalClosure)
+
+		case userID := <-r.kick:
+			for c := range r.clients {
+				if c.user.UserID == userID {
+					r.remove(c, websocket.ClosePolicyViolation)
+				}
+			}
+
+		case update := <-r.typing:
+			if !r.clients[update.client] {
+				continue
+			}
+			if r.setTyping(update.client.user, update.typing) {
+				r.broadcast(r.presenceMessage())
+			}
+
+		case event, open := <-sub.C:
+			if !open {
+				// La sala no leyó a tiempo y el broker la dio de baja: se
+				// vuelve a suscribir (los eventos perdidos no se recuperan)
+				sub, _, _ = r.hub.broker.Subscribe([]int{r.postID}, 0)
+				continue
+			}
+			r.deliver(event)
+
+		case event := <-r.direct:
+			r.deliver(event)
+
+		case now := <-ticker.C:
+			if r.expireTypers(now) {
+				r.broadcast(r.presenceMessage())
+			}
+
+		case <-r.quit:
+			for c := range r.clients {
+				c.closeCode = websocket.CloseGoingAway
+				close(c.send)
+			}
+			return
+		}
+
+		if r.empty {
+			return
+		}
+	}
+}
+
+// deliver reenvía un evento del post a toda la sala
+func (r *room) deliver(event *events.Event) {
+	r.broadcast(message(event.Type, event.Data))
+	if event.Type == events.CommentCreated {
+		r.commentPosted(event.Data)
+	}
+}
+
+// remove saca al cliente de la sala y cierra su conexión con closeCode.
+// Si era el último, marca la sala como vacía.
+func (r *room) remove(c *client, closeCode int) {
+	if !r.clients[c] {
+		return
+	}
+	delete(r.clients, c)
+	c.closeCode = closeCode
+	close(c.send)
+
+	if r.hub.leaveRoom(r) {
+		r.empty = true
+		return
+	}
+	if !r.connected(c.user.UserID) && r.setTyping(c.user, false) {
+		r.broadcast(r.presenceMessage())
+	}
+}
+
+// connected indica si el usuario sigue en la sala (desde otra pestaña)
+func (r *room) connected(userID int) bool {
+	for c := range r.clients {
+		if c.user.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// setTyping actualiza el estado del usuario y devuelve true si la lista
+// de quienes escriben cambió
+func (r *room) setTyping(user Typer, typing bool) bool {
+	current, ok := r.typers[user.UserID]
+	if !typing {
+		delete(r.typers, user.UserID)
+		return ok
+	}
+
+	expires := time.Now().Add(r.hub.config.TypingTTL)
+	if ok {
+		current.expires = expires
+		return false
+	}
+	r.typers[user.UserID] = &typer{username: user.Username, expires: expires}
+	return true
+}
+
+// expireTypers saca a quienes dejaron de avisar que escriben
+func (r *room) expireTypers(now time.Time) bool {
+	changed := false
+	for userID, t := range r.typers {
+		if now.After(t.expires) {
+			delete(r.typers, userID)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// commentPosted deja de mostrar como escribiendo al autor de un comentario
+// recién publicado
+func (r *room) commentPosted(data []byte) {
+	var comment struct {
+		UserID int `json:"user_id"`
+	}
+	if json.Unmarshal(data, &comment) != nil {
+		return
+	}
+	if _, ok := r.typers[comment.UserID]; ok {
+		delete(r.typers, comment.UserID)
+		r.broadcast(r.presenceMessage())
+	}
+}
+
+// presenceMessage arma el mensaje con quienes están escribiendo,
+// ordenados por usuario
+func (r *room) presenceMessage() []byte {
+	typers := make([]Typer, 0, len(r.typers))
+	for userID, t := range r.typers {
+		typers = append(typers, Typer{UserID: userID, Username: t.username})
+	}
+	sort.Slice(typers, func(i, j int) bool { return typers[i].UserID < typers[j].UserID })
+
+	data, _ := json.Marshal(map[string][]Typer{"typing": typers})
+	return message(MessagePresence, data)
+}
+
+// message serializa un mensaje para los clientes
+func message(messageType string, data []byte) []byte {
+	encoded, _ := json.Marshal(Message{Type: messageType, Data: data})
+	return encoded
+}
+
+// broadcast envía el mensaje a todos. Al cliente que no vacía su buffer
+// se lo desconecta en lugar de frenar a la sala.
+func (r *room) broadcast(message []byte) {
+	for c := range r.clients {
+		select {
+		case c.send <- message:
+		default:
+			r.remove(c, websocket.ClosePolicyViolation)
+		}
+	}
+}
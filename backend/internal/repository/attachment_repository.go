@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"tp06-testing/internal/models"
+)
+
+// AttachmentRepository define las operaciones sobre los archivos adjuntos
+type AttachmentRepository interface {
+	Create(attachment *models.Attachment) error
+	FindByID(id int) (*models.Attachment, error)
+	FindByPostID(postID int) ([]*models.Attachment, error)
+	FindPendingImages(limit int) ([]*models.Attachment, error)
+	SaveVariants(attachmentID int, variants []*models.AttachmentVariant) error
+	UpdateProcessingStatus(attachmentID int, status string) error
+	Delete(id int) error
+}
+
+// SQLiteAttachmentRepository implementa AttachmentRepository usando SQLite
+type SQLiteAttachmentRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteAttachmentRepository crea una nueva instancia
+func NewSQLiteAttachmentRepository(db *sql.DB) *SQLiteAttachmentRepository {
+	return &SQLiteAttachmentRepository{db: db}
+}
+
+// attachmentColumns son las columnas que se leen al armar un models.Attachment
+const attachmentColumns = `id, post_id, user_id, storage_key, filename, content_type, size, width, height, processing_status, created_at`
+
+// scanAttachment lee una fila con attachmentColumns
+func scanAttachment(row rowScanner) (*models.Attachment, error) {
+	attachment := &models.Attachment{}
+	var postID sql.NullInt64
+	err := row.Scan(
+		&attachment.ID,
+		&postID,
+		&attachment.UserID,
+		&attachment.StorageKey,
+		&attachment.Filename,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.Width,
+		&attachment.Height,
+		&attachment.ProcessingStatus,
+		&attachment.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if postID.Valid {
+		id := int(postID.Int64)
+		attachment.PostID = &id
+	}
+
+	return attachment, nil
+}
+
+// Create inserta un nuevo adjunto
+func (r *SQLiteAttachmentRepository) Create(attachment *models.Attachment) error {
+	query := `
+		INSERT INTO attachments (post_id, user_id, storage_key, filename, content_type, size, width, height, processing_status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	now := time.Now().UTC().Truncate(time.Second)
+	result, err := r.db.Exec(query, attachment.PostID, attachment.UserID, attachment.StorageKey,
+		attachment.Filename, attachment.ContentType, attachment.Size,
+		attachment.Width, attachment.Height, attachment.ProcessingStatus, sqlTime(now))
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	attachment.ID = int(id)
+	attachment.CreatedAt = now
+	return nil
+}
+
+// FindByID busca un adjunto por ID
+func (r *SQLiteAttachmentRepository) FindByID(id int) (*models.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE id = ?`
+
+	attachment, err := scanAttachment(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadVariants([]*models.Attachment{attachment}); err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// FindByPostID obtiene los adjuntos de un post en orden de subida
+func (r *SQLiteAttachmentRepository) FindByPostID(postID int) ([]*models.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE post_id = ? ORDER BY id ASC`
+
+	attachments, err := r.query(query, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadVariants(attachments); err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+// FindPendingImages obtiene las imágenes que todavía no tienen sus
+// versiones reducidas, las más antiguas primero
+func (r *SQLiteAttachmentRepository) FindPendingImages(limit int) ([]*models.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE processing_status = ? ORDER BY id ASC LIMIT ?`
+	return r.query(query, models.AttachmentProcessingPending, limit)
+}
+
+// SaveVariants guarda las versiones reducidas de una imagen y la marca
+// como procesada, en una sola transacción
+func (r *SQLiteAttachmentRepository) SaveVariants(attachmentID int, variants []*models.AttachmentVariant) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE attachments SET processing_status = ? WHERE id = ?`,
+		models.AttachmentProcessingReady, attachmentID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return sql.ErrNoRows // el adjunto se eliminó mientras se procesaba
+	}
+
+	for _, variant := range variants {
+		_, err := tx.Exec(`
+			INSERT OR REPLACE INTO attachment_variants (attachment_id, name, storage_key, content_type, width, height, size)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, attachmentID, variant.Name, variant.StorageKey, variant.ContentType, variant.Width, variant.Height, variant.Size)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UpdateProcessingStatus cambia el estado del procesamiento de una imagen
+func (r *SQLiteAttachmentRepository) UpdateProcessingStatus(attachmentID int, status string) error {
+	_, err := r.db.Exec(`UPDATE attachments SET processing_status = ? WHERE id = ?`, status, attachmentID)
+	return err
+}
+
+// Delete elimina el registro de un adjunto y de sus versiones reducidas
+// (los archivos los borra el service)
+func (r *SQLiteAttachmentRepository) Delete(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM attachment_variants WHERE attachment_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM attachments WHERE id = ?`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// query ejecuta una consulta que devuelve attachmentColumns
+func (r *SQLiteAttachmentRepository) query(query string, args ...interface{}) ([]*models.Attachment, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []*models.Attachment
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, rows.Err()
+}
+
+// loadVariants completa Variants de los adjuntos con una sola consulta
+func (r *SQLiteAttachmentRepository) loadVariants(attachments []*models.Attachment) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*models.Attachment, len(attachments))
+	args := make([]interface{}, 0, len(attachments))
+	for _, attachment := range attachments {
+		byID[attachment.ID] = attachment
+		args = append(args, attachment.ID)
+	}
+
+	query := `
+		SELECT attachment_id, name, storage_key, content_type, width, height, size
+		FROM attachment_variants
+		WHERE attachment_id IN (?` + strings.Repeat(", ?", len(args)-1) + `)
+	`
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var attachmentID int
+		variant := &models.AttachmentVariant{}
+		if err := rows.Scan(&attachmentID, &variant.Name, &variant.StorageKey, &variant.ContentType,
+			&variant.Width, &variant.Height, &variant.Size); err != nil {
+			return err
+		}
+
+		attachment := byID[attachmentID]
+		if attachment.Variants == nil {
+			attachment.Variants = map[string]*models.AttachmentVariant{}
+		}
+		attachment.Variants[variant.Name] = variant
+	}
+
+	return rows.Err()
+}
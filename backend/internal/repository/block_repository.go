@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"tp06-testing/internal/models"
+)
+
+// BlockRepository define las operaciones sobre usuarios bloqueados y
+// silenciados
+type BlockRepository interface {
+	Block(userID int, blockedID int) error
+	Unblock(userID int, blockedID int) error
+	Mute(userID int, mutedID int) error
+	Unmute(userID int, mutedID int) error
+	FindStatus(userID int, otherID int) (*models.BlockStatus, error)
+	FindBlockers(userIDs []int, blockedID int) (map[int]bool, error)
+	FindBlocked(userID int) ([]*models.BlockedUser, error)
+	FindMuted(userID int) ([]*models.BlockedUser, error)
+}
+
+// SQLiteBlockRepository implementa BlockRepository usando SQLite
+type SQLiteBlockRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteBlockRepository crea una nueva instancia
+func NewSQLiteBlockRepository(db *sql.DB) *SQLiteBlockRepository {
+	return &SQLiteBlockRepository{db: db}
+}
+
+// hiddenAuthorFilter es la condición que excluye a los autores (column)
+// que el viewer bloqueó o silenció. Recibe el viewer dos veces.
+func hiddenAuthorFilter(column string) string {
+	return `NOT EXISTS (SELECT 1 FROM user_mutes WHERE user_id = ? AND muted_id = ` + column + `)
+		AND NOT EXISTS (SELECT 1 FROM user_blocks WHERE user_id = ? AND blocked_id = ` + column + `)`
+}
+
+// Block registra que userID bloqueó a blockedID. Si ya estaba bloqueado
+// no cambia nada.
+func (r *SQLiteBlockRepository) Block(userID int, blockedID int) error {
+	_, err := r.db.Exec(`INSERT OR IGNORE INTO user_blocks (user_id, blocked_id, created_at) VALUES (?, ?, ?)`,
+		userID, blockedID, sqlTime(time.Now()))
+	return err
+}
+
+// Unblock quita el bloqueo
+func (r *SQLiteBlockRepository) Unblock(userID int, blockedID int) error {
+	_, err := r.db.Exec(`DELETE FROM user_blocks WHERE user_id = ? AND blocked_id = ?`, userID, blockedID)
+	return err
+}
+
+// Mute registra que userID silenció a mutedID. Si ya estaba silenciado no
+// cambia nada.
+func (r *SQLiteBlockRepository) Mute(userID int, mutedID int) error {
+	_, err := r.db.Exec(`INSERT OR IGNORE INTO user_mutes (user_id, muted_id, created_at) VALUES (?, ?, ?)`,
+		userID, mutedID, sqlTime(time.Now()))
+	return err
+}
+
+// Unmute quita el silencio
+func (r *SQLiteBlockRepository) Unmute(userID int, mutedID int) error {
+	_, err := r.db.Exec(`DELETE FROM user_mutes WHERE user_id = ? AND muted_id = ?`, userID, mutedID)
+	return err
+}
+
+// FindStatus indica si userID bloqueó o silenció a otherID
+func (r *SQLiteBlockRepository) FindStatus(userID int, otherID int) (*models.BlockStatus, error) {
+	status := &models.BlockStatus{UserID: otherID}
+	err := r.db.QueryRow(`
+		SELECT
+			EXISTS (SELECT 1 FROM user_blocks WHERE user_id = ? AND blocked_id = ?),
+			EXISTS (SELECT 1 FROM user_mutes WHERE user_id = ? AND muted_id = ?)
+	`, userID, otherID, userID, otherID).Scan(&status.Blocked, &status.Muted)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// FindBlockers devuelve cuáles de userIDs bloquearon a blockedID
+func (r *SQLiteBlockRepository) FindBlockers(userIDs []int, blockedID int) (map[int]bool, error) {
+	blockers := make(map[int]bool)
+
+	for start := 0; start < len(userIDs); start += idBatchSize {
+		batch := userIDs[start:min(start+idBatchSize, len(userIDs))]
+
+		args := []interface{}{blockedID}
+		for _, id := range batch {
+			args = append(args, id)
+		}
+
+		query := `SELECT user_id FROM user_blocks WHERE blocked_id = ? AND user_id IN (?` + strings.Repeat(", ?", len(batch)-1) + `)`
+		rows, err := r.db.Query(query, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			blockers[id] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return blockers, nil
+}
+
+// FindBlocked obtiene los usuarios que bloqueó userID, los más recientes
+// primero
+func (r *SQLiteBlockRepository) FindBlocked(userID int) ([]*models.BlockedUser, error) {
+	return r.findUsers(`
+		SELECT u.id, u.username, b.created_at
+		FROM user_blocks b
+		JOIN users u ON u.id = b.blocked_id
+		WHERE b.user_id = ?
+		ORDER BY b.created_at DESC, u.id DESC
+	`, userID)
+}
+
+// FindMuted obtiene los usuarios que silenció userID, los más recientes
+// primero
+func (r *SQLiteBlockRepository) FindMuted(userID int) ([]*models.BlockedUser, error) {
+	return r.findUsers(`
+		SELECT u.id, u.username, m.created_at
+		FROM user_mutes m
+		JOIN users u ON u.id = m.muted_id
+		WHERE m.user_id = ?
+		ORDER BY m.created_at DESC, u.id DESC
+	`, userID)
+}
+
+func (r *SQLiteBlockRepository) findUsers(query string, userID int) ([]*models.BlockedUser, error) {
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*models.BlockedUser{}
+	for rows.Next() {
+		user := &models.BlockedUser{}
+		if err := rows.Scan(&user.ID, &user.Username, &user.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"tp06-testing/internal/models"
+)
+
+// BookmarkRepository define las operaciones sobre los posts guardados
+type BookmarkRepository interface {
+	Add(userID int, postID int) error
+	Remove(userID int, postID int) error
+	FindPostsByUser(userID int, after *models.Cursor, limit int) ([]*models.Post, error)
+	BookmarkedPostIDs(userID int, postIDs []int) (map[int]bool, error)
+}
+
+// SQLiteBookmarkRepository implementa BookmarkRepository usando SQLite
+type SQLiteBookmarkRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteBookmarkRepository crea una nueva instancia
+func NewSQLiteBookmarkRepository(db *sql.DB) *SQLiteBookmarkRepository {
+	return &SQLiteBookmarkRepository{db: db}
+}
+
+// Add guarda el post para el usuario (si ya estaba guardado no cambia nada)
+func (r *SQLiteBookmarkRepository) Add(userID int, postID int) error {
+	query := `INSERT OR IGNORE INTO bookmarks (user_id, post_id, created_at) VALUES (?, ?, ?)`
+	_, err := r.db.Exec(query, userID, postID, sqlTime(time.Now()))
+	return err
+}
+
+// Remove quita el post de los guardados del usuario
+func (r *SQLiteBookmarkRepository) Remove(userID int, postID int) error {
+	_, err := r.db.Exec(`DELETE FROM bookmarks WHERE user_id = ? AND post_id = ?`, userID, postID)
+	return err
+}
+
+// FindPostsByUser obtiene los posts guardados por el usuario, los últimos
+// guardados primero, a partir del cursor (nil para la primera página).
+// Solo incluye los posts que el usuario puede ver (los no listados
+// también, ya que llegó a ellos por el link). BookmarkedAt de cada post es
+// la fecha en que se guardó, que es la que usa el cursor.
+func (r *SQLiteBookmarkRepository) FindPostsByUser(userID int, after *models.Cursor, limit int) ([]*models.Post, error) {
+	query := `
+		SELECT ` + postColumns + `, b.created_at
+		FROM bookmarks b
+		JOIN posts p ON p.id = b.post_id
+		JOIN users u ON p.user_id = u.id
+		WHERE b.user_id = ?
+			AND ((p.status = 'published' AND p.hidden_at IS NULL
+				AND (p.visibility = 'unlisted' OR ` + listedVisibility + `)) OR p.user_id = ?)
+			AND p.deleted_at IS NULL
+	`
+	args := []interface{}{userID, userID, userID}
+
+	if after != nil {
+		query += ` AND (b.created_at < ? OR (b.created_at = ? AND b.post_id < ?))`
+		at := sqlTime(after.Time)
+		args = append(args, at, at, after.ID)
+	}
+
+	query += ` ORDER BY b.created_at DESC, b.post_id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*models.Post
+	for rows.Next() {
+		var bookmarkedAt time.Time
+		post, err := scanPost(rows, &bookmarkedAt)
+		if err != nil {
+			return nil, err
+		}
+		post.Bookmarked = true
+		post.BookmarkedAt = &bookmarkedAt
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// BookmarkedPostIDs indica cuáles de los posts guardó el usuario
+func (r *SQLiteBookmarkRepository) BookmarkedPostIDs(userID int, postIDs []int) (map[int]bool, error) {
+	result := make(map[int]bool)
+
+	for start := 0; start < len(postIDs); start += idBatchSize {
+		batch := postIDs[start:min(start+idBatchSize, len(postIDs))]
+
+		args := []interface{}{userID}
+		for _, id := range batch {
+			args = append(args, id)
+		}
+
+		query := `SELECT post_id FROM bookmarks WHERE user_id = ? AND post_id IN (?` + strings.Repeat(", ?", len(batch)-1) + `)`
+		rows, err := r.db.Query(query, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var postID int
+			if err := rows.Scan(&postID); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			result[postID] = true
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
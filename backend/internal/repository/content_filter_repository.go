@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"tp06-testing/internal/models"
+)
+
+// ContentFilterRepository define los datos que usan los filtros de
+// contenido: lo último que escribió un usuario y las estadísticas del
+// clasificador de spam
+type ContentFilterRepository interface {
+	FindRecentContent(userID int, since time.Time, excludePostID int, limit int) ([]string, error)
+	FindTokenCounts(tokens []string) (map[string]models.SpamCounts, error)
+	FindDocumentCounts() (models.SpamCounts, error)
+	Train(tokens []string, spam bool) error
+}
+
+// SQLiteContentFilterRepository implementa ContentFilterRepository usando SQLite
+type SQLiteContentFilterRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteContentFilterRepository crea una nueva instancia
+func NewSQLiteContentFilterRepository(db *sql.DB) *SQLiteContentFilterRepository {
+	return &SQLiteContentFilterRepository{db: db}
+}
+
+// FindRecentContent obtiene los textos de los posts (título y contenido)
+// y comentarios que el usuario creó desde since, los más nuevos primero.
+// excludePostID es el post que se está editando, que no cuenta, y lo que
+// está en la papelera tampoco.
+func (r *SQLiteContentFilterRepository) FindRecentContent(userID int, since time.Time, excludePostID int, limit int) ([]string, error) {
+	rows, err := r.db.Query(`
+		SELECT text FROM (
+			SELECT title || char(10) || char(10) || content AS text, created_at
+			FROM posts WHERE user_id = ? AND created_at > ? AND id != ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT content, created_at
+			FROM comments WHERE user_id = ? AND created_at > ? AND deleted_at IS NULL
+		)
+		ORDER BY created_at DESC
+		LIMIT ?
+	`, userID, sqlTime(since), excludePostID, userID, sqlTime(since), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var texts []string
+	for rows.Next() {
+		var text string
+		if err := rows.Scan(&text); err != nil {
+			return nil, err
+		}
+		texts = append(texts, text)
+	}
+
+	return texts, rows.Err()
+}
+
+// FindTokenCounts obtiene cuántas veces se vio cada palabra en spam y en
+// ham. Las que nunca se vieron no aparecen en el mapa.
+func (r *SQLiteContentFilterRepository) FindTokenCounts(tokens []string) (map[string]models.SpamCounts, error) {
+	counts := make(map[string]models.SpamCounts)
+
+	for start := 0; start < len(tokens); start += idBatchSize {
+		batch := tokens[start:min(start+idBatchSize, len(tokens))]
+
+		args := make([]interface{}, len(batch))
+		for i, token := range batch {
+			args[i] = token
+		}
+
+		query := `SELECT token, spam_count, ham_count FROM spam_tokens WHERE token IN (?` + strings.Repeat(", ?", len(batch)-1) + `)`
+		rows, err := r.db.Query(query, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var token string
+			var count models.SpamCounts
+			if err := rows.Scan(&token, &count.Spam, &count.Ham); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			counts[token] = count
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return counts, nil
+}
+
+// FindDocumentCounts obtiene cuántos documentos de spam y de ham se
+// usaron para entrenar
+func (r *SQLiteContentFilterRepository) FindDocumentCounts() (models.SpamCounts, error) {
+	var counts models.SpamCounts
+	err := r.db.QueryRow(`
+		SELECT
+			COALESCE((SELECT count FROM spam_documents WHERE label = 'spam'), 0),
+			COALESCE((SELECT count FROM spam_documents WHERE label = 'ham'), 0)
+	`).Scan(&counts.Spam, &counts.Ham)
+	return counts, err
+}
+
+// Train suma un documento de spam o de ham con sus palabras (sin
+// repetir), en una transacción
+func (r *SQLiteContentFilterRepository) Train(tokens []string, spam bool) error {
+	label, column := "ham", "ham_count"
+	if spam {
+		label, column = "spam", "spam_count"
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO spam_tokens (token, ` + column + `) VALUES (?, 1)
+		ON CONFLICT (token) DO UPDATE SET ` + column + ` = ` + column + ` + 1
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, token := range tokens {
+		if _, err := stmt.Exec(token); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO spam_documents (label, count) VALUES (?, 1)
+		ON CONFLICT (label) DO UPDATE SET count = count + 1
+	`, label)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
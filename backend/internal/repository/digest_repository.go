@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"tp06-testing/internal/models"
+)
+
+// DigestRepository define las operaciones del resumen por email
+type DigestRepository interface {
+	FindSettings(userID int) (*models.DigestSettings, error)
+	SaveSettings(settings *models.DigestSettings) error
+	FindDue(now time.Time, limit int) ([]*models.DigestSettings, error)
+	MarkDigested(userID int, until time.Time) error
+	FindNewPosts(userID int, since time.Time, until time.Time, limit int) ([]*models.Post, error)
+	FindReplies(userID int, since time.Time, until time.Time, limit int) ([]*models.DigestReply, error)
+}
+
+// SQLiteDigestRepository implementa DigestRepository usando SQLite
+type SQLiteDigestRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteDigestRepository crea una nueva instancia
+func NewSQLiteDigestRepository(db *sql.DB) *SQLiteDigestRepository {
+	return &SQLiteDigestRepository{db: db}
+}
+
+// scanDigestSettings lee una fila con user_id, frequency y last_digest_at
+func scanDigestSettings(row rowScanner) (*models.DigestSettings, error) {
+	settings := &models.DigestSettings{}
+	var lastDigestAt sql.NullTime
+	if err := row.Scan(&settings.UserID, &settings.Frequency, &lastDigestAt); err != nil {
+		return nil, err
+	}
+	if lastDigestAt.Valid {
+		settings.LastDigestAt = &lastDigestAt.Time
+	}
+	return settings, nil
+}
+
+// FindSettings obtiene la configuración del usuario. Si nunca la cambió
+// el resumen está desactivado.
+func (r *SQLiteDigestRepository) FindSettings(userID int) (*models.DigestSettings, error) {
+	settings, err := scanDigestSettings(r.db.QueryRow(`
+		SELECT user_id, frequency, last_digest_at FROM digest_settings WHERE user_id = ?
+	`, userID))
+	if err == sql.ErrNoRows {
+		return &models.DigestSettings{UserID: userID, Frequency: models.DigestOff}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// SaveSettings guarda la configuración del usuario
+func (r *SQLiteDigestRepository) SaveSettings(settings *models.DigestSettings) error {
+	_, err := r.db.Exec(`
+		INSERT INTO digest_settings (user_id, frequency, last_digest_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			frequency = excluded.frequency,
+			last_digest_at = excluded.last_digest_at
+	`, settings.UserID, settings.Frequency, nullableTime(settings.LastDigestAt))
+	return err
+}
+
+// FindDue obtiene los usuarios a los que ya les corresponde un resumen
+// (pasó un día o una semana desde el anterior), los más atrasados primero
+func (r *SQLiteDigestRepository) FindDue(now time.Time, limit int) ([]*models.DigestSettings, error) {
+	rows, err := r.db.Query(`
+		SELECT user_id, frequency, last_digest_at
+		FROM digest_settings
+		WHERE (frequency = ? AND last_digest_at <= ?)
+			OR (frequency = ? AND last_digest_at <= ?)
+		ORDER BY last_digest_at, user_id
+		LIMIT ?
+	`,
+		models.DigestDaily, sqlTime(now.Add(-models.DigestPeriod(models.DigestDaily))),
+		models.DigestWeekly, sqlTime(now.Add(-models.DigestPeriod(models.DigestWeekly))),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var due []*models.DigestSettings
+	for rows.Next() {
+		settings, err := scanDigestSettings(rows)
+		if err != nil {
+			return nil, err
+		}
+		due = append(due, settings)
+	}
+
+	return due, rows.Err()
+}
+
+// MarkDigested registra hasta dónde cubrió el último resumen del usuario
+func (r *SQLiteDigestRepository) MarkDigested(userID int, until time.Time) error {
+	_, err := r.db.Exec(`UPDATE digest_settings SET last_digest_at = ? WHERE user_id = ?`, sqlTime(until), userID)
+	return err
+}
+
+// FindNewPosts obtiene los posts que publicaron los autores que sigue el
+// usuario en el período (since, until], los más nuevos primero. Como en
+// el feed, incluye los públicos y los de solo seguidores, y se omiten los
+// autores bloqueados o silenciados.
+func (r *SQLiteDigestRepository) FindNewPosts(userID int, since time.Time, until time.Time, limit int) ([]*models.Post, error) {
+	rows, err := r.db.Query(`
+		SELECT `+postColumns+`
+		FROM follows f
+		JOIN posts p ON p.user_id = f.followee_id
+		JOIN users u ON p.user_id = u.id
+		WHERE f.follower_id = ?
+			AND p.status = 'published'
+			AND p.visibility IN ('public', 'followers')
+			AND p.hidden_at IS NULL
+			AND p.deleted_at IS NULL
+			AND p.published_at > ? AND p.published_at <= ?
+			AND `+hiddenAuthorFilter("p.user_id")+`
+		ORDER BY p.published_at DESC, p.id DESC
+		LIMIT ?
+	`, userID, sqlTime(since), sqlTime(until), userID, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*models.Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// FindReplies obtiene los comentarios que otros usuarios dejaron en los
+// posts del usuario en el período (since, until], los más nuevos primero
+func (r *SQLiteDigestRepository) FindReplies(userID int, since time.Time, until time.Time, limit int) ([]*models.DigestReply, error) {
+	rows, err := r.db.Query(`
+		SELECT c.id, p.id, p.title, p.slug, u.username, c.content, c.created_at
+		FROM posts p
+		JOIN comments c ON c.post_id = p.id
+		JOIN users u ON u.id = c.user_id
+		WHERE p.user_id = ?
+			AND c.user_id != p.user_id
+			AND c.hidden_at IS NULL
+			AND c.deleted_at IS NULL
+			AND p.deleted_at IS NULL
+			AND c.created_at > ? AND c.created_at <= ?
+			AND `+hiddenAuthorFilter("c.user_id")+`
+		ORDER BY c.created_at DESC, c.id DESC
+		LIMIT ?
+	`, userID, sqlTime(since), sqlTime(until), userID, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var replies []*models.DigestReply
+	for rows.Next() {
+		reply := &models.DigestReply{}
+		err := rows.Scan(&reply.CommentID, &reply.PostID, &reply.PostTitle, &reply.PostSlug, &reply.Username, &reply.Content, &reply.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		replies = append(replies, reply)
+	}
+
+	return replies, rows.Err()
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"database/sql"
+
+	"tp06-testing/internal/models"
+)
+
+// FederationRepository define las operaciones de ActivityPub: claves de
+// los usuarios locales, actores remotos y respuestas importadas
+type FederationRepository interface {
+	FindKey(userID int) (*models.ActorKey, error)
+	SaveKey(key *models.ActorKey) error
+	FindRemoteActor(uri string) (*models.RemoteActor, error)
+	FindRemoteActorByUser(userID int) (*models.RemoteActor, error)
+	SaveRemoteActor(actor *models.RemoteActor) error
+	FindFollowerActors(userID int) ([]*models.RemoteActor, error)
+	SaveRemoteComment(comment *models.RemoteComment) error
+	FindRemoteComment(objectURI string) (*models.RemoteComment, error)
+}
+
+// SQLiteFederationRepository implementa FederationRepository usando SQLite
+type SQLiteFederationRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteFederationRepository crea una nueva instancia
+func NewSQLiteFederationRepository(db *sql.DB) *SQLiteFederationRepository {
+	return &SQLiteFederationRepository{db: db}
+}
+
+// FindKey busca las claves de un usuario local
+func (r *SQLiteFederationRepository) FindKey(userID int) (*models.ActorKey, error) {
+	key := &models.ActorKey{UserID: userID}
+	err := r.db.QueryRow(`SELECT private_key_pem, public_key_pem FROM actor_keys WHERE user_id = ?`, userID).
+		Scan(&key.PrivateKeyPEM, &key.PublicKeyPEM)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// SaveKey guarda las claves de un usuario. Si ya tenía (otro request las
+// generó al mismo tiempo) se conservan las existentes.
+func (r *SQLiteFederationRepository) SaveKey(key *models.ActorKey) error {
+	_, err := r.db.Exec(`
+		INSERT OR IGNORE INTO actor_keys (user_id, private_key_pem, public_key_pem, created_at)
+		VALUES (?, ?, ?, datetime('now'))
+	`, key.UserID, key.PrivateKeyPEM, key.PublicKeyPEM)
+	return err
+}
+
+// remoteActorColumns son las columnas que se leen al armar un models.RemoteActor
+const remoteActorColumns = `a.user_id, a.uri, u.username, a.key_id, a.public_key_pem, a.inbox, a.shared_inbox`
+
+// scanRemoteActor lee una fila con remoteActorColumns
+func scanRemoteActor(row rowScanner) (*models.RemoteActor, error) {
+	actor := &models.RemoteActor{}
+	err := row.Scan(&actor.UserID, &actor.URI, &actor.Username, &actor.KeyID, &actor.PublicKeyPEM, &actor.Inbox, &actor.SharedInbox)
+	if err != nil {
+		return nil, err
+	}
+	return actor, nil
+}
+
+// FindRemoteActor busca un actor remoto por su ID de ActivityPub
+func (r *SQLiteFederationRepository) FindRemoteActor(uri string) (*models.RemoteActor, error) {
+	return r.findRemoteActor(`a.uri = ?`, uri)
+}
+
+// FindRemoteActorByUser busca el actor remoto que representa un usuario
+// local. Devuelve nil si el usuario es un usuario común.
+func (r *SQLiteFederationRepository) FindRemoteActorByUser(userID int) (*models.RemoteActor, error) {
+	return r.findRemoteActor(`a.user_id = ?`, userID)
+}
+
+func (r *SQLiteFederationRepository) findRemoteActor(condition string, arg interface{}) (*models.RemoteActor, error) {
+	query := `
+		SELECT ` + remoteActorColumns + `
+		FROM remote_actors a
+		JOIN users u ON u.id = a.user_id
+		WHERE ` + condition
+
+	actor, err := scanRemoteActor(r.db.QueryRow(query, arg))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return actor, nil
+}
+
+// SaveRemoteActor guarda un actor remoto. La primera vez crea el usuario
+// local que lo representa (el email es el URI del actor y no tiene
+// contraseña, así que nadie puede iniciar sesión con él); las siguientes
+// actualiza su clave, inboxes y nombre. Completa actor.UserID.
+func (r *SQLiteFederationRepository) SaveRemoteActor(actor *models.RemoteActor) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var userID int
+	err = tx.QueryRow(`SELECT user_id FROM remote_actors WHERE uri = ?`, actor.URI).Scan(&userID)
+	switch {
+	case err == sql.ErrNoRows:
+		result, err := tx.Exec(`
+			INSERT INTO users (email, password, username, created_at)
+			VALUES (?, '', ?, datetime('now'))
+		`, actor.URI, actor.Username)
+		if err != nil {
+			return err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		userID = int(id)
+
+		_, err = tx.Exec(`
+			INSERT INTO remote_actors (user_id, uri, key_id, public_key_pem, inbox, shared_inbox, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, datetime('now'))
+		`, userID, actor.URI, actor.KeyID, actor.PublicKeyPEM, actor.Inbox, actor.SharedInbox)
+		if err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		_, err = tx.Exec(`
+			UPDATE remote_actors
+			SET key_id = ?, public_key_pem = ?, inbox = ?, shared_inbox = ?, updated_at = datetime('now')
+			WHERE user_id = ?
+		`, actor.KeyID, actor.PublicKeyPEM, actor.Inbox, actor.SharedInbox, userID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE users SET username = ? WHERE id = ?`, actor.Username, userID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	actor.UserID = userID
+	return nil
+}
+
+// FindFollowerActors obtiene los actores remotos que siguen a un usuario
+func (r *SQLiteFederationRepository) FindFollowerActors(userID int) ([]*models.RemoteActor, error) {
+	query := `
+		SELECT ` + remoteActorColumns + `
+		FROM follows f
+		JOIN remote_actors a ON a.user_id = f.follower_id
+		JOIN users u ON u.id = a.user_id
+		WHERE f.followee_id = ?
+		ORDER BY a.user_id
+	`
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actors []*models.RemoteActor
+	for rows.Next() {
+		actor, err := scanRemoteActor(rows)
+		if err != nil {
+			return nil, err
+		}
+		actors = append(actors, actor)
+	}
+
+	return actors, rows.Err()
+}
+
+// SaveRemoteComment registra de qué respuesta remota salió un comentario
+func (r *SQLiteFederationRepository) SaveRemoteComment(comment *models.RemoteComment) error {
+	_, err := r.db.Exec(`
+		INSERT INTO remote_comments (object_uri, comment_id, post_id) VALUES (?, ?, ?)
+	`, comment.ObjectURI, comment.CommentID, comment.PostID)
+	return err
+}
+
+// FindRemoteComment busca el comentario importado de una respuesta remota
+func (r *SQLiteFederationRepository) FindRemoteComment(objectURI string) (*models.RemoteComment, error) {
+	comment := &models.RemoteComment{ObjectURI: objectURI}
+	query := `
+		SELECT rc.comment_id, rc.post_id, c.user_id
+		FROM remote_comments rc
+		JOIN comments c ON c.id = rc.comment_id
+		WHERE rc.object_uri = ? AND c.deleted_at IS NULL
+	`
+	err := r.db.QueryRow(query, objectURI).Scan(&comment.CommentID, &comment.PostID, &comment.UserID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"tp06-testing/internal/models"
+)
+
+// FollowRepository define las operaciones sobre quién sigue a quién
+type FollowRepository interface {
+	Follow(followerID int, followeeID int) (bool, error)
+	Unfollow(followerID int, followeeID int) error
+	IsFollowing(followerID int, followeeID int) (bool, error)
+	FindProfile(userID int, viewerID int) (*models.UserProfile, error)
+	FindFollowers(userID int, viewerID int, after *models.Cursor, limit int) ([]*models.UserProfile, error)
+	FindFollowing(userID int, viewerID int, after *models.Cursor, limit int) ([]*models.UserProfile, error)
+}
+
+// SQLiteFollowRepository implementa FollowRepository usando SQLite
+type SQLiteFollowRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteFollowRepository crea una nueva instancia
+func NewSQLiteFollowRepository(db *sql.DB) *SQLiteFollowRepository {
+	return &SQLiteFollowRepository{db: db}
+}
+
+// profileColumns arma un models.UserProfile a partir de la tabla users
+// (alias u). Recibe el viewer como parámetro para followed_by_me.
+const profileColumns = `u.id, u.username, u.created_at,
+	(SELECT COUNT(*) FROM follows WHERE followee_id = u.id),
+	(SELECT COUNT(*) FROM follows WHERE follower_id = u.id),
+	EXISTS (SELECT 1 FROM follows WHERE follower_id = ? AND followee_id = u.id)`
+
+// scanProfile lee una fila con profileColumns (más los destinos extra)
+func scanProfile(row rowScanner, extra ...interface{}) (*models.UserProfile, error) {
+	profile := &models.UserProfile{}
+	dest := []interface{}{
+		&profile.ID,
+		&profile.Username,
+		&profile.CreatedAt,
+		&profile.FollowerCount,
+		&profile.FollowingCount,
+		&profile.FollowedByMe,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// Follow registra que followerID sigue a followeeID. Si ya lo seguía no
+// cambia nada y devuelve false.
+func (r *SQLiteFollowRepository) Follow(followerID int, followeeID int) (bool, error) {
+	query := `INSERT OR IGNORE INTO follows (follower_id, followee_id, created_at) VALUES (?, ?, ?)`
+	result, err := r.db.Exec(query, followerID, followeeID, sqlTime(time.Now()))
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// Unfollow deja de seguir a un usuario
+func (r *SQLiteFollowRepository) Unfollow(followerID int, followeeID int) error {
+	_, err := r.db.Exec(`DELETE FROM follows WHERE follower_id = ? AND followee_id = ?`, followerID, followeeID)
+	return err
+}
+
+// IsFollowing indica si followerID sigue a followeeID
+func (r *SQLiteFollowRepository) IsFollowing(followerID int, followeeID int) (bool, error) {
+	var following bool
+	err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM follows WHERE follower_id = ? AND followee_id = ?)`, followerID, followeeID).Scan(&following)
+	return following, err
+}
+
+// FindProfile busca el perfil público de un usuario
+func (r *SQLiteFollowRepository) FindProfile(userID int, viewerID int) (*models.UserProfile, error) {
+	query := `SELECT ` + profileColumns + ` FROM users u WHERE u.id = ?`
+
+	profile, err := scanProfile(r.db.QueryRow(query, viewerID, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// FindFollowers obtiene quienes siguen al usuario, los más recientes primero
+func (r *SQLiteFollowRepository) FindFollowers(userID int, viewerID int, after *models.Cursor, limit int) ([]*models.UserProfile, error) {
+	return r.findRelated("f.followee_id", "f.follower_id", userID, viewerID, after, limit)
+}
+
+// FindFollowing obtiene a quienes sigue el usuario, los más recientes primero
+func (r *SQLiteFollowRepository) FindFollowing(userID int, viewerID int, after *models.Cursor, limit int) ([]*models.UserProfile, error) {
+	return r.findRelated("f.follower_id", "f.followee_id", userID, viewerID, after, limit)
+}
+
+// findRelated lista los usuarios del otro extremo de la relación. El
+// cursor es la fecha del follow más el ID del usuario listado.
+func (r *SQLiteFollowRepository) findRelated(ownColumn, otherColumn string, userID int, viewerID int, after *models.Cursor, limit int) ([]*models.UserProfile, error) {
+	query := `
+		SELECT ` + profileColumns + `, f.created_at
+		FROM follows f
+		JOIN users u ON u.id = ` + otherColumn + `
+		WHERE ` + ownColumn + ` = ?
+	`
+	args := []interface{}{viewerID, userID}
+
+	if after != nil {
+		query += ` AND (f.created_at < ? OR (f.created_at = ? AND ` + otherColumn + ` < ?))`
+		at := sqlTime(after.Time)
+		args = append(args, at, at, after.ID)
+	}
+
+	query += ` ORDER BY f.created_at DESC, ` + otherColumn + ` DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []*models.UserProfile
+	for rows.Next() {
+		var followedAt time.Time
+		profile, err := scanProfile(rows, &followedAt)
+		if err != nil {
+			return nil, err
+		}
+		profile.FollowedAt = &followedAt
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, rows.Err()
+}
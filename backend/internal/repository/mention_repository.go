@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+
+	"tp06-testing/internal/models"
+)
+
+// MentionRepository define las operaciones sobre las menciones @usuario
+type MentionRepository interface {
+	ReplacePostMentions(postID int, mentions []models.Mention) error
+	SaveCommentMentions(postID int, commentID int, mentions []models.Mention) error
+	PostMentions(postIDs []int) (map[int][]models.Mention, error)
+	CommentMentions(commentIDs []int) (map[int][]models.Mention, error)
+}
+
+// SQLiteMentionRepository implementa MentionRepository usando SQLite
+type SQLiteMentionRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteMentionRepository crea una nueva instancia
+func NewSQLiteMentionRepository(db *sql.DB) *SQLiteMentionRepository {
+	return &SQLiteMentionRepository{db: db}
+}
+
+// ReplacePostMentions reemplaza las menciones del contenido de un post
+// (al editarlo cambian todas las posiciones)
+func (r *SQLiteMentionRepository) ReplacePostMentions(postID int, mentions []models.Mention) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM mentions WHERE post_id = ? AND comment_id IS NULL`, postID); err != nil {
+		return err
+	}
+	if err := insertMentions(tx, postID, nil, mentions); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SaveCommentMentions guarda las menciones de un comentario nuevo
+func (r *SQLiteMentionRepository) SaveCommentMentions(postID int, commentID int, mentions []models.Mention) error {
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertMentions(tx, postID, commentID, mentions); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertMentions(tx *sql.Tx, postID int, commentID interface{}, mentions []models.Mention) error {
+	for _, mention := range mentions {
+		_, err := tx.Exec(`
+			INSERT INTO mentions (post_id, comment_id, user_id, char_offset, char_length)
+			VALUES (?, ?, ?, ?, ?)
+		`, postID, commentID, mention.UserID, mention.Offset, mention.Length)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PostMentions obtiene las menciones del contenido de los posts indicados,
+// en orden de aparición. Los posts sin menciones no aparecen en el mapa.
+func (r *SQLiteMentionRepository) PostMentions(postIDs []int) (map[int][]models.Mention, error) {
+	return r.find("m.post_id", "m.comment_id IS NULL", postIDs)
+}
+
+// CommentMentions es el equivalente de PostMentions para comentarios
+func (r *SQLiteMentionRepository) CommentMentions(commentIDs []int) (map[int][]models.Mention, error) {
+	return r.find("m.comment_id", "1 = 1", commentIDs)
+}
+
+// find carga las menciones agrupadas por la columna target. El nombre de
+// usuario es el actual, no el que se escribió.
+func (r *SQLiteMentionRepository) find(target string, filter string, ids []int) (map[int][]models.Mention, error) {
+	result := make(map[int][]models.Mention)
+
+	for start := 0; start < len(ids); start += idBatchSize {
+		batch := ids[start:min(start+idBatchSize, len(ids))]
+
+		args := make([]interface{}, len(batch))
+		for i, id := range batch {
+			args[i] = id
+		}
+
+		query := `
+			SELECT ` + target + `, m.user_id, u.username, m.char_offset, m.char_length
+			FROM mentions m
+			JOIN users u ON u.id = m.user_id
+			WHERE ` + target + ` IN (?` + strings.Repeat(", ?", len(batch)-1) + `) AND ` + filter + `
+			ORDER BY ` + target + `, m.char_offset
+		`
+		rows, err := r.db.Query(query, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var id int
+			var mention models.Mention
+			if err := rows.Scan(&id, &mention.UserID, &mention.Username, &mention.Offset, &mention.Length); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			result[id] = append(result[id], mention)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"tp06-testing/internal/models"
+)
+
+// NotificationRepository define las operaciones sobre notificaciones
+type NotificationRepository interface {
+	CreateOrCoalesce(notification *models.Notification) error
+	FindByUser(userID int, unreadOnly bool, after *models.Cursor, limit int) ([]*models.Notification, error)
+	CountUnread(userID int) (int, error)
+	MarkRead(userID int, notificationID int) (bool, error)
+	MarkAllRead(userID int) (int, error)
+	FindPreferences(userID int) (*models.NotificationPreferences, error)
+	SavePreferences(prefs *models.NotificationPreferences) error
+}
+
+// SQLiteNotificationRepository implementa NotificationRepository usando SQLite
+type SQLiteNotificationRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteNotificationRepository crea una nueva instancia
+func NewSQLiteNotificationRepository(db *sql.DB) *SQLiteNotificationRepository {
+	return &SQLiteNotificationRepository{db: db}
+}
+
+// notificationColumns son las columnas que se leen al armar un
+// models.Notification (n = notifications, a = actor, p = post)
+const notificationColumns = `n.id, n.user_id, n.type, n.actor_id, a.username,
+	n.post_id, COALESCE(p.title, ''), COALESCE(p.slug, ''), n.comment_id,
+	n.count, n.read_at, n.created_at, n.updated_at`
+
+// scanNotification lee una fila con notificationColumns
+func scanNotification(row rowScanner) (*models.Notification, error) {
+	notification := &models.Notification{}
+	var postID, commentID sql.NullInt64
+	var readAt sql.NullTime
+	err := row.Scan(
+		&notification.ID,
+		&notification.UserID,
+		&notification.Type,
+		&notification.ActorID,
+		&notification.ActorUsername,
+		&postID,
+		&notification.PostTitle,
+		&notification.PostSlug,
+		&commentID,
+		&notification.Count,
+		&readAt,
+		&notification.CreatedAt,
+		&notification.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if postID.Valid {
+		id := int(postID.Int64)
+		notification.PostID = &id
+	}
+	if commentID.Valid {
+		id := int(commentID.Int64)
+		notification.CommentID = &id
+	}
+	if readAt.Valid {
+		notification.ReadAt = &readAt.Time
+		notification.Read = true
+	}
+
+	return notification, nil
+}
+
+// nullableID convierte un *int en un valor apto para Exec
+func nullableID(id *int) interface{} {
+	if id == nil {
+		return nil
+	}
+	return *id
+}
+
+// CreateOrCoalesce guarda la notificación. Si el destinatario ya tiene
+// una sin leer del mismo tipo y sobre el mismo post, la agrupa con esa:
+// suma uno a count y pasa a apuntar al último actor y comentario. En
+// ambos casos completa ID, Count y las fechas de la notificación.
+func (r *SQLiteNotificationRepository) CreateOrCoalesce(notification *models.Notification) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	postID := nullableID(notification.PostID)
+
+	var id, count int
+	var createdAt time.Time
+	err = tx.QueryRow(`
+		SELECT id, count, created_at FROM notifications
+		WHERE user_id = ? AND type = ? AND post_id IS ? AND read_at IS NULL
+		ORDER BY id DESC LIMIT 1
+	`, notification.UserID, notification.Type, postID).Scan(&id, &count, &createdAt)
+
+	switch {
+	case err == sql.ErrNoRows:
+		result, err := tx.Exec(`
+			INSERT INTO notifications (user_id, type, actor_id, post_id, comment_id, count, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, 1, ?, ?)
+		`, notification.UserID, notification.Type, notification.ActorID, postID,
+			nullableID(notification.CommentID), sqlTime(now), sqlTime(now))
+		if err != nil {
+			return err
+		}
+		lastID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		id, count, createdAt = int(lastID), 1, now
+	case err != nil:
+		return err
+	default:
+		count++
+		_, err := tx.Exec(`
+			UPDATE notifications SET count = ?, actor_id = ?, comment_id = ?, updated_at = ?
+			WHERE id = ?
+		`, count, notification.ActorID, nullableID(notification.CommentID), sqlTime(now), id)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	notification.ID = id
+	notification.Count = count
+	notification.CreatedAt = createdAt
+	notification.UpdatedAt = now
+	return nil
+}
+
+// FindByUser obtiene las notificaciones del usuario, las de actividad
+// más reciente primero, a partir del cursor (nil para la primera página).
+// Se omiten las de posts que están en la papelera.
+func (r *SQLiteNotificationRepository) FindByUser(userID int, unreadOnly bool, after *models.Cursor, limit int) ([]*models.Notification, error) {
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications n
+		JOIN users a ON a.id = n.actor_id
+		LEFT JOIN posts p ON p.id = n.post_id
+		WHERE n.user_id = ? AND p.deleted_at IS NULL
+	`
+	args := []interface{}{userID}
+
+	if unreadOnly {
+		query += ` AND n.read_at IS NULL`
+	}
+
+	if after != nil {
+		query += ` AND (n.updated_at < ? OR (n.updated_at = ? AND n.id < ?))`
+		at := sqlTime(after.Time)
+		args = append(args, at, at, after.ID)
+	}
+
+	query += ` ORDER BY n.updated_at DESC, n.id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []*models.Notification
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, rows.Err()
+}
+
+// CountUnread cuenta las notificaciones sin leer del usuario, salvo las
+// de posts que están en la papelera
+func (r *SQLiteNotificationRepository) CountUnread(userID int) (int, error) {
+	var count int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM notifications n
+		LEFT JOIN posts p ON p.id = n.post_id
+		WHERE n.user_id = ? AND n.read_at IS NULL AND p.deleted_at IS NULL
+	`, userID).Scan(&count)
+	return count, err
+}
+
+// MarkRead marca como leída una notificación del usuario. Devuelve false
+// si no existe o es de otro usuario.
+func (r *SQLiteNotificationRepository) MarkRead(userID int, notificationID int) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE notifications SET read_at = COALESCE(read_at, ?)
+		WHERE id = ? AND user_id = ?
+	`, sqlTime(time.Now()), notificationID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// MarkAllRead marca como leídas todas las notificaciones del usuario y
+// devuelve cuántas estaban sin leer
+func (r *SQLiteNotificationRepository) MarkAllRead(userID int) (int, error) {
+	result, err := r.db.Exec(`
+		UPDATE notifications SET read_at = ?
+		WHERE user_id = ? AND read_at IS NULL
+	`, sqlTime(time.Now()), userID)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+// FindPreferences obtiene las preferencias del usuario, o las de por
+// defecto si nunca las cambió
+func (r *SQLiteNotificationRepository) FindPreferences(userID int) (*models.NotificationPreferences, error) {
+	prefs := &models.NotificationPreferences{UserID: userID}
+	err := r.db.QueryRow(`
+		SELECT comments, replies, mentions, follows
+		FROM notification_preferences WHERE user_id = ?
+	`, userID).Scan(&prefs.Comments, &prefs.Replies, &prefs.Mentions, &prefs.Follows)
+
+	if err == sql.ErrNoRows {
+		return models.DefaultNotificationPreferences(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return prefs, nil
+}
+
+// SavePreferences guarda las preferencias del usuario
+func (r *SQLiteNotificationRepository) SavePreferences(prefs *models.NotificationPreferences) error {
+	_, err := r.db.Exec(`
+		INSERT INTO notification_preferences (user_id, comments, replies, mentions, follows)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			comments = excluded.comments,
+			replies = excluded.replies,
+			mentions = excluded.mentions,
+			follows = excluded.follows
+	`, prefs.UserID, prefs.Comments, prefs.Replies, prefs.Mentions, prefs.Follows)
+	return err
+}
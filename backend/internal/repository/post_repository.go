@@ -3,38 +3,157 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"strings"
+	"time"
 
 	"tp06-testing/internal/models"
+
+	"github.com/mattn/go-sqlite3"
 )
 
+// ErrSlugTaken se devuelve al guardar un post cuyo slug ya usa otro post
+// (por ejemplo, si otro lo tomó entre SlugExists y la escritura)
+const ErrSlugTaken = "el slug ya está en uso por otro post"
+
 // PostRepository define las operaciones sobre posts
 type PostRepository interface {
 	Create(post *models.Post) error
-	FindAll() ([]*models.Post, error)
+	FindAll(viewerID int) ([]*models.Post, error)
+	FindFeed(userID int, after *models.Cursor, limit int) ([]*models.Post, error)
 	FindByID(id int) (*models.Post, error)
-	Delete(id int) error
+	FindBySlug(slug string) (*models.Post, error)
+	SlugExists(slug string) (bool, error)
+	Update(post *models.Post, previousSlug string) error
+	UpdateStatus(postID int, status string, publishedAt *time.Time) error
+	PublishDue(now time.Time) ([]int, error)
+	Delete(id int, deletedBy int) error
 	CreateComment(comment *models.Comment) error
-	FindCommentsByPostID(postID int) ([]*models.Comment, error)
-	DeleteComment(postID int, commentID int, userID int) error
+	FindCommentsByPostID(postID int, viewerID int) ([]*models.Comment, error)
+	FindCommentByID(commentID int) (*models.Comment, error)
+	FindCommenterIDs(postID int) ([]int, error)
+	DeleteComment(postID int, commentID int, userID int, deletedBy int) error
+	FindDeletedPosts(userID int, since time.Time) ([]*models.Post, error)
+	FindDeletedComments(userID int, since time.Time) ([]*models.Comment, error)
+	Restore(postID int, userID int, since time.Time) (bool, error)
+	RestoreComment(postID int, commentID int, userID int, since time.Time) (bool, error)
+	PurgeDeleted(before time.Time) (int, int, error)
+	RecountComments() (int, error)
+	CountPublished() (int, error)
+	FindLatestPublic(limit int) ([]*models.Post, error)
+	CountPublicByUser(userID int) (int, error)
+	FindPublicByUser(userID int, offset int, limit int) ([]*models.Post, error)
+	EachPublished(offset int, limit int, fn func(entry *models.SitemapEntry) error) error
 }
 
 // SQLitePostRepository implementa PostRepository usando SQLite
 type SQLitePostRepository struct {
-	db *sql.DB
+	db    *sql.DB
+	clock Clock
+}
+
+// Clock abstrae la hora actual (services.Clock y el reloj falso de los
+// tests la cumplen)
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock usa la hora del sistema
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now().UTC()
 }
 
 // NewSQLitePostRepository crea una nueva instancia
 func NewSQLitePostRepository(db *sql.DB) *SQLitePostRepository {
-	return &SQLitePostRepository{db: db}
+	return &SQLitePostRepository{db: db, clock: systemClock{}}
+}
+
+// SetClock reemplaza el reloj con el que se fechan los comentarios nuevos,
+// para que coincida con el de los servicios (útil en tests)
+func (r *SQLitePostRepository) SetClock(clock Clock) {
+	r.clock = clock
+}
+
+// postColumns son las columnas que se leen al armar un models.Post
+const postColumns = `p.id, p.title, p.content, p.slug, p.status, p.visibility, p.published_at,
+	p.comment_count, p.last_comment_at, p.user_id, u.username, p.created_at, p.updated_at, p.hidden_at`
+
+// listedVisibility es la condición de visibilidad de los posts ajenos que
+// aparecen en los listados del viewer: los públicos y, si sigue al autor,
+// los de solo seguidores. Los no listados y los privados nunca aparecen.
+// Recibe el viewer una vez.
+const listedVisibility = `(p.visibility = 'public' OR (p.visibility = 'followers'
+	AND EXISTS (SELECT 1 FROM follows WHERE follower_id = ? AND followee_id = p.user_id)))`
+
+// rowScanner abstrae *sql.Row y *sql.Rows para reutilizar scanPost
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPost lee una fila con postColumns. Si la consulta agrega columnas
+// después de postColumns, sus destinos se pasan en extra.
+func scanPost(row rowScanner, extra ...interface{}) (*models.Post, error) {
+	post := &models.Post{}
+	var publishedAt, lastCommentAt, hiddenAt sql.NullTime
+	dest := []interface{}{
+		&post.ID,
+		&post.Title,
+		&post.Content,
+		&post.Slug,
+		&post.Status,
+		&post.Visibility,
+		&publishedAt,
+		&post.CommentCount,
+		&lastCommentAt,
+		&post.UserID,
+		&post.Username,
+		&post.CreatedAt,
+		&post.UpdatedAt,
+		&hiddenAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if publishedAt.Valid {
+		post.PublishedAt = &publishedAt.Time
+	}
+	if lastCommentAt.Valid {
+		post.LastCommentAt = &lastCommentAt.Time
+	}
+	if hiddenAt.Valid {
+		post.HiddenAt = &hiddenAt.Time
+	}
+
+	return post, nil
+}
+
+// sqlTime formatea una fecha igual que datetime('now') de SQLite (UTC)
+// para que las comparaciones entre columnas DATETIME sean consistentes
+func sqlTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+// nullableTime convierte un *time.Time en un valor apto para Exec
+func nullableTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return sqlTime(*t)
 }
 
 // Create inserta un nuevo post
 func (r *SQLitePostRepository) Create(post *models.Post) error {
 	query := `
-		INSERT INTO posts (title, content, user_id, created_at)
-		VALUES (?, ?, ?, datetime('now'))
+		INSERT INTO posts (title, content, slug, status, visibility, published_at, user_id, hidden_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))
 	`
-	result, err := r.db.Exec(query, post.Title, post.Content, post.UserID)
+	result, err := r.db.Exec(query, post.Title, post.Content, post.Slug, post.Status, post.Visibility, nullableTime(post.PublishedAt), post.UserID, nullableTime(post.HiddenAt))
+	if isSlugTaken(err) {
+		return errors.New(ErrSlugTaken)
+	}
 	if err != nil {
 		return err
 	}
@@ -48,16 +167,24 @@ func (r *SQLitePostRepository) Create(post *models.Post) error {
 	return nil
 }
 
-// FindAll obtiene todos los posts con información del autor
-func (r *SQLitePostRepository) FindAll() ([]*models.Post, error) {
+// FindAll obtiene los posts publicados con información del autor que el
+// viewer puede ver en un listado (ver listedVisibility), salvo los ocultos
+// por moderación y los que están en la papelera. Si viewerID corresponde a
+// un usuario, incluye también todos sus propios posts (borradores,
+// programados, archivados, ocultos, no listados y privados) y omite los
+// posts de los usuarios que bloqueó o silenció.
+func (r *SQLitePostRepository) FindAll(viewerID int) ([]*models.Post, error) {
 	query := `
-		SELECT p.id, p.title, p.content, p.user_id, u.username, p.created_at
+		SELECT ` + postColumns + `
 		FROM posts p
 		JOIN users u ON p.user_id = u.id
-		ORDER BY p.created_at DESC
+		WHERE ((p.status = 'published' AND p.hidden_at IS NULL AND ` + listedVisibility + `) OR p.user_id = ?)
+			AND p.deleted_at IS NULL
+			AND ` + hiddenAuthorFilter("p.user_id") + `
+		ORDER BY COALESCE(p.published_at, p.created_at) DESC
 	`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, viewerID, viewerID, viewerID, viewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -65,15 +192,7 @@ func (r *SQLitePostRepository) FindAll() ([]*models.Post, error) {
 
 	var posts []*models.Post
 	for rows.Next() {
-		post := &models.Post{}
-		err := rows.Scan(
-			&post.ID,
-			&post.Title,
-			&post.Content,
-			&post.UserID,
-			&post.Username,
-			&post.CreatedAt,
-		)
+		post, err := scanPost(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -83,25 +202,88 @@ func (r *SQLitePostRepository) FindAll() ([]*models.Post, error) {
 	return posts, nil
 }
 
-// FindByID busca un post por ID
+// FindFeed obtiene los posts publicados de los autores que sigue el
+// usuario, los más nuevos primero, a partir del cursor (fecha de
+// publicación + ID). Se arma al leer (fan-out on read): el join recorre
+// idx_posts_user_feed por cada autor seguido. Incluye los posts públicos y
+// los de solo seguidores, y omite los ocultos por moderación y a los
+// autores que el usuario bloqueó o silenció aunque los siga.
+func (r *SQLitePostRepository) FindFeed(userID int, after *models.Cursor, limit int) ([]*models.Post, error) {
+	query := `
+		SELECT ` + postColumns + `
+		FROM follows f
+		JOIN posts p ON p.user_id = f.followee_id
+		JOIN users u ON p.user_id = u.id
+		WHERE f.follower_id = ?
+			AND p.status = 'published'
+			AND p.visibility IN ('public', 'followers')
+			AND p.hidden_at IS NULL
+			AND p.deleted_at IS NULL
+			AND ` + hiddenAuthorFilter("p.user_id") + `
+	`
+	args := []interface{}{userID, userID, userID}
+
+	if after != nil {
+		query += ` AND (p.published_at < ? OR (p.published_at = ? AND p.id < ?))`
+		at := sqlTime(after.Time)
+		args = append(args, at, at, after.ID)
+	}
+
+	query += ` ORDER BY p.published_at DESC, p.id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*models.Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// FindByID busca un post por ID. Los posts en la papelera no se encuentran.
 func (r *SQLitePostRepository) FindByID(id int) (*models.Post, error) {
 	query := `
-		SELECT p.id, p.title, p.content, p.user_id, u.username, p.created_at
+		SELECT ` + postColumns + `
 		FROM posts p
 		JOIN users u ON p.user_id = u.id
-		WHERE p.id = ?
+		WHERE p.id = ? AND p.deleted_at IS NULL
 	`
 
-	post := &models.Post{}
-	err := r.db.QueryRow(query, id).Scan(
-		&post.ID,
-		&post.Title,
-		&post.Content,
-		&post.UserID,
-		&post.Username,
-		&post.CreatedAt,
-	)
+	post, err := scanPost(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// FindBySlug busca un post por su slug actual o por uno anterior
+// que quedó como redirección. El post devuelto trae siempre el slug actual.
+func (r *SQLitePostRepository) FindBySlug(slug string) (*models.Post, error) {
+	query := `
+		SELECT ` + postColumns + `
+		FROM posts p
+		JOIN users u ON p.user_id = u.id
+		WHERE (p.slug = ? OR p.id = (SELECT post_id FROM post_slug_redirects WHERE slug = ?))
+			AND p.deleted_at IS NULL
+		ORDER BY p.slug = ? DESC
+		LIMIT 1
+	`
 
+	post, err := scanPost(r.db.QueryRow(query, slug, slug, slug))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -112,20 +294,128 @@ func (r *SQLitePostRepository) FindByID(id int) (*models.Post, error) {
 	return post, nil
 }
 
-// Delete elimina un post por ID
-func (r *SQLitePostRepository) Delete(id int) error {
-	query := `DELETE FROM posts WHERE id = ?`
-	_, err := r.db.Exec(query, id)
+// SlugExists indica si el slug está en uso, ya sea como slug actual
+// de un post o como redirección de un slug anterior. Los posts en la
+// papelera conservan su slug por si se restauran.
+func (r *SQLitePostRepository) SlugExists(slug string) (bool, error) {
+	query := `
+		SELECT EXISTS(SELECT 1 FROM posts WHERE slug = ?)
+		    OR EXISTS(SELECT 1 FROM post_slug_redirects WHERE slug = ?)
+	`
+
+	var exists bool
+	if err := r.db.QueryRow(query, slug, slug).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// Update actualiza título, contenido, slug y visibilidad de un post. Si el
+// slug cambió, el anterior se guarda como redirección dentro de la misma
+// transacción.
+func (r *SQLitePostRepository) Update(post *models.Post, previousSlug string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// hidden_at solo se agrega: una edición retenida por el filtro se guarda
+	// oculta, pero volver a mostrar un post es decisión de moderación
+	query := `UPDATE posts SET title = ?, content = ?, slug = ?, visibility = ?, hidden_at = COALESCE(hidden_at, ?), updated_at = datetime('now') WHERE id = ?`
+	_, err = tx.Exec(query, post.Title, post.Content, post.Slug, post.Visibility, nullableTime(post.HiddenAt), post.ID)
+	if isSlugTaken(err) {
+		return errors.New(ErrSlugTaken)
+	}
+	if err != nil {
+		return err
+	}
+
+	if previousSlug != "" && previousSlug != post.Slug {
+		// Si el nuevo slug era una redirección de este post, deja de serlo
+		if _, err := tx.Exec(`DELETE FROM post_slug_redirects WHERE slug = ? AND post_id = ?`, post.Slug, post.ID); err != nil {
+			return err
+		}
+
+		redirect := `INSERT INTO post_slug_redirects (slug, post_id, created_at) VALUES (?, ?, datetime('now'))`
+		if _, err := tx.Exec(redirect, previousSlug, post.ID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// isSlugTaken indica si err es la violación del índice único de posts.slug
+func isSlugTaken(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) &&
+		sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique &&
+		strings.Contains(sqliteErr.Error(), "posts.slug")
+}
+
+// UpdateStatus cambia el estado y la fecha de publicación de un post
+func (r *SQLitePostRepository) UpdateStatus(postID int, status string, publishedAt *time.Time) error {
+	query := `UPDATE posts SET status = ?, published_at = ? WHERE id = ?`
+	_, err := r.db.Exec(query, status, nullableTime(publishedAt), postID)
+	return err
+}
+
+// PublishDue publica los posts programados cuya fecha ya llegó
+// y devuelve los IDs de los posts publicados
+func (r *SQLitePostRepository) PublishDue(now time.Time) ([]int, error) {
+	query := `
+		UPDATE posts SET status = 'published'
+		WHERE status = 'scheduled' AND published_at <= ? AND deleted_at IS NULL
+		RETURNING id
+	`
+
+	rows, err := r.db.Query(query, sqlTime(now))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// Delete manda un post a la papelera registrando quién lo borró. Sus
+// comentarios quedan como estaban y vuelven con él si se restaura.
+func (r *SQLitePostRepository) Delete(id int, deletedBy int) error {
+	query := `
+		UPDATE posts SET deleted_at = datetime('now'), deleted_by = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`
+	_, err := r.db.Exec(query, deletedBy, id)
 	return err
 }
 
-// CreateComment inserta un nuevo comentario
+// CreateComment inserta un nuevo comentario y actualiza, en la misma
+// transacción, el contador y la fecha del último comentario del post
 func (r *SQLitePostRepository) CreateComment(comment *models.Comment) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	createdAt := r.clock.Now().UTC().Truncate(time.Second)
+	now := sqlTime(createdAt)
 	query := `
-		INSERT INTO comments (post_id, user_id, content, created_at)
-		VALUES (?, ?, ?, datetime('now'))
+		INSERT INTO comments (post_id, user_id, content, hidden_at, created_at)
+		VALUES (?, ?, ?, ?, ?)
 	`
-	result, err := r.db.Exec(query, comment.PostID, comment.UserID, comment.Content)
+	result, err := tx.Exec(query, comment.PostID, comment.UserID, comment.Content, nullableTime(comment.HiddenAt), now)
 	if err != nil {
 		return err
 	}
@@ -135,21 +425,70 @@ func (r *SQLitePostRepository) CreateComment(comment *models.Comment) error {
 		return err
 	}
 
+	// Un comentario que nace oculto (retenido por los filtros) no cuenta
+	if comment.HiddenAt == nil {
+		counters := `
+			UPDATE posts
+			SET comment_count = comment_count + 1, last_comment_at = ?
+			WHERE id = ?
+		`
+		if _, err := tx.Exec(counters, now, comment.PostID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
 	comment.ID = int(id)
+	comment.CreatedAt = createdAt
 	return nil
 }
 
-// FindCommentsByPostID obtiene todos los comentarios de un post
-func (r *SQLitePostRepository) FindCommentsByPostID(postID int) ([]*models.Comment, error) {
+// commentColumns son las columnas que se leen al armar un models.Comment
+const commentColumns = `c.id, c.post_id, c.user_id, u.username, c.content, c.created_at, c.hidden_at`
+
+// scanComment lee una fila con commentColumns. Si la consulta agrega
+// columnas después de commentColumns, sus destinos se pasan en extra.
+func scanComment(row rowScanner, extra ...interface{}) (*models.Comment, error) {
+	comment := &models.Comment{}
+	var hiddenAt sql.NullTime
+	dest := []interface{}{
+		&comment.ID,
+		&comment.PostID,
+		&comment.UserID,
+		&comment.Username,
+		&comment.Content,
+		&comment.CreatedAt,
+		&hiddenAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+	if hiddenAt.Valid {
+		comment.HiddenAt = &hiddenAt.Time
+	}
+	return comment, nil
+}
+
+// FindCommentsByPostID obtiene los comentarios de un post, salvo los de
+// usuarios que el viewer bloqueó o silenció, los ocultos por moderación
+// (que solo ve su autor) y los que están en la papelera
+func (r *SQLitePostRepository) FindCommentsByPostID(postID int, viewerID int) ([]*models.Comment, error) {
 	query := `
-		SELECT c.id, c.post_id, c.user_id, u.username, c.content, c.created_at
+		SELECT ` + commentColumns + `
 		FROM comments c
 		JOIN users u ON c.user_id = u.id
 		WHERE c.post_id = ?
+			AND c.deleted_at IS NULL
+			AND (c.hidden_at IS NULL OR c.user_id = ?)
+			AND ` + hiddenAuthorFilter("c.user_id") + `
 		ORDER BY c.created_at ASC
 	`
 
-	rows, err := r.db.Query(query, postID)
+	rows, err := r.db.Query(query, postID, viewerID, viewerID, viewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -157,15 +496,7 @@ func (r *SQLitePostRepository) FindCommentsByPostID(postID int) ([]*models.Comme
 
 	var comments []*models.Comment
 	for rows.Next() {
-		comment := &models.Comment{}
-		err := rows.Scan(
-			&comment.ID,
-			&comment.PostID,
-			&comment.UserID,
-			&comment.Username,
-			&comment.Content,
-			&comment.CreatedAt,
-		)
+		comment, err := scanComment(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -175,12 +506,62 @@ func (r *SQLitePostRepository) FindCommentsByPostID(postID int) ([]*models.Comme
 	return comments, nil
 }
 
-func (r *SQLitePostRepository) DeleteComment(postID int, commentID int, userID int) error {
+// FindCommentByID busca un comentario por ID. Los comentarios en la
+// papelera no se encuentran.
+func (r *SQLitePostRepository) FindCommentByID(commentID int) (*models.Comment, error) {
 	query := `
-		DELETE FROM comments
-		WHERE id = ? AND post_id = ? AND user_id = ?
+		SELECT ` + commentColumns + `
+		FROM comments c
+		JOIN users u ON c.user_id = u.id
+		WHERE c.id = ? AND c.deleted_at IS NULL
 	`
-	result, err := r.db.Exec(query, commentID, postID, userID)
+
+	comment, err := scanComment(r.db.QueryRow(query, commentID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// FindCommenterIDs obtiene los usuarios que comentaron en un post
+func (r *SQLitePostRepository) FindCommenterIDs(postID int) ([]int, error) {
+	rows, err := r.db.Query(`SELECT DISTINCT user_id FROM comments WHERE post_id = ? AND deleted_at IS NULL ORDER BY user_id`, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// DeleteComment manda a la papelera un comentario de userID registrando
+// quién lo borró, y recalcula los contadores del post en la misma
+// transacción
+func (r *SQLitePostRepository) DeleteComment(postID int, commentID int, userID int, deletedBy int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE comments SET deleted_at = datetime('now'), deleted_by = ?
+		WHERE id = ? AND post_id = ? AND user_id = ? AND deleted_at IS NULL
+	`
+	result, err := tx.Exec(query, deletedBy, commentID, postID, userID)
 	if err != nil {
 		return err
 	}
@@ -191,5 +572,314 @@ func (r *SQLitePostRepository) DeleteComment(postID int, commentID int, userID i
 	if rowsAffected == 0 {
 		return errors.New("no tienes permiso para eliminar este comentario o no existe")
 	}
-	return nil
+
+	if err := updateCommentCounters(tx, postID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// FindDeletedPosts obtiene los posts que el usuario mandó a la papelera
+// después de since, los borrados más recientemente primero. Los que borró
+// moderación no se incluyen porque el autor no puede restaurarlos.
+func (r *SQLitePostRepository) FindDeletedPosts(userID int, since time.Time) ([]*models.Post, error) {
+	rows, err := r.db.Query(`
+		SELECT `+postColumns+`, p.deleted_at
+		FROM posts p
+		JOIN users u ON p.user_id = u.id
+		WHERE p.user_id = ? AND p.deleted_by = p.user_id AND p.deleted_at > ?
+		ORDER BY p.deleted_at DESC, p.id DESC
+	`, userID, sqlTime(since))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*models.Post
+	for rows.Next() {
+		var deletedAt time.Time
+		post, err := scanPost(rows, &deletedAt)
+		if err != nil {
+			return nil, err
+		}
+		post.DeletedAt = &deletedAt
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// FindDeletedComments obtiene los comentarios que el usuario mandó a la
+// papelera después de since, los borrados más recientemente primero. Se
+// omiten los de posts que también están en la papelera, que vuelven con
+// el post.
+func (r *SQLitePostRepository) FindDeletedComments(userID int, since time.Time) ([]*models.Comment, error) {
+	rows, err := r.db.Query(`
+		SELECT `+commentColumns+`, c.deleted_at
+		FROM comments c
+		JOIN users u ON c.user_id = u.id
+		JOIN posts p ON p.id = c.post_id
+		WHERE c.user_id = ? AND c.deleted_by = c.user_id AND c.deleted_at > ?
+			AND p.deleted_at IS NULL
+		ORDER BY c.deleted_at DESC, c.id DESC
+	`, userID, sqlTime(since))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []*models.Comment
+	for rows.Next() {
+		var deletedAt time.Time
+		comment, err := scanComment(rows, &deletedAt)
+		if err != nil {
+			return nil, err
+		}
+		comment.DeletedAt = &deletedAt
+		comments = append(comments, comment)
+	}
+
+	return comments, rows.Err()
+}
+
+// Restore saca de la papelera un post que el propio usuario borró después
+// de since. Devuelve false si no había ninguno que restaurar.
+func (r *SQLitePostRepository) Restore(postID int, userID int, since time.Time) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE posts SET deleted_at = NULL, deleted_by = NULL
+		WHERE id = ? AND user_id = ? AND deleted_by = user_id AND deleted_at > ?
+	`, postID, userID, sqlTime(since))
+	if err != nil {
+		return false, err
+	}
+
+	restored, err := result.RowsAffected()
+	return restored > 0, err
+}
+
+// RestoreComment saca de la papelera un comentario que el propio usuario
+// borró después de since, siempre que su post no esté también borrado, y
+// recalcula los contadores del post en la misma transacción. Devuelve
+// false si no había ninguno que restaurar.
+func (r *SQLitePostRepository) RestoreComment(postID int, commentID int, userID int, since time.Time) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE comments SET deleted_at = NULL, deleted_by = NULL
+		WHERE id = ? AND post_id = ? AND user_id = ? AND deleted_by = user_id AND deleted_at > ?
+			AND EXISTS(SELECT 1 FROM posts WHERE id = ? AND deleted_at IS NULL)
+	`, commentID, postID, userID, sqlTime(since), postID)
+	if err != nil {
+		return false, err
+	}
+	restored, err := result.RowsAffected()
+	if err != nil || restored == 0 {
+		return false, err
+	}
+
+	if err := updateCommentCounters(tx, postID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// PurgeDeleted elimina definitivamente los posts y comentarios que están
+// en la papelera desde antes de before (lo que cuelga de ellos se borra en
+// cascada) y devuelve cuántos posts y cuántos comentarios se eliminaron
+func (r *SQLitePostRepository) PurgeDeleted(before time.Time) (int, int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	cutoff := sqlTime(before)
+	result, err := tx.Exec(`DELETE FROM comments WHERE deleted_at <= ?`, cutoff)
+	if err != nil {
+		return 0, 0, err
+	}
+	comments, err := result.RowsAffected()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	result, err = tx.Exec(`DELETE FROM posts WHERE deleted_at <= ?`, cutoff)
+	if err != nil {
+		return 0, 0, err
+	}
+	posts, err := result.RowsAffected()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return int(posts), int(comments), tx.Commit()
+}
+
+// updateCommentCounters recalcula contador y último comentario de un
+// post. Los comentarios ocultos por moderación o en la papelera no cuentan.
+func updateCommentCounters(tx *sql.Tx, postID int) error {
+	query := `
+		UPDATE posts SET
+			comment_count = (SELECT COUNT(*) FROM comments WHERE post_id = ? AND hidden_at IS NULL AND deleted_at IS NULL),
+			last_comment_at = (SELECT MAX(created_at) FROM comments WHERE post_id = ? AND hidden_at IS NULL AND deleted_at IS NULL)
+		WHERE id = ?
+	`
+	_, err := tx.Exec(query, postID, postID, postID)
+	return err
+}
+
+// RecountComments recalcula los contadores de comentarios de todos los
+// posts a partir de la tabla comments y devuelve cuántos posts estaban
+// desactualizados. Lo usa el comando cmd/repair.
+func (r *SQLitePostRepository) RecountComments() (int, error) {
+	query := `
+		WITH actual AS (
+			SELECT p.id,
+				(SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id AND c.hidden_at IS NULL AND c.deleted_at IS NULL) AS total,
+				(SELECT MAX(c.created_at) FROM comments c WHERE c.post_id = p.id AND c.hidden_at IS NULL AND c.deleted_at IS NULL) AS last_at
+			FROM posts p
+		)
+		UPDATE posts SET
+			comment_count = (SELECT total FROM actual WHERE actual.id = posts.id),
+			last_comment_at = (SELECT last_at FROM actual WHERE actual.id = posts.id)
+		WHERE id IN (
+			SELECT a.id FROM actual a JOIN posts p ON p.id = a.id
+			WHERE p.comment_count != a.total OR p.last_comment_at IS NOT a.last_at
+		)
+	`
+	result, err := r.db.Exec(query)
+	if err != nil {
+		return 0, err
+	}
+
+	fixed, err := result.RowsAffected()
+	return int(fixed), err
+}
+
+// CountPublished cuenta los posts publicados y públicos (sin los ocultos
+// por moderación ni los que están en la papelera)
+func (r *SQLitePostRepository) CountPublished() (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM posts WHERE status = 'published' AND visibility = 'public' AND hidden_at IS NULL AND deleted_at IS NULL`).Scan(&count)
+	return count, err
+}
+
+// FindLatestPublic obtiene los últimos limit posts publicados y públicos
+// de todos los autores, los más nuevos primero. Recorre
+// idx_posts_status_published_at y corta en limit, así que no lee el resto.
+func (r *SQLitePostRepository) FindLatestPublic(limit int) ([]*models.Post, error) {
+	query := `
+		SELECT ` + postColumns + `
+		FROM posts p
+		JOIN users u ON p.user_id = u.id
+		WHERE p.status = 'published'
+			AND p.visibility = 'public'
+			AND p.hidden_at IS NULL
+			AND p.deleted_at IS NULL
+		ORDER BY p.published_at DESC, p.id DESC
+		LIMIT ?
+	`
+
+	rows, err := r.db.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*models.Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// CountPublicByUser cuenta los posts publicados y públicos de un usuario
+// (sin los ocultos por moderación ni los que están en la papelera)
+func (r *SQLitePostRepository) CountPublicByUser(userID int) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM posts WHERE user_id = ? AND status = 'published' AND visibility = 'public' AND hidden_at IS NULL AND deleted_at IS NULL`, userID).Scan(&count)
+	return count, err
+}
+
+// FindPublicByUser obtiene los posts publicados y públicos de un usuario,
+// los más nuevos primero, desde offset y hasta limit. Recorre
+// idx_posts_user_feed, así que no lee los posts de otros usuarios.
+func (r *SQLitePostRepository) FindPublicByUser(userID int, offset int, limit int) ([]*models.Post, error) {
+	query := `
+		SELECT ` + postColumns + `
+		FROM posts p
+		JOIN users u ON p.user_id = u.id
+		WHERE p.user_id = ?
+			AND p.status = 'published'
+			AND p.visibility = 'public'
+			AND p.hidden_at IS NULL
+			AND p.deleted_at IS NULL
+		ORDER BY p.published_at DESC, p.id DESC
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := r.db.Query(query, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*models.Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// EachPublished recorre los posts publicados y públicos en orden de ID (desde
+// offset, hasta limit) llamando a fn con cada uno a medida que se leen,
+// sin cargarlos todos en memoria. La entrada se reutiliza entre llamadas,
+// así que fn no debe guardarla. Si fn devuelve un error se corta.
+func (r *SQLitePostRepository) EachPublished(offset int, limit int, fn func(entry *models.SitemapEntry) error) error {
+	rows, err := r.db.Query(`
+		SELECT slug, published_at, updated_at FROM posts
+		WHERE status = 'published' AND visibility = 'public' AND hidden_at IS NULL AND deleted_at IS NULL
+		ORDER BY id
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	entry := &models.SitemapEntry{}
+	for rows.Next() {
+		var publishedAt, updatedAt sql.NullTime
+		if err := rows.Scan(&entry.Slug, &publishedAt, &updatedAt); err != nil {
+			return err
+		}
+
+		// Se toma la fecha más reciente entre la publicación y la última edición
+		entry.LastModified = publishedAt.Time
+		if updatedAt.Time.After(entry.LastModified) {
+			entry.LastModified = updatedAt.Time
+		}
+
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
 }
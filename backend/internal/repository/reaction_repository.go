@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"tp06-testing/internal/models"
+)
+
+// ReactionRepository define las operaciones sobre las reacciones a posts
+// y comentarios. Agregar una reacción que ya existe, o quitar una que no
+// existe, no es un error (las operaciones son idempotentes).
+type ReactionRepository interface {
+	AddPostReaction(postID int, userID int, reactionType string) error
+	RemovePostReaction(postID int, userID int, reactionType string) error
+	PostReactions(postIDs []int, viewerID int) (map[int]*models.ReactionSummary, error)
+	AddCommentReaction(commentID int, userID int, reactionType string) error
+	RemoveCommentReaction(commentID int, userID int, reactionType string) error
+	CommentReactions(commentIDs []int, viewerID int) (map[int]*models.ReactionSummary, error)
+}
+
+// SQLiteReactionRepository implementa ReactionRepository usando SQLite
+type SQLiteReactionRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteReactionRepository crea una nueva instancia
+func NewSQLiteReactionRepository(db *sql.DB) *SQLiteReactionRepository {
+	return &SQLiteReactionRepository{db: db}
+}
+
+// reactionTable describe una de las dos tablas de reacciones, que tienen
+// la misma forma y solo cambian en la columna del objetivo
+type reactionTable struct {
+	name   string
+	target string
+}
+
+var (
+	postReactionsTable    = reactionTable{name: "post_reactions", target: "post_id"}
+	commentReactionsTable = reactionTable{name: "comment_reactions", target: "comment_id"}
+)
+
+// idBatchSize limita la cantidad de IDs por consulta con IN (...)
+// (SQLite tiene un máximo de parámetros por sentencia)
+const idBatchSize = 500
+
+// AddPostReaction registra la reacción de un usuario a un post
+func (r *SQLiteReactionRepository) AddPostReaction(postID int, userID int, reactionType string) error {
+	return r.add(postReactionsTable, postID, userID, reactionType)
+}
+
+// RemovePostReaction quita la reacción de un usuario a un post
+func (r *SQLiteReactionRepository) RemovePostReaction(postID int, userID int, reactionType string) error {
+	return r.remove(postReactionsTable, postID, userID, reactionType)
+}
+
+// PostReactions resume las reacciones de los posts indicados. Los posts
+// sin reacciones no aparecen en el mapa.
+func (r *SQLiteReactionRepository) PostReactions(postIDs []int, viewerID int) (map[int]*models.ReactionSummary, error) {
+	return r.summaries(postReactionsTable, postIDs, viewerID)
+}
+
+// AddCommentReaction registra la reacción de un usuario a un comentario
+func (r *SQLiteReactionRepository) AddCommentReaction(commentID int, userID int, reactionType string) error {
+	return r.add(commentReactionsTable, commentID, userID, reactionType)
+}
+
+// RemoveCommentReaction quita la reacción de un usuario a un comentario
+func (r *SQLiteReactionRepository) RemoveCommentReaction(commentID int, userID int, reactionType string) error {
+	return r.remove(commentReactionsTable, commentID, userID, reactionType)
+}
+
+// CommentReactions resume las reacciones de los comentarios indicados
+func (r *SQLiteReactionRepository) CommentReactions(commentIDs []int, viewerID int) (map[int]*models.ReactionSummary, error) {
+	return r.summaries(commentReactionsTable, commentIDs, viewerID)
+}
+
+func (r *SQLiteReactionRepository) add(table reactionTable, targetID int, userID int, reactionType string) error {
+	query := `INSERT OR IGNORE INTO ` + table.name + ` (` + table.target + `, user_id, type, created_at) VALUES (?, ?, ?, ?)`
+	_, err := r.db.Exec(query, targetID, userID, reactionType, sqlTime(time.Now()))
+	return err
+}
+
+func (r *SQLiteReactionRepository) remove(table reactionTable, targetID int, userID int, reactionType string) error {
+	query := `DELETE FROM ` + table.name + ` WHERE ` + table.target + ` = ? AND user_id = ? AND type = ?`
+	_, err := r.db.Exec(query, targetID, userID, reactionType)
+	return err
+}
+
+// summaries cuenta por tipo y marca las del viewer en una consulta por lote
+func (r *SQLiteReactionRepository) summaries(table reactionTable, targetIDs []int, viewerID int) (map[int]*models.ReactionSummary, error) {
+	result := make(map[int]*models.ReactionSummary)
+
+	for start := 0; start < len(targetIDs); start += idBatchSize {
+		batch := targetIDs[start:min(start+idBatchSize, len(targetIDs))]
+
+		args := []interface{}{viewerID}
+		for _, id := range batch {
+			args = append(args, id)
+		}
+
+		query := `
+			SELECT ` + table.target + `, type, COUNT(*), MAX(user_id = ?)
+			FROM ` + table.name + `
+			WHERE ` + table.target + ` IN (?` + strings.Repeat(", ?", len(batch)-1) + `)
+			GROUP BY ` + table.target + `, type
+			ORDER BY type
+		`
+		if err := r.scanSummaries(result, query, args); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (r *SQLiteReactionRepository) scanSummaries(result map[int]*models.ReactionSummary, query string, args []interface{}) error {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			targetID     int
+			reactionType string
+			count        int
+			mine         bool
+		)
+		if err := rows.Scan(&targetID, &reactionType, &count, &mine); err != nil {
+			return err
+		}
+
+		summary, ok := result[targetID]
+		if !ok {
+			summary = models.NewReactionSummary()
+			result[targetID] = summary
+		}
+		summary.Counts[reactionType] = count
+		summary.Total += count
+		if mine {
+			summary.Mine = append(summary.Mine, reactionType)
+		}
+	}
+
+	return rows.Err()
+}
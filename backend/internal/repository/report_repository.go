@@ -0,0 +1,308 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"tp06-testing/internal/models"
+)
+
+// ReportRepository define las operaciones de reportes y moderación
+type ReportRepository interface {
+	Create(report *models.Report) (bool, error)
+	CountOpen(postID int, commentID int) (int, error)
+	FindByID(id int) (*models.Report, error)
+	FindByStatus(status string, after *models.Cursor, limit int) ([]*models.Report, error)
+	FindActions(reportID int) ([]*models.ReportAction, error)
+	AddAction(action *models.ReportAction) error
+	Close(report *models.Report, status string, action *models.ReportAction) error
+	SetHidden(postID int, commentID int, hidden bool) error
+}
+
+// SQLiteReportRepository implementa ReportRepository usando SQLite
+type SQLiteReportRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteReportRepository crea una nueva instancia
+func NewSQLiteReportRepository(db *sql.DB) *SQLiteReportRepository {
+	return &SQLiteReportRepository{db: db}
+}
+
+// reportColumns son las columnas que se leen al armar un models.Report,
+// incluido el estado actual del contenido y sus reportes abiertos. El
+// contenido en la papelera cuenta como borrado.
+const reportColumns = `r.id, r.reporter_id, r.post_id, r.comment_id, r.author_id, r.reason,
+	r.details, r.content, r.status, r.created_at, r.resolved_at,
+	COALESCE(CASE WHEN r.comment_id = 0
+		THEN (SELECT CASE WHEN hidden_at IS NULL THEN 'visible' ELSE 'hidden' END FROM posts WHERE id = r.post_id AND deleted_at IS NULL)
+		ELSE (SELECT CASE WHEN hidden_at IS NULL THEN 'visible' ELSE 'hidden' END FROM comments WHERE id = r.comment_id AND deleted_at IS NULL)
+	END, 'deleted'),
+	(SELECT COUNT(*) FROM reports o WHERE o.post_id = r.post_id AND o.comment_id = r.comment_id AND o.status = 'open')`
+
+// scanReport lee una fila con reportColumns
+func scanReport(row rowScanner) (*models.Report, error) {
+	report := &models.Report{}
+	var reporterID sql.NullInt64
+	var resolvedAt sql.NullTime
+	err := row.Scan(
+		&report.ID,
+		&reporterID,
+		&report.PostID,
+		&report.CommentID,
+		&report.AuthorID,
+		&report.Reason,
+		&report.Details,
+		&report.Content,
+		&report.Status,
+		&report.CreatedAt,
+		&resolvedAt,
+		&report.TargetStatus,
+		&report.OpenReports,
+	)
+	if err != nil {
+		return nil, err
+	}
+	report.ReporterID = int(reporterID.Int64)
+	if resolvedAt.Valid {
+		report.ResolvedAt = &resolvedAt.Time
+	}
+	return report, nil
+}
+
+// Create guarda un reporte abierto. Devuelve false (sin error) si el
+// usuario ya tenía un reporte abierto sobre el mismo contenido. Un
+// ReporterID 0 (reporte del filtro de contenido) se guarda como NULL.
+func (r *SQLiteReportRepository) Create(report *models.Report) (bool, error) {
+	report.Status = models.ReportOpen
+	report.CreatedAt = time.Now().UTC().Truncate(time.Second)
+
+	var reporterID interface{}
+	if report.ReporterID != 0 {
+		reporterID = report.ReporterID
+	}
+
+	result, err := r.db.Exec(`
+		INSERT OR IGNORE INTO reports (reporter_id, post_id, comment_id, author_id, reason, details, content, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, reporterID, report.PostID, report.CommentID, report.AuthorID, report.Reason, report.Details,
+		report.Content, report.Status, sqlTime(report.CreatedAt))
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return false, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return false, err
+	}
+
+	report.ID = int(id)
+	return true, nil
+}
+
+// CountOpen cuenta los reportes abiertos sobre un contenido. Como cada
+// usuario tiene a lo sumo uno abierto, son los usuarios distintos que lo
+// reportaron.
+func (r *SQLiteReportRepository) CountOpen(postID int, commentID int) (int, error) {
+	var count int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM reports WHERE post_id = ? AND comment_id = ? AND status = 'open'
+	`, postID, commentID).Scan(&count)
+	return count, err
+}
+
+// FindByID busca un reporte por ID
+func (r *SQLiteReportRepository) FindByID(id int) (*models.Report, error) {
+	report, err := scanReport(r.db.QueryRow(`SELECT `+reportColumns+` FROM reports r WHERE r.id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// FindByStatus obtiene los reportes en un estado a partir del cursor. Los
+// abiertos se listan del más antiguo al más nuevo (la cola se atiende en
+// orden); los cerrados, del más nuevo al más antiguo.
+func (r *SQLiteReportRepository) FindByStatus(status string, after *models.Cursor, limit int) ([]*models.Report, error) {
+	query := `SELECT ` + reportColumns + ` FROM reports r WHERE r.status = ?`
+	args := []interface{}{status}
+
+	direction, compare := "DESC", "<"
+	if status == models.ReportOpen {
+		direction, compare = "ASC", ">"
+	}
+
+	if after != nil {
+		query += ` AND (r.created_at ` + compare + ` ? OR (r.created_at = ? AND r.id ` + compare + ` ?))`
+		at := sqlTime(after.Time)
+		args = append(args, at, at, after.ID)
+	}
+
+	query += ` ORDER BY r.created_at ` + direction + `, r.id ` + direction + ` LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []*models.Report
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, rows.Err()
+}
+
+// FindActions obtiene el historial de acciones de un reporte, en orden
+func (r *SQLiteReportRepository) FindActions(reportID int) ([]*models.ReportAction, error) {
+	rows, err := r.db.Query(`
+		SELECT id, report_id, moderator_id, action, note, created_at
+		FROM report_actions WHERE report_id = ? ORDER BY id
+	`, reportID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	actions := []*models.ReportAction{}
+	for rows.Next() {
+		action := &models.ReportAction{}
+		var moderatorID sql.NullInt64
+		if err := rows.Scan(&action.ID, &action.ReportID, &moderatorID, &action.Action, &action.Note, &action.CreatedAt); err != nil {
+			return nil, err
+		}
+		if moderatorID.Valid {
+			id := int(moderatorID.Int64)
+			action.ModeratorID = &id
+		}
+		actions = append(actions, action)
+	}
+
+	return actions, rows.Err()
+}
+
+// AddAction registra una acción sobre el reporte action.ReportID
+func (r *SQLiteReportRepository) AddAction(action *models.ReportAction) error {
+	return insertReportAction(r.db, action)
+}
+
+// reportExecer abstrae *sql.DB y *sql.Tx para insertReportAction
+type reportExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func insertReportAction(db reportExecer, action *models.ReportAction) error {
+	action.CreatedAt = time.Now().UTC().Truncate(time.Second)
+
+	var moderatorID interface{}
+	if action.ModeratorID != nil {
+		moderatorID = *action.ModeratorID
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO report_actions (report_id, moderator_id, action, note, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, action.ReportID, moderatorID, action.Action, action.Note, sqlTime(action.CreatedAt))
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	action.ID = int(id)
+	return nil
+}
+
+// Close cierra con status todos los reportes abiertos sobre el mismo
+// contenido que report (en una transacción) y registra action en el
+// historial de cada uno. action.ReportID queda con el de report.
+func (r *SQLiteReportRepository) Close(report *models.Report, status string, action *models.ReportAction) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		UPDATE reports SET status = ?, resolved_at = ?
+		WHERE post_id = ? AND comment_id = ? AND status = 'open'
+		RETURNING id
+	`, status, sqlTime(time.Now()), report.PostID, report.CommentID)
+	if err != nil {
+		return err
+	}
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		entry := *action
+		entry.ReportID = id
+		if err := insertReportAction(tx, &entry); err != nil {
+			return err
+		}
+		if id == report.ID {
+			*action = entry
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SetHidden oculta o vuelve a mostrar un post (commentID 0) o un
+// comentario. Al ocultar un comentario se recalculan los contadores del
+// post, que no cuentan los ocultos.
+func (r *SQLiteReportRepository) SetHidden(postID int, commentID int, hidden bool) error {
+	var hiddenAt interface{}
+	if hidden {
+		hiddenAt = sqlTime(time.Now())
+	}
+
+	if commentID == 0 {
+		_, err := r.db.Exec(`UPDATE posts SET hidden_at = ? WHERE id = ?`, hiddenAt, postID)
+		return err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE comments SET hidden_at = ? WHERE id = ? AND post_id = ?`, hiddenAt, commentID, postID); err != nil {
+		return err
+	}
+	if err := updateCommentCounters(tx, postID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
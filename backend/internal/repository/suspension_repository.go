@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"tp06-testing/internal/models"
+)
+
+// SuspensionRepository define las operaciones sobre las suspensiones de
+// usuarios
+type SuspensionRepository interface {
+	Create(suspension *models.Suspension) error
+	FindActive(userID int, now time.Time) (*models.Suspension, error)
+	FindByUser(userID int) ([]*models.Suspension, error)
+	Lift(userID int, moderatorID int, now time.Time) (bool, error)
+}
+
+// SQLiteSuspensionRepository implementa SuspensionRepository usando SQLite
+type SQLiteSuspensionRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteSuspensionRepository crea una nueva instancia
+func NewSQLiteSuspensionRepository(db *sql.DB) *SQLiteSuspensionRepository {
+	return &SQLiteSuspensionRepository{db: db}
+}
+
+// suspensionColumns son las columnas que se leen al armar un
+// models.Suspension
+const suspensionColumns = `id, user_id, moderator_id, reason, created_at, expires_at, lifted_at, lifted_by`
+
+// activeSuspension es la condición de una suspensión vigente en el
+// momento del parámetro
+const activeSuspension = `lifted_at IS NULL AND (expires_at IS NULL OR expires_at > ?)`
+
+// scanSuspension lee una fila con suspensionColumns
+func scanSuspension(row rowScanner) (*models.Suspension, error) {
+	suspension := &models.Suspension{}
+	var moderatorID, liftedBy sql.NullInt64
+	var expiresAt, liftedAt sql.NullTime
+	err := row.Scan(
+		&suspension.ID,
+		&suspension.UserID,
+		&moderatorID,
+		&suspension.Reason,
+		&suspension.CreatedAt,
+		&expiresAt,
+		&liftedAt,
+		&liftedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if moderatorID.Valid {
+		id := int(moderatorID.Int64)
+		suspension.ModeratorID = &id
+	}
+	if expiresAt.Valid {
+		suspension.ExpiresAt = &expiresAt.Time
+	}
+	if liftedAt.Valid {
+		suspension.LiftedAt = &liftedAt.Time
+	}
+	if liftedBy.Valid {
+		id := int(liftedBy.Int64)
+		suspension.LiftedBy = &id
+	}
+	return suspension, nil
+}
+
+// Create guarda una suspensión nueva. Las que el usuario tenía vigentes
+// quedan levantadas por el mismo moderador (en la misma transacción), así
+// la nueva las reemplaza sin perder el historial.
+func (r *SQLiteSuspensionRepository) Create(suspension *models.Suspension) error {
+	suspension.CreatedAt = time.Now().UTC().Truncate(time.Second)
+
+	var moderatorID interface{}
+	if suspension.ModeratorID != nil {
+		moderatorID = *suspension.ModeratorID
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE suspensions SET lifted_at = ?, lifted_by = ?
+		WHERE user_id = ? AND `+activeSuspension,
+		sqlTime(suspension.CreatedAt), moderatorID, suspension.UserID, sqlTime(suspension.CreatedAt))
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO suspensions (user_id, moderator_id, reason, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, suspension.UserID, moderatorID, suspension.Reason, sqlTime(suspension.CreatedAt), nullableTime(suspension.ExpiresAt))
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	suspension.ID = int(id)
+	return nil
+}
+
+// FindActive obtiene la suspensión vigente del usuario en now, o nil si
+// no tiene
+func (r *SQLiteSuspensionRepository) FindActive(userID int, now time.Time) (*models.Suspension, error) {
+	query := `
+		SELECT ` + suspensionColumns + `
+		FROM suspensions
+		WHERE user_id = ? AND ` + activeSuspension + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`
+
+	suspension, err := scanSuspension(r.db.QueryRow(query, userID, sqlTime(now)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return suspension, nil
+}
+
+// FindByUser obtiene el historial de suspensiones del usuario, las más
+// recientes primero
+func (r *SQLiteSuspensionRepository) FindByUser(userID int) ([]*models.Suspension, error) {
+	rows, err := r.db.Query(`
+		SELECT `+suspensionColumns+`
+		FROM suspensions
+		WHERE user_id = ?
+		ORDER BY created_at DESC, id DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suspensions := []*models.Suspension{}
+	for rows.Next() {
+		suspension, err := scanSuspension(rows)
+		if err != nil {
+			return nil, err
+		}
+		suspensions = append(suspensions, suspension)
+	}
+
+	return suspensions, rows.Err()
+}
+
+// Lift levanta las suspensiones vigentes del usuario. Devuelve false si
+// no tenía ninguna.
+func (r *SQLiteSuspensionRepository) Lift(userID int, moderatorID int, now time.Time) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE suspensions SET lifted_at = ?, lifted_by = ?
+		WHERE user_id = ? AND `+activeSuspension,
+		sqlTime(now), moderatorID, userID, sqlTime(now))
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
@@ -2,16 +2,26 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
+	"strings"
 
 	"tp06-testing/internal/models"
+
+	"github.com/mattn/go-sqlite3"
 )
 
+// ErrUsernameTaken se devuelve al crear un usuario con un nombre que ya
+// usa otro (sin distinguir mayúsculas)
+const ErrUsernameTaken = "el nombre de usuario ya está en uso"
+
 // UserRepository define las operaciones sobre usuarios
 // INTERFACE: permite crear mocks fácilmente para testing
 type UserRepository interface {
 	Create(user *models.User) error
 	FindByEmail(email string) (*models.User, error)
 	FindByID(id int) (*models.User, error)
+	FindByUsername(username string) (*models.User, error)
+	SetRole(userID int, role string) error
 }
 
 // SQLiteUserRepository implementa UserRepository usando SQLite
@@ -24,6 +34,19 @@ func NewSQLiteUserRepository(db *sql.DB) *SQLiteUserRepository {
 	return &SQLiteUserRepository{db: db}
 }
 
+// userColumns son las columnas que se leen al armar un models.User
+const userColumns = `id, email, password, username, role, created_at`
+
+// scanUser lee una fila con userColumns
+func scanUser(row rowScanner) (*models.User, error) {
+	user := &models.User{}
+	err := row.Scan(&user.ID, &user.Email, &user.Password, &user.Username, &user.Role, &user.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
 // Create inserta un nuevo usuario en la base de datos
 func (r *SQLiteUserRepository) Create(user *models.User) error {
 	query := `
@@ -31,6 +54,9 @@ func (r *SQLiteUserRepository) Create(user *models.User) error {
 		VALUES (?, ?, ?, datetime('now'))
 	`
 	result, err := r.db.Exec(query, user.Email, user.Password, user.Username)
+	if isUsernameTaken(err) {
+		return errors.New(ErrUsernameTaken)
+	}
 	if err != nil {
 		return err
 	}
@@ -44,19 +70,20 @@ func (r *SQLiteUserRepository) Create(user *models.User) error {
 	return nil
 }
 
+// isUsernameTaken indica si err es la violación del índice único de
+// users.username
+func isUsernameTaken(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) &&
+		sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique &&
+		strings.Contains(sqliteErr.Error(), "users.username")
+}
+
 // FindByEmail busca un usuario por email
 func (r *SQLiteUserRepository) FindByEmail(email string) (*models.User, error) {
-	query := `SELECT id, email, password, username, created_at FROM users WHERE email = ?`
-
-	user := &models.User{}
-	err := r.db.QueryRow(query, email).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Password,
-		&user.Username,
-		&user.CreatedAt,
-	)
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = ?`
 
+	user, err := scanUser(r.db.QueryRow(query, email))
 	if err == sql.ErrNoRows {
 		return nil, nil // Usuario no encontrado (no es error)
 	}
@@ -69,17 +96,28 @@ func (r *SQLiteUserRepository) FindByEmail(email string) (*models.User, error) {
 
 // FindByID busca un usuario por ID
 func (r *SQLiteUserRepository) FindByID(id int) (*models.User, error) {
-	query := `SELECT id, email, password, username, created_at FROM users WHERE id = ?`
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ?`
 
-	user := &models.User{}
-	err := r.db.QueryRow(query, id).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Password,
-		&user.Username,
-		&user.CreatedAt,
-	)
+	user, err := scanUser(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
 
+	return user, nil
+}
+
+// FindByUsername busca un usuario por nombre de usuario (sin distinguir
+// mayúsculas, igual que el índice único idx_users_username)
+func (r *SQLiteUserRepository) FindByUsername(username string) (*models.User, error) {
+	query := `
+		SELECT ` + userColumns + ` FROM users
+		WHERE username = ? COLLATE NOCASE
+	`
+
+	user, err := scanUser(r.db.QueryRow(query, username))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -89,3 +127,9 @@ func (r *SQLiteUserRepository) FindByID(id int) (*models.User, error) {
 
 	return user, nil
 }
+
+// SetRole cambia el rol del usuario (lo usa el comando cmd/moderator)
+func (r *SQLiteUserRepository) SetRole(userID int, role string) error {
+	_, err := r.db.Exec(`UPDATE users SET role = ? WHERE id = ?`, role, userID)
+	return err
+}
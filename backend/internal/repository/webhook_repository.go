@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"tp06-testing/internal/models"
+)
+
+// WebhookRepository define las operaciones sobre webhooks y su cola de entregas
+type WebhookRepository interface {
+	Create(webhook *models.Webhook) error
+	FindByID(id int) (*models.Webhook, error)
+	FindByUser(userID int) ([]*models.Webhook, error)
+	FindSubscribed(eventType string) ([]*models.Webhook, error)
+	Delete(id int) error
+	CreateDelivery(delivery *models.WebhookDelivery) error
+	FindDelivery(webhookID int, deliveryID int) (*models.WebhookDelivery, error)
+	FindDeliveries(webhookID int, after *models.Cursor, limit int) ([]*models.WebhookDelivery, error)
+	FindDueDeliveries(now time.Time, limit int) ([]*models.WebhookDelivery, error)
+	UpdateDelivery(delivery *models.WebhookDelivery) error
+}
+
+// SQLiteWebhookRepository implementa WebhookRepository usando SQLite
+type SQLiteWebhookRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteWebhookRepository crea una nueva instancia
+func NewSQLiteWebhookRepository(db *sql.DB) *SQLiteWebhookRepository {
+	return &SQLiteWebhookRepository{db: db}
+}
+
+// webhookColumns son las columnas que se leen al armar un models.Webhook
+const webhookColumns = `id, user_id, url, secret, events, created_at`
+
+// scanWebhook lee una fila con webhookColumns
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
+	webhook := &models.Webhook{}
+	var events string
+	err := row.Scan(&webhook.ID, &webhook.UserID, &webhook.URL, &webhook.Secret, &events, &webhook.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	webhook.Events = strings.Split(events, ",")
+	return webhook, nil
+}
+
+// Create guarda un webhook nuevo y completa su ID y fecha de creación
+func (r *SQLiteWebhookRepository) Create(webhook *models.Webhook) error {
+	now := time.Now().UTC().Truncate(time.Second)
+	result, err := r.db.Exec(`
+		INSERT INTO webhooks (user_id, url, secret, events, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, webhook.UserID, webhook.URL, webhook.Secret, strings.Join(webhook.Events, ","), sqlTime(now))
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	webhook.ID = int(id)
+	webhook.CreatedAt = now
+	return nil
+}
+
+// FindByID busca un webhook por su ID
+func (r *SQLiteWebhookRepository) FindByID(id int) (*models.Webhook, error) {
+	webhook, err := scanWebhook(r.db.QueryRow(`SELECT `+webhookColumns+` FROM webhooks WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return webhook, err
+}
+
+// FindByUser obtiene los webhooks del usuario, los más nuevos primero
+func (r *SQLiteWebhookRepository) FindByUser(userID int) ([]*models.Webhook, error) {
+	return r.findAll(`SELECT `+webhookColumns+` FROM webhooks WHERE user_id = ? ORDER BY id DESC`, userID)
+}
+
+// FindSubscribed obtiene los webhooks suscriptos al tipo de evento
+func (r *SQLiteWebhookRepository) FindSubscribed(eventType string) ([]*models.Webhook, error) {
+	return r.findAll(`
+		SELECT `+webhookColumns+` FROM webhooks
+		WHERE ',' || events || ',' LIKE '%,' || ? || ',%'
+		ORDER BY id
+	`, eventType)
+}
+
+func (r *SQLiteWebhookRepository) findAll(query string, args ...interface{}) ([]*models.Webhook, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []*models.Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// Delete elimina un webhook junto con sus entregas
+func (r *SQLiteWebhookRepository) Delete(id int) error {
+	_, err := r.db.Exec(`DELETE FROM webhooks WHERE id = ?`, id)
+	return err
+}
+
+// deliveryColumns son las columnas que se leen al armar un
+// models.WebhookDelivery (d = webhook_deliveries)
+const deliveryColumns = `d.id, d.webhook_id, d.event_type, d.payload, d.status, d.attempts,
+	d.next_attempt_at, d.response_status, d.last_error, d.redelivery_of, d.created_at, d.delivered_at`
+
+// scanDelivery lee una fila con deliveryColumns más las columnas extra
+func scanDelivery(row rowScanner, extra ...interface{}) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{}
+	var nextAttemptAt, deliveredAt sql.NullTime
+	var responseStatus, redeliveryOf sql.NullInt64
+	dest := append([]interface{}{
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.EventType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&nextAttemptAt,
+		&responseStatus,
+		&delivery.LastError,
+		&redeliveryOf,
+		&delivery.CreatedAt,
+		&deliveredAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	if nextAttemptAt.Valid {
+		delivery.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+	if responseStatus.Valid {
+		status := int(responseStatus.Int64)
+		delivery.ResponseStatus = &status
+	}
+	if redeliveryOf.Valid {
+		id := int(redeliveryOf.Int64)
+		delivery.RedeliveryOf = &id
+	}
+
+	return delivery, nil
+}
+
+// CreateDelivery encola una entrega y completa su ID y fecha de creación
+func (r *SQLiteWebhookRepository) CreateDelivery(delivery *models.WebhookDelivery) error {
+	now := time.Now().UTC().Truncate(time.Second)
+	result, err := r.db.Exec(`
+		INSERT INTO webhook_deliveries (webhook_id, event_type, payload, status, attempts, next_attempt_at, redelivery_of, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, delivery.WebhookID, delivery.EventType, delivery.Payload, delivery.Status, delivery.Attempts,
+		nullableTime(delivery.NextAttemptAt), nullableID(delivery.RedeliveryOf), sqlTime(now))
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	delivery.ID = int(id)
+	delivery.CreatedAt = now
+	return nil
+}
+
+// FindDelivery busca una entrega de un webhook
+func (r *SQLiteWebhookRepository) FindDelivery(webhookID int, deliveryID int) (*models.WebhookDelivery, error) {
+	delivery, err := scanDelivery(r.db.QueryRow(`
+		SELECT `+deliveryColumns+` FROM webhook_deliveries d
+		WHERE d.id = ? AND d.webhook_id = ?
+	`, deliveryID, webhookID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return delivery, err
+}
+
+// FindDeliveries obtiene el registro de entregas del webhook, las más
+// recientes primero, a partir del cursor (nil para la primera página)
+func (r *SQLiteWebhookRepository) FindDeliveries(webhookID int, after *models.Cursor, limit int) ([]*models.WebhookDelivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries d WHERE d.webhook_id = ?`
+	args := []interface{}{webhookID}
+
+	if after != nil {
+		query += ` AND (d.created_at < ? OR (d.created_at = ? AND d.id < ?))`
+		at := sqlTime(after.Time)
+		args = append(args, at, at, after.ID)
+	}
+
+	query += ` ORDER BY d.created_at DESC, d.id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*models.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// FindDueDeliveries obtiene las entregas pendientes cuyo próximo intento
+// ya venció, las más viejas primero, con la URL y el secreto del webhook
+func (r *SQLiteWebhookRepository) FindDueDeliveries(now time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	rows, err := r.db.Query(`
+		SELECT `+deliveryColumns+`, w.url, w.secret
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.status = ? AND d.next_attempt_at <= ?
+		ORDER BY d.next_attempt_at, d.id
+		LIMIT ?
+	`, models.DeliveryPending, sqlTime(now), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*models.WebhookDelivery
+	for rows.Next() {
+		var url, secret string
+		delivery, err := scanDelivery(rows, &url, &secret)
+		if err != nil {
+			return nil, err
+		}
+		delivery.URL, delivery.Secret = url, secret
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// UpdateDelivery guarda el resultado de un intento de entrega
+func (r *SQLiteWebhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	_, err := r.db.Exec(`
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, next_attempt_at = ?, response_status = ?, last_error = ?, delivered_at = ?
+		WHERE id = ?
+	`, delivery.Status, delivery.Attempts, nullableTime(delivery.NextAttemptAt), nullableID(delivery.ResponseStatus),
+		delivery.LastError, nullableTime(delivery.DeliveredAt), delivery.ID)
+	return err
+}
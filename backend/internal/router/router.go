@@ -9,12 +9,15 @@ import (
 )
 
 // Setup configura todas las rutas de la aplicación
-func Setup(authHandler *handlers.AuthHandler, postHandler *handlers.PostHandler) *mux.Router {
+func Setup(authHandler *handlers.AuthHandler, postHandler *handlers.PostHandler, attachmentHandler *handlers.AttachmentHandler, reactionHandler *handlers.ReactionHandler, userHandler *handlers.UserHandler, notificationHandler *handlers.NotificationHandler, streamHandler *handlers.StreamHandler, liveHandler *handlers.LiveHandler, webhookHandler *handlers.WebhookHandler, feedHandler *handlers.FeedHandler, sitemapHandler *handlers.SitemapHandler, activityPubHandler *handlers.ActivityPubHandler, digestHandler *handlers.DigestHandler, blockHandler *handlers.BlockHandler, moderationHandler *handlers.ModerationHandler) *mux.Router {
 	router := mux.NewRouter()
 
 	// Middleware CORS
 	router.Use(corsMiddleware)
 
+	// Los usuarios suspendidos no pueden seguir usando su sesión
+	router.Use(authHandler.RejectSuspended)
+
 	// Rutas de autenticación
 	router.HandleFunc("/api/auth/register", authHandler.Register).Methods("POST", "OPTIONS")
 	router.HandleFunc("/api/auth/login", authHandler.Login).Methods("POST", "OPTIONS")
@@ -22,14 +25,114 @@ func Setup(authHandler *handlers.AuthHandler, postHandler *handlers.PostHandler)
 	// Rutas de posts
 	router.HandleFunc("/api/posts", postHandler.GetAllPosts).Methods("GET", "OPTIONS")
 	router.HandleFunc("/api/posts", postHandler.CreatePost).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/posts/by-slug/{slug}", postHandler.GetPostBySlug).Methods("GET", "OPTIONS")
 	router.HandleFunc("/api/posts/{id}", postHandler.GetPostByID).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/posts/{id}", postHandler.UpdatePost).Methods("PUT", "OPTIONS")
 	router.HandleFunc("/api/posts/{id}", postHandler.DeletePost).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/api/posts/{id}/publish", postHandler.PublishPost).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/posts/{id}/unpublish", postHandler.UnpublishPost).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/posts/{id}/archive", postHandler.ArchivePost).Methods("POST", "OPTIONS")
+
+	// Posts guardados
+	router.HandleFunc("/api/posts/{id}/bookmark", postHandler.BookmarkPost).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/posts/{id}/bookmark", postHandler.UnbookmarkPost).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/api/users/me/bookmarks", postHandler.GetBookmarks).Methods("GET", "OPTIONS")
+
+	// Papelera: lo borrado se puede restaurar hasta que vence el plazo
+	router.HandleFunc("/api/users/me/trash", postHandler.GetTrash).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/posts/{id:[0-9]+}/restore", postHandler.RestorePost).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/posts/{postId:[0-9]+}/comments/{commentId:[0-9]+}/restore", postHandler.RestoreComment).Methods("POST", "OPTIONS")
+
+	// Perfiles, seguidores y feed personal
+	router.HandleFunc("/api/feed", postHandler.GetFeed).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/users/{id:[0-9]+}", userHandler.GetProfile).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/users/{id:[0-9]+}/follow", userHandler.Follow).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/users/{id:[0-9]+}/follow", userHandler.Unfollow).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/api/users/{id:[0-9]+}/followers", userHandler.GetFollowers).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/users/{id:[0-9]+}/following", userHandler.GetFollowing).Methods("GET", "OPTIONS")
+
+	// Usuarios bloqueados y silenciados
+	router.HandleFunc("/api/users/{id:[0-9]+}/block", blockHandler.Block).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/users/{id:[0-9]+}/block", blockHandler.Unblock).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/api/users/{id:[0-9]+}/mute", blockHandler.Mute).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/users/{id:[0-9]+}/mute", blockHandler.Unmute).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/api/users/me/blocks", blockHandler.GetBlocked).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/users/me/mutes", blockHandler.GetMuted).Methods("GET", "OPTIONS")
+
+	// Reportes y cola de moderación (solo moderadores en /api/admin)
+	router.HandleFunc("/api/posts/{id:[0-9]+}/report", moderationHandler.ReportPost).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/posts/{postId:[0-9]+}/comments/{commentId:[0-9]+}/report", moderationHandler.ReportComment).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/admin/reports", moderationHandler.GetReports).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/admin/reports/{id:[0-9]+}", moderationHandler.GetReport).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/admin/reports/{id:[0-9]+}/resolve", moderationHandler.Resolve).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/admin/reports/{id:[0-9]+}/dismiss", moderationHandler.Dismiss).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/admin/reports/{id:[0-9]+}/actions", moderationHandler.Act).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/admin/users/{id:[0-9]+}/suspend", moderationHandler.SuspendUser).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/admin/users/{id:[0-9]+}/suspend", moderationHandler.LiftSuspension).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/api/admin/users/{id:[0-9]+}/suspensions", moderationHandler.GetSuspensions).Methods("GET", "OPTIONS")
+
+	// Eventos en tiempo real (Server-Sent Events)
+	router.HandleFunc("/api/stream", streamHandler.Stream).Methods("GET", "OPTIONS")
+
+	// Hilo de comentarios en vivo con avisos de escritura (WebSocket)
+	router.HandleFunc("/api/posts/{id:[0-9]+}/live", liveHandler.Live).Methods("GET")
+
+	// Notificaciones del usuario autenticado
+	router.HandleFunc("/api/notifications", notificationHandler.GetNotifications).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/notifications/unread-count", notificationHandler.GetUnreadCount).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/notifications/read-all", notificationHandler.MarkAllRead).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/notifications/preferences", notificationHandler.GetPreferences).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/notifications/preferences", notificationHandler.UpdatePreferences).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/notifications/{id:[0-9]+}/read", notificationHandler.MarkRead).Methods("POST", "OPTIONS")
+
+	// Resumen por email (el link para desuscribirse no requiere sesión)
+	router.HandleFunc("/api/users/me/digest", digestHandler.GetSettings).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/users/me/digest", digestHandler.UpdateSettings).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/digest/unsubscribe", digestHandler.UnsubscribePage).Methods("GET")
+	router.HandleFunc("/api/digest/unsubscribe", digestHandler.Unsubscribe).Methods("POST")
+
+	// Webhooks del usuario autenticado y su registro de entregas
+	router.HandleFunc("/api/webhooks", webhookHandler.GetWebhooks).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/webhooks", webhookHandler.CreateWebhook).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/webhooks/{id:[0-9]+}", webhookHandler.DeleteWebhook).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/api/webhooks/{id:[0-9]+}/deliveries", webhookHandler.GetDeliveries).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/webhooks/{id:[0-9]+}/deliveries/{deliveryId:[0-9]+}/redeliver", webhookHandler.Redeliver).Methods("POST", "OPTIONS")
+
+	// Feeds RSS (.xml), Atom (.atom) y JSON Feed (.json), del blog y por autor
+	router.HandleFunc("/feed.{format:xml|atom|json}", feedHandler.GetFeed).Methods("GET", "HEAD", "OPTIONS")
+	router.HandleFunc("/users/{id:[0-9]+}/feed.{format:xml|atom|json}", feedHandler.GetFeed).Methods("GET", "HEAD", "OPTIONS")
+
+	// Sitemaps de los posts publicados y robots.txt
+	router.HandleFunc("/sitemap.xml", sitemapHandler.GetSitemap).Methods("GET", "HEAD", "OPTIONS")
+	router.HandleFunc("/sitemap-{page:[0-9]+}.xml", sitemapHandler.GetSitemapPage).Methods("GET", "HEAD", "OPTIONS")
+	router.HandleFunc("/robots.txt", sitemapHandler.GetRobots).Methods("GET", "HEAD", "OPTIONS")
+
+	// Federación por ActivityPub (para seguir a los usuarios desde Mastodon)
+	router.HandleFunc("/.well-known/webfinger", activityPubHandler.WebFinger).Methods("GET", "OPTIONS")
+	router.HandleFunc("/ap/users/{id:[0-9]+}", activityPubHandler.GetActor).Methods("GET", "OPTIONS")
+	router.HandleFunc("/ap/users/{id:[0-9]+}/outbox", activityPubHandler.GetOutbox).Methods("GET", "OPTIONS")
+	router.HandleFunc("/ap/users/{id:[0-9]+}/followers", activityPubHandler.GetFollowers).Methods("GET", "OPTIONS")
+	router.HandleFunc("/ap/users/{id:[0-9]+}/inbox", activityPubHandler.PostInbox).Methods("POST", "OPTIONS")
+	router.HandleFunc("/ap/posts/{id:[0-9]+}", activityPubHandler.GetPostObject).Methods("GET", "OPTIONS")
 
 	// Rutas de comentarios
 	router.HandleFunc("/api/posts/{id}/comments", postHandler.GetComments).Methods("GET", "OPTIONS")
 	router.HandleFunc("/api/posts/{id}/comments", postHandler.CreateComment).Methods("POST", "OPTIONS")
 	router.HandleFunc("/api/posts/{postId}/comments/{commentId}", postHandler.DeleteComment).Methods("DELETE", "OPTIONS")
 
+	// Rutas de reacciones
+	router.HandleFunc("/api/posts/{id}/reactions/{type}", reactionHandler.ReactToPost).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/posts/{id}/reactions/{type}", reactionHandler.UnreactToPost).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/api/posts/{postId}/comments/{commentId}/reactions/{type}", reactionHandler.ReactToComment).Methods("PUT", "OPTIONS")
+	router.HandleFunc("/api/posts/{postId}/comments/{commentId}/reactions/{type}", reactionHandler.UnreactToComment).Methods("DELETE", "OPTIONS")
+
+	// Rutas de archivos adjuntos
+	router.HandleFunc("/api/uploads", attachmentHandler.Upload).Methods("POST", "OPTIONS")
+	router.HandleFunc("/api/attachments/{id}", attachmentHandler.Download).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/attachments/{id}", attachmentHandler.Delete).Methods("DELETE", "OPTIONS")
+	router.HandleFunc("/api/attachments/{id}/variants/{name}", attachmentHandler.DownloadVariant).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/posts/{id}/attachments", attachmentHandler.GetByPost).Methods("GET", "OPTIONS")
+
 	return router
 }
 
@@ -39,7 +142,8 @@ func corsMiddleware(next http.Handler) http.Handler {
 		// Configurar headers CORS
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-User-ID")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-User-ID, Range, Last-Event-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "Content-Range, Content-Disposition")
 
 		// Si es una petición OPTIONS (preflight), responder inmediatamente
 		if r.Method == "OPTIONS" {
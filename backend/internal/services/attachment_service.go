@@ -0,0 +1,372 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"tp06-testing/internal/imaging"
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/repository"
+	"tp06-testing/internal/storage"
+)
+
+// MaxUploadSize es el tamaño máximo de un archivo subido (10 MB)
+const MaxUploadSize = 10 << 20
+
+// Constantes para mensajes de error de adjuntos
+const (
+	ErrAttachmentNotFound = "archivo no encontrado"
+	ErrFileTooLarge       = "el archivo supera el tamaño máximo de 10 MB"
+)
+
+// allowedUploadTypes son los tipos aceptados (detectados por contenido,
+// no por la extensión ni por el Content-Type que manda el cliente)
+// y la extensión con la que se guardan
+var allowedUploadTypes = map[string]string{
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+	"text/plain":      ".txt",
+}
+
+// AttachmentService maneja la subida y descarga de archivos adjuntos
+type AttachmentService struct {
+	attachmentRepo repository.AttachmentRepository
+	postRepo       repository.PostRepository
+	userRepo       repository.UserRepository
+	store          storage.BlobStore
+	clock          Clock
+	imageProcessor *ImageProcessor // opcional, genera las versiones reducidas
+
+	// Opcional: sin él, los adjuntos de posts de solo seguidores solo los ve el autor
+	followRepo repository.FollowRepository
+}
+
+// NewAttachmentService crea una nueva instancia
+func NewAttachmentService(attachmentRepo repository.AttachmentRepository, postRepo repository.PostRepository, userRepo repository.UserRepository, store storage.BlobStore) *AttachmentService {
+	return &AttachmentService{
+		attachmentRepo: attachmentRepo,
+		postRepo:       postRepo,
+		userRepo:       userRepo,
+		store:          store,
+		clock:          RealClock{},
+	}
+}
+
+// SetImageProcessor configura el procesador al que se avisa cuando se
+// sube una imagen. Sin él las imágenes quedan pendientes hasta que algún
+// procesador revise la base.
+func (s *AttachmentService) SetImageProcessor(processor *ImageProcessor) {
+	s.imageProcessor = processor
+}
+
+// SetFollowRepository permite a los seguidores descargar los adjuntos de
+// los posts de solo seguidores
+func (s *AttachmentService) SetFollowRepository(followRepo repository.FollowRepository) {
+	s.followRepo = followRepo
+}
+
+// Upload guarda un archivo y lo registra. Si postID > 0 el archivo queda
+// asociado a ese post, que debe pertenecer al usuario.
+func (s *AttachmentService) Upload(ctx context.Context, userID int, postID int, filename string, size int64, content io.Reader) (*models.Attachment, error) {
+	if size <= 0 {
+		return nil, errors.New("el archivo está vacío")
+	}
+	if size > MaxUploadSize {
+		return nil, errors.New(ErrFileTooLarge)
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New(ErrUserNotFound)
+	}
+
+	attachment := &models.Attachment{
+		UserID:   userID,
+		Filename: cleanFilename(filename),
+		Size:     size,
+	}
+
+	if postID > 0 {
+		post, err := s.postRepo.FindByID(postID)
+		if err != nil {
+			return nil, err
+		}
+		if post == nil {
+			return nil, errors.New(ErrPostNotFound)
+		}
+		if post.UserID != userID {
+			return nil, errors.New("no tienes permiso para adjuntar archivos a este post")
+		}
+		attachment.PostID = &postID
+	}
+
+	// Se detecta el tipo real a partir de los primeros 512 bytes
+	head := make([]byte, 512)
+	n, err := io.ReadFull(content, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+
+	contentType, ext, err := detectUploadType(head)
+	if err != nil {
+		return nil, err
+	}
+	attachment.ContentType = contentType
+
+	var body io.Reader = io.MultiReader(bytes.NewReader(head), content)
+	if strings.HasPrefix(contentType, "image/") {
+		// Las imágenes se leen enteras para validarlas y quitarles los
+		// metadatos antes de guardarlas
+		data, err := io.ReadAll(io.LimitReader(body, MaxUploadSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > MaxUploadSize {
+			return nil, errors.New(ErrFileTooLarge)
+		}
+		if data, err = prepareImage(attachment, data); err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+		attachment.Size = int64(len(data))
+	}
+
+	key, err := storage.NewKey("attachments", ext, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	attachment.StorageKey = key
+
+	if err := s.store.Put(ctx, key, body, attachment.Size, contentType); err != nil {
+		return nil, err
+	}
+
+	if err := s.attachmentRepo.Create(attachment); err != nil {
+		// Sin registro el archivo quedaría huérfano
+		s.store.Delete(ctx, key)
+		return nil, err
+	}
+
+	if attachment.ProcessingStatus == models.AttachmentProcessingPending && s.imageProcessor != nil {
+		s.imageProcessor.Notify()
+	}
+
+	return withAttachmentURL(attachment), nil
+}
+
+// Open devuelve los datos del adjunto y su contenido para descargarlo.
+// Los adjuntos de un post siguen la visibilidad del post; los que no
+// están asociados solo los descarga quien los subió.
+func (s *AttachmentService) Open(ctx context.Context, attachmentID int, viewerID int) (*models.Attachment, io.ReadSeekCloser, error) {
+	attachment, err := s.findVisible(attachmentID, viewerID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.openBlob(ctx, attachment.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return withAttachmentURL(attachment), content, nil
+}
+
+// OpenVariant es como Open pero para una versión reducida de una imagen
+// (thumbnail o medium). Los permisos son los del adjunto original.
+func (s *AttachmentService) OpenVariant(ctx context.Context, attachmentID int, name string, viewerID int) (*models.AttachmentVariant, io.ReadSeekCloser, error) {
+	attachment, err := s.findVisible(attachmentID, viewerID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	variant, ok := withAttachmentURL(attachment).Variants[name]
+	if !ok {
+		return nil, nil, errors.New("versión de imagen no encontrada")
+	}
+
+	content, err := s.openBlob(ctx, variant.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return variant, content, nil
+}
+
+// findVisible busca un adjunto que el viewer pueda descargar: los de un
+// post siguen la visibilidad del post y los sueltos solo los ve su dueño
+func (s *AttachmentService) findVisible(attachmentID int, viewerID int) (*models.Attachment, error) {
+	attachment, err := s.attachmentRepo.FindByID(attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	if attachment == nil {
+		return nil, errors.New(ErrAttachmentNotFound)
+	}
+
+	if attachment.PostID == nil {
+		if attachment.UserID != viewerID {
+			return nil, errors.New(ErrAttachmentNotFound)
+		}
+		return attachment, nil
+	}
+
+	post, err := s.postRepo.FindByID(*attachment.PostID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVisible(post, viewerID, s.followRepo); err != nil {
+		if err.Error() == ErrPostNotFound {
+			return nil, errors.New(ErrAttachmentNotFound)
+		}
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// openBlob abre un archivo del BlobStore traduciendo ErrNotFound
+func (s *AttachmentService) openBlob(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	content, err := s.store.Open(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, errors.New(ErrAttachmentNotFound)
+	}
+	return content, err
+}
+
+// GetByPostID lista los adjuntos de un post visible para el viewer
+func (s *AttachmentService) GetByPostID(postID int, viewerID int) ([]*models.Attachment, error) {
+	post, err := s.postRepo.FindByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVisible(post, viewerID, s.followRepo); err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.attachmentRepo.FindByPostID(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if attachments == nil {
+		return []*models.Attachment{}, nil
+	}
+
+	for _, attachment := range attachments {
+		withAttachmentURL(attachment)
+	}
+
+	return attachments, nil
+}
+
+// Delete elimina un adjunto (solo quien lo subió puede hacerlo)
+func (s *AttachmentService) Delete(ctx context.Context, attachmentID int, userID int) error {
+	attachment, err := s.attachmentRepo.FindByID(attachmentID)
+	if err != nil {
+		return err
+	}
+	if attachment == nil {
+		return errors.New(ErrAttachmentNotFound)
+	}
+
+	if attachment.UserID != userID {
+		return errors.New("no tienes permiso para eliminar este archivo")
+	}
+
+	if err := s.attachmentRepo.Delete(attachment.ID); err != nil {
+		return err
+	}
+
+	for _, variant := range attachment.Variants {
+		if err := s.store.Delete(ctx, variant.StorageKey); err != nil {
+			return err
+		}
+	}
+
+	return s.store.Delete(ctx, attachment.StorageKey)
+}
+
+// prepareImage valida las dimensiones de la imagen (rechaza las que
+// declaran más píxeles de los que se pueden procesar) y le quita los
+// metadatos. Las que se pueden decodificar quedan pendientes de generar
+// sus versiones reducidas.
+func prepareImage(attachment *models.Attachment, data []byte) ([]byte, error) {
+	mediaType := strings.SplitN(attachment.ContentType, ";", 2)[0]
+
+	if imaging.Decodable(mediaType) {
+		width, height, err := imaging.Probe(data)
+		if err != nil {
+			return nil, err
+		}
+		// StripMetadata guarda girados los JPEG con orientación 5 a 8
+		if imaging.Orientation(data) >= 5 {
+			width, height = height, width
+		}
+		attachment.Width = width
+		attachment.Height = height
+		attachment.ProcessingStatus = models.AttachmentProcessingPending
+	}
+
+	return imaging.StripMetadata(mediaType, data)
+}
+
+// detectUploadType valida el tipo del archivo según su contenido
+func detectUploadType(head []byte) (string, string, error) {
+	detected := http.DetectContentType(head)
+
+	mediaType, _, err := mime.ParseMediaType(detected)
+	if err != nil {
+		return "", "", errors.New("tipo de archivo no permitido")
+	}
+
+	ext, ok := allowedUploadTypes[mediaType]
+	if !ok {
+		return "", "", fmt.Errorf("tipo de archivo no permitido: %s", mediaType)
+	}
+
+	return detected, ext, nil
+}
+
+// cleanFilename deja solo el nombre base, sin caracteres de control
+func cleanFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+
+	if name == "" || name == "." || name == "/" {
+		return "archivo"
+	}
+	if len([]rune(name)) > 255 {
+		name = string([]rune(name)[:255])
+	}
+	return name
+}
+
+// withAttachmentURL completa la ruta de descarga del adjunto
+func withAttachmentURL(attachment *models.Attachment) *models.Attachment {
+	attachment.URL = fmt.Sprintf("/api/attachments/%d", attachment.ID)
+	for name, variant := range attachment.Variants {
+		variant.URL = fmt.Sprintf("/api/attachments/%d/variants/%s", attachment.ID, name)
+	}
+	return attachment
+}
@@ -11,13 +11,63 @@ import (
 // AuthService maneja la lógica de autenticación
 type AuthService struct {
 	userRepo repository.UserRepository
+	clock    Clock
+
+	// Opcional: impide iniciar sesión a los usuarios suspendidos
+	suspensionRepo repository.SuspensionRepository
 }
 
 // NewAuthService crea una nueva instancia
 func NewAuthService(userRepo repository.UserRepository) *AuthService {
 	return &AuthService{
 		userRepo: userRepo,
+		clock:    RealClock{},
+	}
+}
+
+// SuspendedError es el error de un usuario con una suspensión vigente. El
+// mensaje le explica el motivo y hasta cuándo dura.
+type SuspendedError struct {
+	Suspension *models.Suspension
+}
+
+func (e *SuspendedError) Error() string {
+	message := "tu cuenta está suspendida"
+	if e.Suspension.ExpiresAt != nil {
+		message += " hasta el " + e.Suspension.ExpiresAt.UTC().Format("02/01/2006 15:04") + " (UTC)"
+	} else {
+		message += " de forma permanente"
+	}
+	return message + ". Motivo: " + e.Suspension.Reason
+}
+
+// SetSuspensionRepository habilita el control de suspensiones en Login y
+// CheckSession
+func (s *AuthService) SetSuspensionRepository(suspensionRepo repository.SuspensionRepository) {
+	s.suspensionRepo = suspensionRepo
+}
+
+// SetClock reemplaza el reloj usado para ver si una suspensión venció
+// (útil en tests)
+func (s *AuthService) SetClock(clock Clock) {
+	s.clock = clock
+}
+
+// CheckSession verifica que el usuario pueda seguir usando su sesión:
+// devuelve un *SuspendedError si tiene una suspensión vigente
+func (s *AuthService) CheckSession(userID int) error {
+	if s.suspensionRepo == nil {
+		return nil
+	}
+
+	suspension, err := s.suspensionRepo.FindActive(userID, s.clock.Now())
+	if err != nil {
+		return err
+	}
+	if suspension != nil {
+		return &SuspendedError{Suspension: suspension}
 	}
+	return nil
 }
 
 // Register registra un nuevo usuario
@@ -96,5 +146,10 @@ func (s *AuthService) Login(creds *models.Credentials) (*models.User, error) {
 		return nil, errors.New("credenciales inválidas")
 	}
 
+	// Validación 5: No debe tener una suspensión vigente
+	if err := s.CheckSession(user.ID); err != nil {
+		return nil, err
+	}
+
 	return user, nil
 }
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"errors"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/repository"
+)
+
+// Errores de bloqueos y silenciados
+const (
+	ErrCannotBlockSelf = "no puedes bloquearte a ti mismo"
+	ErrCannotMuteSelf  = "no puedes silenciarte a ti mismo"
+	ErrBlockedByAuthor = "el autor del post te bloqueó"
+)
+
+// BlockService maneja los usuarios bloqueados y silenciados. Los efectos
+// se aplican en otros lados: PostService rechaza comentarios y menciones
+// de bloqueados, y las consultas de posts y comentarios omiten a los
+// bloqueados y silenciados de quien consulta.
+type BlockService struct {
+	blockRepo repository.BlockRepository
+	userRepo  repository.UserRepository
+}
+
+// NewBlockService crea una nueva instancia
+func NewBlockService(blockRepo repository.BlockRepository, userRepo repository.UserRepository) *BlockService {
+	return &BlockService{
+		blockRepo: blockRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// Block bloquea a otro usuario y devuelve cómo queda la relación
+func (s *BlockService) Block(userID int, otherID int) (*models.BlockStatus, error) {
+	return s.change(userID, otherID, ErrCannotBlockSelf, s.blockRepo.Block)
+}
+
+// Unblock quita el bloqueo
+func (s *BlockService) Unblock(userID int, otherID int) (*models.BlockStatus, error) {
+	return s.change(userID, otherID, ErrCannotBlockSelf, s.blockRepo.Unblock)
+}
+
+// Mute silencia a otro usuario y devuelve cómo queda la relación
+func (s *BlockService) Mute(userID int, otherID int) (*models.BlockStatus, error) {
+	return s.change(userID, otherID, ErrCannotMuteSelf, s.blockRepo.Mute)
+}
+
+// Unmute quita el silencio
+func (s *BlockService) Unmute(userID int, otherID int) (*models.BlockStatus, error) {
+	return s.change(userID, otherID, ErrCannotMuteSelf, s.blockRepo.Unmute)
+}
+
+// change valida los usuarios, aplica el cambio y devuelve el estado nuevo
+func (s *BlockService) change(userID int, otherID int, selfErr string, apply func(userID int, otherID int) error) (*models.BlockStatus, error) {
+	if userID == otherID {
+		return nil, errors.New(selfErr)
+	}
+
+	other, err := s.userRepo.FindByID(otherID)
+	if err != nil {
+		return nil, err
+	}
+	if other == nil {
+		return nil, errors.New(ErrUserNotFound)
+	}
+
+	if err := apply(userID, otherID); err != nil {
+		return nil, err
+	}
+
+	return s.blockRepo.FindStatus(userID, otherID)
+}
+
+// GetBlocked obtiene los usuarios bloqueados
+func (s *BlockService) GetBlocked(userID int) ([]*models.BlockedUser, error) {
+	return s.blockRepo.FindBlocked(userID)
+}
+
+// GetMuted obtiene los usuarios silenciados
+func (s *BlockService) GetMuted(userID int) ([]*models.BlockedUser, error) {
+	return s.blockRepo.FindMuted(userID)
+}
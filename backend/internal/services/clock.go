@@ -0,0 +1,16 @@
+package services
+
+import "time"
+
+// Clock abstrae la hora actual para poder controlarla en los tests
+type Clock interface {
+	Now() time.Time
+}
+
+// RealClock usa la hora del sistema
+type RealClock struct{}
+
+// Now devuelve la hora actual en UTC
+func (RealClock) Now() time.Time {
+	return time.Now().UTC()
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"tp06-testing/internal/mail"
+	"tp06-testing/internal/markdown"
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/repository"
+)
+
+const (
+	digestBatchSize     = 50  // Usuarios que se procesan por vuelta
+	digestMaxItems      = 20  // Posts y respuestas como máximo por resumen
+	digestExcerptLength = 160 // Largo del resumen de cada post o respuesta
+)
+
+// DigestSender arma y envía los resúmenes por email en segundo plano: a
+// cada usuario suscripto al que ya le corresponde uno le manda los posts
+// nuevos de los autores que sigue y los comentarios en sus posts desde el
+// resumen anterior.
+type DigestSender struct {
+	digestService *DigestService
+	digestRepo    repository.DigestRepository
+	userRepo      repository.UserRepository
+	mailer        mail.Mailer
+	clock         Clock
+	interval      time.Duration
+	siteURL       string
+}
+
+// NewDigestSender crea una nueva instancia que revisa cada interval.
+// siteURL es la dirección del frontend, a donde apuntan los links.
+func NewDigestSender(digestService *DigestService, digestRepo repository.DigestRepository, userRepo repository.UserRepository, mailer mail.Mailer, clock Clock, interval time.Duration, siteURL string) *DigestSender {
+	return &DigestSender{
+		digestService: digestService,
+		digestRepo:    digestRepo,
+		userRepo:      userRepo,
+		mailer:        mailer,
+		clock:         clock,
+		interval:      interval,
+		siteURL:       strings.TrimRight(siteURL, "/"),
+	}
+}
+
+// RunOnce procesa un lote de usuarios con el resumen vencido y devuelve a
+// cuántos se les resolvió (enviado o vacío). Si el envío a un usuario
+// falla se lo vuelve a intentar en la próxima vuelta.
+func (d *DigestSender) RunOnce(ctx context.Context) (int, error) {
+	now := d.clock.Now()
+	due, err := d.digestRepo.FindDue(now, digestBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	done := 0
+	for _, settings := range due {
+		if err := d.send(ctx, settings, now); err != nil {
+			log.Printf("Error al enviar el resumen al usuario %d: %v", settings.UserID, err)
+			continue
+		}
+		if err := d.digestRepo.MarkDigested(settings.UserID, now); err != nil {
+			return done, err
+		}
+		done++
+	}
+
+	return done, nil
+}
+
+// Start ejecuta RunOnce periódicamente hasta que se cancele el contexto.
+// Está pensado para correr en su propia goroutine.
+func (d *DigestSender) Start(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		// Se sigue mientras haya lotes completos sin errores
+		for {
+			count, err := d.RunOnce(ctx)
+			if err != nil {
+				log.Println("Error al enviar resúmenes:", err)
+			}
+			if err != nil || count < digestBatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// send arma el resumen del usuario y lo envía. Si no pasó nada en el
+// período no se envía nada.
+func (d *DigestSender) send(ctx context.Context, settings *models.DigestSettings, now time.Time) error {
+	user, err := d.userRepo.FindByID(settings.UserID)
+	if err != nil || user == nil {
+		return err
+	}
+
+	since := now.Add(-models.DigestPeriod(settings.Frequency))
+	if settings.LastDigestAt != nil {
+		since = *settings.LastDigestAt
+	}
+
+	posts, err := d.digestRepo.FindNewPosts(user.ID, since, now, digestMaxItems+1)
+	if err != nil {
+		return err
+	}
+	replies, err := d.digestRepo.FindReplies(user.ID, since, now, digestMaxItems+1)
+	if err != nil {
+		return err
+	}
+	if len(posts) == 0 && len(replies) == 0 {
+		return nil
+	}
+
+	view := d.buildView(user, settings.Frequency, posts, replies)
+	msg, err := renderDigest(view)
+	if err != nil {
+		return err
+	}
+	msg.To = user.Email
+	msg.Headers = map[string]string{
+		// Desuscripción con un clic desde el cliente de correo (RFC 8058)
+		"List-Unsubscribe":      "<" + view.UnsubscribeURL + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+
+	return d.mailer.Send(ctx, msg)
+}
+
+// buildView arma los datos que usan las plantillas
+func (d *DigestSender) buildView(user *models.User, frequency string, posts []*models.Post, replies []*models.DigestReply) *digestView {
+	view := &digestView{
+		Username:       user.Username,
+		Period:         "diario",
+		SiteURL:        d.siteURL + "/",
+		UnsubscribeURL: d.digestService.UnsubscribeURL(user.ID),
+	}
+	if frequency == models.DigestWeekly {
+		view.Period = "semanal"
+	}
+
+	if len(posts) > digestMaxItems {
+		posts, view.MorePosts = posts[:digestMaxItems], true
+	}
+	for _, post := range posts {
+		view.Posts = append(view.Posts, digestItem{
+			Title:   post.Title,
+			Author:  post.Username,
+			Excerpt: markdown.ExcerptFromHTML(markdown.ToHTML(post.Content), digestExcerptLength),
+			URL:     d.siteURL + "/posts/" + post.Slug,
+		})
+	}
+
+	if len(replies) > digestMaxItems {
+		replies, view.MoreReplies = replies[:digestMaxItems], true
+	}
+	for _, reply := range replies {
+		view.Replies = append(view.Replies, digestItem{
+			Title:   reply.PostTitle,
+			Author:  reply.Username,
+			Excerpt: markdown.Excerpt(markdown.TextFromHTML(markdown.ToHTML(reply.Content)), digestExcerptLength),
+			URL:     d.siteURL + "/posts/" + reply.PostSlug + "#comment-" + strconv.Itoa(reply.CommentID),
+		})
+	}
+
+	return view
+}
+
+// renderDigest arma el asunto y las versiones en texto y HTML
+func renderDigest(view *digestView) (*mail.Message, error) {
+	var text, html bytes.Buffer
+	if err := digestTextTemplate.Execute(&text, view); err != nil {
+		return nil, err
+	}
+	if err := digestHTMLTemplate.Execute(&html, view); err != nil {
+		return nil, err
+	}
+
+	var parts []string
+	if len(view.Posts) > 0 {
+		parts = append(parts, countLabel(len(view.Posts), view.MorePosts, "post nuevo", "posts nuevos"))
+	}
+	if len(view.Replies) > 0 {
+		parts = append(parts, countLabel(len(view.Replies), view.MoreReplies, "respuesta", "respuestas"))
+	}
+
+	return &mail.Message{
+		Subject: "Tu resumen " + view.Period + ": " + strings.Join(parts, " y "),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// countLabel arma "1 post nuevo", "3 posts nuevos" o "más de 20 posts nuevos"
+func countLabel(n int, more bool, singular string, plural string) string {
+	switch {
+	case more:
+		return fmt.Sprintf("más de %d %s", n, plural)
+	case n == 1:
+		return "1 " + singular
+	default:
+		return fmt.Sprintf("%d %s", n, plural)
+	}
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/repository"
+)
+
+// Errores del resumen por email
+const (
+	ErrInvalidDigestFrequency  = "la frecuencia del resumen debe ser daily, weekly u off"
+	ErrInvalidUnsubscribeToken = "el link para desuscribirse es inválido"
+)
+
+// DigestService maneja la suscripción al resumen por email y los links
+// firmados para desuscribirse con un clic
+type DigestService struct {
+	digestRepo repository.DigestRepository
+	clock      Clock
+	secret     []byte
+	baseURL    string
+}
+
+// NewDigestService crea una nueva instancia. secret firma los links para
+// desuscribirse y baseURL es la dirección pública de la API.
+func NewDigestService(digestRepo repository.DigestRepository, clock Clock, secret string, baseURL string) *DigestService {
+	return &DigestService{
+		digestRepo: digestRepo,
+		clock:      clock,
+		secret:     []byte(secret),
+		baseURL:    strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// GetSettings obtiene la frecuencia del resumen del usuario
+func (s *DigestService) GetSettings(userID int) (*models.DigestSettings, error) {
+	return s.digestRepo.FindSettings(userID)
+}
+
+// UpdateSettings cambia la frecuencia del resumen. Al activarlo el primer
+// resumen cubre desde ese momento, no todo lo anterior.
+func (s *DigestService) UpdateSettings(userID int, req *models.UpdateDigestSettingsRequest) (*models.DigestSettings, error) {
+	if req.Frequency != models.DigestOff && models.DigestPeriod(req.Frequency) == 0 {
+		return nil, errors.New(ErrInvalidDigestFrequency)
+	}
+
+	settings, err := s.digestRepo.FindSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.Frequency == models.DigestOff && req.Frequency != models.DigestOff {
+		now := s.clock.Now()
+		settings.LastDigestAt = &now
+	}
+	settings.Frequency = req.Frequency
+
+	if err := s.digestRepo.SaveSettings(settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// UnsubscribeToken firma el ID del usuario. El token no vence: un link de
+// un resumen viejo tiene que seguir sirviendo para desuscribirse.
+func (s *DigestService) UnsubscribeToken(userID int) string {
+	id := strconv.Itoa(userID)
+	return id + "." + base64.RawURLEncoding.EncodeToString(s.unsubscribeMAC(id))
+}
+
+// UnsubscribeURL devuelve el link para desuscribirse que va en el resumen
+func (s *DigestService) UnsubscribeURL(userID int) string {
+	return s.baseURL + "/api/digest/unsubscribe?token=" + url.QueryEscape(s.UnsubscribeToken(userID))
+}
+
+// VerifyUnsubscribeToken devuelve el usuario al que corresponde el token
+func (s *DigestService) VerifyUnsubscribeToken(token string) (int, error) {
+	id, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, errors.New(ErrInvalidUnsubscribeToken)
+	}
+
+	userID, err := strconv.Atoi(id)
+	if err != nil || userID <= 0 {
+		return 0, errors.New(ErrInvalidUnsubscribeToken)
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.unsubscribeMAC(id)) {
+		return 0, errors.New(ErrInvalidUnsubscribeToken)
+	}
+
+	return userID, nil
+}
+
+// Unsubscribe desactiva el resumen del usuario del token
+func (s *DigestService) Unsubscribe(token string) error {
+	userID, err := s.VerifyUnsubscribeToken(token)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.UpdateSettings(userID, &models.UpdateDigestSettingsRequest{Frequency: models.DigestOff})
+	return err
+}
+
+// unsubscribeMAC es HMAC-SHA256(secreto, "digest-unsubscribe:<id>"). El
+// prefijo evita que la firma sirva para otro uso del mismo secreto.
+func (s *DigestService) unsubscribeMAC(id string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("digest-unsubscribe:" + id))
+	return mac.Sum(nil)
+}
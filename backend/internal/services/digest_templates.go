@@ -0,0 +1,114 @@
+package services
+
+import (
+	htmltemplate "html/template"
+	"text/template"
+)
+
+// digestItem es un post o una respuesta dentro del resumen
+type digestItem struct {
+	Title   string // Título del post
+	Author  string
+	Excerpt string
+	URL     string
+}
+
+// digestView son los datos de las plantillas del resumen
+type digestView struct {
+	Username       string
+	Period         string // "diario" o "semanal"
+	Posts          []digestItem
+	MorePosts      bool
+	Replies        []digestItem
+	MoreReplies    bool
+	SiteURL        string
+	UnsubscribeURL string
+}
+
+// digestTextTemplate es la versión en texto plano del resumen
+var digestTextTemplate = template.Must(template.New("digest.txt").Parse(`Hola {{.Username}},
+
+Este es tu resumen {{.Period}}.
+{{- if .Posts}}
+
+POSTS NUEVOS DE QUIENES SIGUES
+{{- range .Posts}}
+
+* {{.Title}} (de {{.Author}})
+{{- if .Excerpt}}
+  {{.Excerpt}}
+{{- end}}
+  {{.URL}}
+{{- end}}
+{{- if .MorePosts}}
+
+Hay más posts nuevos en {{.SiteURL}}
+{{- end}}
+{{- end}}
+{{- if .Replies}}
+
+RESPUESTAS EN TUS POSTS
+{{- range .Replies}}
+
+* {{.Author}} en "{{.Title}}"
+{{- if .Excerpt}}
+  {{.Excerpt}}
+{{- end}}
+  {{.URL}}
+{{- end}}
+{{- if .MoreReplies}}
+
+Hay más respuestas en {{.SiteURL}}
+{{- end}}
+{{- end}}
+
+--
+Recibes este email porque te suscribiste al resumen {{.Period}}.
+Para no recibirlo más: {{.UnsubscribeURL}}
+`))
+
+// digestHTMLTemplate es la versión en HTML del resumen. Los estilos van
+// en línea porque muchos clientes de correo ignoran los <style>.
+var digestHTMLTemplate = htmltemplate.Must(htmltemplate.New("digest.html").Parse(`<!DOCTYPE html>
+<html lang="es">
+<head><meta charset="utf-8"><title>Tu resumen {{.Period}}</title></head>
+<body style="margin:0;padding:24px;background:#f5f5f5;font-family:Arial,Helvetica,sans-serif;color:#222">
+<div style="max-width:600px;margin:0 auto;background:#fff;padding:24px;border-radius:8px">
+<p>Hola {{.Username}},</p>
+<p>Este es tu resumen {{.Period}}.</p>
+{{- if .Posts}}
+<h2 style="font-size:18px;margin-top:24px">Posts nuevos de quienes sigues</h2>
+{{- range .Posts}}
+<div style="margin-bottom:16px">
+<a href="{{.URL}}" style="font-size:16px;font-weight:bold;color:#1a5fb4;text-decoration:none">{{.Title}}</a>
+<div style="font-size:13px;color:#666">de {{.Author}}</div>
+{{- if .Excerpt}}
+<p style="margin:4px 0 0">{{.Excerpt}}</p>
+{{- end}}
+</div>
+{{- end}}
+{{- if .MorePosts}}
+<p><a href="{{.SiteURL}}" style="color:#1a5fb4">Ver más posts nuevos</a></p>
+{{- end}}
+{{- end}}
+{{- if .Replies}}
+<h2 style="font-size:18px;margin-top:24px">Respuestas en tus posts</h2>
+{{- range .Replies}}
+<div style="margin-bottom:16px">
+<div style="font-size:13px;color:#666">{{.Author}} en <a href="{{.URL}}" style="color:#1a5fb4">{{.Title}}</a></div>
+{{- if .Excerpt}}
+<p style="margin:4px 0 0">{{.Excerpt}}</p>
+{{- end}}
+</div>
+{{- end}}
+{{- if .MoreReplies}}
+<p><a href="{{.SiteURL}}" style="color:#1a5fb4">Ver más respuestas</a></p>
+{{- end}}
+{{- end}}
+<hr style="border:none;border-top:1px solid #ddd;margin-top:24px">
+<p style="font-size:12px;color:#888">Recibes este email porque te suscribiste al resumen {{.Period}}.
+<a href="{{.UnsubscribeURL}}" style="color:#888">Desuscribirse</a></p>
+</div>
+</body>
+</html>
+`))
@@ -0,0 +1,786 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"tp06-testing/internal/activitypub"
+	"tp06-testing/internal/events"
+	"tp06-testing/internal/markdown"
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/repository"
+)
+
+// Constantes para mensajes de error
+const (
+	ErrActorNotFound    = "actor no encontrado"
+	ErrInvalidSignature = "firma HTTP inválida"
+	ErrInvalidActivity  = "actividad inválida"
+	ErrInvalidPage      = "página inválida"
+)
+
+const (
+	outboxPageSize        = 20      // Actividades por página del outbox
+	maxRemoteDocumentSize = 1 << 20 // Tamaño máximo de un actor remoto
+)
+
+// lineBreakRe encuentra los <br> del HTML que manda Mastodon
+var lineBreakRe = regexp.MustCompile(`(?i)<br\s*/?>`)
+
+// FederationService publica a los usuarios como actores de ActivityPub
+// para que se los pueda seguir desde Mastodon: envía sus posts a los
+// seguidores remotos y convierte las respuestas recibidas en comentarios.
+//
+// Los IDs de ActivityPub cuelgan de baseURL (la dirección pública de la
+// API); los links para personas apuntan a siteURL (el frontend).
+type FederationService struct {
+	fedRepo       repository.FederationRepository
+	userRepo      repository.UserRepository
+	postService   *PostService
+	followService *FollowService
+	client        *http.Client
+	clock         Clock
+	ctx           context.Context
+	baseURL       string
+	siteURL       string
+	domain        string
+}
+
+// NewFederationService crea una nueva instancia
+func NewFederationService(fedRepo repository.FederationRepository, userRepo repository.UserRepository, postService *PostService, followService *FollowService, client *http.Client, baseURL string, siteURL string) *FederationService {
+	baseURL = strings.TrimRight(baseURL, "/")
+	domain := baseURL
+	if parsed, err := url.Parse(baseURL); err == nil && parsed.Host != "" {
+		domain = parsed.Host
+	}
+
+	return &FederationService{
+		fedRepo:       fedRepo,
+		userRepo:      userRepo,
+		postService:   postService,
+		followService: followService,
+		client:        client,
+		clock:         RealClock{},
+		ctx:           context.Background(),
+		baseURL:       baseURL,
+		siteURL:       strings.TrimRight(siteURL, "/"),
+		domain:        domain,
+	}
+}
+
+// SetClock reemplaza el reloj con el que se firman y verifican los requests
+func (s *FederationService) SetClock(clock Clock) {
+	s.clock = clock
+}
+
+// SetContext configura el contexto de las entregas en segundo plano
+// (normalmente el del apagado del servidor): al cancelarse se cortan las
+// que estén en curso
+func (s *FederationService) SetContext(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// ActorURI devuelve el ID de ActivityPub de un usuario
+func (s *FederationService) ActorURI(userID int) string {
+	return s.baseURL + "/ap/users/" + strconv.Itoa(userID)
+}
+
+// PostURI devuelve el ID de ActivityPub de un post
+func (s *FederationService) PostURI(postID int) string {
+	return s.baseURL + "/ap/posts/" + strconv.Itoa(postID)
+}
+
+func (s *FederationService) keyID(userID int) string {
+	return s.ActorURI(userID) + "#main-key"
+}
+
+func (s *FederationService) followersURI(userID int) string {
+	return s.ActorURI(userID) + "/followers"
+}
+
+func (s *FederationService) outboxURI(userID int) string {
+	return s.ActorURI(userID) + "/outbox"
+}
+
+// localUser obtiene un usuario que se puede publicar como actor. Los
+// usuarios que representan actores remotos se informan como inexistentes.
+func (s *FederationService) localUser(userID int) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New(ErrActorNotFound)
+	}
+
+	remote, err := s.fedRepo.FindRemoteActorByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if remote != nil {
+		return nil, errors.New(ErrActorNotFound)
+	}
+
+	return user, nil
+}
+
+// actorKey obtiene las claves del usuario; la primera vez las genera
+func (s *FederationService) actorKey(userID int) (*models.ActorKey, error) {
+	key, err := s.fedRepo.FindKey(userID)
+	if err != nil || key != nil {
+		return key, err
+	}
+
+	privatePEM, publicPEM, err := activitypub.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fedRepo.SaveKey(&models.ActorKey{UserID: userID, PrivateKeyPEM: privatePEM, PublicKeyPEM: publicPEM}); err != nil {
+		return nil, err
+	}
+
+	// Se vuelve a leer por si otro request guardó las suyas primero
+	key, err = s.fedRepo.FindKey(userID)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, errors.New("no se pudieron guardar las claves del usuario")
+	}
+	return key, nil
+}
+
+// WebFinger resuelve "acct:usuario@dominio" al actor del usuario
+func (s *FederationService) WebFinger(resource string) (*activitypub.JRD, error) {
+	username, domain, ok := activitypub.ParseAcct(resource)
+	if !ok || !strings.EqualFold(domain, s.domain) {
+		return nil, errors.New(ErrActorNotFound)
+	}
+
+	user, err := s.userRepo.FindByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New(ErrActorNotFound)
+	}
+	if _, err := s.localUser(user.ID); err != nil {
+		return nil, err
+	}
+
+	actorURI := s.ActorURI(user.ID)
+	return &activitypub.JRD{
+		Subject: "acct:" + user.Username + "@" + s.domain,
+		Aliases: []string{actorURI},
+		Links: []activitypub.JRDLink{
+			{Rel: "self", Type: activitypub.ContentType, Href: actorURI},
+			{Rel: "http://webfinger.net/rel/profile-page", Type: "text/html", Href: s.siteURL + "/users/" + strconv.Itoa(user.ID)},
+		},
+	}, nil
+}
+
+// GetActor arma el documento de actor de un usuario
+func (s *FederationService) GetActor(userID int) (*activitypub.Actor, error) {
+	user, err := s.localUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.actorKey(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	actorURI := s.ActorURI(userID)
+	return &activitypub.Actor{
+		Context:           []string{activitypub.ContextActivityStreams, activitypub.ContextSecurity},
+		ID:                actorURI,
+		Type:              activitypub.TypePerson,
+		PreferredUsername: user.Username,
+		Name:              user.Username,
+		URL:               s.siteURL + "/users/" + strconv.Itoa(userID),
+		Inbox:             actorURI + "/inbox",
+		Outbox:            s.outboxURI(userID),
+		Followers:         s.followersURI(userID),
+		Published:         user.CreatedAt.UTC().Format(time.RFC3339),
+		PublicKey: activitypub.PublicKey{
+			ID:           s.keyID(userID),
+			Owner:        actorURI,
+			PublicKeyPem: key.PublicKeyPEM,
+		},
+	}, nil
+}
+
+// GetFollowers arma la colección de seguidores. Como Mastodon, solo
+// informa la cantidad, no quiénes son.
+func (s *FederationService) GetFollowers(userID int) (*activitypub.OrderedCollection, error) {
+	if _, err := s.localUser(userID); err != nil {
+		return nil, err
+	}
+
+	profile, err := s.followService.GetProfile(userID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &activitypub.OrderedCollection{
+		Context:    activitypub.ContextActivityStreams,
+		ID:         s.followersURI(userID),
+		Type:       activitypub.TypeOrderedCollection,
+		TotalItems: profile.FollowerCount,
+	}, nil
+}
+
+// GetOutbox arma el outbox de un usuario: la cantidad de posts y el link
+// a la primera página
+func (s *FederationService) GetOutbox(userID int) (*activitypub.OrderedCollection, error) {
+	if _, err := s.localUser(userID); err != nil {
+		return nil, err
+	}
+
+	count, err := s.postService.CountPublicPostsByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &activitypub.OrderedCollection{
+		Context:    activitypub.ContextActivityStreams,
+		ID:         s.outboxURI(userID),
+		Type:       activitypub.TypeOrderedCollection,
+		TotalItems: count,
+		First:      s.outboxURI(userID) + "?page=1",
+	}, nil
+}
+
+// GetOutboxPage devuelve una página (desde 1) del outbox con una
+// actividad Create por post. Solo lee los posts de esa página (y uno más
+// para saber si hay una siguiente).
+func (s *FederationService) GetOutboxPage(userID int, page int) (*activitypub.OrderedCollectionPage, error) {
+	if page < 1 {
+		return nil, errors.New(ErrInvalidPage)
+	}
+
+	if _, err := s.localUser(userID); err != nil {
+		return nil, err
+	}
+
+	posts, err := s.postService.GetPublicPostsByUser(userID, (page-1)*outboxPageSize, outboxPageSize+1)
+	if err != nil {
+		return nil, err
+	}
+
+	outbox := s.outboxURI(userID)
+	result := &activitypub.OrderedCollectionPage{
+		Context:      activitypub.ContextActivityStreams,
+		ID:           outbox + "?page=" + strconv.Itoa(page),
+		Type:         activitypub.TypeOrderedCollectionPage,
+		PartOf:       outbox,
+		OrderedItems: []interface{}{},
+	}
+
+	if len(posts) > outboxPageSize {
+		posts = posts[:outboxPageSize]
+		result.Next = outbox + "?page=" + strconv.Itoa(page+1)
+	}
+	for _, post := range posts {
+		activity := s.createActivity(post)
+		activity.Context = nil // Ya está en la página
+		result.OrderedItems = append(result.OrderedItems, activity)
+	}
+	if page > 1 {
+		result.Prev = outbox + "?page=" + strconv.Itoa(page-1)
+	}
+
+	return result, nil
+}
+
+// GetPostObject devuelve el Article de un post publicado
+func (s *FederationService) GetPostObject(postID int) (*activitypub.Object, error) {
+	post, err := s.postService.GetPostByID(postID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	object := s.postObject(post)
+	object.Context = activitypub.ContextActivityStreams
+	return object, nil
+}
+
+// postObject convierte un post en un Article público dirigido también a
+// los seguidores del autor
+func (s *FederationService) postObject(post *models.Post) *activitypub.Object {
+	published := post.CreatedAt
+	if post.PublishedAt != nil {
+		published = *post.PublishedAt
+	}
+
+	object := &activitypub.Object{
+		ID:           s.PostURI(post.ID),
+		Type:         activitypub.TypeArticle,
+		AttributedTo: s.ActorURI(post.UserID),
+		Name:         post.Title,
+		Content:      post.ContentHTML,
+		URL:          s.siteURL + "/posts/" + post.Slug,
+		Published:    published.UTC().Format(time.RFC3339),
+		To:           []string{activitypub.Public},
+		Cc:           []string{s.followersURI(post.UserID)},
+	}
+	if post.UpdatedAt.After(published) {
+		object.Updated = post.UpdatedAt.UTC().Format(time.RFC3339)
+	}
+	return object
+}
+
+// createActivity envuelve un post en la actividad Create con que se publica
+func (s *FederationService) createActivity(post *models.Post) *activitypub.Activity {
+	object := s.postObject(post)
+	return &activitypub.Activity{
+		Context:   activitypub.ContextActivityStreams,
+		ID:        object.ID + "/activity",
+		Type:      activitypub.TypeCreate,
+		Actor:     object.AttributedTo,
+		Object:    object,
+		Published: object.Published,
+		To:        object.To,
+		Cc:        object.Cc,
+	}
+}
+
+// deleteActivity avisa que un post ya no está disponible
+func (s *FederationService) deleteActivity(postID int, userID int) *activitypub.Activity {
+	return &activitypub.Activity{
+		Context: activitypub.ContextActivityStreams,
+		ID:      s.PostURI(postID) + "#delete",
+		Type:    activitypub.TypeDelete,
+		Actor:   s.ActorURI(userID),
+		Object:  &activitypub.Object{ID: s.PostURI(postID), Type: activitypub.TypeTombstone},
+		To:      []string{activitypub.Public},
+		Cc:      []string{s.followersURI(userID)},
+	}
+}
+
+// HandleInbox procesa una actividad recibida en el inbox de un usuario.
+// El request tiene que estar firmado por el actor de la actividad. Se
+// procesan Follow, Undo de un Follow, Create de una respuesta a un post
+// y Delete de una respuesta importada; el resto se ignora.
+func (s *FederationService) HandleInbox(userID int, r *http.Request, body []byte) error {
+	if _, err := s.localUser(userID); err != nil {
+		return err
+	}
+
+	activity := &activitypub.IncomingActivity{}
+	if err := json.Unmarshal(body, activity); err != nil || activity.Type == "" || activity.Actor == "" {
+		return errors.New(ErrInvalidActivity)
+	}
+
+	sig, err := activitypub.ParseSignature(r)
+	if err != nil {
+		return errors.New(ErrInvalidSignature)
+	}
+
+	cached, err := s.fedRepo.FindRemoteActor(activity.Actor)
+	if err != nil {
+		return err
+	}
+	if cached == nil && activity.Type == activitypub.TypeDelete {
+		// Al borrarse una cuenta su servidor avisa a todos los que conoce;
+		// si no la conocíamos no hay nada que borrar (y su actor ya no existe)
+		return nil
+	}
+
+	actor, err := s.verifiedActor(activity.Actor, cached, sig, r, body)
+	if err != nil {
+		return err
+	}
+
+	switch activity.Type {
+	case activitypub.TypeFollow:
+		return s.handleFollow(userID, actor, activity)
+	case activitypub.TypeUndo:
+		return s.handleUndo(userID, actor, activity)
+	case activitypub.TypeCreate:
+		return s.handleCreate(actor, activity)
+	case activitypub.TypeDelete:
+		return s.handleDelete(actor, activity)
+	}
+	return nil
+}
+
+// verifiedActor comprueba la firma con la clave del actor. Si no se lo
+// conocía, o la firma no coincide con la clave guardada (puede haberla
+// cambiado), se lo busca en su servidor y se lo guarda.
+func (s *FederationService) verifiedActor(uri string, cached *models.RemoteActor, sig *activitypub.Signature, r *http.Request, body []byte) (*models.RemoteActor, error) {
+	// El actor viene de un request todavía sin verificar: antes de ir a
+	// buscarlo se exige que sea https y del mismo servidor que la clave
+	if err := checkActorURI(uri, sig.KeyID); err != nil {
+		return nil, errors.New(ErrInvalidSignature)
+	}
+
+	if cached != nil && cached.KeyID == sig.KeyID && s.verify(cached, sig, r, body) == nil {
+		return cached, nil
+	}
+
+	actor, err := s.fetchActor(r.Context(), uri)
+	if err != nil {
+		log.Printf("No se pudo obtener el actor %s: %v", uri, err)
+		return nil, errors.New(ErrInvalidSignature)
+	}
+	if actor.KeyID != sig.KeyID {
+		return nil, errors.New(ErrInvalidSignature)
+	}
+	if err := s.verify(actor, sig, r, body); err != nil {
+		return nil, errors.New(ErrInvalidSignature)
+	}
+
+	if err := s.fedRepo.SaveRemoteActor(actor); err != nil {
+		return nil, err
+	}
+	return actor, nil
+}
+
+func (s *FederationService) verify(actor *models.RemoteActor, sig *activitypub.Signature, r *http.Request, body []byte) error {
+	key, err := activitypub.ParsePublicKey(actor.PublicKeyPEM)
+	if err != nil {
+		return err
+	}
+	return sig.Verify(r, key, body, s.clock.Now())
+}
+
+// checkActorURI verifica que el ID de un actor sea una URL https y que
+// esté en el mismo host que keyID, la clave con la que se firmó el request
+func checkActorURI(uri string, keyID string) error {
+	actorURL, err := url.Parse(uri)
+	if err != nil || actorURL.Scheme != "https" || actorURL.Host == "" {
+		return errors.New("el actor debe ser una URL https")
+	}
+
+	keyURL, err := url.Parse(keyID)
+	if err != nil || !strings.EqualFold(keyURL.Host, actorURL.Host) {
+		return errors.New("la clave no es del servidor del actor")
+	}
+	return nil
+}
+
+// isHTTPS indica si rawURL es una URL https con host
+func isHTTPS(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	return err == nil && parsed.Scheme == "https" && parsed.Host != ""
+}
+
+// fetchActor busca el documento de un actor en su servidor
+func (s *FederationService) fetchActor(ctx context.Context, uri string) (*models.RemoteActor, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", activitypub.Accept)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("el servidor respondió %d", resp.StatusCode)
+	}
+
+	var doc activitypub.Actor
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxRemoteDocumentSize)).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc.ID != uri || doc.Inbox == "" || doc.PublicKey.PublicKeyPem == "" {
+		return nil, errors.New("el documento del actor está incompleto")
+	}
+	if doc.PublicKey.Owner != "" && doc.PublicKey.Owner != doc.ID {
+		return nil, errors.New("la clave no pertenece al actor")
+	}
+	if !isHTTPS(doc.Inbox) || (doc.Endpoints != nil && doc.Endpoints.SharedInbox != "" && !isHTTPS(doc.Endpoints.SharedInbox)) {
+		return nil, errors.New("los inboxes del actor deben ser URLs https")
+	}
+
+	parsed, err := url.Parse(doc.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	actor := &models.RemoteActor{
+		URI:          doc.ID,
+		Username:     doc.PreferredUsername + "@" + parsed.Host,
+		KeyID:        doc.PublicKey.ID,
+		PublicKeyPEM: doc.PublicKey.PublicKeyPem,
+		Inbox:        doc.Inbox,
+	}
+	if doc.Endpoints != nil {
+		actor.SharedInbox = doc.Endpoints.SharedInbox
+	}
+	return actor, nil
+}
+
+// handleFollow registra al actor como seguidor y le responde con Accept
+func (s *FederationService) handleFollow(userID int, actor *models.RemoteActor, activity *activitypub.IncomingActivity) error {
+	object, err := activitypub.ParseObject(activity.Object)
+	if err != nil || object.ID != s.ActorURI(userID) {
+		return errors.New(ErrInvalidActivity)
+	}
+
+	if _, err := s.followService.Follow(actor.UserID, userID); err != nil {
+		return err
+	}
+
+	accept := &activitypub.Activity{
+		Context: activitypub.ContextActivityStreams,
+		ID:      s.ActorURI(userID) + "#accepts/follows/" + strconv.Itoa(actor.UserID),
+		Type:    activitypub.TypeAccept,
+		Actor:   s.ActorURI(userID),
+		Object: &activitypub.Activity{
+			ID:     activity.ID,
+			Type:   activitypub.TypeFollow,
+			Actor:  actor.URI,
+			Object: s.ActorURI(userID),
+		},
+	}
+	go func() {
+		if err := s.deliver(s.ctx, userID, actor.Inbox, accept); err != nil {
+			log.Printf("Error al aceptar el seguidor %s: %v", actor.URI, err)
+		}
+	}()
+	return nil
+}
+
+// handleUndo procesa el Undo de un Follow
+func (s *FederationService) handleUndo(userID int, actor *models.RemoteActor, activity *activitypub.IncomingActivity) error {
+	object, err := activitypub.ParseObject(activity.Object)
+	if err != nil {
+		return errors.New(ErrInvalidActivity)
+	}
+	if object.Type != activitypub.TypeFollow {
+		return nil
+	}
+	if object.Actor != actor.URI {
+		return errors.New(ErrInvalidActivity)
+	}
+
+	_, err = s.followService.Unfollow(actor.UserID, userID)
+	return err
+}
+
+// handleCreate importa como comentario una respuesta (Note) a un post o a
+// otra respuesta ya importada
+func (s *FederationService) handleCreate(actor *models.RemoteActor, activity *activitypub.IncomingActivity) error {
+	note, err := activitypub.ParseObject(activity.Object)
+	if err != nil || note.ID == "" {
+		return errors.New(ErrInvalidActivity)
+	}
+	if note.Type != activitypub.TypeNote || note.InReplyTo == "" {
+		return nil
+	}
+	if note.AttributedTo != actor.URI {
+		return errors.New(ErrInvalidActivity)
+	}
+
+	postID, err := s.replyTarget(note.InReplyTo)
+	if err != nil || postID == 0 {
+		return err
+	}
+
+	existing, err := s.fedRepo.FindRemoteComment(note.ID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return nil
+	}
+
+	content := remoteText(note.Content)
+	if content == "" {
+		return errors.New(ErrInvalidActivity)
+	}
+
+	comment, err := s.postService.CreateComment(postID, &models.CreateCommentRequest{Content: content}, actor.UserID)
+	if err != nil {
+		if err.Error() == ErrPostNotFound {
+			// El post se borró o ya no es público
+			return nil
+		}
+		return err
+	}
+
+	return s.fedRepo.SaveRemoteComment(&models.RemoteComment{ObjectURI: note.ID, CommentID: comment.ID, PostID: postID})
+}
+
+// replyTarget devuelve el post al que responde inReplyTo, o 0 si no es
+// un post de este servidor ni una respuesta importada
+func (s *FederationService) replyTarget(inReplyTo string) (int, error) {
+	if id, ok := strings.CutPrefix(inReplyTo, s.baseURL+"/ap/posts/"); ok {
+		if postID, err := strconv.Atoi(id); err == nil {
+			return postID, nil
+		}
+	}
+
+	parent, err := s.fedRepo.FindRemoteComment(inReplyTo)
+	if err != nil || parent == nil {
+		return 0, err
+	}
+	return parent.PostID, nil
+}
+
+// remoteText convierte el HTML de una respuesta remota en el texto del
+// comentario
+func remoteText(content string) string {
+	return markdown.TextFromHTML(lineBreakRe.ReplaceAllString(content, " "))
+}
+
+// handleDelete borra el comentario importado de una respuesta remota
+func (s *FederationService) handleDelete(actor *models.RemoteActor, activity *activitypub.IncomingActivity) error {
+	object, err := activitypub.ParseObject(activity.Object)
+	if err != nil {
+		return errors.New(ErrInvalidActivity)
+	}
+
+	remote, err := s.fedRepo.FindRemoteComment(object.ID)
+	if err != nil || remote == nil {
+		return err
+	}
+	if remote.UserID != actor.UserID {
+		return errors.New(ErrInvalidActivity)
+	}
+
+	return s.postService.DeleteComment(remote.PostID, remote.CommentID, actor.UserID)
+}
+
+// Listen envía a los seguidores remotos los posts que se publican o
+// borran, hasta que se cancele ctx. Si el broker descarta la suscripción
+// por no leer a tiempo, vuelve a suscribirse desde el último evento.
+func (s *FederationService) Listen(ctx context.Context, broker *events.Broker) {
+	var lastID uint64
+	for {
+		sub, backlog, resumed := broker.Subscribe(nil, lastID)
+		if !resumed {
+			log.Println("Se perdieron eventos para la federación: el broker ya no los tenía")
+		}
+		for _, event := range backlog {
+			lastID = event.ID
+			s.handleEvent(event)
+		}
+
+		for open := true; open; {
+			select {
+			case <-ctx.Done():
+				sub.Close()
+				return
+			case event, ok := <-sub.C:
+				if !ok {
+					open = false
+					continue
+				}
+				lastID = event.ID
+				s.handleEvent(event)
+			}
+		}
+	}
+}
+
+// handleEvent arma la actividad de un evento y la envía en segundo plano
+func (s *FederationService) handleEvent(event *events.Event) {
+	var userID int
+	var activity *activitypub.Activity
+
+	switch event.Type {
+	case events.PostCreated:
+		post, err := s.postService.GetPostByID(event.PostID, 0)
+		if err != nil {
+			log.Printf("Error al federar el post %d: %v", event.PostID, err)
+			return
+		}
+		userID, activity = post.UserID, s.createActivity(post)
+	case events.PostDeleted:
+		var payload struct {
+			UserID int `json:"user_id"`
+		}
+		if err := json.Unmarshal(event.Data, &payload); err != nil || payload.UserID == 0 {
+			return
+		}
+		userID, activity = payload.UserID, s.deleteActivity(event.PostID, payload.UserID)
+	default:
+		return
+	}
+
+	go s.deliverToFollowers(s.ctx, userID, activity)
+}
+
+// deliverToFollowers envía una actividad a los seguidores remotos del
+// usuario, una vez por servidor si tienen inbox compartido. Los errores
+// solo se registran.
+func (s *FederationService) deliverToFollowers(ctx context.Context, userID int, activity *activitypub.Activity) {
+	followers, err := s.fedRepo.FindFollowerActors(userID)
+	if err != nil {
+		log.Printf("Error al obtener los seguidores remotos de %d: %v", userID, err)
+		return
+	}
+
+	sent := make(map[string]bool)
+	for _, follower := range followers {
+		inbox := follower.DeliveryInbox()
+		if sent[inbox] {
+			continue
+		}
+		sent[inbox] = true
+
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.deliver(ctx, userID, inbox, activity); err != nil {
+			log.Printf("Error al enviar %s a %s: %v", activity.Type, inbox, err)
+		}
+	}
+}
+
+// deliver envía una actividad firmada con la clave del usuario
+func (s *FederationService) deliver(ctx context.Context, userID int, inbox string, activity *activitypub.Activity) error {
+	body, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+
+	key, err := s.actorKey(userID)
+	if err != nil {
+		return err
+	}
+	privateKey, err := activitypub.ParsePrivateKey(key.PrivateKeyPEM)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, inbox, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", activitypub.ContentType)
+	if err := activitypub.SignRequest(req, s.keyID(userID), privateKey, body, s.clock.Now()); err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("el servidor respondió %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"log"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/repository"
+)
+
+// ErrCannotFollowSelf se devuelve al intentar seguirse a uno mismo
+const ErrCannotFollowSelf = "no puedes seguirte a ti mismo"
+
+// FollowService maneja los seguidores entre usuarios y sus perfiles públicos
+type FollowService struct {
+	followRepo repository.FollowRepository
+	userRepo   repository.UserRepository
+
+	// Opcional: avisa al usuario cuando tiene un seguidor nuevo
+	notifications *NotificationService
+}
+
+// NewFollowService crea una nueva instancia
+func NewFollowService(followRepo repository.FollowRepository, userRepo repository.UserRepository) *FollowService {
+	return &FollowService{
+		followRepo: followRepo,
+		userRepo:   userRepo,
+	}
+}
+
+// SetNotificationService habilita las notificaciones de seguidores nuevos
+func (s *FollowService) SetNotificationService(notifications *NotificationService) {
+	s.notifications = notifications
+}
+
+// Follow hace que followerID siga a followeeID. Seguirlo de nuevo no es
+// un error. Devuelve el perfil actualizado del usuario seguido.
+func (s *FollowService) Follow(followerID int, followeeID int) (*models.UserProfile, error) {
+	if followerID == followeeID {
+		return nil, errors.New(ErrCannotFollowSelf)
+	}
+
+	if err := s.checkUsers(followerID, followeeID); err != nil {
+		return nil, err
+	}
+
+	created, err := s.followRepo.Follow(followerID, followeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Solo se avisa la primera vez, no al repetir el follow
+	if created && s.notifications != nil {
+		if err := s.notifications.NotifyFollow(followerID, followeeID); err != nil {
+			log.Printf("Error al notificar el seguidor %d de %d: %v", followerID, followeeID, err)
+		}
+	}
+
+	return s.followRepo.FindProfile(followeeID, followerID)
+}
+
+// Unfollow deja de seguir a un usuario. Devuelve su perfil actualizado.
+func (s *FollowService) Unfollow(followerID int, followeeID int) (*models.UserProfile, error) {
+	if err := s.checkUsers(followerID, followeeID); err != nil {
+		return nil, err
+	}
+
+	if err := s.followRepo.Unfollow(followerID, followeeID); err != nil {
+		return nil, err
+	}
+
+	return s.followRepo.FindProfile(followeeID, followerID)
+}
+
+// GetProfile obtiene el perfil público de un usuario con sus contadores
+func (s *FollowService) GetProfile(userID int, viewerID int) (*models.UserProfile, error) {
+	profile, err := s.followRepo.FindProfile(userID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, errors.New(ErrUserNotFound)
+	}
+	return profile, nil
+}
+
+// GetFollowers obtiene una página de los seguidores del usuario
+func (s *FollowService) GetFollowers(userID int, viewerID int, cursor string, limit int) (*models.UserPage, error) {
+	return s.listPage(userID, viewerID, cursor, limit, s.followRepo.FindFollowers)
+}
+
+// GetFollowing obtiene una página de los usuarios que sigue el usuario
+func (s *FollowService) GetFollowing(userID int, viewerID int, cursor string, limit int) (*models.UserPage, error) {
+	return s.listPage(userID, viewerID, cursor, limit, s.followRepo.FindFollowing)
+}
+
+// listPage valida los parámetros de paginación y arma la página
+func (s *FollowService) listPage(userID int, viewerID int, cursor string, limit int, find func(userID int, viewerID int, after *models.Cursor, limit int) ([]*models.UserProfile, error)) (*models.UserPage, error) {
+	limit, err := pageLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := models.ParseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.GetProfile(userID, viewerID); err != nil {
+		return nil, err
+	}
+
+	profiles, err := find(userID, viewerID, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.UserPage{}
+	page.Users, page.NextCursor = paginate(profiles, limit, func(profile *models.UserProfile) models.Cursor {
+		return models.Cursor{Time: *profile.FollowedAt, ID: profile.ID}
+	})
+	return page, nil
+}
+
+// checkUsers verifica que ambos usuarios existan
+func (s *FollowService) checkUsers(followerID int, followeeID int) error {
+	for _, id := range []int{followerID, followeeID} {
+		user, err := s.userRepo.FindByID(id)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return errors.New(ErrUserNotFound)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"tp06-testing/internal/imaging"
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/repository"
+	"tp06-testing/internal/storage"
+)
+
+// imageBatchSize es la cantidad de imágenes que se procesan por vuelta
+const imageBatchSize = 10
+
+// imageVariants son las versiones reducidas que se generan de cada imagen,
+// con el tamaño máximo (ancho y alto) de cada una
+var imageVariants = []struct {
+	name    string
+	maxSize int
+}{
+	{models.VariantThumbnail, 320},
+	{models.VariantMedium, 1024},
+}
+
+// ImageProcessor genera en segundo plano las versiones reducidas de las
+// imágenes subidas. Las pendientes quedan marcadas en la base, así que
+// un reinicio del servidor no pierde trabajo.
+type ImageProcessor struct {
+	attachmentRepo repository.AttachmentRepository
+	store          storage.BlobStore
+	interval       time.Duration
+	wake           chan struct{}
+}
+
+// NewImageProcessor crea una nueva instancia que revisa cada interval
+// (además de despertarse con Notify cuando se sube una imagen)
+func NewImageProcessor(attachmentRepo repository.AttachmentRepository, store storage.BlobStore, interval time.Duration) *ImageProcessor {
+	return &ImageProcessor{
+		attachmentRepo: attachmentRepo,
+		store:          store,
+		interval:       interval,
+		wake:           make(chan struct{}, 1),
+	}
+}
+
+// Notify avisa que hay imágenes nuevas. No bloquea: si ya hay un aviso
+// pendiente, este se descarta.
+func (p *ImageProcessor) Notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// RunOnce procesa un lote de imágenes pendientes y devuelve cuántas tomó.
+// Las que fallan quedan como "failed" para no reintentarlas en cada vuelta.
+func (p *ImageProcessor) RunOnce(ctx context.Context) (int, error) {
+	pending, err := p.attachmentRepo.FindPendingImages(imageBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, attachment := range pending {
+		if err := p.process(ctx, attachment); err != nil {
+			log.Printf("Error al procesar la imagen %d: %v", attachment.ID, err)
+			if err := p.attachmentRepo.UpdateProcessingStatus(attachment.ID, models.AttachmentProcessingFailed); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return len(pending), nil
+}
+
+// Start ejecuta RunOnce hasta vaciar la cola cada vez que vence el
+// intervalo o llega un Notify, hasta que se cancele el contexto.
+// Está pensado para correr en su propia goroutine.
+func (p *ImageProcessor) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := p.RunOnce(ctx)
+			if err != nil {
+				log.Println("Error al procesar imágenes:", err)
+				break
+			}
+			if n < imageBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-p.wake:
+		}
+	}
+}
+
+// process genera y guarda todas las versiones de una imagen
+func (p *ImageProcessor) process(ctx context.Context, attachment *models.Attachment) error {
+	content, err := p.store.Open(ctx, attachment.StorageKey)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(io.LimitReader(content, MaxUploadSize+1))
+	content.Close()
+	if err != nil {
+		return err
+	}
+
+	img, err := imaging.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	var variants []*models.AttachmentVariant
+	for _, spec := range imageVariants {
+		resized := imaging.Fit(img, spec.maxSize, spec.maxSize)
+		encoded, contentType, err := imaging.Encode(resized, attachment.ContentType)
+		if err != nil {
+			return err
+		}
+
+		variant := &models.AttachmentVariant{
+			Name:        spec.name,
+			StorageKey:  variantKey(attachment.StorageKey, spec.name, contentType),
+			ContentType: contentType,
+			Width:       resized.Bounds().Dx(),
+			Height:      resized.Bounds().Dy(),
+			Size:        int64(len(encoded)),
+		}
+		if err := p.store.Put(ctx, variant.StorageKey, bytes.NewReader(encoded), variant.Size, contentType); err != nil {
+			return err
+		}
+		variants = append(variants, variant)
+	}
+
+	if err := p.attachmentRepo.SaveVariants(attachment.ID, variants); err != nil {
+		// Sin registro los archivos quedarían huérfanos
+		for _, variant := range variants {
+			p.store.Delete(ctx, variant.StorageKey)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// variantKey deriva la clave de una versión a partir de la del original,
+// así reprocesar una imagen reemplaza los archivos en lugar de duplicarlos.
+// Ejemplo: "attachments/2025/10/3f2a.jpg" -> "attachments/2025/10/3f2a_thumbnail.jpg"
+func variantKey(originalKey string, name string, contentType string) string {
+	ext := ".png"
+	if contentType == "image/jpeg" {
+		ext = ".jpg"
+	}
+	return strings.TrimSuffix(originalKey, path.Ext(originalKey)) + "_" + name + ext
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"log"
+	"strings"
+
+	"tp06-testing/internal/markdown"
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/repository"
+)
+
+// SetMentionRepository habilita guardar y devolver las menciones @usuario
+func (s *PostService) SetMentionRepository(mentionRepo repository.MentionRepository) {
+	s.mentionRepo = mentionRepo
+}
+
+// resolveMentions busca los @usuario del contenido (fuera del código) y
+// devuelve los que corresponden a un usuario existente, con su posición.
+// Los usuarios que bloquearon al autor no cuentan como mencionados.
+// Si no se guardan menciones ni hay notificaciones no hace nada.
+func (s *PostService) resolveMentions(content string, authorID int) ([]models.Mention, error) {
+	if s.mentionRepo == nil && s.notifications == nil {
+		return nil, nil
+	}
+
+	var mentions []models.Mention
+	users := map[string]*models.User{}
+
+	for _, found := range markdown.FindMentions(content) {
+		key := strings.ToLower(found.Username)
+		user, seen := users[key]
+		if !seen {
+			var err error
+			user, err = s.userRepo.FindByUsername(key)
+			if err != nil {
+				return nil, err
+			}
+			users[key] = user
+		}
+		if user == nil {
+			continue
+		}
+
+		mentions = append(mentions, models.Mention{
+			UserID:   user.ID,
+			Username: user.Username,
+			Offset:   found.Offset,
+			Length:   found.Length,
+		})
+	}
+
+	return s.withoutBlockers(mentions, authorID)
+}
+
+// withoutBlockers quita las menciones de usuarios que bloquearon al autor
+func (s *PostService) withoutBlockers(mentions []models.Mention, authorID int) ([]models.Mention, error) {
+	if s.blockRepo == nil || len(mentions) == 0 {
+		return mentions, nil
+	}
+
+	blockers, err := s.blockRepo.FindBlockers(mentionedUserIDs(mentions, nil), authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := mentions[:0]
+	for _, mention := range mentions {
+		if !blockers[mention.UserID] {
+			allowed = append(allowed, mention)
+		}
+	}
+	return allowed, nil
+}
+
+// mentionedUserIDs devuelve los usuarios mencionados sin repetir, salvo
+// los de exclude
+func mentionedUserIDs(mentions []models.Mention, exclude map[int]bool) []int {
+	var ids []int
+	seen := map[int]bool{}
+	for _, mention := range mentions {
+		if seen[mention.UserID] || exclude[mention.UserID] {
+			continue
+		}
+		seen[mention.UserID] = true
+		ids = append(ids, mention.UserID)
+	}
+	return ids
+}
+
+// savePostMentions reemplaza las menciones guardadas del post
+func (s *PostService) savePostMentions(post *models.Post, mentions []models.Mention) error {
+	post.Mentions = mentions
+	if s.mentionRepo == nil {
+		return nil
+	}
+	return s.mentionRepo.ReplacePostMentions(post.ID, mentions)
+}
+
+// saveCommentMentions guarda las menciones de un comentario nuevo
+func (s *PostService) saveCommentMentions(comment *models.Comment, mentions []models.Mention) error {
+	comment.Mentions = mentions
+	if s.mentionRepo == nil {
+		return nil
+	}
+	return s.mentionRepo.SaveCommentMentions(comment.PostID, comment.ID, mentions)
+}
+
+// loadPostMentions completa las menciones de los posts
+func (s *PostService) loadPostMentions(posts []*models.Post) error {
+	if s.mentionRepo == nil || len(posts) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+
+	mentions, err := s.mentionRepo.PostMentions(ids)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		post.Mentions = mentions[post.ID]
+	}
+	return nil
+}
+
+// loadCommentMentions completa las menciones de los comentarios
+func (s *PostService) loadCommentMentions(comments []*models.Comment) error {
+	if s.mentionRepo == nil || len(comments) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID
+	}
+
+	mentions, err := s.mentionRepo.CommentMentions(ids)
+	if err != nil {
+		return err
+	}
+
+	for _, comment := range comments {
+		comment.Mentions = mentions[comment.ID]
+	}
+	return nil
+}
+
+// notifyPostMentions avisa a los mencionados en un post publicado (y no
+// oculto por moderación) que pueden verlo. Con previous (las menciones que ya tenía) solo se avisa a
+// los nuevos. Los errores solo se registran: el post ya se guardó.
+func (s *PostService) notifyPostMentions(post *models.Post, previous []models.Mention) {
+	if s.notifications == nil || !post.IsPublished() || post.IsHidden() {
+		return
+	}
+
+	exclude := map[int]bool{post.UserID: true}
+	for _, mention := range previous {
+		exclude[mention.UserID] = true
+	}
+
+	userIDs := s.filterViewers(post, mentionedUserIDs(post.Mentions, exclude))
+	if len(userIDs) == 0 {
+		return
+	}
+
+	if err := s.notifications.NotifyPostMentions(post, userIDs); err != nil {
+		log.Printf("Error al notificar las menciones del post %d: %v", post.ID, err)
+	}
+}
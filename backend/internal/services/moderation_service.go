@@ -0,0 +1,572 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"tp06-testing/internal/contentfilter"
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/repository"
+)
+
+// Errores de reportes y moderación
+const (
+	ErrReportNotFound        = "reporte no encontrado"
+	ErrInvalidReportReason   = "motivo inválido: debe ser spam, harassment, hate, violence, sexual, misinformation u other"
+	ErrReportDetailsRequired = "indica en details el motivo del reporte"
+	ErrReportDetailsTooLong  = "el detalle del reporte no puede superar los 1000 caracteres"
+	ErrCannotReportOwn       = "no puedes reportar tu propio contenido"
+	ErrAlreadyReported       = "ya reportaste este contenido"
+	ErrNotModerator          = "no tienes permisos de moderador"
+	ErrReportClosed          = "el reporte ya fue cerrado"
+	ErrInvalidReportAction   = "acción inválida: debe ser hide, delete o ban"
+	ErrInvalidReportStatus   = "estado inválido: debe ser open, resolved o dismissed"
+
+	ErrSuspensionReasonRequired = "indica el motivo de la suspensión"
+	ErrSuspensionReasonTooLong  = "el motivo de la suspensión no puede superar los 1000 caracteres"
+	ErrSuspensionExpiresInPast  = "la suspensión debe terminar en el futuro"
+	ErrCannotSuspendModerator   = "no puedes suspender a un moderador"
+	ErrUserNotSuspended         = "el usuario no está suspendido"
+)
+
+// maxReportDetails es el largo máximo (en caracteres) del detalle de un
+// reporte
+const maxReportDetails = 1000
+
+// ModerationService maneja los reportes de posts y comentarios, la cola
+// que atienden los moderadores y las suspensiones de usuarios. Cuando un
+// contenido junta hideThreshold reportes abiertos de usuarios distintos se
+// oculta automáticamente hasta que un moderador decida.
+type ModerationService struct {
+	reportRepo     repository.ReportRepository
+	suspensionRepo repository.SuspensionRepository
+	postRepo       repository.PostRepository
+	userRepo       repository.UserRepository
+	clock          Clock
+	hideThreshold  int // 0 desactiva el ocultamiento automático
+
+	// Opcional: aprende de las decisiones sobre reportes de spam y del
+	// filtro de contenido
+	spamTrainer SpamTrainer
+
+	// Opcional: sin él, nadie puede reportar los posts de solo seguidores
+	followRepo repository.FollowRepository
+
+	// Opcional: corta las conexiones en vivo de los usuarios que se suspenden
+	sessionCloser SessionCloser
+}
+
+// SessionCloser cierra las conexiones abiertas de un usuario (lo
+// implementa realtime.Hub)
+type SessionCloser interface {
+	Disconnect(userID int)
+}
+
+// SpamTrainer aprende a reconocer spam a partir de ejemplos (lo
+// implementa contentfilter.SpamClassifier)
+type SpamTrainer interface {
+	Train(text string, spam bool) error
+}
+
+// NewModerationService crea una nueva instancia
+func NewModerationService(reportRepo repository.ReportRepository, suspensionRepo repository.SuspensionRepository, postRepo repository.PostRepository, userRepo repository.UserRepository, hideThreshold int) *ModerationService {
+	return &ModerationService{
+		reportRepo:     reportRepo,
+		suspensionRepo: suspensionRepo,
+		postRepo:       postRepo,
+		userRepo:       userRepo,
+		clock:          RealClock{},
+		hideThreshold:  hideThreshold,
+	}
+}
+
+// SetClock reemplaza el reloj usado para validar y levantar suspensiones
+// (útil en tests)
+func (s *ModerationService) SetClock(clock Clock) {
+	s.clock = clock
+}
+
+// SetSpamTrainer habilita el entrenamiento del clasificador de spam: al
+// actuar sobre un reporte de spam o del filtro el contenido cuenta como
+// spam, y al descartarlo como contenido legítimo
+func (s *ModerationService) SetSpamTrainer(trainer SpamTrainer) {
+	s.spamTrainer = trainer
+}
+
+// SetFollowRepository permite a los seguidores reportar los posts de solo
+// seguidores y sus comentarios
+func (s *ModerationService) SetFollowRepository(followRepo repository.FollowRepository) {
+	s.followRepo = followRepo
+}
+
+// SetSessionCloser hace que suspender a un usuario también lo saque de las
+// salas en vivo, que no vuelven a pasar por el control de cada petición
+func (s *ModerationService) SetSessionCloser(closer SessionCloser) {
+	s.sessionCloser = closer
+}
+
+// train entrena el clasificador con el contenido del reporte si el motivo
+// tiene que ver con spam. Un error acá no debe hacer fallar la decisión
+// del moderador, que ya se guardó: solo se registra.
+func (s *ModerationService) train(report *models.Report, spam bool) {
+	if s.spamTrainer == nil || (report.Reason != models.ReportSpam && report.Reason != models.ReportFilter) {
+		return
+	}
+	if err := s.spamTrainer.Train(report.Content, spam); err != nil {
+		log.Printf("Error al entrenar el clasificador con el reporte %d: %v", report.ID, err)
+	}
+}
+
+// HoldForReview oculta un contenido que retuvo el filtro y lo pone en la
+// cola de moderación con un reporte sin usuario (motivo "filter") que
+// explica qué filtro lo retuvo y por qué. commentID es 0 para un post.
+func (s *ModerationService) HoldForReview(postID int, commentID int, authorID int, content string, decision *contentfilter.Decision) error {
+	if err := s.reportRepo.SetHidden(postID, commentID, true); err != nil {
+		return err
+	}
+
+	report := &models.Report{
+		PostID:    postID,
+		CommentID: commentID,
+		AuthorID:  authorID,
+		Reason:    models.ReportFilter,
+		Details:   decision.Filter + ": " + decision.Reason,
+		Content:   content,
+	}
+	if _, err := s.reportRepo.Create(report); err != nil {
+		return err
+	}
+
+	return s.reportRepo.AddAction(&models.ReportAction{
+		ReportID: report.ID,
+		Action:   models.ReportActionHide,
+		Note:     "retenido por el filtro de contenido",
+	})
+}
+
+// ReportPost reporta un post visible para el usuario
+func (s *ModerationService) ReportPost(postID int, req *models.CreateReportRequest, userID int) (*models.Report, error) {
+	post, err := s.postRepo.FindByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVisible(post, userID, s.followRepo); err != nil {
+		return nil, err
+	}
+
+	report := &models.Report{
+		ReporterID: userID,
+		PostID:     post.ID,
+		AuthorID:   post.UserID,
+		Content:    post.Title + "\n\n" + post.Content,
+	}
+	return s.report(report, req, post.IsHidden())
+}
+
+// ReportComment reporta un comentario de un post visible para el usuario
+func (s *ModerationService) ReportComment(postID int, commentID int, req *models.CreateReportRequest, userID int) (*models.Report, error) {
+	post, err := s.postRepo.FindByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVisible(post, userID, s.followRepo); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.postRepo.FindCommentByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment == nil || comment.PostID != postID {
+		return nil, errors.New(ErrCommentNotFound)
+	}
+
+	report := &models.Report{
+		ReporterID: userID,
+		PostID:     post.ID,
+		CommentID:  comment.ID,
+		AuthorID:   comment.UserID,
+		Content:    comment.Content,
+	}
+	return s.report(report, req, comment.HiddenAt != nil)
+}
+
+// report valida y guarda el reporte y, si el contenido llegó al umbral,
+// lo oculta
+func (s *ModerationService) report(report *models.Report, req *models.CreateReportRequest, hidden bool) (*models.Report, error) {
+	reason, details, err := validateReport(req)
+	if err != nil {
+		return nil, err
+	}
+	if report.AuthorID == report.ReporterID {
+		return nil, errors.New(ErrCannotReportOwn)
+	}
+
+	user, err := s.userRepo.FindByID(report.ReporterID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New(ErrUserNotFound)
+	}
+
+	report.Reason = reason
+	report.Details = details
+
+	created, err := s.reportRepo.Create(report)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, errors.New(ErrAlreadyReported)
+	}
+
+	if s.hideThreshold > 0 && !hidden {
+		if err := s.autoHide(report); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// validateReport normaliza y valida motivo y detalle
+func validateReport(req *models.CreateReportRequest) (string, string, error) {
+	reason := strings.ToLower(strings.TrimSpace(req.Reason))
+	if !models.IsValidReportReason(reason) {
+		return "", "", errors.New(ErrInvalidReportReason)
+	}
+
+	details := strings.TrimSpace(req.Details)
+	if reason == models.ReportOther && details == "" {
+		return "", "", errors.New(ErrReportDetailsRequired)
+	}
+	if utf8.RuneCountInString(details) > maxReportDetails {
+		return "", "", errors.New(ErrReportDetailsTooLong)
+	}
+
+	return reason, details, nil
+}
+
+// autoHide oculta el contenido del reporte si ya tiene hideThreshold
+// reportes abiertos. La acción queda registrada sin moderador.
+func (s *ModerationService) autoHide(report *models.Report) error {
+	count, err := s.reportRepo.CountOpen(report.PostID, report.CommentID)
+	if err != nil {
+		return err
+	}
+	if count < s.hideThreshold {
+		return nil
+	}
+
+	if err := s.reportRepo.SetHidden(report.PostID, report.CommentID, true); err != nil {
+		return err
+	}
+
+	return s.reportRepo.AddAction(&models.ReportAction{
+		ReportID: report.ID,
+		Action:   models.ReportActionHide,
+		Note:     fmt.Sprintf("ocultado automáticamente tras %d reportes", count),
+	})
+}
+
+// requireModerator devuelve ErrNotModerator si el usuario no es moderador
+func (s *ModerationService) requireModerator(userID int) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsModerator() {
+		return errors.New(ErrNotModerator)
+	}
+	return nil
+}
+
+// GetReports obtiene una página de la cola de moderación con los reportes
+// en el estado pedido (vacío equivale a los abiertos)
+func (s *ModerationService) GetReports(moderatorID int, status string, cursor string, limit int) (*models.ReportPage, error) {
+	if err := s.requireModerator(moderatorID); err != nil {
+		return nil, err
+	}
+
+	if status == "" {
+		status = models.ReportOpen
+	}
+	if status != models.ReportOpen && status != models.ReportResolved && status != models.ReportDismissed {
+		return nil, errors.New(ErrInvalidReportStatus)
+	}
+
+	limit, err := pageLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+	after, err := models.ParseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	reports, err := s.reportRepo.FindByStatus(status, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.ReportPage{}
+	page.Reports, page.NextCursor = paginate(reports, limit, func(report *models.Report) models.Cursor {
+		return models.Cursor{Time: report.CreatedAt, ID: report.ID}
+	})
+	return page, nil
+}
+
+// GetReport obtiene un reporte con su historial de acciones
+func (s *ModerationService) GetReport(moderatorID int, reportID int) (*models.Report, error) {
+	if err := s.requireModerator(moderatorID); err != nil {
+		return nil, err
+	}
+	return s.findReport(reportID)
+}
+
+// findReport obtiene un reporte con su historial de acciones
+func (s *ModerationService) findReport(reportID int) (*models.Report, error) {
+	report, err := s.reportRepo.FindByID(reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		return nil, errors.New(ErrReportNotFound)
+	}
+
+	report.Actions, err = s.reportRepo.FindActions(reportID)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// findOpenReport valida el moderador y obtiene un reporte que siga abierto
+func (s *ModerationService) findOpenReport(moderatorID int, reportID int) (*models.Report, error) {
+	if err := s.requireModerator(moderatorID); err != nil {
+		return nil, err
+	}
+
+	report, err := s.reportRepo.FindByID(reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		return nil, errors.New(ErrReportNotFound)
+	}
+	if report.Status != models.ReportOpen {
+		return nil, errors.New(ErrReportClosed)
+	}
+	return report, nil
+}
+
+// close cierra los reportes abiertos sobre el contenido registrando la
+// acción del moderador y devuelve el reporte actualizado
+func (s *ModerationService) close(report *models.Report, status string, action string, moderatorID int, note string) (*models.Report, error) {
+	err := s.reportRepo.Close(report, status, &models.ReportAction{
+		ReportID:    report.ID,
+		ModeratorID: &moderatorID,
+		Action:      action,
+		Note:        strings.TrimSpace(note),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.findReport(report.ID)
+}
+
+// Resolve cierra el reporte (y los demás abiertos sobre el mismo
+// contenido) sin tocar el contenido
+func (s *ModerationService) Resolve(moderatorID int, reportID int, req *models.ModerateReportRequest) (*models.Report, error) {
+	report, err := s.findOpenReport(moderatorID, reportID)
+	if err != nil {
+		return nil, err
+	}
+	return s.close(report, models.ReportResolved, models.ReportActionResolve, moderatorID, req.Note)
+}
+
+// Dismiss descarta el reporte (y los demás abiertos sobre el mismo
+// contenido). Si el contenido se había ocultado vuelve a mostrarse.
+func (s *ModerationService) Dismiss(moderatorID int, reportID int, req *models.ModerateReportRequest) (*models.Report, error) {
+	report, err := s.findOpenReport(moderatorID, reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.TargetStatus == models.ReportTargetHidden {
+		if err := s.reportRepo.SetHidden(report.PostID, report.CommentID, false); err != nil {
+			return nil, err
+		}
+		err := s.reportRepo.AddAction(&models.ReportAction{
+			ReportID:    report.ID,
+			ModeratorID: &moderatorID,
+			Action:      models.ReportActionUnhide,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	closed, err := s.close(report, models.ReportDismissed, models.ReportActionDismiss, moderatorID, req.Note)
+	if err != nil {
+		return nil, err
+	}
+	s.train(report, false)
+	return closed, nil
+}
+
+// Act aplica una acción sobre el contenido reportado y cierra el reporte
+// (y los demás abiertos sobre el mismo contenido): "hide" lo oculta,
+// "delete" lo borra y "ban" suspende al autor de forma permanente (con la
+// nota como motivo) y además oculta el contenido.
+func (s *ModerationService) Act(moderatorID int, reportID int, req *models.ModerateReportRequest) (*models.Report, error) {
+	action := strings.ToLower(strings.TrimSpace(req.Action))
+	if action != models.ReportActionHide && action != models.ReportActionDelete && action != models.ReportActionBan {
+		return nil, errors.New(ErrInvalidReportAction)
+	}
+
+	report, err := s.findOpenReport(moderatorID, reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch action {
+	case models.ReportActionHide:
+		err = s.hide(report)
+	case models.ReportActionDelete:
+		err = s.delete(report, moderatorID)
+	case models.ReportActionBan:
+		reason := strings.TrimSpace(req.Note)
+		if reason == "" {
+			reason = fmt.Sprintf("expulsado por el reporte #%d", report.ID)
+		}
+		err = s.createSuspension(&models.Suspension{UserID: report.AuthorID, ModeratorID: &moderatorID, Reason: reason})
+		if err == nil {
+			err = s.hide(report)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	closed, err := s.close(report, models.ReportResolved, action, moderatorID, req.Note)
+	if err != nil {
+		return nil, err
+	}
+	s.train(report, true)
+	return closed, nil
+}
+
+// SuspendUser suspende a un usuario con un motivo, hasta req.ExpiresAt o
+// de forma permanente si no se indica. Si ya estaba suspendido, la nueva
+// suspensión reemplaza a la anterior.
+func (s *ModerationService) SuspendUser(moderatorID int, userID int, req *models.SuspendUserRequest) (*models.Suspension, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, errors.New(ErrSuspensionReasonRequired)
+	}
+	if utf8.RuneCountInString(reason) > maxReportDetails {
+		return nil, errors.New(ErrSuspensionReasonTooLong)
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(s.clock.Now()) {
+		return nil, errors.New(ErrSuspensionExpiresInPast)
+	}
+
+	if err := s.requireModerator(moderatorID); err != nil {
+		return nil, err
+	}
+
+	suspension := &models.Suspension{
+		UserID:      userID,
+		ModeratorID: &moderatorID,
+		Reason:      reason,
+		ExpiresAt:   req.ExpiresAt,
+	}
+	if err := s.createSuspension(suspension); err != nil {
+		return nil, err
+	}
+	return suspension, nil
+}
+
+// createSuspension verifica que el usuario exista y no sea moderador y
+// guarda la suspensión
+func (s *ModerationService) createSuspension(suspension *models.Suspension) error {
+	user, err := s.userRepo.FindByID(suspension.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New(ErrUserNotFound)
+	}
+	if user.IsModerator() {
+		return errors.New(ErrCannotSuspendModerator)
+	}
+
+	if err := s.suspensionRepo.Create(suspension); err != nil {
+		return err
+	}
+	if s.sessionCloser != nil {
+		s.sessionCloser.Disconnect(suspension.UserID)
+	}
+	return nil
+}
+
+// LiftSuspension levanta la suspensión vigente del usuario y devuelve su
+// historial de suspensiones
+func (s *ModerationService) LiftSuspension(moderatorID int, userID int) ([]*models.Suspension, error) {
+	if err := s.requireModerator(moderatorID); err != nil {
+		return nil, err
+	}
+
+	lifted, err := s.suspensionRepo.Lift(userID, moderatorID, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !lifted {
+		return nil, errors.New(ErrUserNotSuspended)
+	}
+
+	return s.suspensionRepo.FindByUser(userID)
+}
+
+// GetSuspensions obtiene el historial de suspensiones de un usuario, las
+// más recientes primero
+func (s *ModerationService) GetSuspensions(moderatorID int, userID int) ([]*models.Suspension, error) {
+	if err := s.requireModerator(moderatorID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New(ErrUserNotFound)
+	}
+
+	return s.suspensionRepo.FindByUser(userID)
+}
+
+// hide oculta el contenido si sigue visible
+func (s *ModerationService) hide(report *models.Report) error {
+	if report.TargetStatus != models.ReportTargetVisible {
+		return nil
+	}
+	return s.reportRepo.SetHidden(report.PostID, report.CommentID, true)
+}
+
+// delete manda el contenido a la papelera si todavía existe. Como lo
+// borra el moderador, el autor no puede restaurarlo.
+func (s *ModerationService) delete(report *models.Report, moderatorID int) error {
+	if report.TargetStatus == models.ReportTargetDeleted {
+		return nil
+	}
+	if report.IsComment() {
+		return s.postRepo.DeleteComment(report.PostID, report.CommentID, report.AuthorID, moderatorID)
+	}
+	return s.postRepo.Delete(report.PostID, moderatorID)
+}
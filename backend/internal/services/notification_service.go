@@ -0,0 +1,236 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/repository"
+)
+
+// ErrNotificationNotFound se devuelve al marcar una notificación ajena o inexistente
+const ErrNotificationNotFound = "notificación no encontrada"
+
+// NotificationService crea las notificaciones de actividad y las lista
+// para su destinatario
+type NotificationService struct {
+	notificationRepo repository.NotificationRepository
+	postRepo         repository.PostRepository
+}
+
+// NewNotificationService crea una nueva instancia
+func NewNotificationService(notificationRepo repository.NotificationRepository, postRepo repository.PostRepository) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		postRepo:         postRepo,
+	}
+}
+
+// NotifyComment avisa de un comentario nuevo a los mencionados, al autor
+// del post y a quienes ya habían comentado en él. Cada usuario recibe una
+// sola notificación (la mención tiene prioridad) y el autor del
+// comentario ninguna.
+func (s *NotificationService) NotifyComment(post *models.Post, comment *models.Comment, mentionedIDs []int) error {
+	commenterIDs, err := s.postRepo.FindCommenterIDs(post.ID)
+	if err != nil {
+		return err
+	}
+
+	type recipient struct {
+		userID           int
+		notificationType string
+	}
+	var recipients []recipient
+	for _, id := range mentionedIDs {
+		recipients = append(recipients, recipient{id, models.NotificationMention})
+	}
+	recipients = append(recipients, recipient{post.UserID, models.NotificationComment})
+	for _, id := range commenterIDs {
+		recipients = append(recipients, recipient{id, models.NotificationReply})
+	}
+
+	notified := map[int]bool{comment.UserID: true}
+	for _, r := range recipients {
+		if notified[r.userID] {
+			continue
+		}
+		notified[r.userID] = true
+
+		postID, commentID := post.ID, comment.ID
+		err := s.notify(&models.Notification{
+			UserID:    r.userID,
+			Type:      r.notificationType,
+			ActorID:   comment.UserID,
+			PostID:    &postID,
+			CommentID: &commentID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NotifyPostMentions avisa a los usuarios mencionados en un post publicado
+func (s *NotificationService) NotifyPostMentions(post *models.Post, userIDs []int) error {
+	for _, userID := range userIDs {
+		if userID == post.UserID {
+			continue
+		}
+
+		postID := post.ID
+		err := s.notify(&models.Notification{
+			UserID:  userID,
+			Type:    models.NotificationMention,
+			ActorID: post.UserID,
+			PostID:  &postID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NotifyFollow avisa a followeeID que followerID empezó a seguirlo
+func (s *NotificationService) NotifyFollow(followerID int, followeeID int) error {
+	return s.notify(&models.Notification{
+		UserID:  followeeID,
+		Type:    models.NotificationFollow,
+		ActorID: followerID,
+	})
+}
+
+// notify guarda la notificación si el destinatario quiere recibir ese tipo
+func (s *NotificationService) notify(notification *models.Notification) error {
+	prefs, err := s.notificationRepo.FindPreferences(notification.UserID)
+	if err != nil {
+		return err
+	}
+	if !prefs.Allows(notification.Type) {
+		return nil
+	}
+
+	return s.notificationRepo.CreateOrCoalesce(notification)
+}
+
+// GetNotifications obtiene una página de notificaciones del usuario (solo
+// las no leídas si unreadOnly) junto con la cantidad sin leer
+func (s *NotificationService) GetNotifications(userID int, unreadOnly bool, cursor string, limit int) (*models.NotificationPage, error) {
+	limit, err := pageLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := models.ParseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, err := s.notificationRepo.FindByUser(userID, unreadOnly, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := s.notificationRepo.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, notification := range notifications {
+		notification.Message = notificationMessage(notification)
+	}
+
+	page := &models.NotificationPage{UnreadCount: unread}
+	page.Notifications, page.NextCursor = paginate(notifications, limit, func(notification *models.Notification) models.Cursor {
+		return models.Cursor{Time: notification.UpdatedAt, ID: notification.ID}
+	})
+	return page, nil
+}
+
+// GetUnreadCount cuenta las notificaciones sin leer del usuario
+func (s *NotificationService) GetUnreadCount(userID int) (int, error) {
+	return s.notificationRepo.CountUnread(userID)
+}
+
+// MarkRead marca como leída una notificación del usuario
+func (s *NotificationService) MarkRead(userID int, notificationID int) error {
+	found, err := s.notificationRepo.MarkRead(userID, notificationID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New(ErrNotificationNotFound)
+	}
+	return nil
+}
+
+// MarkAllRead marca como leídas todas las notificaciones del usuario
+func (s *NotificationService) MarkAllRead(userID int) (int, error) {
+	return s.notificationRepo.MarkAllRead(userID)
+}
+
+// GetPreferences obtiene qué notificaciones quiere recibir el usuario
+func (s *NotificationService) GetPreferences(userID int) (*models.NotificationPreferences, error) {
+	return s.notificationRepo.FindPreferences(userID)
+}
+
+// UpdatePreferences cambia solo las preferencias que vienen en el request
+func (s *NotificationService) UpdatePreferences(userID int, req *models.UpdateNotificationPreferencesRequest) (*models.NotificationPreferences, error) {
+	prefs, err := s.notificationRepo.FindPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range []struct {
+		value  *bool
+		target *bool
+	}{
+		{req.Comments, &prefs.Comments},
+		{req.Replies, &prefs.Replies},
+		{req.Mentions, &prefs.Mentions},
+		{req.Follows, &prefs.Follows},
+	} {
+		if field.value != nil {
+			*field.target = *field.value
+		}
+	}
+
+	if err := s.notificationRepo.SavePreferences(prefs); err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// notificationMessage arma el texto de la notificación. Las agrupadas
+// dicen cuántos eventos reúnen ("5 comentarios nuevos en ...").
+func notificationMessage(n *models.Notification) string {
+	title := `"` + n.PostTitle + `"`
+
+	if n.Count > 1 {
+		switch n.Type {
+		case models.NotificationComment:
+			return fmt.Sprintf("%d comentarios nuevos en %s", n.Count, title)
+		case models.NotificationReply:
+			return fmt.Sprintf("%d comentarios nuevos en %s, donde comentaste", n.Count, title)
+		case models.NotificationMention:
+			return fmt.Sprintf("%d menciones nuevas en %s", n.Count, title)
+		case models.NotificationFollow:
+			return fmt.Sprintf("%s y %d personas más empezaron a seguirte", n.ActorUsername, n.Count-1)
+		}
+	}
+
+	switch n.Type {
+	case models.NotificationComment:
+		return fmt.Sprintf("%s comentó en %s", n.ActorUsername, title)
+	case models.NotificationReply:
+		return fmt.Sprintf("%s también comentó en %s", n.ActorUsername, title)
+	case models.NotificationMention:
+		return fmt.Sprintf("%s te mencionó en %s", n.ActorUsername, title)
+	case models.NotificationFollow:
+		return fmt.Sprintf("%s empezó a seguirte", n.ActorUsername)
+	default:
+		return ""
+	}
+}
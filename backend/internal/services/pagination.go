@@ -0,0 +1,53 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"tp06-testing/internal/models"
+)
+
+// Tamaños de página para los listados paginados por cursor
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// ErrInvalidLimit se devuelve cuando el tamaño de página pedido es inválido
+const ErrInvalidLimit = "limit inválido: debe estar entre 1 y 100"
+
+// pageLimit valida el tamaño de página (0 equivale al tamaño por defecto)
+func pageLimit(limit int) (int, error) {
+	if limit == 0 {
+		return defaultPageSize, nil
+	}
+	if limit < 0 || limit > maxPageSize {
+		return 0, errors.New(ErrInvalidLimit)
+	}
+	return limit, nil
+}
+
+// paginate recorta a limit elementos una consulta que pidió limit+1:
+// si vino uno de más hay página siguiente y el cursor apunta al último
+// elemento incluido. Nunca devuelve nil.
+func paginate[T any](items []T, limit int, cursorOf func(T) models.Cursor) ([]T, string) {
+	if items == nil {
+		items = []T{}
+	}
+	if len(items) <= limit {
+		return items, ""
+	}
+
+	items = items[:limit]
+	return items, cursorOf(items[limit-1]).String()
+}
+
+// newPostPage arma una página de posts. cursorTime indica la fecha por
+// la que está ordenado el listado.
+func newPostPage(posts []*models.Post, limit int, cursorTime func(*models.Post) time.Time) *models.PostPage {
+	page := &models.PostPage{}
+	page.Posts, page.NextCursor = paginate(posts, limit, func(post *models.Post) models.Cursor {
+		return models.Cursor{Time: cursorTime(post), ID: post.ID}
+	})
+	return page
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"tp06-testing/internal/models"
+)
+
+// PostScheduler publica los posts programados cuando llega su fecha
+type PostScheduler struct {
+	postService *PostService
+	clock       Clock
+	interval    time.Duration
+}
+
+// NewPostScheduler crea una nueva instancia que revisa cada interval
+func NewPostScheduler(postService *PostService, clock Clock, interval time.Duration) *PostScheduler {
+	return &PostScheduler{
+		postService: postService,
+		clock:       clock,
+		interval:    interval,
+	}
+}
+
+// RunOnce publica los posts vencidos según el reloj y los devuelve. Cada
+// uno se anuncia como en PublishPost (menciones y post.created).
+func (s *PostScheduler) RunOnce() ([]*models.Post, error) {
+	return s.postService.PublishDue(s.clock.Now())
+}
+
+// Start ejecuta RunOnce periódicamente hasta que se cancele el contexto.
+// Está pensado para correr en su propia goroutine.
+func (s *PostScheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if posts, err := s.RunOnce(); err != nil {
+			log.Println("Error al publicar posts programados:", err)
+		} else if len(posts) > 0 {
+			ids := make([]int, len(posts))
+			for i, post := range posts {
+				ids[i] = post.ID
+			}
+			log.Printf("Posts programados publicados: %v", ids)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
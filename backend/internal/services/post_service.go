@@ -2,29 +2,444 @@ package services
 
 import (
 	"errors"
+	"log"
 	"strings"
+	"time"
+	"unicode/utf8"
 
+	"tp06-testing/internal/contentfilter"
+	"tp06-testing/internal/events"
+	"tp06-testing/internal/markdown"
 	"tp06-testing/internal/models"
 	"tp06-testing/internal/repository"
 )
 
 // Constantes para mensajes de error
 const (
-	ErrUserNotFound = "usuario no encontrado"
-	ErrPostNotFound = "post no encontrado"
+	ErrUserNotFound  = "usuario no encontrado"
+	ErrPostNotFound  = "post no encontrado"
+	ErrUserSuspended = "tu cuenta está suspendida"
+
+	ErrContentRejected = "el contenido no cumple las normas del sitio y no se publicó"
+	ErrContentTooLong  = "el contenido no puede superar los 50000 caracteres"
+	ErrSlugConflict    = "no se pudo reservar un slug para el título, intenta de nuevo"
 )
 
+// MaxContentLength es el largo máximo (en caracteres) del contenido de un
+// post o comentario. Acota también el trabajo de renderizar el Markdown.
+const MaxContentLength = 50000
+
 // PostService maneja la lógica de posts y comentarios
 type PostService struct {
 	postRepo repository.PostRepository
 	userRepo repository.UserRepository
+	clock    Clock
+	renderer *markdown.Renderer
+
+	// Cuánto tiempo se pueden restaurar los posts y comentarios borrados
+	trashRetention time.Duration
+
+	// Opcional: si está configurado, el detalle de un post incluye sus adjuntos
+	attachmentRepo repository.AttachmentRepository
+
+	// Opcional: si está configurado, posts y comentarios incluyen sus reacciones
+	reactionRepo repository.ReactionRepository
+
+	// Opcional: habilita los posts guardados y el flag "bookmarked"
+	bookmarkRepo repository.BookmarkRepository
+
+	// Opcional: guarda las menciones @usuario y las devuelve con el contenido
+	mentionRepo repository.MentionRepository
+
+	// Opcional: impide comentar y mencionar a quien bloqueó al autor
+	blockRepo repository.BlockRepository
+
+	// Opcional: sin él, los posts de solo seguidores solo los ve su autor
+	followRepo repository.FollowRepository
+
+	// Opcional: impide publicar, editar y borrar a los usuarios suspendidos
+	suspensionRepo repository.SuspensionRepository
+
+	// Opcional: avisa de los comentarios nuevos al autor, a los otros
+	// participantes y a los mencionados
+	notifications *NotificationService
+
+	// Opcional: publica los cambios de posts y comentarios visibles para
+	// los clientes conectados en tiempo real
+	broker *events.Broker
+
+	// Opcional: lleva los comentarios de los posts que no son públicos a
+	// las salas en vivo, donde solo entra quien puede ver el post
+	rooms RoomPublisher
+
+	// Opcional: revisa posts y comentarios antes de guardarlos. Lo que se
+	// retiene queda oculto y va a la cola de moderación.
+	contentFilter contentfilter.ContentFilter
+	moderation    *ModerationService
 }
 
+const (
+	// renderCacheSize es la cantidad de revisiones de contenido cuyo HTML
+	// se mantiene en memoria
+	renderCacheSize = 1000
+
+	// excerptLength es el largo máximo (en caracteres) del resumen de un post
+	excerptLength = 200
+
+	// wordsPerMinute es la velocidad de lectura usada para estimar el tiempo
+	wordsPerMinute = 200
+)
+
 // NewPostService crea una nueva instancia
 func NewPostService(postRepo repository.PostRepository, userRepo repository.UserRepository) *PostService {
 	return &PostService{
 		postRepo: postRepo,
 		userRepo: userRepo,
+		clock:    RealClock{},
+		renderer: markdown.NewRenderer(renderCacheSize),
+
+		trashRetention: DefaultTrashRetention,
+	}
+}
+
+// renderPost completa ContentHTML, resumen, cantidad de palabras y
+// tiempo de lectura a partir del Markdown del post
+func (s *PostService) renderPost(post *models.Post) *models.Post {
+	post.ContentHTML = s.renderer.Render(post.Content)
+
+	post.Excerpt = markdown.ExcerptFromHTML(post.ContentHTML, excerptLength)
+	post.WordCount = markdown.WordCount(markdown.TextFromHTML(post.ContentHTML))
+	post.ReadingTime = 0
+	if post.WordCount > 0 {
+		post.ReadingTime = (post.WordCount + wordsPerMinute - 1) / wordsPerMinute
+	}
+
+	if post.Reactions == nil {
+		post.Reactions = map[string]int{}
+		post.MyReactions = []string{}
+	}
+	if post.Mentions == nil {
+		post.Mentions = []models.Mention{}
+	}
+
+	return post
+}
+
+// renderComment completa ContentHTML a partir del Markdown del comentario
+func (s *PostService) renderComment(comment *models.Comment) *models.Comment {
+	comment.ContentHTML = s.renderer.Render(comment.Content)
+
+	if comment.Reactions == nil {
+		comment.Reactions = map[string]int{}
+		comment.MyReactions = []string{}
+	}
+	if comment.Mentions == nil {
+		comment.Mentions = []models.Mention{}
+	}
+
+	return comment
+}
+
+// SetClock reemplaza el reloj usado para programar publicaciones (útil en tests)
+func (s *PostService) SetClock(clock Clock) {
+	s.clock = clock
+}
+
+// SetAttachmentRepository habilita la carga de adjuntos en el detalle de un post
+func (s *PostService) SetAttachmentRepository(attachmentRepo repository.AttachmentRepository) {
+	s.attachmentRepo = attachmentRepo
+}
+
+// SetBlockRepository habilita los bloqueos: un usuario bloqueado no puede
+// comentar los posts de quien lo bloqueó ni mencionarlo
+func (s *PostService) SetBlockRepository(blockRepo repository.BlockRepository) {
+	s.blockRepo = blockRepo
+}
+
+// SetFollowRepository permite a los seguidores ver los posts de solo
+// seguidores
+func (s *PostService) SetFollowRepository(followRepo repository.FollowRepository) {
+	s.followRepo = followRepo
+}
+
+// SetSuspensionRepository habilita el control de suspensiones: un usuario
+// suspendido no puede crear, editar, publicar ni borrar posts o comentarios
+func (s *PostService) SetSuspensionRepository(suspensionRepo repository.SuspensionRepository) {
+	s.suspensionRepo = suspensionRepo
+}
+
+// checkNotSuspended devuelve ErrUserSuspended si el usuario tiene una
+// suspensión vigente
+func (s *PostService) checkNotSuspended(userID int) error {
+	if s.suspensionRepo == nil {
+		return nil
+	}
+
+	suspension, err := s.suspensionRepo.FindActive(userID, s.clock.Now())
+	if err != nil {
+		return err
+	}
+	if suspension != nil {
+		return errors.New(ErrUserSuspended)
+	}
+	return nil
+}
+
+// SetNotificationService habilita las notificaciones de comentarios
+func (s *PostService) SetNotificationService(notifications *NotificationService) {
+	s.notifications = notifications
+}
+
+// SetContentFilter habilita la revisión de posts y comentarios. Para que
+// el contenido retenido llegue a los moderadores hay que configurar
+// también SetModerationService.
+func (s *PostService) SetContentFilter(filter contentfilter.ContentFilter) {
+	s.contentFilter = filter
+}
+
+// SetModerationService habilita el envío a la cola de moderación del
+// contenido que retiene el filtro
+func (s *PostService) SetModerationService(moderation *ModerationService) {
+	s.moderation = moderation
+}
+
+// checkContent pasa el texto por el filtro, si está configurado. Devuelve
+// ErrContentRejected si lo rechaza, o la decisión si hay que retenerlo
+// para revisión (nil si se publica normalmente).
+func (s *PostService) checkContent(kind string, authorID int, postID int, text string) (*contentfilter.Decision, error) {
+	if s.contentFilter == nil {
+		return nil, nil
+	}
+
+	author, err := s.userRepo.FindByID(authorID)
+	if err != nil {
+		return nil, err
+	}
+	if author == nil {
+		return nil, errors.New(ErrUserNotFound)
+	}
+
+	decision, err := s.contentFilter.Check(&contentfilter.Content{
+		Kind:            kind,
+		AuthorID:        authorID,
+		AuthorCreatedAt: author.CreatedAt,
+		PostID:          postID,
+		Text:            text,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if decision == nil || decision.Verdict == contentfilter.Allow {
+		return nil, nil
+	}
+	if decision.Filter == "" {
+		decision.Filter = s.contentFilter.Name()
+	}
+
+	if decision.Verdict == contentfilter.Reject {
+		log.Printf("Filtro %s: rechazado %s de %s (%s)", decision.Filter, kind, author.Username, decision.Reason)
+		return nil, errors.New(ErrContentRejected)
+	}
+	return decision, nil
+}
+
+// holdForReview manda a la cola de moderación el contenido retenido, que
+// ya se guardó oculto. Sin servicio de moderación queda oculto sin entrar
+// a la cola.
+func (s *PostService) holdForReview(postID int, commentID int, authorID int, text string, decision *contentfilter.Decision) error {
+	if s.moderation == nil {
+		return nil
+	}
+	return s.moderation.HoldForReview(postID, commentID, authorID, text, decision)
+}
+
+// SetEventBroker habilita la publicación de eventos en tiempo real
+func (s *PostService) SetEventBroker(broker *events.Broker) {
+	s.broker = broker
+}
+
+// publish envía un evento si el broker está configurado. Solo se publican
+// cambios de posts públicos (IsPublic), ya que el stream lo puede escuchar
+// cualquiera.
+func (s *PostService) publish(eventType string, postID int, data interface{}) {
+	if s.broker == nil {
+		return
+	}
+	if err := s.broker.Publish(eventType, postID, data); err != nil {
+		log.Printf("Error al publicar el evento %s del post %d: %v", eventType, postID, err)
+	}
+}
+
+// RoomPublisher envía un evento a quienes están en la sala en vivo de un
+// post (realtime.Hub)
+type RoomPublisher interface {
+	Publish(eventType string, postID int, data interface{}) error
+}
+
+// SetRoomPublisher habilita los eventos de comentarios en las salas en
+// vivo de los posts que no son públicos
+func (s *PostService) SetRoomPublisher(rooms RoomPublisher) {
+	s.rooms = rooms
+}
+
+// publishComment envía un evento de comentario. Los de posts públicos van
+// al broker; los del resto (no listados, de seguidores o privados) solo a
+// la sala en vivo del post, porque el stream, los webhooks y la federación
+// no saben quién puede verlo.
+func (s *PostService) publishComment(post *models.Post, eventType string, data interface{}) {
+	if post.IsPublic() {
+		s.publish(eventType, post.ID, data)
+		return
+	}
+	if s.rooms == nil || !post.IsPublished() || post.IsHidden() {
+		return
+	}
+	if err := s.rooms.Publish(eventType, post.ID, data); err != nil {
+		log.Printf("Error al enviar el evento %s a la sala del post %d: %v", eventType, post.ID, err)
+	}
+}
+
+// loadAttachments completa los adjuntos del post si el repositorio está configurado
+func (s *PostService) loadAttachments(post *models.Post) error {
+	if s.attachmentRepo == nil {
+		return nil
+	}
+
+	attachments, err := s.attachmentRepo.FindByPostID(post.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, attachment := range attachments {
+		withAttachmentURL(attachment)
+	}
+	post.Attachments = attachments
+
+	return nil
+}
+
+// SetReactionRepository habilita la carga de reacciones en posts y comentarios
+func (s *PostService) SetReactionRepository(reactionRepo repository.ReactionRepository) {
+	s.reactionRepo = reactionRepo
+}
+
+// SetBookmarkRepository habilita los posts guardados
+func (s *PostService) SetBookmarkRepository(bookmarkRepo repository.BookmarkRepository) {
+	s.bookmarkRepo = bookmarkRepo
+}
+
+// loadPostData completa lo que se carga en lote para varios posts: las
+// menciones, las reacciones y si el viewer guardó cada post
+func (s *PostService) loadPostData(posts []*models.Post, viewerID int) error {
+	if err := s.loadPostMentions(posts); err != nil {
+		return err
+	}
+	if err := s.loadPostReactions(posts, viewerID); err != nil {
+		return err
+	}
+	return s.loadBookmarks(posts, viewerID)
+}
+
+// loadBookmarks marca los posts que el viewer guardó (los anónimos no
+// tienen guardados)
+func (s *PostService) loadBookmarks(posts []*models.Post, viewerID int) error {
+	if s.bookmarkRepo == nil || viewerID <= 0 || len(posts) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+
+	bookmarked, err := s.bookmarkRepo.BookmarkedPostIDs(viewerID, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		post.Bookmarked = bookmarked[post.ID]
+	}
+
+	return nil
+}
+
+// loadPostReactions completa las reacciones de los posts (y cuáles son
+// del viewer) si el repositorio está configurado
+func (s *PostService) loadPostReactions(posts []*models.Post, viewerID int) error {
+	if s.reactionRepo == nil || len(posts) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+
+	summaries, err := s.reactionRepo.PostReactions(ids, viewerID)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		if summary, ok := summaries[post.ID]; ok {
+			post.Reactions = summary.Counts
+			post.ReactionCount = summary.Total
+			post.MyReactions = summary.Mine
+		}
+	}
+
+	return nil
+}
+
+// loadCommentReactions es el equivalente de loadPostReactions para comentarios
+func (s *PostService) loadCommentReactions(comments []*models.Comment, viewerID int) error {
+	if s.reactionRepo == nil || len(comments) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID
+	}
+
+	summaries, err := s.reactionRepo.CommentReactions(ids, viewerID)
+	if err != nil {
+		return err
+	}
+
+	for _, comment := range comments {
+		if summary, ok := summaries[comment.ID]; ok {
+			comment.Reactions = summary.Counts
+			comment.ReactionCount = summary.Total
+			comment.MyReactions = summary.Mine
+		}
+	}
+
+	return nil
+}
+
+// resolvePublication calcula estado y fecha de publicación de un post nuevo
+func (s *PostService) resolvePublication(status string, publishAt *time.Time) (string, *time.Time, error) {
+	now := s.clock.Now()
+
+	switch strings.TrimSpace(status) {
+	case "", models.PostStatusPublished:
+		return models.PostStatusPublished, &now, nil
+	case models.PostStatusDraft:
+		return models.PostStatusDraft, nil, nil
+	case models.PostStatusScheduled:
+		if publishAt == nil {
+			return "", nil, errors.New("la fecha de publicación es requerida")
+		}
+		if !publishAt.After(now) {
+			return "", nil, errors.New("la fecha de publicación debe ser futura")
+		}
+		at := publishAt.UTC()
+		return models.PostStatusScheduled, &at, nil
+	default:
+		return "", nil, errors.New("estado inválido")
 	}
 }
 
@@ -42,6 +457,20 @@ func (s *PostService) CreatePost(req *models.CreatePostRequest, userID int) (*mo
 		return nil, errors.New("el contenido es requerido")
 	}
 
+	if utf8.RuneCountInString(strings.TrimSpace(req.Content)) > MaxContentLength {
+		return nil, errors.New(ErrContentTooLong)
+	}
+
+	status, publishedAt, err := s.resolvePublication(req.Status, req.PublishAt)
+	if err != nil {
+		return nil, err
+	}
+
+	visibility, err := resolveVisibility(req.Visibility, models.PostVisibilityPublic)
+	if err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.FindByID(userID)
 	if err != nil {
 		return nil, err
@@ -49,27 +478,80 @@ func (s *PostService) CreatePost(req *models.CreatePostRequest, userID int) (*mo
 	if user == nil {
 		return nil, errors.New(ErrUserNotFound)
 	}
+	if err := s.checkNotSuspended(userID); err != nil {
+		return nil, err
+	}
+
+	slug, err := s.uniqueSlug(req.Title, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimSpace(req.Title) + "\n\n" + strings.TrimSpace(req.Content)
+	held, err := s.checkContent(contentfilter.KindPost, userID, 0, text)
+	if err != nil {
+		return nil, err
+	}
 
 	post := &models.Post{
-		Title:   strings.TrimSpace(req.Title),
-		Content: strings.TrimSpace(req.Content),
-		UserID:  userID,
+		Title:       strings.TrimSpace(req.Title),
+		Content:     strings.TrimSpace(req.Content),
+		Slug:        slug,
+		Status:      status,
+		Visibility:  visibility,
+		PublishedAt: publishedAt,
+		UserID:      userID,
+	}
+
+	if held != nil {
+		hiddenAt := s.clock.Now()
+		post.HiddenAt = &hiddenAt
 	}
 
-	err = s.postRepo.Create(post)
+	mentions, err := s.resolveMentions(post.Content, post.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.saveWithUniqueSlug(post, func() error { return s.postRepo.Create(post) })
 	if err != nil {
 		return nil, err
 	}
 
 	post.Username = user.Username
 
+	if err := s.savePostMentions(post, mentions); err != nil {
+		return nil, err
+	}
+
+	s.renderPost(post)
+	if held != nil {
+		if err := s.holdForReview(post.ID, 0, userID, text, held); err != nil {
+			return nil, err
+		}
+		return post, nil
+	}
+
+	s.notifyPostMentions(post, nil)
+	if post.IsPublic() {
+		s.publish(events.PostCreated, post.ID, post)
+	}
+
 	return post, nil
 }
 
-// GetAllPosts obtiene todos los posts del sistema.
+// GetAllPosts obtiene los posts publicados que el viewer puede ver en un
+// listado (públicos, y de solo seguidores si sigue al autor) más todos los
+// suyos (viewerID 0 para un visitante anónimo), en el orden indicado
+// (SortNew o SortTop; vacío equivale a SortNew).
 // Retorna una lista vacía si no hay posts, nunca retorna nil.
-func (s *PostService) GetAllPosts() ([]*models.Post, error) {
-	posts, err := s.postRepo.FindAll()
+func (s *PostService) GetAllPosts(viewerID int, order string) ([]*models.Post, error) {
+	order, err := validateSort(order)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := s.postRepo.FindAll(viewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -80,11 +562,60 @@ func (s *PostService) GetAllPosts() ([]*models.Post, error) {
 		return []*models.Post{}, nil
 	}
 
+	if err := s.loadPostData(posts, viewerID); err != nil {
+		return nil, err
+	}
+
+	for _, post := range posts {
+		s.renderPost(post)
+	}
+
+	if order == SortTop {
+		sortPostsByTop(posts, s.clock.Now())
+	}
+
+	return posts, nil
+}
+
+// GetLatestPublicPosts obtiene los últimos limit posts publicados y
+// públicos de todos los autores, los más nuevos primero (los usa el feed)
+func (s *PostService) GetLatestPublicPosts(limit int) ([]*models.Post, error) {
+	posts, err := s.postRepo.FindLatestPublic(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, post := range posts {
+		s.renderPost(post)
+	}
+
+	return posts, nil
+}
+
+// CountPublicPostsByUser cuenta los posts publicados y públicos de un usuario
+func (s *PostService) CountPublicPostsByUser(userID int) (int, error) {
+	return s.postRepo.CountPublicByUser(userID)
+}
+
+// GetPublicPostsByUser obtiene una página (desde offset, hasta limit) de los
+// posts publicados y públicos de un usuario, los más nuevos primero
+func (s *PostService) GetPublicPostsByUser(userID int, offset int, limit int) ([]*models.Post, error) {
+	posts, err := s.postRepo.FindPublicByUser(userID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, post := range posts {
+		s.renderPost(post)
+	}
+
 	return posts, nil
 }
 
-// GetPostByID obtiene un post específico
-func (s *PostService) GetPostByID(id int) (*models.Post, error) {
+// GetPostByID obtiene un post específico. Los que el viewer no puede ver
+// (no publicados, privados o de solo seguidores sin seguir al autor) se
+// informan como inexistentes; los no listados los ve cualquiera con el link.
+func (s *PostService) GetPostByID(id int, viewerID int) (*models.Post, error) {
 	if id <= 0 {
 		return nil, errors.New("id inválido")
 	}
@@ -94,15 +625,301 @@ func (s *PostService) GetPostByID(id int) (*models.Post, error) {
 		return nil, err
 	}
 
+	if err := checkVisible(post, viewerID, s.followRepo); err != nil {
+		return nil, err
+	}
+
+	if err := s.loadAttachments(post); err != nil {
+		return nil, err
+	}
+
+	if err := s.loadPostData([]*models.Post{post}, viewerID); err != nil {
+		return nil, err
+	}
+
+	return s.renderPost(post), nil
+}
+
+// GetPostBySlug obtiene un post por su slug. Si el slug es uno anterior
+// (el título cambió), devuelve el post con su slug actual para que
+// el handler pueda redirigir.
+func (s *PostService) GetPostBySlug(slug string, viewerID int) (*models.Post, error) {
+	slug = strings.TrimSpace(slug)
+	if slug == "" {
+		return nil, errors.New("slug inválido")
+	}
+
+	post, err := s.postRepo.FindBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkVisible(post, viewerID, s.followRepo); err != nil {
+		return nil, err
+	}
+
+	if err := s.loadAttachments(post); err != nil {
+		return nil, err
+	}
+
+	if err := s.loadPostData([]*models.Post{post}, viewerID); err != nil {
+		return nil, err
+	}
+
+	return s.renderPost(post), nil
+}
+
+// UpdatePost edita título, contenido y visibilidad de un post (solo el autor
+// puede hacerlo). Si el título cambia se genera un slug nuevo y el anterior
+// queda como redirección.
+func (s *PostService) UpdatePost(postID int, req *models.UpdatePostRequest, userID int) (*models.Post, error) {
+	if strings.TrimSpace(req.Title) == "" {
+		return nil, errors.New("el título es requerido")
+	}
+
+	if len(strings.TrimSpace(req.Title)) < 3 {
+		return nil, errors.New("el título debe tener al menos 3 caracteres")
+	}
+
+	if strings.TrimSpace(req.Content) == "" {
+		return nil, errors.New("el contenido es requerido")
+	}
+
+	if utf8.RuneCountInString(strings.TrimSpace(req.Content)) > MaxContentLength {
+		return nil, errors.New(ErrContentTooLong)
+	}
+
+	if err := s.checkNotSuspended(userID); err != nil {
+		return nil, err
+	}
+
+	post, err := s.findOwnPost(postID, userID, "no tienes permiso para editar este post")
+	if err != nil {
+		return nil, err
+	}
+
+	visibility, err := resolveVisibility(req.Visibility, post.Visibility)
+	if err != nil {
+		return nil, err
+	}
+	wasPublic := post.IsPublic()
+	post.Visibility = visibility
+
+	previousSlug := post.Slug
+	title := strings.TrimSpace(req.Title)
+
+	if title != post.Title {
+		slug, err := s.uniqueSlug(title, post.ID)
+		if err != nil {
+			return nil, err
+		}
+		post.Slug = slug
+	}
+
+	post.Title = title
+	post.Content = strings.TrimSpace(req.Content)
+
+	held, err := s.checkContent(contentfilter.KindPost, userID, post.ID, post.Title+"\n\n"+post.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	// La edición retenida se guarda ya oculta: no puede quedar visible ni
+	// por un instante, aunque falle la cola de moderación
+	wasHidden := post.IsHidden()
+	if held != nil && !wasHidden {
+		hiddenAt := s.clock.Now()
+		post.HiddenAt = &hiddenAt
+	}
+
+	mentions, err := s.resolveMentions(post.Content, post.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loadPostMentions([]*models.Post{post}); err != nil {
+		return nil, err
+	}
+	previous := post.Mentions
+
+	if err := s.saveWithUniqueSlug(post, func() error { return s.postRepo.Update(post, previousSlug) }); err != nil {
+		return nil, err
+	}
+
+	if err := s.savePostMentions(post, mentions); err != nil {
+		return nil, err
+	}
+
+	if held != nil {
+		if wasPublic {
+			s.publish(events.PostDeleted, post.ID, deletedPayload(post, 0))
+		}
+		if err := s.holdForReview(post.ID, 0, userID, post.Title+"\n\n"+post.Content, held); err != nil {
+			return nil, err
+		}
+		return s.renderPost(post), nil
+	}
+
+	s.notifyPostMentions(post, previous)
+	s.renderPost(post)
+
+	// Para los clientes conectados, cambiar la visibilidad equivale a crear
+	// o borrar el post (los ocultos por moderación nunca son públicos)
+	switch {
+	case wasPublic && !post.IsPublic():
+		s.publish(events.PostDeleted, post.ID, deletedPayload(post, 0))
+	case !wasPublic && post.IsPublic():
+		s.publish(events.PostCreated, post.ID, post)
+	}
+
+	return post, nil
+}
+
+// PublishPost publica un post (solo el autor puede hacerlo). Si se indica
+// una fecha futura el post queda programado y lo publica el PostScheduler.
+func (s *PostService) PublishPost(postID int, req *models.PublishPostRequest, userID int) (*models.Post, error) {
+	if err := s.checkNotSuspended(userID); err != nil {
+		return nil, err
+	}
+
+	post, err := s.findOwnPost(postID, userID, "no tienes permiso para publicar este post")
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	wasPublished := post.IsPublished()
+
+	if req != nil && req.PublishAt != nil && req.PublishAt.After(now) {
+		at := req.PublishAt.UTC()
+		post.Status = models.PostStatusScheduled
+		post.PublishedAt = &at
+	} else {
+		// Si ya había sido publicado se conserva la fecha original
+		if post.PublishedAt == nil || post.Status == models.PostStatusScheduled {
+			post.PublishedAt = &now
+		}
+		post.Status = models.PostStatusPublished
+	}
+
+	if err := s.postRepo.UpdateStatus(post.ID, post.Status, post.PublishedAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.loadPostMentions([]*models.Post{post}); err != nil {
+		return nil, err
+	}
+	s.renderPost(post)
+
+	if !wasPublished && post.IsPublished() {
+		s.announcePublished(post)
+	}
+
+	return post, nil
+}
+
+// PublishDue publica los posts programados cuya fecha ya llegó y los
+// anuncia igual que PublishPost. Lo usa el PostScheduler.
+func (s *PostService) PublishDue(now time.Time) ([]*models.Post, error) {
+	ids, err := s.postRepo.PublishDue(now)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]*models.Post, 0, len(ids))
+	for _, id := range ids {
+		post, err := s.postRepo.FindByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if post != nil {
+			posts = append(posts, post)
+		}
+	}
+
+	if err := s.loadPostMentions(posts); err != nil {
+		return nil, err
+	}
+	for _, post := range posts {
+		s.renderPost(post)
+		s.announcePublished(post)
+	}
+
+	return posts, nil
+}
+
+// announcePublished avisa que un post se publicó: las menciones de un
+// borrador o de un post programado se avisan recién al publicarlo, y para
+// los clientes conectados es un post nuevo si es público
+func (s *PostService) announcePublished(post *models.Post) {
+	s.notifyPostMentions(post, nil)
+	if post.IsPublic() {
+		s.publish(events.PostCreated, post.ID, post)
+	}
+}
+
+// UnpublishPost vuelve un post a borrador (solo el autor puede hacerlo)
+func (s *PostService) UnpublishPost(postID int, userID int) (*models.Post, error) {
+	return s.changeStatus(postID, userID, models.PostStatusDraft)
+}
+
+// ArchivePost retira un post del listado público sin eliminarlo
+func (s *PostService) ArchivePost(postID int, userID int) (*models.Post, error) {
+	return s.changeStatus(postID, userID, models.PostStatusArchived)
+}
+
+// changeStatus pasa un post a borrador o archivado
+func (s *PostService) changeStatus(postID int, userID int, status string) (*models.Post, error) {
+	if err := s.checkNotSuspended(userID); err != nil {
+		return nil, err
+	}
+
+	post, err := s.findOwnPost(postID, userID, "no tienes permiso para modificar este post")
+	if err != nil {
+		return nil, err
+	}
+
+	// Un post programado que vuelve a borrador pierde su fecha
+	if post.Status == models.PostStatusScheduled {
+		post.PublishedAt = nil
+	}
+	wasPublic := post.IsPublic()
+	post.Status = status
+
+	if err := s.postRepo.UpdateStatus(post.ID, post.Status, post.PublishedAt); err != nil {
+		return nil, err
+	}
+
+	// Para los clientes conectados, un post que deja de ser público se borró
+	if wasPublic {
+		s.publish(events.PostDeleted, post.ID, deletedPayload(post, 0))
+	}
+
+	return s.renderPost(post), nil
+}
+
+// findOwnPost busca un post y verifica que pertenezca al usuario
+func (s *PostService) findOwnPost(postID int, userID int, forbidden string) (*models.Post, error) {
+	post, err := s.postRepo.FindByID(postID)
+	if err != nil {
+		return nil, err
+	}
 	if post == nil {
 		return nil, errors.New(ErrPostNotFound)
 	}
 
+	if post.UserID != userID {
+		return nil, errors.New(forbidden)
+	}
+
 	return post, nil
 }
 
-// DeletePost elimina un post (solo el autor puede hacerlo)
+// DeletePost manda un post a la papelera (solo el autor puede hacerlo)
 func (s *PostService) DeletePost(postID int, userID int) error {
+	if err := s.checkNotSuspended(userID); err != nil {
+		return err
+	}
+
 	post, err := s.postRepo.FindByID(postID)
 	if err != nil {
 		return err
@@ -115,7 +932,24 @@ func (s *PostService) DeletePost(postID int, userID int) error {
 		return errors.New("no tienes permiso para eliminar este post")
 	}
 
-	return s.postRepo.Delete(postID)
+	if err := s.postRepo.Delete(postID, userID); err != nil {
+		return err
+	}
+
+	if post.IsPublic() {
+		s.publish(events.PostDeleted, postID, deletedPayload(post, 0))
+	}
+	return nil
+}
+
+// deletedPayload arma el payload de post.deleted y comment.deleted. El de
+// post.deleted incluye el autor, que la federación necesita para avisarles
+// a sus seguidores remotos.
+func deletedPayload(post *models.Post, commentID int) map[string]int {
+	if commentID == 0 {
+		return map[string]int{"id": post.ID, "user_id": post.UserID}
+	}
+	return map[string]int{"id": commentID, "post_id": post.ID}
 }
 
 // CreateComment agrega un comentario a un post
@@ -124,12 +958,16 @@ func (s *PostService) CreateComment(postID int, req *models.CreateCommentRequest
 		return nil, errors.New("el contenido del comentario es requerido")
 	}
 
+	if utf8.RuneCountInString(strings.TrimSpace(req.Content)) > MaxContentLength {
+		return nil, errors.New(ErrContentTooLong)
+	}
+
 	post, err := s.postRepo.FindByID(postID)
 	if err != nil {
 		return nil, err
 	}
-	if post == nil {
-		return nil, errors.New(ErrPostNotFound)
+	if err := checkVisible(post, userID, s.followRepo); err != nil {
+		return nil, err
 	}
 
 	user, err := s.userRepo.FindByID(userID)
@@ -139,6 +977,13 @@ func (s *PostService) CreateComment(postID int, req *models.CreateCommentRequest
 	if user == nil {
 		return nil, errors.New(ErrUserNotFound)
 	}
+	if err := s.checkNotSuspended(userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkNotBlocked(post.UserID, userID); err != nil {
+		return nil, err
+	}
 
 	comment := &models.Comment{
 		PostID:  postID,
@@ -146,6 +991,20 @@ func (s *PostService) CreateComment(postID int, req *models.CreateCommentRequest
 		Content: strings.TrimSpace(req.Content),
 	}
 
+	held, err := s.checkContent(contentfilter.KindComment, userID, post.ID, comment.Content)
+	if err != nil {
+		return nil, err
+	}
+	if held != nil {
+		hiddenAt := s.clock.Now()
+		comment.HiddenAt = &hiddenAt
+	}
+
+	mentions, err := s.resolveMentions(comment.Content, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	err = s.postRepo.CreateComment(comment)
 	if err != nil {
 		return nil, err
@@ -153,20 +1012,71 @@ func (s *PostService) CreateComment(postID int, req *models.CreateCommentRequest
 
 	comment.Username = user.Username
 
+	if err := s.saveCommentMentions(comment, mentions); err != nil {
+		return nil, err
+	}
+
+	s.renderComment(comment)
+	if held != nil {
+		if err := s.holdForReview(post.ID, comment.ID, userID, comment.Content, held); err != nil {
+			return nil, err
+		}
+		return comment, nil
+	}
+
+	s.notifyComment(post, comment)
+	s.publishComment(post, events.CommentCreated, comment)
+
 	return comment, nil
 }
 
-// GetCommentsByPostID obtiene todos los comentarios de un post
-func (s *PostService) GetCommentsByPostID(postID int) ([]*models.Comment, error) {
+// checkNotBlocked devuelve ErrBlockedByAuthor si authorID bloqueó a userID
+func (s *PostService) checkNotBlocked(authorID int, userID int) error {
+	if s.blockRepo == nil || authorID == userID {
+		return nil
+	}
+
+	blockers, err := s.blockRepo.FindBlockers([]int{authorID}, userID)
+	if err != nil {
+		return err
+	}
+	if blockers[authorID] {
+		return errors.New(ErrBlockedByAuthor)
+	}
+	return nil
+}
+
+// notifyComment avisa del comentario nuevo si las notificaciones están
+// configuradas y el post no está oculto. Un error acá no debe hacer
+// fallar el comentario, que ya se guardó: solo se registra.
+func (s *PostService) notifyComment(post *models.Post, comment *models.Comment) {
+	if s.notifications == nil || post.IsHidden() {
+		return
+	}
+
+	mentionedIDs := s.filterViewers(post, mentionedUserIDs(comment.Mentions, nil))
+	if err := s.notifications.NotifyComment(post, comment, mentionedIDs); err != nil {
+		log.Printf("Error al notificar el comentario %d: %v", comment.ID, err)
+	}
+}
+
+// GetCommentsByPostID obtiene todos los comentarios de un post visible
+// para el viewer, en orden cronológico (SortNew) o por reacciones (SortTop)
+func (s *PostService) GetCommentsByPostID(postID int, viewerID int, order string) ([]*models.Comment, error) {
+	order, err := validateSort(order)
+	if err != nil {
+		return nil, err
+	}
+
 	post, err := s.postRepo.FindByID(postID)
 	if err != nil {
 		return nil, err
 	}
-	if post == nil {
-		return nil, errors.New(ErrPostNotFound)
+	if err := checkVisible(post, viewerID, s.followRepo); err != nil {
+		return nil, err
 	}
 
-	comments, err := s.postRepo.FindCommentsByPostID(postID)
+	comments, err := s.postRepo.FindCommentsByPostID(postID, viewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -175,10 +1085,30 @@ func (s *PostService) GetCommentsByPostID(postID int) ([]*models.Comment, error)
 		return []*models.Comment{}, nil
 	}
 
+	if err := s.loadCommentReactions(comments, viewerID); err != nil {
+		return nil, err
+	}
+	if err := s.loadCommentMentions(comments); err != nil {
+		return nil, err
+	}
+
+	for _, comment := range comments {
+		s.renderComment(comment)
+	}
+
+	if order == SortTop {
+		sortCommentsByTop(comments, s.clock.Now())
+	}
+
 	return comments, nil
 }
 
+// DeleteComment manda a la papelera un comentario del usuario
 func (s *PostService) DeleteComment(postID int, commentID int, userID int) error {
+	if err := s.checkNotSuspended(userID); err != nil {
+		return err
+	}
+
 	post, err := s.postRepo.FindByID(postID)
 	if err != nil {
 		return err
@@ -195,5 +1125,115 @@ func (s *PostService) DeleteComment(postID int, commentID int, userID int) error
 		return errors.New(ErrUserNotFound)
 	}
 
-	return s.postRepo.DeleteComment(postID, commentID, userID)
+	if err := s.postRepo.DeleteComment(postID, commentID, userID, userID); err != nil {
+		return err
+	}
+
+	s.publishComment(post, events.CommentDeleted, deletedPayload(post, commentID))
+	return nil
+}
+
+// BookmarkPost guarda un post visible para el usuario. Guardarlo de
+// nuevo no es un error.
+func (s *PostService) BookmarkPost(postID int, userID int) error {
+	if s.bookmarkRepo == nil {
+		return errors.New("los posts guardados no están habilitados")
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New(ErrUserNotFound)
+	}
+
+	post, err := s.postRepo.FindByID(postID)
+	if err != nil {
+		return err
+	}
+	if err := checkVisible(post, userID, s.followRepo); err != nil {
+		return err
+	}
+
+	return s.bookmarkRepo.Add(userID, postID)
+}
+
+// UnbookmarkPost quita un post de los guardados del usuario. Quitar uno
+// que no estaba guardado no es un error.
+func (s *PostService) UnbookmarkPost(postID int, userID int) error {
+	if s.bookmarkRepo == nil {
+		return errors.New("los posts guardados no están habilitados")
+	}
+
+	return s.bookmarkRepo.Remove(userID, postID)
+}
+
+// GetBookmarks obtiene una página de los posts guardados por el usuario,
+// los últimos guardados primero. cursor es el NextCursor de la página
+// anterior (vacío para la primera) y limit 0 usa el tamaño por defecto.
+func (s *PostService) GetBookmarks(userID int, cursor string, limit int) (*models.PostPage, error) {
+	if s.bookmarkRepo == nil {
+		return nil, errors.New("los posts guardados no están habilitados")
+	}
+
+	limit, err := pageLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := models.ParseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Se pide uno de más para saber si hay otra página
+	posts, err := s.bookmarkRepo.FindPostsByUser(userID, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loadPostMentions(posts); err != nil {
+		return nil, err
+	}
+	if err := s.loadPostReactions(posts, userID); err != nil {
+		return nil, err
+	}
+	for _, post := range posts {
+		s.renderPost(post)
+	}
+
+	return newPostPage(posts, limit, func(post *models.Post) time.Time {
+		return *post.BookmarkedAt
+	}), nil
+}
+
+// GetFeed obtiene una página del feed personal: los posts publicados de
+// los autores que sigue el usuario, los más nuevos primero
+func (s *PostService) GetFeed(userID int, cursor string, limit int) (*models.PostPage, error) {
+	limit, err := pageLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := models.ParseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := s.postRepo.FindFeed(userID, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.loadPostData(posts, userID); err != nil {
+		return nil, err
+	}
+	for _, post := range posts {
+		s.renderPost(post)
+	}
+
+	return newPostPage(posts, limit, func(post *models.Post) time.Time {
+		return *post.PublishedAt
+	}), nil
 }
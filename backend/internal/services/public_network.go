@@ -0,0 +1,82 @@
+package services
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress se devuelve al intentar conectarse (o registrar una
+// URL) a una dirección que no es pública
+const ErrPrivateAddress = "la dirección apunta a una red privada o local"
+
+// nonPublicPrefixes son rangos reservados que netip no distingue por sí
+// solo y que tampoco son alcanzables en Internet
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "esta" red
+	netip.MustParsePrefix("100.64.0.0/10"), // CGNAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // asignaciones de protocolo
+	netip.MustParsePrefix("198.18.0.0/15"), // pruebas de rendimiento
+	netip.MustParsePrefix("240.0.0.0/4"),   // reservada (incluye el broadcast)
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64: puede traducir a una IPv4 privada
+}
+
+// isPublicAddr indica si addr es una dirección de Internet: descarta
+// loopback, redes privadas, link-local (donde está la metadata de los
+// proveedores cloud, 169.254.169.254), multicast y los rangos reservados
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkPublicHost rechaza los hosts que ya por su nombre apuntan a la
+// máquina o a la red local: IPs literales no públicas y "localhost". Los
+// demás nombres recién se pueden juzgar al resolverlos, y de eso se ocupa
+// el dialer de NewPublicHTTPClient.
+func checkPublicHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errors.New(ErrPrivateAddress)
+	}
+	if addr, err := netip.ParseAddr(strings.Trim(host, "[]")); err == nil && !isPublicAddr(addr) {
+		return errors.New(ErrPrivateAddress)
+	}
+	return nil
+}
+
+// NewPublicHTTPClient crea un cliente HTTP que solo se conecta a
+// direcciones públicas. Se usa para las URLs que elige un usuario o un
+// servidor remoto (webhooks, actores e inboxes de ActivityPub), para que
+// no se puedan usar para llegar a servicios internos. El control se hace
+// en el dialer sobre la IP ya resuelta, así que vale también para las
+// redirecciones y para un DNS que cambia entre la validación y la conexión.
+func NewPublicHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !isPublicAddr(addrPort.Addr()) {
+				return errors.New(ErrPrivateAddress)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // Con un proxy el dialer solo vería la IP del proxy
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
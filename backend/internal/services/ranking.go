@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"tp06-testing/internal/models"
+)
+
+// Órdenes disponibles para listar posts y comentarios
+const (
+	SortNew = "new" // Más recientes primero (posts) o en orden cronológico (comentarios)
+	SortTop = "top" // Por reacciones, con decaimiento en el tiempo
+)
+
+// ErrInvalidSort se devuelve cuando el orden pedido no existe
+const ErrInvalidSort = "orden inválido: usar new o top"
+
+// topGravity controla qué tan rápido pierden peso las reacciones viejas
+const topGravity = 1.5
+
+// validateSort normaliza el orden pedido ("" equivale a SortNew)
+func validateSort(order string) (string, error) {
+	switch order {
+	case "", SortNew:
+		return SortNew, nil
+	case SortTop:
+		return SortTop, nil
+	}
+	return "", errors.New(ErrInvalidSort)
+}
+
+// hotScore es el puntaje para SortTop: reacciones / (horas + 2)^gravity,
+// la fórmula de Hacker News. Un post nuevo con pocas reacciones puede
+// superar a uno viejo con muchas.
+func hotScore(reactions int, at time.Time, now time.Time) float64 {
+	hours := now.Sub(at).Hours()
+	if hours < 0 {
+		hours = 0
+	}
+	return float64(reactions) / math.Pow(hours+2, topGravity)
+}
+
+// sortPostsByTop ordena por hotScore usando la fecha de publicación.
+// A igual puntaje se mantiene el orden recibido (más recientes primero).
+func sortPostsByTop(posts []*models.Post, now time.Time) {
+	scores := make(map[int]float64, len(posts))
+	for _, post := range posts {
+		at := post.CreatedAt
+		if post.PublishedAt != nil {
+			at = *post.PublishedAt
+		}
+		scores[post.ID] = hotScore(post.ReactionCount, at, now)
+	}
+
+	sort.SliceStable(posts, func(i, j int) bool {
+		return scores[posts[i].ID] > scores[posts[j].ID]
+	})
+}
+
+// sortCommentsByTop ordena los comentarios por hotScore
+func sortCommentsByTop(comments []*models.Comment, now time.Time) {
+	scores := make(map[int]float64, len(comments))
+	for _, comment := range comments {
+		scores[comment.ID] = hotScore(comment.ReactionCount, comment.CreatedAt, now)
+	}
+
+	sort.SliceStable(comments, func(i, j int) bool {
+		return scores[comments[i].ID] > scores[comments[j].ID]
+	})
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"errors"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/repository"
+)
+
+// Constantes para mensajes de error de reacciones
+const (
+	ErrInvalidReaction = "tipo de reacción inválido"
+	ErrCommentNotFound = "comentario no encontrado"
+)
+
+// ReactionService maneja las reacciones a posts y comentarios
+type ReactionService struct {
+	reactionRepo repository.ReactionRepository
+	postRepo     repository.PostRepository
+	userRepo     repository.UserRepository
+
+	// Opcional: sin él, a los posts de solo seguidores solo reacciona el autor
+	followRepo repository.FollowRepository
+}
+
+// NewReactionService crea una nueva instancia
+func NewReactionService(reactionRepo repository.ReactionRepository, postRepo repository.PostRepository, userRepo repository.UserRepository) *ReactionService {
+	return &ReactionService{
+		reactionRepo: reactionRepo,
+		postRepo:     postRepo,
+		userRepo:     userRepo,
+	}
+}
+
+// SetFollowRepository permite a los seguidores reaccionar a los posts de
+// solo seguidores
+func (s *ReactionService) SetFollowRepository(followRepo repository.FollowRepository) {
+	s.followRepo = followRepo
+}
+
+// ReactToPost agrega una reacción del usuario al post (si ya existía no
+// cambia nada) y devuelve el resumen actualizado
+func (s *ReactionService) ReactToPost(postID int, userID int, reactionType string) (*models.ReactionSummary, error) {
+	if err := s.validate(userID, reactionType); err != nil {
+		return nil, err
+	}
+	if err := s.checkPost(postID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.reactionRepo.AddPostReaction(postID, userID, reactionType); err != nil {
+		return nil, err
+	}
+
+	return s.postSummary(postID, userID)
+}
+
+// RemovePostReaction quita una reacción del usuario al post
+func (s *ReactionService) RemovePostReaction(postID int, userID int, reactionType string) (*models.ReactionSummary, error) {
+	if err := s.validate(userID, reactionType); err != nil {
+		return nil, err
+	}
+	if err := s.checkPost(postID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.reactionRepo.RemovePostReaction(postID, userID, reactionType); err != nil {
+		return nil, err
+	}
+
+	return s.postSummary(postID, userID)
+}
+
+// ReactToComment agrega una reacción del usuario a un comentario del post
+func (s *ReactionService) ReactToComment(postID int, commentID int, userID int, reactionType string) (*models.ReactionSummary, error) {
+	if err := s.validate(userID, reactionType); err != nil {
+		return nil, err
+	}
+	if err := s.checkComment(postID, commentID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.reactionRepo.AddCommentReaction(commentID, userID, reactionType); err != nil {
+		return nil, err
+	}
+
+	return s.commentSummary(commentID, userID)
+}
+
+// RemoveCommentReaction quita una reacción del usuario a un comentario
+func (s *ReactionService) RemoveCommentReaction(postID int, commentID int, userID int, reactionType string) (*models.ReactionSummary, error) {
+	if err := s.validate(userID, reactionType); err != nil {
+		return nil, err
+	}
+	if err := s.checkComment(postID, commentID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.reactionRepo.RemoveCommentReaction(commentID, userID, reactionType); err != nil {
+		return nil, err
+	}
+
+	return s.commentSummary(commentID, userID)
+}
+
+// validate verifica el tipo de reacción y que el usuario exista
+func (s *ReactionService) validate(userID int, reactionType string) error {
+	if !models.IsValidReaction(reactionType) {
+		return errors.New(ErrInvalidReaction)
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New(ErrUserNotFound)
+	}
+
+	return nil
+}
+
+// checkPost verifica que el post exista y el usuario pueda verlo
+func (s *ReactionService) checkPost(postID int, userID int) error {
+	post, err := s.postRepo.FindByID(postID)
+	if err != nil {
+		return err
+	}
+	if err := checkVisible(post, userID, s.followRepo); err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkComment verifica que el comentario exista y pertenezca a un post visible
+func (s *ReactionService) checkComment(postID int, commentID int, userID int) error {
+	if err := s.checkPost(postID, userID); err != nil {
+		return err
+	}
+
+	comment, err := s.postRepo.FindCommentByID(commentID)
+	if err != nil {
+		return err
+	}
+	if comment == nil || comment.PostID != postID {
+		return errors.New(ErrCommentNotFound)
+	}
+	return nil
+}
+
+func (s *ReactionService) postSummary(postID int, viewerID int) (*models.ReactionSummary, error) {
+	summaries, err := s.reactionRepo.PostReactions([]int{postID}, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	return summaryOrEmpty(summaries, postID), nil
+}
+
+func (s *ReactionService) commentSummary(commentID int, viewerID int) (*models.ReactionSummary, error) {
+	summaries, err := s.reactionRepo.CommentReactions([]int{commentID}, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	return summaryOrEmpty(summaries, commentID), nil
+}
+
+// summaryOrEmpty devuelve el resumen del objetivo o uno vacío si no tiene reacciones
+func summaryOrEmpty(summaries map[int]*models.ReactionSummary, id int) *models.ReactionSummary {
+	if summary, ok := summaries[id]; ok {
+		return summary
+	}
+	return models.NewReactionSummary()
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"errors"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/repository"
+)
+
+// SitemapMaxURLs es el máximo de URLs por sitemap que admite el protocolo.
+// Con más posts se genera un índice que apunta a varios sitemaps.
+const SitemapMaxURLs = 50000
+
+// ErrSitemapNotFound se devuelve al pedir una página de sitemap que no existe
+const ErrSitemapNotFound = "sitemap no encontrado"
+
+// SitemapService recorre los posts publicados para armar los sitemaps
+type SitemapService struct {
+	postRepo repository.PostRepository
+	pageSize int
+}
+
+// NewSitemapService crea una nueva instancia
+func NewSitemapService(postRepo repository.PostRepository) *SitemapService {
+	return &SitemapService{
+		postRepo: postRepo,
+		pageSize: SitemapMaxURLs,
+	}
+}
+
+// SetPageSize cambia la cantidad de URLs por sitemap (por defecto
+// SitemapMaxURLs). Los valores fuera de rango se ignoran.
+func (s *SitemapService) SetPageSize(size int) {
+	if size > 0 && size <= SitemapMaxURLs {
+		s.pageSize = size
+	}
+}
+
+// PageCount devuelve cuántos sitemaps hacen falta. 1 significa que entra
+// todo en uno solo y no hace falta índice.
+func (s *SitemapService) PageCount() (int, error) {
+	count, err := s.postRepo.CountPublished()
+	if err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 1, nil
+	}
+	return (count + s.pageSize - 1) / s.pageSize, nil
+}
+
+// EachEntry llama a fn con cada post publicado del sitemap número page
+// (desde 1), a medida que se leen de la base
+func (s *SitemapService) EachEntry(page int, fn func(entry *models.SitemapEntry) error) error {
+	pages, err := s.PageCount()
+	if err != nil {
+		return err
+	}
+	if page < 1 || page > pages {
+		return errors.New(ErrSitemapNotFound)
+	}
+
+	return s.postRepo.EachPublished((page-1)*s.pageSize, s.pageSize, fn)
+}
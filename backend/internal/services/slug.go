@@ -0,0 +1,114 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/repository"
+)
+
+// maxSlugLength limita el largo del slug generado a partir del título
+const maxSlugLength = 80
+
+// maxSlugAttempts limita cuántas veces se intenta guardar un post cuando
+// otro toma su slug entre uniqueSlug y la escritura
+const maxSlugAttempts = 5
+
+// transliteraciones reemplaza acentos y letras propias del español
+// por su equivalente ASCII antes de generar el slug
+var transliteraciones = map[rune]string{
+	'á': "a", 'é': "e", 'í': "i", 'ó': "o", 'ú': "u", 'ü': "u", 'ñ': "n",
+	'à': "a", 'è': "e", 'ì': "i", 'ò': "o", 'ù': "u",
+	'â': "a", 'ê': "e", 'î': "i", 'ô': "o", 'û': "u",
+	'ä': "a", 'ë': "e", 'ï': "i", 'ö': "o", 'ç': "c",
+}
+
+// Slugify convierte un título en un slug en minúsculas, ASCII y separado por guiones.
+// Ejemplo: "¿Qué es el Diseño?" -> "que-es-el-diseno"
+func Slugify(title string) string {
+	var b strings.Builder
+	lastDash := true // evita guiones al inicio
+
+	for _, r := range strings.ToLower(title) {
+		if t, ok := transliteraciones[r]; ok {
+			b.WriteString(t)
+			lastDash = false
+			continue
+		}
+
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+			lastDash = false
+			continue
+		}
+
+		if !lastDash {
+			b.WriteByte('-')
+			lastDash = true
+		}
+	}
+
+	slug := strings.Trim(b.String(), "-")
+	if len(slug) > maxSlugLength {
+		slug = strings.Trim(slug[:maxSlugLength], "-")
+	}
+	if slug == "" {
+		return "post"
+	}
+
+	return slug
+}
+
+// uniqueSlug busca un slug libre a partir del título agregando un sufijo
+// numérico en caso de colisión ("titulo", "titulo-2", "titulo-3", ...).
+// postID permite que un post recupere un slug que ya le pertenecía.
+func (s *PostService) uniqueSlug(title string, postID int) (string, error) {
+	base := Slugify(title)
+	candidate := base
+
+	for i := 2; ; i++ {
+		exists, err := s.postRepo.SlugExists(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+
+		if postID > 0 {
+			owner, err := s.postRepo.FindBySlug(candidate)
+			if err != nil {
+				return "", err
+			}
+			if owner != nil && owner.ID == postID {
+				return candidate, nil
+			}
+		}
+
+		candidate = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// saveWithUniqueSlug ejecuta save y, si el índice único rechaza el slug
+// porque otro post lo tomó mientras tanto, busca el siguiente libre y
+// vuelve a intentar
+func (s *PostService) saveWithUniqueSlug(post *models.Post, save func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := save()
+		if err == nil || err.Error() != repository.ErrSlugTaken {
+			return err
+		}
+		if attempt == maxSlugAttempts {
+			return errors.New(ErrSlugConflict)
+		}
+
+		slug, err := s.uniqueSlug(post.Title, post.ID)
+		if err != nil {
+			return err
+		}
+		post.Slug = slug
+	}
+}
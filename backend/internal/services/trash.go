@@ -0,0 +1,138 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"tp06-testing/internal/events"
+	"tp06-testing/internal/models"
+)
+
+// DefaultTrashRetention es cuánto tiempo se conservan en la papelera los
+// posts y comentarios borrados si no se configura otro plazo
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// Errores de la papelera
+const (
+	ErrPostNotInTrash    = "el post no está en tu papelera o ya venció el plazo para restaurarlo"
+	ErrCommentNotInTrash = "el comentario no está en tu papelera o ya venció el plazo para restaurarlo"
+)
+
+// SetTrashRetention cambia el plazo para restaurar lo borrado. Debería
+// coincidir con el del TrashPurger que lo elimina definitivamente.
+func (s *PostService) SetTrashRetention(retention time.Duration) {
+	s.trashRetention = retention
+}
+
+// trashCutoff es la fecha desde la que lo borrado todavía se puede restaurar
+func (s *PostService) trashCutoff() time.Time {
+	return s.clock.Now().Add(-s.trashRetention)
+}
+
+// GetTrash obtiene la papelera del usuario: los posts y comentarios que
+// borró y todavía puede restaurar, con la fecha en que se eliminarán
+func (s *PostService) GetTrash(userID int) (*models.Trash, error) {
+	since := s.trashCutoff()
+
+	posts, err := s.postRepo.FindDeletedPosts(userID, since)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := s.postRepo.FindDeletedComments(userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	trash := &models.Trash{
+		Posts:         []*models.Post{},
+		Comments:      []*models.Comment{},
+		RetentionDays: int(s.trashRetention / (24 * time.Hour)),
+	}
+	for _, post := range posts {
+		purgeAt := post.DeletedAt.Add(s.trashRetention)
+		post.PurgeAt = &purgeAt
+		trash.Posts = append(trash.Posts, s.renderPost(post))
+	}
+	for _, comment := range comments {
+		purgeAt := comment.DeletedAt.Add(s.trashRetention)
+		comment.PurgeAt = &purgeAt
+		trash.Comments = append(trash.Comments, s.renderComment(comment))
+	}
+
+	return trash, nil
+}
+
+// RestorePost saca un post de la papelera del usuario. Si es público se
+// vuelve a anunciar como creado, ya que al borrarlo se avisó que no
+// existía más.
+func (s *PostService) RestorePost(postID int, userID int) (*models.Post, error) {
+	if err := s.checkNotSuspended(userID); err != nil {
+		return nil, err
+	}
+
+	restored, err := s.postRepo.Restore(postID, userID, s.trashCutoff())
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, errors.New(ErrPostNotInTrash)
+	}
+
+	post, err := s.postRepo.FindByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	if post == nil {
+		return nil, errors.New(ErrPostNotFound)
+	}
+
+	if err := s.loadPostData([]*models.Post{post}, userID); err != nil {
+		return nil, err
+	}
+	s.renderPost(post)
+
+	if post.IsPublic() {
+		s.publish(events.PostCreated, post.ID, post)
+	}
+
+	return post, nil
+}
+
+// RestoreComment saca un comentario de la papelera del usuario. No se
+// puede restaurar si su post también está borrado.
+func (s *PostService) RestoreComment(postID int, commentID int, userID int) (*models.Comment, error) {
+	if err := s.checkNotSuspended(userID); err != nil {
+		return nil, err
+	}
+
+	restored, err := s.postRepo.RestoreComment(postID, commentID, userID, s.trashCutoff())
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, errors.New(ErrCommentNotInTrash)
+	}
+
+	comment, err := s.postRepo.FindCommentByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment == nil {
+		return nil, errors.New(ErrCommentNotInTrash)
+	}
+
+	if err := s.loadCommentMentions([]*models.Comment{comment}); err != nil {
+		return nil, err
+	}
+	s.renderComment(comment)
+
+	post, err := s.postRepo.FindByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	if post != nil && comment.HiddenAt == nil {
+		s.publishComment(post, events.CommentCreated, comment)
+	}
+
+	return comment, nil
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"tp06-testing/internal/repository"
+)
+
+// TrashPurger elimina definitivamente los posts y comentarios que llevan
+// en la papelera más que el plazo de restauración
+type TrashPurger struct {
+	postRepo  repository.PostRepository
+	clock     Clock
+	retention time.Duration
+	interval  time.Duration
+}
+
+// NewTrashPurger crea una nueva instancia que revisa cada interval
+func NewTrashPurger(postRepo repository.PostRepository, clock Clock, retention time.Duration, interval time.Duration) *TrashPurger {
+	return &TrashPurger{
+		postRepo:  postRepo,
+		clock:     clock,
+		retention: retention,
+		interval:  interval,
+	}
+}
+
+// RunOnce elimina lo que venció según el reloj y devuelve cuántos posts y
+// cuántos comentarios se eliminaron
+func (p *TrashPurger) RunOnce() (int, int, error) {
+	return p.postRepo.PurgeDeleted(p.clock.Now().Add(-p.retention))
+}
+
+// Start ejecuta RunOnce periódicamente hasta que se cancele el contexto.
+// Está pensado para correr en su propia goroutine.
+func (p *TrashPurger) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if posts, comments, err := p.RunOnce(); err != nil {
+			log.Println("Error al vaciar la papelera:", err)
+		} else if posts > 0 || comments > 0 {
+			log.Printf("Papelera vaciada: %d posts y %d comentarios eliminados", posts, comments)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/repository"
+)
+
+// ErrInvalidVisibility se devuelve si la visibilidad pedida no existe
+const ErrInvalidVisibility = "visibilidad inválida: debe ser public, unlisted, followers o private"
+
+// resolveVisibility valida la visibilidad pedida para un post. Vacía
+// equivale a fallback (la visibilidad por defecto o la que ya tenía).
+func resolveVisibility(visibility string, fallback string) (string, error) {
+	switch visibility = strings.TrimSpace(visibility); visibility {
+	case "":
+		return fallback, nil
+	case models.PostVisibilityPublic, models.PostVisibilityUnlisted, models.PostVisibilityFollowers, models.PostVisibilityPrivate:
+		return visibility, nil
+	default:
+		return "", errors.New(ErrInvalidVisibility)
+	}
+}
+
+// canView indica si el usuario puede ver el post. El autor lo ve siempre;
+// el resto solo si está publicado, moderación no lo ocultó y la
+// visibilidad lo permite: los públicos y los no listados los ve
+// cualquiera, los de solo seguidores quien sigue al autor (sin followRepo
+// nadie más) y los privados nadie más.
+func canView(post *models.Post, viewerID int, followRepo repository.FollowRepository) (bool, error) {
+	if post.UserID == viewerID {
+		return true, nil
+	}
+	if !post.IsPublished() || post.IsHidden() {
+		return false, nil
+	}
+
+	switch post.Visibility {
+	case "", models.PostVisibilityPublic, models.PostVisibilityUnlisted:
+		return true, nil
+	case models.PostVisibilityFollowers:
+		if viewerID == 0 || followRepo == nil {
+			return false, nil
+		}
+		return followRepo.IsFollowing(viewerID, post.UserID)
+	default:
+		return false, nil
+	}
+}
+
+// checkVisible devuelve ErrPostNotFound si el post no existe o el usuario
+// no puede verlo, para no revelar que existe
+func checkVisible(post *models.Post, viewerID int, followRepo repository.FollowRepository) error {
+	if post == nil {
+		return errors.New(ErrPostNotFound)
+	}
+
+	visible, err := canView(post, viewerID, followRepo)
+	if err != nil {
+		return err
+	}
+	if !visible {
+		return errors.New(ErrPostNotFound)
+	}
+	return nil
+}
+
+// filterViewers deja solo los usuarios que pueden ver el post, para no
+// avisarle a nadie de algo que después no puede abrir. Si falla la
+// consulta se descarta al usuario y se registra el error.
+func (s *PostService) filterViewers(post *models.Post, userIDs []int) []int {
+	var viewers []int
+	for _, userID := range userIDs {
+		visible, err := canView(post, userID, s.followRepo)
+		if err != nil {
+			log.Printf("Error al revisar si el usuario %d puede ver el post %d: %v", userID, post.ID, err)
+			continue
+		}
+		if visible {
+			viewers = append(viewers, userID)
+		}
+	}
+	return viewers
+}
@@ -0,0 +1,340 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"tp06-testing/internal/events"
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/repository"
+)
+
+// Headers de cada entrega de webhook
+const (
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookDelivery  = "X-Webhook-Delivery"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+const (
+	webhookBatchSize    = 20               // Entregas que se envían por vuelta
+	webhookWorkers      = 4                // Webhooks que se atienden a la vez
+	webhookMaxAttempts  = 8                // Intentos antes de pasar a "dead"
+	webhookBaseBackoff  = 30 * time.Second // Espera después del primer fallo
+	webhookMaxBackoff   = 6 * time.Hour    // Espera máxima entre intentos
+	webhookMaxErrorSize = 200              // Largo máximo del error guardado
+)
+
+// WebhookDispatcher convierte los eventos del broker en entregas
+// pendientes y las envía en segundo plano. La cola vive en la base, así
+// que un reinicio del servidor no pierde entregas.
+type WebhookDispatcher struct {
+	webhookRepo repository.WebhookRepository
+	clock       Clock
+	client      *http.Client
+	interval    time.Duration
+	wake        chan struct{}
+}
+
+// NewWebhookDispatcher crea una nueva instancia que revisa la cola cada
+// interval (además de despertarse con Notify al encolar)
+func NewWebhookDispatcher(webhookRepo repository.WebhookRepository, clock Clock, client *http.Client, interval time.Duration) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		webhookRepo: webhookRepo,
+		clock:       clock,
+		client:      client,
+		interval:    interval,
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Notify avisa que hay entregas nuevas. No bloquea.
+func (d *WebhookDispatcher) Notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// webhookPayload es el cuerpo que recibe el webhook
+type webhookPayload struct {
+	ID        uint64          `json:"id"` // ID del evento, igual en todos los webhooks
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Enqueue crea una entrega pendiente del evento para cada webhook
+// suscripto a su tipo. Devuelve cuántas encoló.
+func (d *WebhookDispatcher) Enqueue(event *events.Event) (int, error) {
+	webhooks, err := d.webhookRepo.FindSubscribed(event.Type)
+	if err != nil || len(webhooks) == 0 {
+		return 0, err
+	}
+
+	now := d.clock.Now()
+	payload, err := json.Marshal(webhookPayload{
+		ID:        event.ID,
+		Event:     event.Type,
+		CreatedAt: now,
+		Data:      event.Data,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, webhook := range webhooks {
+		delivery := &models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventType:     event.Type,
+			Payload:       string(payload),
+			Status:        models.DeliveryPending,
+			NextAttemptAt: &now,
+		}
+		if err := d.webhookRepo.CreateDelivery(delivery); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(webhooks), nil
+}
+
+// Listen encola los eventos del broker hasta que se cancele el contexto.
+// Si el broker lo da de baja por lento, se vuelve a suscribir retomando
+// desde el último evento. Está pensado para correr en su propia goroutine.
+func (d *WebhookDispatcher) Listen(ctx context.Context, broker *events.Broker) {
+	var lastID uint64
+	for {
+		sub, backlog, resumed := broker.Subscribe(nil, lastID)
+		if !resumed {
+			log.Println("Se perdieron eventos para los webhooks: el broker ya no los tenía")
+		}
+		for _, event := range backlog {
+			lastID = event.ID
+			d.enqueueAndNotify(event)
+		}
+
+		for open := true; open; {
+			select {
+			case <-ctx.Done():
+				sub.Close()
+				return
+			case event, ok := <-sub.C:
+				if !ok {
+					open = false
+					continue
+				}
+				lastID = event.ID
+				d.enqueueAndNotify(event)
+			}
+		}
+	}
+}
+
+func (d *WebhookDispatcher) enqueueAndNotify(event *events.Event) {
+	count, err := d.Enqueue(event)
+	if err != nil {
+		log.Printf("Error al encolar el evento %s para webhooks: %v", event.Type, err)
+		return
+	}
+	if count > 0 {
+		d.Notify()
+	}
+}
+
+// RunOnce envía un lote de entregas vencidas y devuelve cuántas intentó.
+// Las de cada webhook van en orden por un mismo worker y hasta
+// webhookWorkers webhooks se atienden a la vez, así un receptor lento
+// solo demora sus propias entregas. Si una falla, las siguientes de ese
+// webhook quedan para la próxima vuelta en lugar de esperar cada una su
+// timeout. Los resultados se guardan desde esta goroutine, de a uno.
+func (d *WebhookDispatcher) RunOnce(ctx context.Context) (int, error) {
+	due, err := d.webhookRepo.FindDueDeliveries(d.clock.Now(), webhookBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var order []int
+	byWebhook := make(map[int][]*models.WebhookDelivery)
+	for _, delivery := range due {
+		if _, ok := byWebhook[delivery.WebhookID]; !ok {
+			order = append(order, delivery.WebhookID)
+		}
+		byWebhook[delivery.WebhookID] = append(byWebhook[delivery.WebhookID], delivery)
+	}
+
+	jobs := make(chan []*models.WebhookDelivery)
+	results := make(chan *models.WebhookDelivery)
+	var wg sync.WaitGroup
+	for range min(webhookWorkers, len(order)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range jobs {
+				for _, delivery := range group {
+					d.deliver(ctx, delivery)
+					results <- delivery
+					if delivery.Status != models.DeliveryDelivered {
+						break
+					}
+				}
+			}
+		}()
+	}
+	go func() {
+		for _, webhookID := range order {
+			jobs <- byWebhook[webhookID]
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	// Se vacía results aunque falle la base para no dejar workers colgados
+	attempted := 0
+	var updateErr error
+	for delivery := range results {
+		attempted++
+		if err := d.webhookRepo.UpdateDelivery(delivery); err != nil && updateErr == nil {
+			updateErr = err
+		}
+	}
+	if updateErr != nil {
+		return 0, updateErr
+	}
+
+	return attempted, nil
+}
+
+// Start ejecuta RunOnce periódicamente (o al recibir Notify) hasta que se
+// cancele el contexto. Está pensado para correr en su propia goroutine.
+func (d *WebhookDispatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		// Se sigue mientras haya lotes completos
+		for {
+			count, err := d.RunOnce(ctx)
+			if err != nil {
+				log.Println("Error al enviar webhooks:", err)
+			}
+			if err != nil || count < webhookBatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// deliver hace un intento de entrega y deja el resultado en delivery
+func (d *WebhookDispatcher) deliver(ctx context.Context, delivery *models.WebhookDelivery) {
+	delivery.Attempts++
+
+	status, err := d.send(ctx, delivery)
+	if status != 0 {
+		delivery.ResponseStatus = &status
+	} else {
+		delivery.ResponseStatus = nil
+	}
+
+	now := d.clock.Now()
+	if err == nil {
+		delivery.Status = models.DeliveryDelivered
+		delivery.LastError = ""
+		delivery.NextAttemptAt = nil
+		delivery.DeliveredAt = &now
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if len(delivery.LastError) > webhookMaxErrorSize {
+		delivery.LastError = delivery.LastError[:webhookMaxErrorSize]
+	}
+
+	if delivery.Attempts >= webhookMaxAttempts {
+		delivery.Status = models.DeliveryDead
+		delivery.NextAttemptAt = nil
+		return
+	}
+	next := now.Add(webhookBackoff(delivery.Attempts))
+	delivery.NextAttemptAt = &next
+}
+
+// send envía la entrega firmada. Devuelve el código de respuesta (0 si no
+// hubo) y un error si no fue 2xx.
+func (d *WebhookDispatcher) send(ctx context.Context, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := d.clock.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "tp06-blog-webhooks")
+	req.Header.Set(HeaderWebhookEvent, delivery.EventType)
+	req.Header.Set(HeaderWebhookDelivery, strconv.Itoa(delivery.ID))
+	req.Header.Set(HeaderWebhookTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderWebhookSignature, SignWebhookPayload(delivery.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		// El registro de entregas lo ve el dueño del webhook: el error de red
+		// tal cual ("connection refused", la IP resuelta, ...) le serviría
+		// para sondear la red, así que solo queda en el log del servidor
+		log.Printf("Error al entregar el webhook %d (entrega %d): %v", delivery.WebhookID, delivery.ID, err)
+		if errors.Is(err, context.DeadlineExceeded) || os.IsTimeout(err) {
+			return 0, errors.New("el receptor no respondió a tiempo")
+		}
+		return 0, errors.New("no se pudo conectar con el receptor")
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("el receptor respondió %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// webhookBackoff es la espera antes del próximo intento: se duplica con
+// cada fallo (30s, 1m, 2m, ...) hasta webhookMaxBackoff
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempts && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, webhookMaxBackoff)
+}
+
+// SignWebhookPayload calcula la firma de una entrega:
+// "sha256=" + HMAC-SHA256 en hexadecimal de "<timestamp>.<body>". El
+// receptor la recalcula con su secreto y descarta los timestamps viejos
+// para evitar reenvíos maliciosos.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+
+	"tp06-testing/internal/events"
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/repository"
+)
+
+// Errores de webhooks
+const (
+	ErrWebhookNotFound      = "webhook no encontrado"
+	ErrDeliveryNotFound     = "entrega no encontrada"
+	ErrInvalidWebhookURL    = "la URL del webhook debe ser http:// o https://"
+	ErrInvalidWebhookEvents = "eventos inválidos, los disponibles son: " + events.PostCreated + ", " +
+		events.PostDeleted + ", " + events.CommentCreated + ", " + events.CommentDeleted
+)
+
+// webhookEvents son los tipos de evento a los que se puede suscribir un webhook
+var webhookEvents = []string{events.PostCreated, events.PostDeleted, events.CommentCreated, events.CommentDeleted}
+
+// WebhookService maneja las suscripciones de webhooks y su registro de entregas
+type WebhookService struct {
+	webhookRepo repository.WebhookRepository
+	clock       Clock
+	dispatcher  *WebhookDispatcher
+}
+
+// NewWebhookService crea una nueva instancia
+func NewWebhookService(webhookRepo repository.WebhookRepository, clock Clock) *WebhookService {
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		clock:       clock,
+	}
+}
+
+// SetDispatcher configura el dispatcher al que se avisa cuando hay un
+// reenvío, para no esperar a su próxima vuelta. Es opcional.
+func (s *WebhookService) SetDispatcher(dispatcher *WebhookDispatcher) {
+	s.dispatcher = dispatcher
+}
+
+// CreateWebhook suscribe una URL a los eventos indicados. Es la única
+// respuesta que incluye el secreto para verificar las firmas. No se
+// aceptan destinos locales o de redes privadas; los nombres que resuelven
+// a una los frena el dialer del dispatcher al entregar.
+func (s *WebhookService) CreateWebhook(req *models.CreateWebhookRequest, userID int) (*models.Webhook, error) {
+	target, err := url.Parse(strings.TrimSpace(req.URL))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, errors.New(ErrInvalidWebhookURL)
+	}
+	if err := checkPublicHost(target.Hostname()); err != nil {
+		return nil, err
+	}
+
+	var subscribed []string
+	for _, event := range req.Events {
+		if !containsEvent(webhookEvents, event) {
+			return nil, errors.New(ErrInvalidWebhookEvents)
+		}
+		if !containsEvent(subscribed, event) {
+			subscribed = append(subscribed, event)
+		}
+	}
+	if len(subscribed) == 0 {
+		return nil, errors.New(ErrInvalidWebhookEvents)
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = randomSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	webhook := &models.Webhook{
+		UserID: userID,
+		URL:    target.String(),
+		Events: subscribed,
+		Secret: secret,
+	}
+	if err := s.webhookRepo.Create(webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func containsEvent(list []string, event string) bool {
+	for _, item := range list {
+		if item == event {
+			return true
+		}
+	}
+	return false
+}
+
+// randomSecret genera un secreto de 32 bytes en hexadecimal
+func randomSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// GetWebhooks obtiene los webhooks del usuario (sin el secreto)
+func (s *WebhookService) GetWebhooks(userID int) ([]*models.Webhook, error) {
+	webhooks, err := s.webhookRepo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, webhook := range webhooks {
+		webhook.Secret = ""
+	}
+	if webhooks == nil {
+		webhooks = []*models.Webhook{}
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook elimina un webhook del usuario y su registro de entregas
+func (s *WebhookService) DeleteWebhook(webhookID int, userID int) error {
+	if _, err := s.findOwnWebhook(webhookID, userID); err != nil {
+		return err
+	}
+	return s.webhookRepo.Delete(webhookID)
+}
+
+// findOwnWebhook busca un webhook del usuario. Los ajenos se informan
+// como inexistentes.
+func (s *WebhookService) findOwnWebhook(webhookID int, userID int) (*models.Webhook, error) {
+	webhook, err := s.webhookRepo.FindByID(webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if webhook == nil || webhook.UserID != userID {
+		return nil, errors.New(ErrWebhookNotFound)
+	}
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+// GetDeliveries obtiene una página del registro de entregas del webhook
+func (s *WebhookService) GetDeliveries(webhookID int, userID int, cursor string, limit int) (*models.WebhookDeliveryPage, error) {
+	if _, err := s.findOwnWebhook(webhookID, userID); err != nil {
+		return nil, err
+	}
+
+	limit, err := pageLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := models.ParseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.webhookRepo.FindDeliveries(webhookID, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.WebhookDeliveryPage{}
+	page.Deliveries, page.NextCursor = paginate(deliveries, limit, func(delivery *models.WebhookDelivery) models.Cursor {
+		return models.Cursor{Time: delivery.CreatedAt, ID: delivery.ID}
+	})
+	if page.Deliveries == nil {
+		page.Deliveries = []*models.WebhookDelivery{}
+	}
+	return page, nil
+}
+
+// Redeliver vuelve a encolar una entrega (por ejemplo una que agotó los
+// reintentos). Se crea una entrega nueva con el mismo payload, así el
+// registro conserva el resultado de la original.
+func (s *WebhookService) Redeliver(webhookID int, deliveryID int, userID int) (*models.WebhookDelivery, error) {
+	if _, err := s.findOwnWebhook(webhookID, userID); err != nil {
+		return nil, err
+	}
+
+	original, err := s.webhookRepo.FindDelivery(webhookID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if original == nil {
+		return nil, errors.New(ErrDeliveryNotFound)
+	}
+
+	now := s.clock.Now()
+	delivery := &models.WebhookDelivery{
+		WebhookID:     webhookID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        models.DeliveryPending,
+		NextAttemptAt: &now,
+		RedeliveryOf:  &original.ID,
+	}
+	if err := s.webhookRepo.CreateDelivery(delivery); err != nil {
+		return nil, err
+	}
+
+	if s.dispatcher != nil {
+		s.dispatcher.Notify()
+	}
+	return delivery, nil
+}
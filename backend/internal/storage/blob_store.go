@@ -0,0 +1,54 @@
+// Package storage guarda los archivos subidos (adjuntos de los posts)
+// detrás de la interfaz BlobStore, con una implementación en disco local
+// y otra para servicios compatibles con S3 (AWS, MinIO, ...).
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound indica que no existe un objeto con esa clave
+	ErrNotFound = errors.New("archivo no encontrado")
+
+	// errInvalidKey se devuelve cuando validKey rechaza la clave
+	errInvalidKey = errors.New("clave de archivo inválida")
+)
+
+// BlobStore define las operaciones sobre el almacenamiento de archivos
+// INTERFACE: permite cambiar disco local por S3 o usar mocks en los tests
+type BlobStore interface {
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewKey genera una clave única para un archivo nuevo, agrupada por mes.
+// Ejemplo: "attachments/2025/10/3f2a9c...e1.png"
+func NewKey(prefix string, ext string, now time.Time) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return path.Join(prefix, now.UTC().Format("2006/01"), hex.EncodeToString(random)+ext), nil
+}
+
+// validKey rechaza claves vacías o que intenten salir del directorio base
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
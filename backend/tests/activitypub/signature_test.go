@@ -0,0 +1,107 @@
+package activitypub
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tp06-testing/internal/activitypub"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var signedAt = time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+
+// signedInboxRequest firma un POST como lo haría otro servidor y lo
+// devuelve tal como lo ve el handler que lo recibe
+func signedInboxRequest(t *testing.T, body []byte) (*http.Request, string) {
+	privatePEM, publicPEM, err := activitypub.GenerateKey()
+	require.NoError(t, err)
+	key, err := activitypub.ParsePrivateKey(privatePEM)
+	require.NoError(t, err)
+
+	out, err := http.NewRequest(http.MethodPost, "https://blog.example.com/ap/users/1/inbox", bytes.NewReader(body))
+	require.NoError(t, err)
+	require.NoError(t, activitypub.SignRequest(out, "https://remote.example/users/alice#main-key", key, body, signedAt))
+
+	in := httptest.NewRequest(http.MethodPost, "https://blog.example.com/ap/users/1/inbox", bytes.NewReader(body))
+	in.Header = out.Header.Clone()
+	return in, publicPEM
+}
+
+// TestSignature_RoundTrip: una firma hecha con SignRequest se verifica con la clave pública
+func TestSignature_RoundTrip(t *testing.T) {
+	// ARRANGE
+	body := []byte(`{"type":"Follow"}`)
+	req, publicPEM := signedInboxRequest(t, body)
+	publicKey, err := activitypub.ParsePublicKey(publicPEM)
+	require.NoError(t, err)
+
+	// ACT
+	sig, parseErr := activitypub.ParseSignature(req)
+	require.NoError(t, parseErr)
+	verifyErr := sig.Verify(req, publicKey, body, signedAt.Add(time.Minute))
+
+	// ASSERT
+	assert.NoError(t, verifyErr)
+	assert.Equal(t, "https://remote.example/users/alice#main-key", sig.KeyID)
+	assert.Equal(t, []string{"(request-target)", "host", "date", "digest"}, sig.Headers)
+}
+
+// TestSignature_RejectsTamperedBody: si el cuerpo cambió el Digest no coincide
+func TestSignature_RejectsTamperedBody(t *testing.T) {
+	// ARRANGE
+	req, publicPEM := signedInboxRequest(t, []byte(`{"type":"Follow"}`))
+	publicKey, _ := activitypub.ParsePublicKey(publicPEM)
+	sig, _ := activitypub.ParseSignature(req)
+
+	// ACT
+	err := sig.Verify(req, publicKey, []byte(`{"type":"Delete"}`), signedAt)
+
+	// ASSERT
+	assert.ErrorIs(t, err, activitypub.ErrDigestMismatch)
+}
+
+// TestSignature_RejectsOtherKeyAndOldDate: otra clave o una fecha vieja invalidan la firma
+func TestSignature_RejectsOtherKeyAndOldDate(t *testing.T) {
+	// ARRANGE
+	body := []byte(`{"type":"Follow"}`)
+	req, publicPEM := signedInboxRequest(t, body)
+	publicKey, _ := activitypub.ParsePublicKey(publicPEM)
+	_, otherPEM, err := activitypub.GenerateKey()
+	require.NoError(t, err)
+	otherKey, _ := activitypub.ParsePublicKey(otherPEM)
+	sig, _ := activitypub.ParseSignature(req)
+
+	// ACT
+	otherErr := sig.Verify(req, otherKey, body, signedAt)
+	oldErr := sig.Verify(req, publicKey, body, signedAt.Add(activitypub.SignatureMaxAge+time.Minute))
+
+	// ASSERT
+	assert.ErrorIs(t, otherErr, activitypub.ErrInvalidSignature)
+	assert.ErrorIs(t, oldErr, activitypub.ErrExpiredSignature)
+}
+
+// TestParseAcct: separa usuario y dominio de un recurso de WebFinger
+func TestParseAcct(t *testing.T) {
+	// ARRANGE
+	cases := map[string][2]string{
+		"acct:ana@blog.example.com": {"ana", "blog.example.com"},
+		"@ana@localhost:8080":       {"ana", "localhost:8080"},
+	}
+
+	for resource, want := range cases {
+		// ACT
+		username, domain, ok := activitypub.ParseAcct(resource)
+
+		// ASSERT
+		assert.True(t, ok, resource)
+		assert.Equal(t, want[0], username)
+		assert.Equal(t, want[1], domain)
+	}
+	_, _, ok := activitypub.ParseAcct("acct:ana")
+	assert.False(t, ok)
+}
@@ -0,0 +1,189 @@
+package contentfilter
+
+import (
+	"testing"
+	"time"
+
+	"tp06-testing/internal/contentfilter"
+	"tp06-testing/internal/models"
+	"tp06-testing/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// fixedFilter devuelve siempre la misma decisión
+type fixedFilter struct {
+	name     string
+	decision *contentfilter.Decision
+}
+
+func (f *fixedFilter) Name() string {
+	return f.name
+}
+
+func (f *fixedFilter) Check(content *contentfilter.Content) (*contentfilter.Decision, error) {
+	return f.decision, nil
+}
+
+// TestNormalize: tildes, mayúsculas, leet, homoglifos, ancho completo,
+// caracteres invisibles, letras repetidas y letras sueltas
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		"Canción ÚNICA":         "cancion unica",
+		"V14GR4 barato!!":       "viagra barato",
+		"ＳＰＡＭ spaaaam":          "spam spam",
+		"v i a g r a gratis":    "viagra gratis",
+		"сasino":                "casino", // c cirílica
+		"ca\u200bsi\u200bno":    "casino",
+		"Nos vemos en 2024":     "nos vemos en 2024",
+		"$pam a 100$ de oferta": "spam a 100 de oferta",
+	}
+
+	for input, expected := range cases {
+		assert.Equal(t, expected, contentfilter.Normalize(input), input)
+	}
+}
+
+// TestWordFilter: las palabras que rechazan ganan a las que retienen y
+// solo coinciden palabras completas
+func TestWordFilter(t *testing.T) {
+	// ARRANGE
+	filter := contentfilter.NewWordFilter([]string{"Viagra"}, []string{"casino online", "ass"})
+
+	// ACT
+	rejected, _ := filter.Check(&contentfilter.Content{Text: "Casino online y V1AGRA"})
+	held, _ := filter.Check(&contentfilter.Content{Text: "El mejor CASINO   ONLINE"})
+	allowed, _ := filter.Check(&contentfilter.Content{Text: "Una clase de casino"})
+
+	// ASSERT
+	assert.Equal(t, contentfilter.Reject, rejected.Verdict)
+	assert.Equal(t, contentfilter.Hold, held.Verdict)
+	assert.Contains(t, held.Reason, "casino online")
+	assert.Nil(t, allowed)
+}
+
+// TestLinkFilter: solo las cuentas nuevas tienen límite de links
+func TestLinkFilter(t *testing.T) {
+	// ARRANGE
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	filter := contentfilter.NewLinkFilter(2, 7*24*time.Hour)
+	filter.Now = func() time.Time { return now }
+	text := "Mira https://a.com/x, www.b.net y ofertas.xyz/ya"
+
+	// ACT
+	newAccount, _ := filter.Check(&contentfilter.Content{AuthorCreatedAt: now.Add(-time.Hour), Text: text})
+	oldAccount, _ := filter.Check(&contentfilter.Content{AuthorCreatedAt: now.Add(-30 * 24 * time.Hour), Text: text})
+
+	// ASSERT
+	assert.Equal(t, 3, contentfilter.CountLinks(text))
+	assert.Equal(t, contentfilter.Hold, newAccount.Verdict)
+	assert.Nil(t, oldAccount)
+}
+
+// TestChain: un rechazo gana a una retención anterior y la decisión
+// lleva el nombre del filtro; sin objeciones devuelve Allow
+func TestChain(t *testing.T) {
+	// ARRANGE
+	hold := &fixedFilter{name: "hold", decision: &contentfilter.Decision{Verdict: contentfilter.Hold}}
+	reject := &fixedFilter{name: "reject", decision: &contentfilter.Decision{Verdict: contentfilter.Reject}}
+	allow := &fixedFilter{name: "allow"}
+
+	// ACT
+	rejected, _ := contentfilter.NewChain(allow, hold, reject).Check(&contentfilter.Content{})
+	held, _ := contentfilter.NewChain(hold, allow).Check(&contentfilter.Content{})
+	allowed, _ := contentfilter.NewChain(allow).Check(&contentfilter.Content{})
+
+	// ASSERT
+	assert.Equal(t, "reject", rejected.Filter)
+	assert.Equal(t, contentfilter.Hold, held.Verdict)
+	assert.Equal(t, "hold", held.Filter)
+	assert.Equal(t, contentfilter.Allow, allowed.Verdict)
+}
+
+// TestDuplicateFilter: el mismo texto con otra forma se rechaza y los
+// textos cortos no se comparan
+func TestDuplicateFilter(t *testing.T) {
+	// ARRANGE
+	repo := new(mocks.MockContentFilterRepository)
+	filter := contentfilter.NewDuplicateFilter(repo, 24*time.Hour, 20)
+	repo.On("FindRecentContent", 1, mock.AnythingOfType("time.Time"), 0, mock.Anything).Return([]string{"Otro texto", "Compra seguidores BARATOS aquí"}, nil)
+
+	// ACT
+	duplicated, err := filter.Check(&contentfilter.Content{AuthorID: 1, Text: "compra   seguidores baratos aqui!!"})
+	short, _ := filter.Check(&contentfilter.Content{AuthorID: 1, Text: "¡Gracias!"})
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, contentfilter.Reject, duplicated.Verdict)
+	assert.Nil(t, short)
+	repo.AssertNumberOfCalls(t, "FindRecentContent", 1)
+}
+
+// TestDuplicateFilter_ExcludesOnlyEditedPost: al editar un post no se
+// compara consigo mismo; un comentario se compara con todo lo del autor
+func TestDuplicateFilter_ExcludesOnlyEditedPost(t *testing.T) {
+	// ARRANGE
+	repo := new(mocks.MockContentFilterRepository)
+	filter := contentfilter.NewDuplicateFilter(repo, 24*time.Hour, 5)
+	repo.On("FindRecentContent", 1, mock.AnythingOfType("time.Time"), 4, mock.Anything).Return([]string{}, nil)
+	repo.On("FindRecentContent", 1, mock.AnythingOfType("time.Time"), 0, mock.Anything).Return([]string{"Receta de pan casero"}, nil)
+
+	// ACT
+	edited, err := filter.Check(&contentfilter.Content{Kind: contentfilter.KindPost, AuthorID: 1, PostID: 4, Text: "Receta de pan casero"})
+	comment, _ := filter.Check(&contentfilter.Content{Kind: contentfilter.KindComment, AuthorID: 1, PostID: 4, Text: "Receta de pan casero"})
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Nil(t, edited)
+	assert.Equal(t, contentfilter.Reject, comment.Verdict)
+}
+
+// TestSpamClassifier: sin entrenamiento suficiente no opina; después
+// rechaza el spam claro y deja pasar el contenido normal
+func TestSpamClassifier(t *testing.T) {
+	// ARRANGE
+	untrainedRepo := new(mocks.MockContentFilterRepository)
+	untrainedRepo.On("FindDocumentCounts").Return(models.SpamCounts{Spam: 3, Ham: 50}, nil)
+
+	repo := new(mocks.MockContentFilterRepository)
+	repo.On("FindDocumentCounts").Return(models.SpamCounts{Spam: 50, Ham: 50}, nil)
+	repo.On("FindTokenCounts", []string{"casino", "gratis", "bonos"}).Return(map[string]models.SpamCounts{
+		"casino": {Spam: 40, Ham: 1},
+		"gratis": {Spam: 35, Ham: 3},
+		"bonos":  {Spam: 30, Ham: 0},
+	}, nil)
+	repo.On("FindTokenCounts", []string{"receta", "con", "horno"}).Return(map[string]models.SpamCounts{
+		"receta": {Spam: 0, Ham: 20},
+		"horno":  {Spam: 1, Ham: 15},
+	}, nil)
+	classifier := contentfilter.NewSpamClassifier(repo, 0.9, 0.99, 10)
+
+	// ACT
+	untrained, err := contentfilter.NewSpamClassifier(untrainedRepo, 0.9, 0.99, 10).Check(&contentfilter.Content{Text: "casino gratis"})
+	spam, _ := classifier.Check(&contentfilter.Content{Text: "Casino gratis, ¡bonos!"})
+	ham, _ := classifier.Check(&contentfilter.Content{Text: "Receta con horno"})
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Nil(t, untrained)
+	assert.Equal(t, contentfilter.Reject, spam.Verdict)
+	assert.Nil(t, ham)
+	untrainedRepo.AssertNotCalled(t, "FindTokenCounts", mock.Anything)
+}
+
+// TestSpamClassifier_Train: entrena con las palabras sin repetir y sin
+// las de una letra
+func TestSpamClassifier_Train(t *testing.T) {
+	// ARRANGE
+	repo := new(mocks.MockContentFilterRepository)
+	repo.On("Train", []string{"casino", "gratis"}, true).Return(nil)
+	classifier := contentfilter.NewSpamClassifier(repo, 0.9, 0.99, 10)
+
+	// ACT
+	err := classifier.Train("Casino y CASINO gratis", true)
+
+	// ASSERT
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
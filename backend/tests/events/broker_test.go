@@ -0,0 +1,89 @@
+package events
+
+import (
+	"testing"
+
+	"tp06-testing/internal/events"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBroker_FiltersByPost: una suscripción a un post solo recibe sus eventos
+func TestBroker_FiltersByPost(t *testing.T) {
+	// ARRANGE
+	broker := events.NewBroker(10, 10)
+	all, _, _ := broker.Subscribe(nil, 0)
+	onlyTwo, _, _ := broker.Subscribe([]int{2}, 0)
+
+	// ACT
+	assert.NoError(t, broker.Publish(events.CommentCreated, 1, map[string]int{"id": 10}))
+	assert.NoError(t, broker.Publish(events.CommentCreated, 2, map[string]int{"id": 11}))
+
+	// ASSERT
+	assert.Len(t, all.C, 2)
+	assert.Len(t, onlyTwo.C, 1)
+	event := <-onlyTwo.C
+	assert.Equal(t, 2, event.PostID)
+	assert.JSONEq(t, `{"id": 11}`, string(event.Data))
+}
+
+// TestBroker_ResumeFromLastEventID: al reconectarse recibe los eventos posteriores en orden
+func TestBroker_ResumeFromLastEventID(t *testing.T) {
+	// ARRANGE
+	broker := events.NewBroker(10, 10)
+	first, _, _ := broker.Subscribe(nil, 0)
+	for i := 1; i <= 3; i++ {
+		assert.NoError(t, broker.Publish(events.PostCreated, i, i))
+	}
+	received := <-first.C
+	first.Close()
+
+	// ACT
+	_, backlog, resumed := broker.Subscribe(nil, received.ID)
+
+	// ASSERT
+	assert.True(t, resumed)
+	assert.Len(t, backlog, 2)
+	assert.Equal(t, received.ID+1, backlog[0].ID)
+	assert.Equal(t, received.ID+2, backlog[1].ID)
+}
+
+// TestBroker_ResumeTooOld: si los eventos ya salieron del buffer no se puede retomar
+func TestBroker_ResumeTooOld(t *testing.T) {
+	// ARRANGE
+	broker := events.NewBroker(2, 10)
+	sub, _, _ := broker.Subscribe(nil, 0)
+	for i := 1; i <= 5; i++ {
+		assert.NoError(t, broker.Publish(events.PostCreated, i, i))
+	}
+	first := <-sub.C
+
+	// ACT
+	_, backlog, resumed := broker.Subscribe(nil, first.ID)
+	_, _, resumedUnknown := broker.Subscribe(nil, 42)
+
+	// ASSERT
+	assert.False(t, resumed)
+	assert.Empty(t, backlog)
+	assert.False(t, resumedUnknown)
+}
+
+// TestBroker_SlowSubscriberIsDropped: un cliente que no lee se desconecta sin frenar a los demás
+func TestBroker_SlowSubscriberIsDropped(t *testing.T) {
+	// ARRANGE
+	broker := events.NewBroker(10, 2)
+	slow, _, _ := broker.Subscribe(nil, 0)
+
+	// ACT
+	for i := 1; i <= 3; i++ {
+		assert.NoError(t, broker.Publish(events.PostCreated, i, i))
+	}
+
+	// ASSERT
+	assert.Equal(t, 0, broker.SubscriberCount())
+	<-slow.C
+	<-slow.C
+	_, open := <-slow.C
+	assert.False(t, open)
+	slow.Close() // Cerrar de nuevo no debe fallar
+}
@@ -0,0 +1,134 @@
+package feeds
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"tp06-testing/internal/feeds"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleFeed() *feeds.Feed {
+	published := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	item := &feeds.Item{
+		ID:        feeds.TagURI("https://blog.example.com", published, "post-7"),
+		Title:     "Go & <SQLite>",
+		Link:      "https://blog.example.com/posts/go-sqlite",
+		Author:    "ana",
+		Summary:   "Resumen",
+		Content:   `<p>Hola <strong>mundo</strong> &amp; más</p>`,
+		Published: published,
+		Updated:   published.Add(48 * time.Hour),
+	}
+	return &feeds.Feed{
+		ID:      "https://blog.example.com/",
+		Title:   "Blog",
+		Link:    "https://blog.example.com/",
+		SelfURL: "https://api.example.com/feed.xml",
+		Updated: feeds.LatestUpdate([]*feeds.Item{item}, time.Time{}),
+		Items:   []*feeds.Item{item},
+	}
+}
+
+// TestTagURI_StableAcrossEdits: el ID depende del dominio, la fecha de creación y el post
+func TestTagURI_StableAcrossEdits(t *testing.T) {
+	// ARRANGE
+	created := time.Date(2025, 10, 1, 23, 30, 0, 0, time.UTC)
+
+	// ACT
+	id := feeds.TagURI("https://blog.example.com:8443/", created, "post-7")
+
+	// ASSERT
+	assert.Equal(t, "tag:blog.example.com,2025-10-01:post-7", id)
+}
+
+// TestRSS_EscapesContentAndUsesGUID: el HTML va escapado y la guid no es un permalink
+func TestRSS_EscapesContentAndUsesGUID(t *testing.T) {
+	// ARRANGE
+	feed := sampleFeed()
+
+	// ACT
+	out, err := feeds.RSS(feed)
+
+	// ASSERT
+	require.NoError(t, err)
+	body := string(out)
+	assert.True(t, strings.HasPrefix(body, "<?xml"))
+	assert.Contains(t, body, `<guid isPermaLink="false">tag:blog.example.com,2025-10-01:post-7</guid>`)
+	assert.Contains(t, body, `&lt;p&gt;Hola &lt;strong&gt;mundo&lt;/strong&gt; &amp;amp; más&lt;/p&gt;`)
+	assert.Contains(t, body, `<title>Go &amp; &lt;SQLite&gt;</title>`)
+	assert.Contains(t, body, `<pubDate>Wed, 01 Oct 2025 12:00:00 +0000</pubDate>`)
+	assert.Contains(t, body, `<lastBuildDate>Fri, 03 Oct 2025 12:00:00 +0000</lastBuildDate>`)
+	assert.Contains(t, body, `<atom:link href="https://api.example.com/feed.xml" rel="self" type="application/rss+xml"></atom:link>`)
+
+	// El documento tiene que ser XML válido y devolver el HTML original
+	var parsed struct {
+		Items []struct {
+			Content string `xml:"encoded"`
+		} `xml:"channel>item"`
+	}
+	require.NoError(t, xml.Unmarshal(out, &parsed))
+	assert.Equal(t, feed.Items[0].Content, parsed.Items[0].Content)
+}
+
+// TestAtom_Dates: cada entrada tiene su fecha de publicación y de edición
+func TestAtom_Dates(t *testing.T) {
+	// ARRANGE
+	feed := sampleFeed()
+
+	// ACT
+	out, err := feeds.Atom(feed)
+
+	// ASSERT
+	require.NoError(t, err)
+	var parsed struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		Updated string   `xml:"updated"`
+		Entries []struct {
+			ID        string `xml:"id"`
+			Published string `xml:"published"`
+			Updated   string `xml:"updated"`
+			Content   struct {
+				Type  string `xml:"type,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"content"`
+		} `xml:"entry"`
+	}
+	require.NoError(t, xml.Unmarshal(out, &parsed))
+	assert.Equal(t, "2025-10-03T12:00:00Z", parsed.Updated)
+	require.Len(t, parsed.Entries, 1)
+	assert.Equal(t, "tag:blog.example.com,2025-10-01:post-7", parsed.Entries[0].ID)
+	assert.Equal(t, "2025-10-01T12:00:00Z", parsed.Entries[0].Published)
+	assert.Equal(t, "2025-10-03T12:00:00Z", parsed.Entries[0].Updated)
+	assert.Equal(t, "html", parsed.Entries[0].Content.Type)
+	assert.Equal(t, feed.Items[0].Content, parsed.Entries[0].Content.Value)
+}
+
+// TestJSONFeed_Version: genera JSON Feed 1.1 con content_html
+func TestJSONFeed_Version(t *testing.T) {
+	// ARRANGE
+	feed := sampleFeed()
+	empty := &feeds.Feed{Title: "Vacío"}
+
+	// ACT
+	out, err := feeds.JSON(feed)
+	emptyOut, emptyErr := feeds.JSON(empty)
+
+	// ASSERT
+	require.NoError(t, err)
+	var parsed map[string]interface{}
+	require.NoError(t, json.Unmarshal(out, &parsed))
+	assert.Equal(t, "https://jsonfeed.org/version/1.1", parsed["version"])
+	item := parsed["items"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, feed.Items[0].Content, item["content_html"])
+	assert.Equal(t, "2025-10-03T12:00:00Z", item["date_modified"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "ana"}}, item["authors"])
+
+	require.NoError(t, emptyErr)
+	assert.Contains(t, string(emptyOut), `"items": []`)
+}
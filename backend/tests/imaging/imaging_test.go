@@ -0,0 +1,166 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"tp06-testing/internal/imaging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exifSegment arma un segmento APP1 con un IFD que tiene la orientación
+// indicada y un texto que simula datos GPS
+func exifSegment(orientation uint16) []byte {
+	tiff := []byte("MM\x00*\x00\x00\x00\x08") // big endian, IFD0 en el byte 8
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112) // Orientation
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)      // SHORT
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	tiff = append(tiff, "GPSLatitude -34.6037 GPSLongitude -58.3816"...)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// jpegWithEXIF codifica un JPEG de width x height y le inserta el EXIF
+func jpegWithEXIF(t *testing.T, width, height int, orientation uint16) []byte {
+	var buf bytes.Buffer
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+
+	data := buf.Bytes()
+	out := append([]byte{}, data[:2]...) // SOI
+	out = append(out, exifSegment(orientation)...)
+	return append(out, data[2:]...)
+}
+
+// TestStripMetadata_JPEGRemovesEXIF: se quita el EXIF sin re-codificar
+func TestStripMetadata_JPEGRemovesEXIF(t *testing.T) {
+	// ARRANGE
+	data := jpegWithEXIF(t, 8, 8, 1)
+
+	// ACT
+	stripped, err := imaging.StripMetadata("image/jpeg", data)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.NotContains(t, string(stripped), "GPSLatitude")
+	assert.Equal(t, len(data)-len(exifSegment(1)), len(stripped))
+	_, err = jpeg.Decode(bytes.NewReader(stripped))
+	assert.NoError(t, err)
+}
+
+// TestStripMetadata_JPEGAppliesOrientation: una foto girada por EXIF se
+// guarda ya derecha, porque al quitar el EXIF se perdería la orientación
+func TestStripMetadata_JPEGAppliesOrientation(t *testing.T) {
+	// ARRANGE
+	data := jpegWithEXIF(t, 40, 20, 6) // 90° horario
+	assert.Equal(t, 6, imaging.Orientation(data))
+
+	// ACT
+	stripped, err := imaging.StripMetadata("image/jpeg", data)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.NotContains(t, string(stripped), "GPSLatitude")
+	assert.Equal(t, 1, imaging.Orientation(stripped))
+	width, height, err := imaging.Probe(stripped)
+	require.NoError(t, err)
+	assert.Equal(t, 20, width)
+	assert.Equal(t, 40, height)
+}
+
+// TestStripMetadata_PNGRemovesTextChunks: se quitan los chunks de texto
+func TestStripMetadata_PNGRemovesTextChunks(t *testing.T) {
+	// ARRANGE
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 4, 4))))
+	data := buf.Bytes()
+
+	text := []byte("tEXtComment\x00GPS -34.6037")
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(text)-4))
+	chunk = append(chunk, text...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(text))
+
+	iend := len(data) - 12
+	withText := append(append(append([]byte{}, data[:iend]...), chunk...), data[iend:]...)
+
+	// ACT
+	stripped, err := imaging.StripMetadata("image/png", withText)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, data, stripped)
+}
+
+// TestStripMetadata_WebPRemovesEXIFChunk: se quita el chunk y su flag en VP8X
+func TestStripMetadata_WebPRemovesEXIFChunk(t *testing.T) {
+	// ARRANGE
+	vp8x := append([]byte("VP8X\x0a\x00\x00\x00"), 0x08, 0, 0, 0, 0, 0, 0, 0, 0, 0)
+	exif := []byte("EXIF\x03\x00\x00\x00GPS\x00") // largo impar: lleva relleno
+	image := []byte("VP8 \x02\x00\x00\x00ab")
+	body := append(append(append([]byte("WEBP"), vp8x...), exif...), image...)
+	data := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	data = append(data, body...)
+
+	// ACT
+	stripped, err := imaging.StripMetadata("image/webp", data)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.NotContains(t, string(stripped), "GPS")
+	assert.Equal(t, byte(0), stripped[20]&0x08)
+	assert.Equal(t, uint32(len(stripped)-8), binary.LittleEndian.Uint32(stripped[4:]))
+}
+
+// TestProbe_RejectsDecompressionBomb: se rechaza solo con leer el encabezado
+func TestProbe_RejectsDecompressionBomb(t *testing.T) {
+	// ARRANGE
+	ihdr := []byte("IHDR\x00\x00\xc3\x50\x00\x00\xc3\x50\x08\x06\x00\x00\x00") // 50000x50000
+	data := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0d")
+	data = append(data, ihdr...)
+	data = binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(ihdr))
+
+	// ACT
+	_, _, err := imaging.Probe(data)
+
+	// ASSERT
+	assert.ErrorIs(t, err, imaging.ErrTooManyPixels)
+}
+
+// TestFit_KeepsAspectRatioAndAverages: reduce manteniendo la proporción
+func TestFit_KeepsAspectRatioAndAverages(t *testing.T) {
+	// ARRANGE: franjas verticales alternadas blanco y negro
+	src := image.NewRGBA(image.Rect(0, 0, 640, 320))
+	for y := 0; y < 320; y++ {
+		for x := 0; x < 640; x++ {
+			if x%2 == 0 {
+				src.Set(x, y, color.White)
+			} else {
+				src.Set(x, y, color.Black)
+			}
+		}
+	}
+
+	// ACT
+	thumbnail := imaging.Fit(src, 320, 320)
+	same := imaging.Fit(src, 1024, 1024)
+
+	// ASSERT
+	assert.Equal(t, image.Rect(0, 0, 320, 160), thumbnail.Bounds())
+	r, _, _, _ := thumbnail.At(100, 50).RGBA()
+	assert.InDelta(t, 0x8080, r, 0x0400) // gris: promedio de las franjas
+	assert.Same(t, src, same)            // nunca agranda
+}
@@ -0,0 +1,96 @@
+package mail
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	netmail "net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"tp06-testing/internal/mail"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEncode_MultipartAlternative: el email lleva la parte de texto y la
+// de HTML, el asunto codificado y los headers adicionales
+func TestEncode_MultipartAlternative(t *testing.T) {
+	// ARRANGE
+	msg := &mail.Message{
+		To:      "ana@example.com",
+		Subject: "Tu resumen diario: 1 post nuevo",
+		Text:    "Hola ana,\nhay novedades.",
+		HTML:    "<p>Hola ana, hay novedades.</p>",
+		Headers: map[string]string{"list-unsubscribe": "<https://blog.test/u>"},
+	}
+
+	// ACT
+	raw, err := mail.Encode("Blog <no-reply@blog.test>", msg, time.Date(2025, 10, 8, 9, 0, 0, 0, time.UTC))
+
+	// ASSERT
+	require.NoError(t, err)
+	parsed, err := netmail.ReadMessage(strings.NewReader(string(raw)))
+	require.NoError(t, err)
+
+	subject, _ := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	assert.Equal(t, msg.Subject, subject)
+	assert.Equal(t, "<https://blog.test/u>", parsed.Header.Get("List-Unsubscribe"))
+	assert.Contains(t, parsed.Header.Get("Message-Id"), "@blog.test>")
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	parts := multipart.NewReader(parsed.Body, params["boundary"])
+	var types []string
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		types = append(types, strings.Split(part.Header.Get("Content-Type"), ";")[0])
+	}
+	assert.Equal(t, []string{"text/plain", "text/html"}, types)
+}
+
+// TestEncode_RejectsHeaderInjection: un salto de línea en un header no
+// puede agregar destinatarios
+func TestEncode_RejectsHeaderInjection(t *testing.T) {
+	// ARRANGE
+	msg := &mail.Message{To: "ana@example.com", Subject: "Hola\r\nBcc: todos@example.com", Text: "x"}
+
+	// ACT
+	_, err := mail.Encode("no-reply@blog.test", msg, time.Now())
+
+	// ASSERT
+	assert.ErrorIs(t, err, mail.ErrInvalidMessage)
+}
+
+// TestFileMailer_WritesEml: cada email queda como un .eml en el directorio
+func TestFileMailer_WritesEml(t *testing.T) {
+	// ARRANGE
+	dir := filepath.Join(t.TempDir(), "outbox")
+	mailer, err := mail.NewFileMailer(dir, "no-reply@blog.test")
+	require.NoError(t, err)
+
+	// ACT
+	err1 := mailer.Send(context.Background(), &mail.Message{To: "ana@example.com", Subject: "Uno", Text: "uno"})
+	err2 := mailer.Send(context.Background(), &mail.Message{To: "beto@example.com", Subject: "Dos", Text: "dos"})
+
+	// ASSERT
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		assert.True(t, strings.HasSuffix(entry.Name(), ".eml"))
+	}
+}
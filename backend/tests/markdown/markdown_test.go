@@ -0,0 +1,174 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"tp06-testing/internal/markdown"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestToHTML_Basics prueba títulos, énfasis y código inline
+func TestToHTML_Basics(t *testing.T) {
+	html := markdown.ToHTML("# Hola *mundo*\n\nTexto con **negrita** y `<b>`.")
+
+	assert.Equal(t, "<h1>Hola <em>mundo</em></h1>\n<p>Texto con <strong>negrita</strong> y <code>&lt;b&gt;</code>.</p>", html)
+}
+
+// TestToHTML_OverlappingEmphasis: un ** cuyo primer * cierra otro énfasis
+// usa el que le queda con el cierre siguiente, como en CommonMark, y los
+// tags quedan bien anidados
+func TestToHTML_OverlappingEmphasis(t *testing.T) {
+	html := markdown.ToHTML("*a **b* c**")
+
+	assert.Equal(t, "<p><em>a <em><em>b</em> c</em></em></p>", html)
+}
+
+// TestToHTML_FencedCodeWithLanguage: el bloque de código lleva la clase del lenguaje
+func TestToHTML_FencedCodeWithLanguage(t *testing.T) {
+	html := markdown.ToHTML("```go\nfmt.Println(\"<hi>\")\n```")
+
+	assert.Equal(t, "<pre><code class=\"language-go\">fmt.Println(&#34;&lt;hi&gt;&#34;)\n</code></pre>", html)
+}
+
+// TestToHTML_Table prueba tablas GFM con alineación
+func TestToHTML_Table(t *testing.T) {
+	html := markdown.ToHTML("| A | B |\n|:--|--:|\n| 1 | 2 |")
+
+	assert.Contains(t, html, `<th align="left">A</th>`)
+	assert.Contains(t, html, `<td align="right">2</td>`)
+	assert.Contains(t, html, "<tbody>")
+}
+
+// TestToHTML_Lists prueba listas compactas y anidadas
+func TestToHTML_Lists(t *testing.T) {
+	html := markdown.ToHTML("- uno\n- dos\n  - anidado")
+
+	assert.Equal(t, "<ul>\n<li>uno</li>\n<li>dos\n<ul>\n<li>anidado</li>\n</ul></li>\n</ul>", html)
+}
+
+// TestToHTML_RawHTMLIsEscaped: el HTML escrito a mano se muestra como texto
+func TestToHTML_RawHTMLIsEscaped(t *testing.T) {
+	html := markdown.ToHTML(`<script>alert(1)</script><img src=x onerror=alert(1)>`)
+
+	assert.NotContains(t, html, "<script")
+	assert.NotContains(t, html, "<img")
+	assert.Contains(t, html, "&lt;script&gt;")
+}
+
+// TestToHTML_UnsafeLinks: los links javascript: y las imágenes data: se descartan
+func TestToHTML_UnsafeLinks(t *testing.T) {
+	html := markdown.ToHTML("[a](javascript:alert(1)) [b](JaVaScRiPt:alert(1)) ![c](data:image/png;base64,xx) [ok](https://go.dev)")
+
+	assert.NotContains(t, html, "javascript")
+	assert.NotContains(t, html, "data:")
+	assert.Contains(t, html, `<a href="https://go.dev" rel="nofollow noopener noreferrer">ok</a>`)
+}
+
+// TestToHTML_Entities: las entidades válidas pasan tal cual y el resto de
+// los & se escapan
+func TestToHTML_Entities(t *testing.T) {
+	html := markdown.ToHTML("AT&amp;T &copy; &#233; &#x1F600; &noexiste; &#0; & `&amp;`")
+
+	assert.Equal(t, "<p>AT&amp;T &copy; &#233; &#x1F600; &amp;noexiste; &amp;#0; &amp; <code>&amp;amp;</code></p>", html)
+}
+
+// TestToHTML_PathologicalInput: las aperturas sin cerrar y el anidamiento
+// profundo no hacen que el tiempo crezca en forma cuadrática
+func TestToHTML_PathologicalInput(t *testing.T) {
+	inputs := map[string]string{
+		"links sin cerrar":       strings.Repeat("[a](", 40000),
+		"imágenes sin cerrar":    strings.Repeat("![", 40000),
+		"destinos <> sin cerrar": strings.Repeat("[a](<", 40000),
+		"títulos sin cerrar":     strings.Repeat("[a](x (", 40000),
+		"corchetes anidados":     strings.Repeat("[", 20000) + "a" + strings.Repeat("](b)", 20000),
+		"énfasis sin cerrar":     strings.Repeat("**a", 40000),
+		"tachado sin cerrar":     strings.Repeat("~~a", 40000),
+		"citas anidadas":         strings.Repeat("> ", 40000),
+		"citas y listas":         strings.Repeat("> - ", 40000),
+	}
+
+	for name, input := range inputs {
+		start := time.Now()
+		markdown.ToHTML(input)
+		assert.Less(t, time.Since(start), 2*time.Second, name)
+	}
+}
+
+// TestSanitize_Allowlist: tags y atributos fuera de la lista se eliminan o escapan
+func TestSanitize_Allowlist(t *testing.T) {
+	html := markdown.Sanitize(`<p onclick="x">a</p><a href="javascript:x">l</a><iframe src="x"></iframe><!-- c --><code class="evil">x</code>`)
+
+	assert.Equal(t, `<p>a</p><a>l</a>&lt;iframe src=&#34;x&#34;&gt;&lt;/iframe&gt;<code>x</code>`, html)
+}
+
+// TestSanitize_UnclosedComment: un comentario sin cerrar se escapa en
+// lugar de descartar el resto del texto
+func TestSanitize_UnclosedComment(t *testing.T) {
+	html := markdown.Sanitize(`<p>a</p><!-- sin cerrar <strong>b</strong>`)
+
+	assert.Equal(t, `<p>a</p>&lt;!-- sin cerrar <strong>b</strong>`, html)
+}
+
+// TestRenderer_CachesPerRevision: el mismo contenido se renderiza una sola vez
+func TestRenderer_CachesPerRevision(t *testing.T) {
+	renderer := markdown.NewRenderer(2)
+
+	first := renderer.Render("**hola**")
+	second := renderer.Render("**hola**")
+	renderer.Render("otro")
+	renderer.Render("y otro más")
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, 2, renderer.Len())
+}
+
+// TestExcerpt_CutsAtSentenceBoundary: el resumen termina en el último punto que entra
+func TestExcerpt_CutsAtSentenceBoundary(t *testing.T) {
+	text := "Primera oración. Segunda oración bastante más larga. Tercera."
+
+	assert.Equal(t, "Primera oración.", markdown.Excerpt(text, 30))
+	assert.Equal(t, text, markdown.Excerpt(text, 100))
+}
+
+// TestExcerpt_LongSentenceCutsAtWord: sin fin de oración se corta en una palabra
+func TestExcerpt_LongSentenceCutsAtWord(t *testing.T) {
+	assert.Equal(t, "una oración sin…", markdown.Excerpt("una oración sin ningún punto final", 18))
+}
+
+// TestExcerptFromHTML_SkipsHeadingsAndCode: títulos y código no forman parte del resumen
+func TestExcerptFromHTML_SkipsHeadingsAndCode(t *testing.T) {
+	html := markdown.ToHTML("# Título\n\n```go\ncode()\n```\n\nEl **texto** real.")
+
+	assert.Equal(t, "El texto real.", markdown.ExcerptFromHTML(html, 200))
+	assert.Equal(t, 4, markdown.WordCount(markdown.TextFromHTML(html)))
+}
+
+// TestFindMentions_OffsetsInCharacters: las posiciones se cuentan en caracteres, no bytes
+func TestFindMentions_OffsetsInCharacters(t *testing.T) {
+	mentions := markdown.FindMentions("¿Qué opinás @ana? Gracias @José.")
+
+	assert.Equal(t, []markdown.Mention{
+		{Username: "ana", Offset: 12, Length: 4},
+		{Username: "José", Offset: 26, Length: 5},
+	}, mentions)
+}
+
+// TestFindMentions_SkipsCode: no se toman menciones dentro de código
+func TestFindMentions_SkipsCode(t *testing.T) {
+	src := "Usá `@deprecated` como dice @ana\n\n```java\n@Override\n```\n\n    @indentado\n\nfin"
+
+	mentions := markdown.FindMentions(src)
+
+	assert.Len(t, mentions, 1)
+	assert.Equal(t, "ana", mentions[0].Username)
+}
+
+// TestFindMentions_NotMentions: emails, URLs, escapes y cuentas remotas no son menciones
+func TestFindMentions_NotMentions(t *testing.T) {
+	mentions := markdown.FindMentions(`mail a@b.com, https://x.social/@ana, \@beto, @carla@otro.server`)
+
+	assert.Empty(t, mentions)
+}
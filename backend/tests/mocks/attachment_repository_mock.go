@@ -0,0 +1,69 @@
+package mocks
+
+import (
+	"tp06-testing/internal/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAttachmentRepository es un mock del AttachmentRepository
+type MockAttachmentRepository struct {
+	mock.Mock
+}
+
+// Create simula el registro de un adjunto
+func (m *MockAttachmentRepository) Create(attachment *models.Attachment) error {
+	args := m.Called(attachment)
+	return args.Error(0)
+}
+
+// FindByID simula la búsqueda por ID
+func (m *MockAttachmentRepository) FindByID(id int) (*models.Attachment, error) {
+	args := m.Called(id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Attachment), args.Error(1)
+}
+
+// FindByPostID simula la búsqueda de adjuntos de un post
+func (m *MockAttachmentRepository) FindByPostID(postID int) ([]*models.Attachment, error) {
+	args := m.Called(postID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.Attachment), args.Error(1)
+}
+
+// FindPendingImages simula la búsqueda de imágenes sin procesar
+func (m *MockAttachmentRepository) FindPendingImages(limit int) ([]*models.Attachment, error) {
+	args := m.Called(limit)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.Attachment), args.Error(1)
+}
+
+// SaveVariants simula el guardado de las versiones reducidas
+func (m *MockAttachmentRepository) SaveVariants(attachmentID int, variants []*models.AttachmentVariant) error {
+	args := m.Called(attachmentID, variants)
+	return args.Error(0)
+}
+
+// UpdateProcessingStatus simula el cambio de estado del procesamiento
+func (m *MockAttachmentRepository) UpdateProcessingStatus(attachmentID int, status string) error {
+	args := m.Called(attachmentID, status)
+	return args.Error(0)
+}
+
+// Delete simula la eliminación de un adjunto
+func (m *MockAttachmentRepository) Delete(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
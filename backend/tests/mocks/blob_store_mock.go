@@ -0,0 +1,47 @@
+package mocks
+
+import (
+	"context"
+	"io"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBlobStore es un mock del BlobStore. Put lee el contenido completo
+// y lo guarda en Stored para que los tests puedan verificarlo.
+type MockBlobStore struct {
+	mock.Mock
+	Stored map[string][]byte
+}
+
+// Put simula la subida de un archivo
+func (m *MockBlobStore) Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	if m.Stored == nil {
+		m.Stored = map[string][]byte{}
+	}
+	m.Stored[key] = data
+
+	args := m.Called(key, size, contentType)
+	return args.Error(0)
+}
+
+// Open simula la lectura de un archivo
+func (m *MockBlobStore) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	args := m.Called(key)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(io.ReadSeekCloser), args.Error(1)
+}
+
+// Delete simula la eliminación de un archivo
+func (m *MockBlobStore) Delete(ctx context.Context, key string) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
@@ -0,0 +1,80 @@
+package mocks
+
+import (
+	"tp06-testing/internal/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBlockRepository es un mock del BlockRepository
+type MockBlockRepository struct {
+	mock.Mock
+}
+
+// Block simula bloquear a un usuario
+func (m *MockBlockRepository) Block(userID int, blockedID int) error {
+	args := m.Called(userID, blockedID)
+	return args.Error(0)
+}
+
+// Unblock simula quitar un bloqueo
+func (m *MockBlockRepository) Unblock(userID int, blockedID int) error {
+	args := m.Called(userID, blockedID)
+	return args.Error(0)
+}
+
+// Mute simula silenciar a un usuario
+func (m *MockBlockRepository) Mute(userID int, mutedID int) error {
+	args := m.Called(userID, mutedID)
+	return args.Error(0)
+}
+
+// Unmute simula quitar un silencio
+func (m *MockBlockRepository) Unmute(userID int, mutedID int) error {
+	args := m.Called(userID, mutedID)
+	return args.Error(0)
+}
+
+// FindStatus simula obtener si un usuario bloqueó o silenció a otro
+func (m *MockBlockRepository) FindStatus(userID int, otherID int) (*models.BlockStatus, error) {
+	args := m.Called(userID, otherID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.BlockStatus), args.Error(1)
+}
+
+// FindBlockers simula obtener quiénes de userIDs bloquearon a blockedID
+func (m *MockBlockRepository) FindBlockers(userIDs []int, blockedID int) (map[int]bool, error) {
+	args := m.Called(userIDs, blockedID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(map[int]bool), args.Error(1)
+}
+
+// FindBlocked simula obtener los usuarios bloqueados
+func (m *MockBlockRepository) FindBlocked(userID int) ([]*models.BlockedUser, error) {
+	args := m.Called(userID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.BlockedUser), args.Error(1)
+}
+
+// FindMuted simula obtener los usuarios silenciados
+func (m *MockBlockRepository) FindMuted(userID int) ([]*models.BlockedUser, error) {
+	args := m.Called(userID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.BlockedUser), args.Error(1)
+}
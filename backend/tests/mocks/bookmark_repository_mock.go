@@ -0,0 +1,46 @@
+package mocks
+
+import (
+	"tp06-testing/internal/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBookmarkRepository es un mock del BookmarkRepository
+type MockBookmarkRepository struct {
+	mock.Mock
+}
+
+// Add simula guardar un post
+func (m *MockBookmarkRepository) Add(userID int, postID int) error {
+	args := m.Called(userID, postID)
+	return args.Error(0)
+}
+
+// Remove simula quitar un post de los guardados
+func (m *MockBookmarkRepository) Remove(userID int, postID int) error {
+	args := m.Called(userID, postID)
+	return args.Error(0)
+}
+
+// FindPostsByUser simula obtener una página de posts guardados
+func (m *MockBookmarkRepository) FindPostsByUser(userID int, after *models.Cursor, limit int) ([]*models.Post, error) {
+	args := m.Called(userID, after, limit)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.Post), args.Error(1)
+}
+
+// BookmarkedPostIDs simula la consulta de cuáles posts guardó el usuario
+func (m *MockBookmarkRepository) BookmarkedPostIDs(userID int, postIDs []int) (map[int]bool, error) {
+	args := m.Called(userID, postIDs)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(map[int]bool), args.Error(1)
+}
@@ -0,0 +1,18 @@
+package mocks
+
+import "time"
+
+// FakeClock es un reloj fijo que los tests pueden adelantar a mano
+type FakeClock struct {
+	Current time.Time
+}
+
+// Now devuelve la hora configurada
+func (c *FakeClock) Now() time.Time {
+	return c.Current
+}
+
+// Advance adelanta el reloj
+func (c *FakeClock) Advance(d time.Duration) {
+	c.Current = c.Current.Add(d)
+}
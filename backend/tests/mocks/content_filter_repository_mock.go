@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"time"
+
+	"tp06-testing/internal/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockContentFilterRepository es un mock del ContentFilterRepository
+type MockContentFilterRepository struct {
+	mock.Mock
+}
+
+// FindRecentContent simula obtener los textos recientes de un usuario
+func (m *MockContentFilterRepository) FindRecentContent(userID int, since time.Time, excludePostID int, limit int) ([]string, error) {
+	args := m.Called(userID, since, excludePostID, limit)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// FindTokenCounts simula obtener las estadísticas de las palabras
+func (m *MockContentFilterRepository) FindTokenCounts(tokens []string) (map[string]models.SpamCounts, error) {
+	args := m.Called(tokens)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(map[string]models.SpamCounts), args.Error(1)
+}
+
+// FindDocumentCounts simula obtener cuántos documentos de cada clase hay
+func (m *MockContentFilterRepository) FindDocumentCounts() (models.SpamCounts, error) {
+	args := m.Called()
+	return args.Get(0).(models.SpamCounts), args.Error(1)
+}
+
+// Train simula sumar un documento de entrenamiento
+func (m *MockContentFilterRepository) Train(tokens []string, spam bool) error {
+	args := m.Called(tokens, spam)
+	return args.Error(0)
+}
@@ -0,0 +1,70 @@
+package mocks
+
+import (
+	"time"
+
+	"tp06-testing/internal/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockDigestRepository es un mock del DigestRepository
+type MockDigestRepository struct {
+	mock.Mock
+}
+
+// FindSettings simula obtener la configuración del resumen
+func (m *MockDigestRepository) FindSettings(userID int) (*models.DigestSettings, error) {
+	args := m.Called(userID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.DigestSettings), args.Error(1)
+}
+
+// SaveSettings simula guardar la configuración del resumen
+func (m *MockDigestRepository) SaveSettings(settings *models.DigestSettings) error {
+	args := m.Called(settings)
+	return args.Error(0)
+}
+
+// FindDue simula obtener los usuarios con el resumen vencido
+func (m *MockDigestRepository) FindDue(now time.Time, limit int) ([]*models.DigestSettings, error) {
+	args := m.Called(now, limit)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.DigestSettings), args.Error(1)
+}
+
+// MarkDigested simula registrar hasta dónde cubrió el último resumen
+func (m *MockDigestRepository) MarkDigested(userID int, until time.Time) error {
+	args := m.Called(userID, until)
+	return args.Error(0)
+}
+
+// FindNewPosts simula obtener los posts nuevos de los autores seguidos
+func (m *MockDigestRepository) FindNewPosts(userID int, since time.Time, until time.Time, limit int) ([]*models.Post, error) {
+	args := m.Called(userID, since, until, limit)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.Post), args.Error(1)
+}
+
+// FindReplies simula obtener los comentarios en los posts del usuario
+func (m *MockDigestRepository) FindReplies(userID int, since time.Time, until time.Time, limit int) ([]*models.DigestReply, error) {
+	args := m.Called(userID, since, until, limit)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.DigestReply), args.Error(1)
+}
@@ -0,0 +1,85 @@
+package mocks
+
+import (
+	"tp06-testing/internal/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockFederationRepository es un mock del FederationRepository
+type MockFederationRepository struct {
+	mock.Mock
+}
+
+// FindKey simula buscar las claves de un usuario
+func (m *MockFederationRepository) FindKey(userID int) (*models.ActorKey, error) {
+	args := m.Called(userID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.ActorKey), args.Error(1)
+}
+
+// SaveKey simula guardar las claves de un usuario
+func (m *MockFederationRepository) SaveKey(key *models.ActorKey) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+// FindRemoteActor simula buscar un actor remoto por su URI
+func (m *MockFederationRepository) FindRemoteActor(uri string) (*models.RemoteActor, error) {
+	args := m.Called(uri)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.RemoteActor), args.Error(1)
+}
+
+// FindRemoteActorByUser simula buscar el actor remoto de un usuario local
+func (m *MockFederationRepository) FindRemoteActorByUser(userID int) (*models.RemoteActor, error) {
+	args := m.Called(userID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.RemoteActor), args.Error(1)
+}
+
+// SaveRemoteActor simula guardar un actor remoto
+func (m *MockFederationRepository) SaveRemoteActor(actor *models.RemoteActor) error {
+	args := m.Called(actor)
+	return args.Error(0)
+}
+
+// FindFollowerActors simula obtener los seguidores remotos de un usuario
+func (m *MockFederationRepository) FindFollowerActors(userID int) ([]*models.RemoteActor, error) {
+	args := m.Called(userID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.RemoteActor), args.Error(1)
+}
+
+// SaveRemoteComment simula registrar una respuesta importada
+func (m *MockFederationRepository) SaveRemoteComment(comment *models.RemoteComment) error {
+	args := m.Called(comment)
+	return args.Error(0)
+}
+
+// FindRemoteComment simula buscar una respuesta importada
+func (m *MockFederationRepository) FindRemoteComment(objectURI string) (*models.RemoteComment, error) {
+	args := m.Called(objectURI)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.RemoteComment), args.Error(1)
+}
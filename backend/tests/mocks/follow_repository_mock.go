@@ -0,0 +1,63 @@
+package mocks
+
+import (
+	"tp06-testing/internal/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockFollowRepository es un mock del FollowRepository
+type MockFollowRepository struct {
+	mock.Mock
+}
+
+// Follow simula seguir a un usuario
+func (m *MockFollowRepository) Follow(followerID int, followeeID int) (bool, error) {
+	args := m.Called(followerID, followeeID)
+	return args.Bool(0), args.Error(1)
+}
+
+// Unfollow simula dejar de seguir a un usuario
+func (m *MockFollowRepository) Unfollow(followerID int, followeeID int) error {
+	args := m.Called(followerID, followeeID)
+	return args.Error(0)
+}
+
+// IsFollowing simula consultar si un usuario sigue a otro
+func (m *MockFollowRepository) IsFollowing(followerID int, followeeID int) (bool, error) {
+	args := m.Called(followerID, followeeID)
+	return args.Bool(0), args.Error(1)
+}
+
+// FindProfile simula la búsqueda de un perfil
+func (m *MockFollowRepository) FindProfile(userID int, viewerID int) (*models.UserProfile, error) {
+	args := m.Called(userID, viewerID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.UserProfile), args.Error(1)
+}
+
+// FindFollowers simula obtener una página de seguidores
+func (m *MockFollowRepository) FindFollowers(userID int, viewerID int, after *models.Cursor, limit int) ([]*models.UserProfile, error) {
+	args := m.Called(userID, viewerID, after, limit)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.UserProfile), args.Error(1)
+}
+
+// FindFollowing simula obtener una página de seguidos
+func (m *MockFollowRepository) FindFollowing(userID int, viewerID int, after *models.Cursor, limit int) ([]*models.UserProfile, error) {
+	args := m.Called(userID, viewerID, after, limit)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.UserProfile), args.Error(1)
+}
@@ -0,0 +1,46 @@
+package mocks
+
+import (
+	"tp06-testing/internal/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockMentionRepository es un mock del MentionRepository
+type MockMentionRepository struct {
+	mock.Mock
+}
+
+// ReplacePostMentions simula reemplazar las menciones de un post
+func (m *MockMentionRepository) ReplacePostMentions(postID int, mentions []models.Mention) error {
+	args := m.Called(postID, mentions)
+	return args.Error(0)
+}
+
+// SaveCommentMentions simula guardar las menciones de un comentario
+func (m *MockMentionRepository) SaveCommentMentions(postID int, commentID int, mentions []models.Mention) error {
+	args := m.Called(postID, commentID, mentions)
+	return args.Error(0)
+}
+
+// PostMentions simula cargar las menciones de varios posts
+func (m *MockMentionRepository) PostMentions(postIDs []int) (map[int][]models.Mention, error) {
+	args := m.Called(postIDs)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(map[int][]models.Mention), args.Error(1)
+}
+
+// CommentMentions simula cargar las menciones de varios comentarios
+func (m *MockMentionRepository) CommentMentions(commentIDs []int) (map[int][]models.Mention, error) {
+	args := m.Called(commentIDs)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(map[int][]models.Mention), args.Error(1)
+}
@@ -0,0 +1,64 @@
+package mocks
+
+import (
+	"tp06-testing/internal/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockNotificationRepository es un mock del NotificationRepository
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+// CreateOrCoalesce simula guardar (o agrupar) una notificación
+func (m *MockNotificationRepository) CreateOrCoalesce(notification *models.Notification) error {
+	args := m.Called(notification)
+	return args.Error(0)
+}
+
+// FindByUser simula obtener una página de notificaciones
+func (m *MockNotificationRepository) FindByUser(userID int, unreadOnly bool, after *models.Cursor, limit int) ([]*models.Notification, error) {
+	args := m.Called(userID, unreadOnly, after, limit)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.Notification), args.Error(1)
+}
+
+// CountUnread simula contar las notificaciones sin leer
+func (m *MockNotificationRepository) CountUnread(userID int) (int, error) {
+	args := m.Called(userID)
+	return args.Int(0), args.Error(1)
+}
+
+// MarkRead simula marcar una notificación como leída
+func (m *MockNotificationRepository) MarkRead(userID int, notificationID int) (bool, error) {
+	args := m.Called(userID, notificationID)
+	return args.Bool(0), args.Error(1)
+}
+
+// MarkAllRead simula marcar todas como leídas
+func (m *MockNotificationRepository) MarkAllRead(userID int) (int, error) {
+	args := m.Called(userID)
+	return args.Int(0), args.Error(1)
+}
+
+// FindPreferences simula obtener las preferencias del usuario
+func (m *MockNotificationRepository) FindPreferences(userID int) (*models.NotificationPreferences, error) {
+	args := m.Called(userID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.NotificationPreferences), args.Error(1)
+}
+
+// SavePreferences simula guardar las preferencias
+func (m *MockNotificationRepository) SavePreferences(prefs *models.NotificationPreferences) error {
+	args := m.Called(prefs)
+	return args.Error(0)
+}
@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"time"
+
 	"tp06-testing/internal/models"
 
 	"github.com/stretchr/testify/mock"
@@ -17,9 +19,9 @@ func (m *MockPostRepository) Create(post *models.Post) error {
 	return args.Error(0)
 }
 
-// FindAll simula obtener todos los posts
-func (m *MockPostRepository) FindAll() ([]*models.Post, error) {
-	args := m.Called()
+// FindAll simula obtener todos los posts visibles para viewerID
+func (m *MockPostRepository) FindAll(viewerID int) ([]*models.Post, error) {
+	args := m.Called(viewerID)
 
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -39,9 +41,49 @@ func (m *MockPostRepository) FindByID(id int) (*models.Post, error) {
 	return args.Get(0).(*models.Post), args.Error(1)
 }
 
-// Delete simula eliminar un post
-func (m *MockPostRepository) Delete(id int) error {
-	args := m.Called(id)
+// FindBySlug simula buscar un post por slug
+func (m *MockPostRepository) FindBySlug(slug string) (*models.Post, error) {
+	args := m.Called(slug)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Post), args.Error(1)
+}
+
+// SlugExists simula verificar si un slug está en uso
+func (m *MockPostRepository) SlugExists(slug string) (bool, error) {
+	args := m.Called(slug)
+	return args.Bool(0), args.Error(1)
+}
+
+// Update simula actualizar un post
+func (m *MockPostRepository) Update(post *models.Post, previousSlug string) error {
+	args := m.Called(post, previousSlug)
+	return args.Error(0)
+}
+
+// UpdateStatus simula cambiar el estado de un post
+func (m *MockPostRepository) UpdateStatus(postID int, status string, publishedAt *time.Time) error {
+	args := m.Called(postID, status, publishedAt)
+	return args.Error(0)
+}
+
+// PublishDue simula publicar los posts programados vencidos
+func (m *MockPostRepository) PublishDue(now time.Time) ([]int, error) {
+	args := m.Called(now)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]int), args.Error(1)
+}
+
+// Delete simula mandar un post a la papelera
+func (m *MockPostRepository) Delete(id int, deletedBy int) error {
+	args := m.Called(id, deletedBy)
 	return args.Error(0)
 }
 
@@ -52,8 +94,8 @@ func (m *MockPostRepository) CreateComment(comment *models.Comment) error {
 }
 
 // FindCommentsByPostID simula obtener comentarios de un post
-func (m *MockPostRepository) FindCommentsByPostID(postID int) ([]*models.Comment, error) {
-	args := m.Called(postID)
+func (m *MockPostRepository) FindCommentsByPostID(postID int, viewerID int) ([]*models.Comment, error) {
+	args := m.Called(postID, viewerID)
 
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -62,8 +104,137 @@ func (m *MockPostRepository) FindCommentsByPostID(postID int) ([]*models.Comment
 	return args.Get(0).([]*models.Comment), args.Error(1)
 }
 
-// DeleteComment simula eliminar un comentario
-func (m *MockPostRepository) DeleteComment(postID int, commentID int, userID int) error {
-	args := m.Called(postID, commentID, userID)
+// FindCommentByID simula la búsqueda de un comentario
+func (m *MockPostRepository) FindCommentByID(commentID int) (*models.Comment, error) {
+	args := m.Called(commentID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Comment), args.Error(1)
+}
+
+// DeleteComment simula mandar un comentario a la papelera
+func (m *MockPostRepository) DeleteComment(postID int, commentID int, userID int, deletedBy int) error {
+	args := m.Called(postID, commentID, userID, deletedBy)
 	return args.Error(0)
 }
+
+// FindDeletedPosts simula obtener los posts de la papelera
+func (m *MockPostRepository) FindDeletedPosts(userID int, since time.Time) ([]*models.Post, error) {
+	args := m.Called(userID, since)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.Post), args.Error(1)
+}
+
+// FindDeletedComments simula obtener los comentarios de la papelera
+func (m *MockPostRepository) FindDeletedComments(userID int, since time.Time) ([]*models.Comment, error) {
+	args := m.Called(userID, since)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.Comment), args.Error(1)
+}
+
+// Restore simula sacar un post de la papelera
+func (m *MockPostRepository) Restore(postID int, userID int, since time.Time) (bool, error) {
+	args := m.Called(postID, userID, since)
+	return args.Bool(0), args.Error(1)
+}
+
+// RestoreComment simula sacar un comentario de la papelera
+func (m *MockPostRepository) RestoreComment(postID int, commentID int, userID int, since time.Time) (bool, error) {
+	args := m.Called(postID, commentID, userID, since)
+	return args.Bool(0), args.Error(1)
+}
+
+// PurgeDeleted simula vaciar la papelera
+func (m *MockPostRepository) PurgeDeleted(before time.Time) (int, int, error) {
+	args := m.Called(before)
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+// RecountComments simula recalcular los contadores de comentarios
+func (m *MockPostRepository) RecountComments() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+// FindFeed simula obtener una página del feed personal
+func (m *MockPostRepository) FindFeed(userID int, after *models.Cursor, limit int) ([]*models.Post, error) {
+	args := m.Called(userID, after, limit)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.Post), args.Error(1)
+}
+
+// FindCommenterIDs simula obtener los usuarios que comentaron en un post
+func (m *MockPostRepository) FindCommenterIDs(postID int) ([]int, error) {
+	args := m.Called(postID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]int), args.Error(1)
+}
+
+// CountPublished simula contar los posts publicados
+func (m *MockPostRepository) CountPublished() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+// FindLatestPublic simula obtener los últimos posts públicos
+func (m *MockPostRepository) FindLatestPublic(limit int) ([]*models.Post, error) {
+	args := m.Called(limit)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.Post), args.Error(1)
+}
+
+// CountPublicByUser simula contar los posts públicos de un usuario
+func (m *MockPostRepository) CountPublicByUser(userID int) (int, error) {
+	args := m.Called(userID)
+	return args.Int(0), args.Error(1)
+}
+
+// FindPublicByUser simula obtener una página de los posts públicos de un usuario
+func (m *MockPostRepository) FindPublicByUser(userID int, offset int, limit int) ([]*models.Post, error) {
+	args := m.Called(userID, offset, limit)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.Post), args.Error(1)
+}
+
+// EachPublished simula recorrer los posts publicados: llama a fn con
+// cada entrada configurada en el primer valor de retorno
+func (m *MockPostRepository) EachPublished(offset int, limit int, fn func(entry *models.SitemapEntry) error) error {
+	args := m.Called(offset, limit)
+
+	if entries, ok := args.Get(0).([]*models.SitemapEntry); ok {
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+	}
+
+	return args.Error(1)
+}
@@ -0,0 +1,58 @@
+package mocks
+
+import (
+	"tp06-testing/internal/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReactionRepository es un mock del ReactionRepository
+type MockReactionRepository struct {
+	mock.Mock
+}
+
+// AddPostReaction simula agregar una reacción a un post
+func (m *MockReactionRepository) AddPostReaction(postID int, userID int, reactionType string) error {
+	args := m.Called(postID, userID, reactionType)
+	return args.Error(0)
+}
+
+// RemovePostReaction simula quitar una reacción de un post
+func (m *MockReactionRepository) RemovePostReaction(postID int, userID int, reactionType string) error {
+	args := m.Called(postID, userID, reactionType)
+	return args.Error(0)
+}
+
+// PostReactions simula el resumen de reacciones de varios posts
+func (m *MockReactionRepository) PostReactions(postIDs []int, viewerID int) (map[int]*models.ReactionSummary, error) {
+	args := m.Called(postIDs, viewerID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(map[int]*models.ReactionSummary), args.Error(1)
+}
+
+// AddCommentReaction simula agregar una reacción a un comentario
+func (m *MockReactionRepository) AddCommentReaction(commentID int, userID int, reactionType string) error {
+	args := m.Called(commentID, userID, reactionType)
+	return args.Error(0)
+}
+
+// RemoveCommentReaction simula quitar una reacción de un comentario
+func (m *MockReactionRepository) RemoveCommentReaction(commentID int, userID int, reactionType string) error {
+	args := m.Called(commentID, userID, reactionType)
+	return args.Error(0)
+}
+
+// CommentReactions simula el resumen de reacciones de varios comentarios
+func (m *MockReactionRepository) CommentReactions(commentIDs []int, viewerID int) (map[int]*models.ReactionSummary, error) {
+	args := m.Called(commentIDs, viewerID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(map[int]*models.ReactionSummary), args.Error(1)
+}
@@ -0,0 +1,75 @@
+package mocks
+
+import (
+	"tp06-testing/internal/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReportRepository es un mock del ReportRepository
+type MockReportRepository struct {
+	mock.Mock
+}
+
+// Create simula guardar un reporte
+func (m *MockReportRepository) Create(report *models.Report) (bool, error) {
+	args := m.Called(report)
+	return args.Bool(0), args.Error(1)
+}
+
+// CountOpen simula contar los reportes abiertos sobre un contenido
+func (m *MockReportRepository) CountOpen(postID int, commentID int) (int, error) {
+	args := m.Called(postID, commentID)
+	return args.Int(0), args.Error(1)
+}
+
+// FindByID simula buscar un reporte
+func (m *MockReportRepository) FindByID(id int) (*models.Report, error) {
+	args := m.Called(id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Report), args.Error(1)
+}
+
+// FindByStatus simula obtener una página de la cola de moderación
+func (m *MockReportRepository) FindByStatus(status string, after *models.Cursor, limit int) ([]*models.Report, error) {
+	args := m.Called(status, after, limit)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.Report), args.Error(1)
+}
+
+// FindActions simula obtener el historial de un reporte
+func (m *MockReportRepository) FindActions(reportID int) ([]*models.ReportAction, error) {
+	args := m.Called(reportID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.ReportAction), args.Error(1)
+}
+
+// AddAction simula registrar una acción
+func (m *MockReportRepository) AddAction(action *models.ReportAction) error {
+	args := m.Called(action)
+	return args.Error(0)
+}
+
+// Close simula cerrar los reportes abiertos sobre un contenido
+func (m *MockReportRepository) Close(report *models.Report, status string, action *models.ReportAction) error {
+	args := m.Called(report, status, action)
+	return args.Error(0)
+}
+
+// SetHidden simula ocultar o mostrar un post o comentario
+func (m *MockReportRepository) SetHidden(postID int, commentID int, hidden bool) error {
+	args := m.Called(postID, commentID, hidden)
+	return args.Error(0)
+}
@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"time"
+
+	"tp06-testing/internal/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockSuspensionRepository es un mock del SuspensionRepository
+type MockSuspensionRepository struct {
+	mock.Mock
+}
+
+// Create simula guardar una suspensión
+func (m *MockSuspensionRepository) Create(suspension *models.Suspension) error {
+	args := m.Called(suspension)
+	return args.Error(0)
+}
+
+// FindActive simula obtener la suspensión vigente de un usuario
+func (m *MockSuspensionRepository) FindActive(userID int, now time.Time) (*models.Suspension, error) {
+	args := m.Called(userID, now)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Suspension), args.Error(1)
+}
+
+// FindByUser simula obtener el historial de suspensiones de un usuario
+func (m *MockSuspensionRepository) FindByUser(userID int) ([]*models.Suspension, error) {
+	args := m.Called(userID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.Suspension), args.Error(1)
+}
+
+// Lift simula levantar las suspensiones vigentes de un usuario
+func (m *MockSuspensionRepository) Lift(userID int, moderatorID int, now time.Time) (bool, error) {
+	args := m.Called(userID, moderatorID, now)
+	return args.Bool(0), args.Error(1)
+}
@@ -39,3 +39,20 @@ func (m *MockUserRepository) FindByID(id int) (*models.User, error) {
 
 	return args.Get(0).(*models.User), args.Error(1)
 }
+
+// FindByUsername simula la búsqueda por nombre de usuario
+func (m *MockUserRepository) FindByUsername(username string) (*models.User, error) {
+	args := m.Called(username)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+// SetRole simula cambiar el rol de un usuario
+func (m *MockUserRepository) SetRole(userID int, role string) error {
+	args := m.Called(userID, role)
+	return args.Error(0)
+}
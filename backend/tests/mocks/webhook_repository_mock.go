@@ -0,0 +1,104 @@
+package mocks
+
+import (
+	"time"
+
+	"tp06-testing/internal/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// MockWebhookRepository es un mock del WebhookRepository
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+// Create simula guardar un webhook
+func (m *MockWebhookRepository) Create(webhook *models.Webhook) error {
+	args := m.Called(webhook)
+	return args.Error(0)
+}
+
+// FindByID simula buscar un webhook por ID
+func (m *MockWebhookRepository) FindByID(id int) (*models.Webhook, error) {
+	args := m.Called(id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Webhook), args.Error(1)
+}
+
+// FindByUser simula obtener los webhooks de un usuario
+func (m *MockWebhookRepository) FindByUser(userID int) ([]*models.Webhook, error) {
+	args := m.Called(userID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.Webhook), args.Error(1)
+}
+
+// FindSubscribed simula obtener los webhooks suscriptos a un evento
+func (m *MockWebhookRepository) FindSubscribed(eventType string) ([]*models.Webhook, error) {
+	args := m.Called(eventType)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.Webhook), args.Error(1)
+}
+
+// Delete simula eliminar un webhook
+func (m *MockWebhookRepository) Delete(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// CreateDelivery simula encolar una entrega
+func (m *MockWebhookRepository) CreateDelivery(delivery *models.WebhookDelivery) error {
+	args := m.Called(delivery)
+	return args.Error(0)
+}
+
+// FindDelivery simula buscar una entrega de un webhook
+func (m *MockWebhookRepository) FindDelivery(webhookID int, deliveryID int) (*models.WebhookDelivery, error) {
+	args := m.Called(webhookID, deliveryID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+// FindDeliveries simula obtener una página del registro de entregas
+func (m *MockWebhookRepository) FindDeliveries(webhookID int, after *models.Cursor, limit int) ([]*models.WebhookDelivery, error) {
+	args := m.Called(webhookID, after, limit)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.WebhookDelivery), args.Error(1)
+}
+
+// FindDueDeliveries simula obtener las entregas vencidas
+func (m *MockWebhookRepository) FindDueDeliveries(now time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	args := m.Called(now, limit)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.WebhookDelivery), args.Error(1)
+}
+
+// UpdateDelivery simula guardar el resultado de un intento
+func (m *MockWebhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	args := m.Called(delivery)
+	return args.Error(0)
+}
@@ -0,0 +1,244 @@
+package realtime
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"tp06-testing/internal/events"
+	"tp06-testing/internal/realtime"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hubFixture levanta un servidor que mete cada conexión en la sala de
+// ?post_id= con el usuario ?user_id=
+type hubFixture struct {
+	broker *events.Broker
+	hub    *realtime.Hub
+	server *httptest.Server
+}
+
+func newHubFixture(t *testing.T, config realtime.Config) *hubFixture {
+	f := &hubFixture{broker: events.NewBroker(10, 10)}
+	f.hub = realtime.NewHub(f.broker, config)
+
+	upgrader := websocket.Upgrader{}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		postID, _ := strconv.Atoi(r.URL.Query().Get("post_id"))
+		userID, _ := strconv.Atoi(r.URL.Query().Get("user_id"))
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		user := realtime.Typer{UserID: userID, Username: "user" + strconv.Itoa(userID)}
+		if err := f.hub.Join(postID, user, conn); err != nil {
+			conn.Close()
+		}
+	}))
+	t.Cleanup(func() {
+		f.hub.Shutdown()
+		f.server.Close()
+	})
+	return f
+}
+
+// connect abre un WebSocket y descarta la presencia inicial
+func (f *hubFixture) connect(t *testing.T, postID int, userID int) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(f.server.URL, "http") +
+		"?post_id=" + strconv.Itoa(postID) + "&user_id=" + strconv.Itoa(userID)
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	msg := readMessage(t, conn)
+	require.Equal(t, realtime.MessagePresence, msg.Type)
+	return conn
+}
+
+func readMessage(t *testing.T, conn *websocket.Conn) realtime.Message {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg realtime.Message
+	require.NoError(t, conn.ReadJSON(&msg))
+	return msg
+}
+
+// typingIDs devuelve los usuarios de un mensaje de presencia
+func typingIDs(t *testing.T, msg realtime.Message) []int {
+	var data struct {
+		Typing []realtime.Typer `json:"typing"`
+	}
+	require.NoError(t, json.Unmarshal(msg.Data, &data))
+	ids := []int{}
+	for _, typer := range data.Typing {
+		ids = append(ids, typer.UserID)
+	}
+	return ids
+}
+
+// waitFor espera hasta que la condición se cumpla
+func waitFor(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("la condición no se cumplió a tiempo")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestHub_ForwardsCommentEvents: la sala recibe solo los eventos de su post
+func TestHub_ForwardsCommentEvents(t *testing.T) {
+	// ARRANGE
+	f := newHubFixture(t, realtime.Config{})
+	conn := f.connect(t, 1, 10)
+
+	// ACT
+	assert.NoError(t, f.broker.Publish(events.CommentCreated, 2, map[string]int{"id": 5}))
+	assert.NoError(t, f.broker.Publish(events.CommentCreated, 1, map[string]int{"id": 6, "user_id": 11}))
+
+	// ASSERT
+	msg := readMessage(t, conn)
+	assert.Equal(t, events.CommentCreated, msg.Type)
+	assert.JSONEq(t, `{"id": 6, "user_id": 11}`, string(msg.Data))
+}
+
+// TestHub_TypingBroadcastAndStop: los avisos de escritura llegan al resto de la sala
+func TestHub_TypingBroadcastAndStop(t *testing.T) {
+	// ARRANGE
+	f := newHubFixture(t, realtime.Config{})
+	ana := f.connect(t, 1, 10)
+	bob := f.connect(t, 1, 11)
+
+	// ACT
+	require.NoError(t, bob.WriteJSON(map[string]string{"type": realtime.MessageTyping}))
+	started := readMessage(t, ana)
+	require.NoError(t, bob.WriteJSON(map[string]string{"type": realtime.MessageStopTyping}))
+	stopped := readMessage(t, ana)
+
+	// ASSERT
+	assert.Equal(t, realtime.MessagePresence, started.Type)
+	assert.Equal(t, []int{11}, typingIDs(t, started))
+	assert.Contains(t, string(started.Data), `"username":"user11"`)
+	assert.Empty(t, typingIDs(t, stopped))
+}
+
+// TestHub_TypingExpires: si no vuelve a avisar, deja de figurar como escribiendo
+func TestHub_TypingExpires(t *testing.T) {
+	// ARRANGE
+	f := newHubFixture(t, realtime.Config{TypingTTL: 100 * time.Millisecond})
+	ana := f.connect(t, 1, 10)
+	bob := f.connect(t, 1, 11)
+	require.NoError(t, bob.WriteJSON(map[string]string{"type": realtime.MessageTyping}))
+	require.Equal(t, []int{11}, typingIDs(t, readMessage(t, ana)))
+
+	// ACT
+	expired := readMessage(t, ana)
+
+	// ASSERT
+	assert.Empty(t, typingIDs(t, expired))
+}
+
+// TestHub_CommentClearsTyping: al publicar el comentario deja de estar escribiendo
+func TestHub_CommentClearsTyping(t *testing.T) {
+	// ARRANGE
+	f := newHubFixture(t, realtime.Config{})
+	ana := f.connect(t, 1, 10)
+	bob := f.connect(t, 1, 11)
+	require.NoError(t, bob.WriteJSON(map[string]string{"type": realtime.MessageTyping}))
+	require.Equal(t, []int{11}, typingIDs(t, readMessage(t, ana)))
+
+	// ACT
+	assert.NoError(t, f.broker.Publish(events.CommentCreated, 1, map[string]int{"id": 6, "user_id": 11}))
+
+	// ASSERT
+	assert.Equal(t, events.CommentCreated, readMessage(t, ana).Type)
+	presence := readMessage(t, ana)
+	assert.Equal(t, realtime.MessagePresence, presence.Type)
+	assert.Empty(t, typingIDs(t, presence))
+}
+
+// TestHub_RoomClosesWhenEmpty: la sala termina cuando se va el último cliente
+func TestHub_RoomClosesWhenEmpty(t *testing.T) {
+	// ARRANGE
+	f := newHubFixture(t, realtime.Config{})
+	conn := f.connect(t, 1, 10)
+	require.Equal(t, 1, f.hub.RoomCount())
+
+	// ACT
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	conn.Close()
+
+	// ASSERT
+	waitFor(t, func() bool { return f.hub.RoomCount() == 0 })
+	waitFor(t, func() bool { return f.broker.SubscriberCount() == 0 })
+}
+
+// TestHub_ShutdownClosesConnections: al apagarse avisa a los clientes con 1001
+func TestHub_ShutdownClosesConnections(t *testing.T) {
+	// ARRANGE
+	f := newHubFixture(t, realtime.Config{})
+	conn := f.connect(t, 1, 10)
+
+	// ACT
+	f.hub.Shutdown()
+
+	// ASSERT
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway))
+	assert.Equal(t, 0, f.broker.SubscriberCount())
+}
+
+// TestHub_PublishReachesOnlyTheRoom: los eventos de un post que no es
+// público llegan a su sala sin pasar por el broker
+func TestHub_PublishReachesOnlyTheRoom(t *testing.T) {
+	// ARRANGE
+	f := newHubFixture(t, realtime.Config{})
+	conn := f.connect(t, 1, 10)
+	other := f.connect(t, 2, 11)
+	sub, _, _ := f.broker.Subscribe(nil, 0)
+	defer sub.Close()
+
+	// ACT
+	assert.NoError(t, f.hub.Publish(events.CommentCreated, 1, map[string]int{"id": 6, "user_id": 11}))
+	assert.NoError(t, f.hub.Publish(events.CommentCreated, 3, map[string]int{"id": 7}))
+
+	// ASSERT
+	msg := readMessage(t, conn)
+	assert.Equal(t, events.CommentCreated, msg.Type)
+	assert.JSONEq(t, `{"id": 6, "user_id": 11}`, string(msg.Data))
+	other.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	_, _, err := other.ReadMessage()
+	assert.Error(t, err)
+	assert.Len(t, sub.C, 0)
+}
+
+// TestHub_DisconnectClosesUserConnections: se cierran todas las conexiones
+// del usuario, en cualquier sala, y el resto sigue conectado
+func TestHub_DisconnectClosesUserConnections(t *testing.T) {
+	// ARRANGE
+	f := newHubFixture(t, realtime.Config{})
+	first := f.connect(t, 1, 10)
+	second := f.connect(t, 2, 10)
+	other := f.connect(t, 1, 11)
+
+	// ACT
+	f.hub.Disconnect(10)
+
+	// ASSERT
+	for _, conn := range []*websocket.Conn{first, second} {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, _, err := conn.ReadMessage()
+		assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation))
+	}
+	waitFor(t, func() bool { return f.hub.RoomCount() == 1 })
+	assert.NoError(t, f.broker.Publish(events.CommentCreated, 1, map[string]int{"id": 6}))
+	assert.Equal(t, events.CommentCreated, readMessage(t, other).Type)
+}
@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tp06-testing/internal/database"
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/repository"
+	"tp06-testing/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDB crea una base SQLite temporal con el schema completo, un
+// usuario (ID 1) y un post suyo (ID 1)
+func newTestDB(t *testing.T) (*sql.DB, *repository.SQLitePostRepository) {
+	t.Helper()
+
+	db, err := database.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	require.NoError(t, repository.NewSQLiteUserRepository(db).Create(&models.User{Email: "ana@example.com", Password: "x", Username: "ana"}))
+	postRepo := repository.NewSQLitePostRepository(db)
+	require.NoError(t, postRepo.Create(&models.Post{Title: "Hola", Content: "texto", Slug: "hola", Status: models.PostStatusPublished, Visibility: models.PostVisibilityPublic, UserID: 1}))
+
+	return db, postRepo
+}
+
+// createComment agrega un comentario del usuario 1 al post 1 con la fecha
+// indicada (la de la base tiene resolución de segundos)
+func createComment(t *testing.T, db *sql.DB, postRepo *repository.SQLitePostRepository, createdAt time.Time) int {
+	t.Helper()
+
+	comment := &models.Comment{PostID: 1, UserID: 1, Content: "comentario"}
+	require.NoError(t, postRepo.CreateComment(comment))
+	_, err := db.Exec(`UPDATE comments SET created_at = ? WHERE id = ?`, createdAt.UTC().Format("2006-01-02 15:04:05"), comment.ID)
+	require.NoError(t, err)
+	return comment.ID
+}
+
+// findPost lee el post 1 con sus contadores
+func findPost(t *testing.T, postRepo *repository.SQLitePostRepository) *models.Post {
+	t.Helper()
+
+	post, err := postRepo.FindByID(1)
+	require.NoError(t, err)
+	require.NotNil(t, post)
+	return post
+}
+
+// TestCreateComment_UpdatesCounters: cada comentario suma al contador del
+// post y actualiza la fecha del último; uno retenido por los filtros no
+func TestCreateComment_UpdatesCounters(t *testing.T) {
+	// ARRANGE
+	_, postRepo := newTestDB(t)
+	hiddenAt := time.Now()
+
+	// ACT
+	require.NoError(t, postRepo.CreateComment(&models.Comment{PostID: 1, UserID: 1, Content: "uno"}))
+	require.NoError(t, postRepo.CreateComment(&models.Comment{PostID: 1, UserID: 1, Content: "dos"}))
+	require.NoError(t, postRepo.CreateComment(&models.Comment{PostID: 1, UserID: 1, Content: "retenido", HiddenAt: &hiddenAt}))
+
+	// ASSERT
+	post := findPost(t, postRepo)
+	assert.Equal(t, 2, post.CommentCount)
+	assert.NotNil(t, post.LastCommentAt)
+}
+
+// TestCreateComment_UsesClock: el comentario y el último comentario del
+// post se fechan con el reloj del repositorio
+func TestCreateComment_UsesClock(t *testing.T) {
+	// ARRANGE
+	_, postRepo := newTestDB(t)
+	clock := &mocks.FakeClock{Current: time.Date(2024, 5, 10, 12, 30, 15, 0, time.UTC)}
+	postRepo.SetClock(clock)
+	comment := &models.Comment{PostID: 1, UserID: 1, Content: "comentario"}
+
+	// ACT
+	err := postRepo.CreateComment(comment)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.True(t, clock.Current.Equal(comment.CreatedAt))
+	comments, err := postRepo.FindCommentsByPostID(1, 0)
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.True(t, clock.Current.Equal(comments[0].CreatedAt))
+	post := findPost(t, postRepo)
+	require.NotNil(t, post.LastCommentAt)
+	assert.True(t, clock.Current.Equal(*post.LastCommentAt))
+}
+
+// TestDeleteComment_UpdatesCounters: borrar el último comentario resta uno
+// y la fecha pasa a ser la del anterior; borrar todos deja el post sin fecha
+func TestDeleteComment_UpdatesCounters(t *testing.T) {
+	// ARRANGE
+	db, postRepo := newTestDB(t)
+	first := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	second := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	firstID := createComment(t, db, postRepo, first)
+	secondID := createComment(t, db, postRepo, second)
+
+	// ACT
+	errSecond := postRepo.DeleteComment(1, secondID, 1, 1)
+	afterSecond := findPost(t, postRepo)
+	errFirst := postRepo.DeleteComment(1, firstID, 1, 1)
+	afterFirst := findPost(t, postRepo)
+
+	// ASSERT
+	assert.NoError(t, errSecond)
+	assert.Equal(t, 1, afterSecond.CommentCount)
+	require.NotNil(t, afterSecond.LastCommentAt)
+	assert.True(t, first.Equal(*afterSecond.LastCommentAt))
+
+	assert.NoError(t, errFirst)
+	assert.Equal(t, 0, afterFirst.CommentCount)
+	assert.Nil(t, afterFirst.LastCommentAt)
+}
+
+// TestDeleteComment_NotFoundKeepsCounters: borrar un comentario que no
+// existe, de otro post, de otro usuario o ya borrado no toca los contadores
+func TestDeleteComment_NotFoundKeepsCounters(t *testing.T) {
+	// ARRANGE
+	db, postRepo := newTestDB(t)
+	commentID := createComment(t, db, postRepo, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	require.NoError(t, postRepo.DeleteComment(1, commentID, 1, 1))
+	kept := createComment(t, db, postRepo, time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC))
+	before := findPost(t, postRepo)
+
+	// ACT
+	errMissing := postRepo.DeleteComment(1, 999, 1, 1)
+	errOtherPost := postRepo.DeleteComment(2, kept, 1, 1)
+	errOtherUser := postRepo.DeleteComment(1, kept, 2, 2)
+	errDeleted := postRepo.DeleteComment(1, commentID, 1, 1)
+
+	// ASSERT
+	assert.Error(t, errMissing)
+	assert.Error(t, errOtherPost)
+	assert.Error(t, errOtherUser)
+	assert.Error(t, errDeleted)
+
+	after := findPost(t, postRepo)
+	assert.Equal(t, 1, after.CommentCount)
+	assert.Equal(t, before.CommentCount, after.CommentCount)
+	assert.Equal(t, before.LastCommentAt, after.LastCommentAt)
+}
+
+// TestRecountComments_FixesCorruptedCounters: el comando de reparación
+// recalcula los contadores desactualizados y solo informa los que cambió
+func TestRecountComments_FixesCorruptedCounters(t *testing.T) {
+	// ARRANGE
+	db, postRepo := newTestDB(t)
+	require.NoError(t, postRepo.Create(&models.Post{Title: "Sin comentarios", Content: "texto", Slug: "sin-comentarios", Status: models.PostStatusPublished, Visibility: models.PostVisibilityPublic, UserID: 1}))
+	last := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	createComment(t, db, postRepo, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	createComment(t, db, postRepo, last)
+
+	_, err := db.Exec(`UPDATE posts SET comment_count = 7, last_comment_at = NULL WHERE id = 1`)
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE posts SET comment_count = -3, last_comment_at = '2020-01-01 00:00:00' WHERE id = 2`)
+	require.NoError(t, err)
+
+	// ACT
+	fixed, err := postRepo.RecountComments()
+	again, errAgain := postRepo.RecountComments()
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fixed)
+	assert.NoError(t, errAgain)
+	assert.Equal(t, 0, again)
+
+	post := findPost(t, postRepo)
+	assert.Equal(t, 2, post.CommentCount)
+	require.NotNil(t, post.LastCommentAt)
+	assert.True(t, last.Equal(*post.LastCommentAt))
+
+	empty, err := postRepo.FindByID(2)
+	require.NoError(t, err)
+	assert.Equal(t, 0, empty.CommentCount)
+	assert.Nil(t, empty.LastCommentAt)
+}
+
+// TestInitDB_BackfillsCountersLikeRecount: al agregar los contadores a una
+// base existente no se cuentan los comentarios ocultos ni borrados
+func TestInitDB_BackfillsCountersLikeRecount(t *testing.T) {
+	// ARRANGE
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := database.InitDB(path)
+	require.NoError(t, err)
+	require.NoError(t, repository.NewSQLiteUserRepository(db).Create(&models.User{Email: "ana@example.com", Password: "x", Username: "ana"}))
+	postRepo := repository.NewSQLitePostRepository(db)
+	require.NoError(t, postRepo.Create(&models.Post{Title: "Hola", Content: "texto", Slug: "hola", Status: models.PostStatusPublished, Visibility: models.PostVisibilityPublic, UserID: 1}))
+
+	visible := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	createComment(t, db, postRepo, visible)
+	hidden := createComment(t, db, postRepo, time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC))
+	deleted := createComment(t, db, postRepo, time.Date(2024, 5, 3, 10, 0, 0, 0, time.UTC))
+	_, err = db.Exec(`UPDATE comments SET hidden_at = CURRENT_TIMESTAMP WHERE id = ?`, hidden)
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE comments SET deleted_at = CURRENT_TIMESTAMP WHERE id = ?`, deleted)
+	require.NoError(t, err)
+
+	// Base de antes de los contadores
+	_, err = db.Exec(`ALTER TABLE posts DROP COLUMN comment_count`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	// ACT
+	db, err = database.InitDB(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	post, err := repository.NewSQLitePostRepository(db).FindByID(1)
+
+	// ASSERT
+	require.NoError(t, err)
+	assert.Equal(t, 1, post.CommentCount)
+	require.NotNil(t, post.LastCommentAt)
+	assert.True(t, visible.Equal(*post.LastCommentAt))
+}
+
+// TestCreateAndUpdate_SlugTaken: el índice único de slug se informa como
+// ErrSlugTaken para que el servicio pueda reintentar con otro sufijo
+func TestCreateAndUpdate_SlugTaken(t *testing.T) {
+	// ARRANGE
+	_, postRepo := newTestDB(t)
+	other := &models.Post{Title: "Otro", Content: "texto", Slug: "otro", Status: models.PostStatusPublished, Visibility: models.PostVisibilityPublic, UserID: 1}
+	require.NoError(t, postRepo.Create(other))
+
+	// ACT
+	errCreate := postRepo.Create(&models.Post{Title: "Hola", Content: "texto", Slug: "hola", Status: models.PostStatusPublished, Visibility: models.PostVisibilityPublic, UserID: 1})
+	other.Slug = "hola"
+	errUpdate := postRepo.Update(other, "otro")
+
+	// ASSERT
+	assert.EqualError(t, errCreate, repository.ErrSlugTaken)
+	assert.EqualError(t, errUpdate, repository.ErrSlugTaken)
+
+	kept, err := postRepo.FindByID(other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "otro", kept.Slug)
+}
+
+// TestFindPublicByUser_OnlyPublicPostsOfUser: el outbox solo lee los posts
+// publicados y públicos del usuario, los más nuevos primero y paginados
+func TestFindPublicByUser_OnlyPublicPostsOfUser(t *testing.T) {
+	// ARRANGE
+	db, postRepo := newTestDB(t)
+	require.NoError(t, repository.NewSQLiteUserRepository(db).Create(&models.User{Email: "beto@example.com", Password: "x", Username: "beto"}))
+
+	day := func(d int) *time.Time {
+		at := time.Date(2024, 5, d, 10, 0, 0, 0, time.UTC)
+		return &at
+	}
+	newPost := func(slug string, userID int, status string, visibility string, publishedAt *time.Time, hiddenAt *time.Time) *models.Post {
+		post := &models.Post{Title: slug, Content: "texto", Slug: slug, Status: status, Visibility: visibility, PublishedAt: publishedAt, UserID: userID, HiddenAt: hiddenAt}
+		require.NoError(t, postRepo.Create(post))
+		return post
+	}
+
+	older := newPost("viejo", 1, models.PostStatusPublished, models.PostVisibilityPublic, day(1), nil)
+	newer := newPost("nuevo", 1, models.PostStatusPublished, models.PostVisibilityPublic, day(3), nil)
+	newPost("de-beto", 2, models.PostStatusPublished, models.PostVisibilityPublic, day(4), nil)
+	newPost("borrador", 1, models.PostStatusDraft, models.PostVisibilityPublic, nil, nil)
+	newPost("privado", 1, models.PostStatusPublished, models.PostVisibilityPrivate, day(4), nil)
+	newPost("no-listado", 1, models.PostStatusPublished, models.PostVisibilityUnlisted, day(4), nil)
+	newPost("oculto", 1, models.PostStatusPublished, models.PostVisibilityPublic, day(4), day(4))
+	trashed := newPost("en-papelera", 1, models.PostStatusPublished, models.PostVisibilityPublic, day(4), nil)
+	require.NoError(t, postRepo.Delete(trashed.ID, 1))
+	_, err := db.Exec(`UPDATE posts SET published_at = ? WHERE id = 1`, day(2).Format("2006-01-02 15:04:05"))
+	require.NoError(t, err)
+
+	// ACT
+	count, errCount := postRepo.CountPublicByUser(1)
+	first, errFirst := postRepo.FindPublicByUser(1, 0, 2)
+	second, errSecond := postRepo.FindPublicByUser(1, 2, 2)
+
+	// ASSERT
+	assert.NoError(t, errCount)
+	assert.Equal(t, 3, count)
+
+	assert.NoError(t, errFirst)
+	require.Len(t, first, 2)
+	assert.Equal(t, newer.ID, first[0].ID)
+	assert.Equal(t, 1, first[1].ID)
+
+	assert.NoError(t, errSecond)
+	require.Len(t, second, 1)
+	assert.Equal(t, older.ID, second[0].ID)
+}
+
+// TestFindLatestPublic_LimitsAndSkipsNonPublic: el feed global lee solo los
+// últimos posts públicos de todos los autores, hasta el límite
+func TestFindLatestPublic_LimitsAndSkipsNonPublic(t *testing.T) {
+	// ARRANGE
+	db, postRepo := newTestDB(t)
+	require.NoError(t, repository.NewSQLiteUserRepository(db).Create(&models.User{Email: "beto@example.com", Password: "x", Username: "beto"}))
+
+	day := func(d int) *time.Time {
+		at := time.Date(2024, 5, d, 10, 0, 0, 0, time.UTC)
+		return &at
+	}
+	newPost := func(slug string, userID int, visibility string, publishedAt *time.Time) *models.Post {
+		post := &models.Post{Title: slug, Content: "texto", Slug: slug, Status: models.PostStatusPublished, Visibility: visibility, PublishedAt: publishedAt, UserID: userID}
+		require.NoError(t, postRepo.Create(post))
+		return post
+	}
+
+	newPost("viejo", 1, models.PostVisibilityPublic, day(1))
+	middle := newPost("de-beto", 2, models.PostVisibilityPublic, day(3))
+	newest := newPost("nuevo", 1, models.PostVisibilityPublic, day(4))
+	newPost("privado", 1, models.PostVisibilityPrivate, day(5))
+	newPost("de-seguidores", 2, models.PostVisibilityFollowers, day(5))
+	newPost("no-listado", 2, models.PostVisibilityUnlisted, day(5))
+
+	// ACT
+	posts, err := postRepo.FindLatestPublic(2)
+
+	// ASSERT
+	assert.NoError(t, err)
+	require.Len(t, posts, 2)
+	assert.Equal(t, newest.ID, posts[0].ID)
+	assert.Equal(t, middle.ID, posts[1].ID)
+	assert.Equal(t, "beto", posts[1].Username)
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+
+	"tp06-testing/internal/database"
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCreateUser_UsernameTaken: el nombre de usuario es único sin
+// distinguir mayúsculas, para que una mención lleve a una sola persona
+func TestCreateUser_UsernameTaken(t *testing.T) {
+	// ARRANGE
+	db, _ := newTestDB(t)
+	userRepo := repository.NewSQLiteUserRepository(db)
+
+	// ACT
+	err := userRepo.Create(&models.User{Email: "otra@example.com", Password: "x", Username: "ANA"})
+
+	// ASSERT
+	assert.EqualError(t, err, repository.ErrUsernameTaken)
+}
+
+// TestInitDB_RenamesDuplicateUsernames: en una base con nombres repetidos
+// el más antiguo conserva el suyo y los demás reciben el ID como sufijo
+func TestInitDB_RenamesDuplicateUsernames(t *testing.T) {
+	// ARRANGE
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := database.InitDB(path)
+	require.NoError(t, err)
+	_, err = db.Exec(`DROP INDEX idx_users_username`)
+	require.NoError(t, err)
+	userRepo := repository.NewSQLiteUserRepository(db)
+	for _, email := range []string{"ana@example.com", "ana2@example.com", "ana3@example.com"} {
+		require.NoError(t, userRepo.Create(&models.User{Email: email, Password: "x", Username: "Ana"}))
+	}
+	_, err = db.Exec(`UPDATE users SET username = 'ana' WHERE id = 3`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	// ACT
+	db, err = database.InitDB(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	userRepo = repository.NewSQLiteUserRepository(db)
+	found, errFind := userRepo.FindByUsername("ANA")
+
+	// ASSERT
+	assert.NoError(t, errFind)
+	require.NotNil(t, found)
+	assert.Equal(t, 1, found.ID)
+	for id, username := range map[int]string{2: "Ana_2", 3: "ana_3"} {
+		user, err := userRepo.FindByID(id)
+		require.NoError(t, err)
+		assert.Equal(t, username, user.Username)
+	}
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"tp06-testing/internal/imaging"
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+	"tp06-testing/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testPNG codifica un PNG real de las dimensiones indicadas
+func testPNG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// bombPNG es solo el encabezado de un PNG que declara 50000x50000 píxeles:
+// pesa unos bytes pero decodificarlo requeriría gigas de memoria
+func bombPNG() []byte {
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], 50000)
+	binary.BigEndian.PutUint32(ihdr[8:], 50000)
+	ihdr[12], ihdr[13] = 8, 6 // 8 bits, RGBA
+
+	data := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0d")
+	data = append(data, ihdr...)
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(ihdr))
+}
+
+func newAttachmentServiceWithMocks() (*services.AttachmentService, *mocks.MockAttachmentRepository, *mocks.MockPostRepository, *mocks.MockUserRepository, *mocks.MockBlobStore) {
+	attachmentRepo := new(mocks.MockAttachmentRepository)
+	postRepo := new(mocks.MockPostRepository)
+	userRepo := new(mocks.MockUserRepository)
+	store := new(mocks.MockBlobStore)
+	return services.NewAttachmentService(attachmentRepo, postRepo, userRepo, store), attachmentRepo, postRepo, userRepo, store
+}
+
+// TestUpload_Success: un PNG se guarda con el tipo detectado por contenido
+func TestUpload_Success(t *testing.T) {
+	// ARRANGE
+	attachmentService, attachmentRepo, postRepo, userRepo, store := newAttachmentServiceWithMocks()
+	content := testPNG(t, 40, 30)
+
+	userRepo.On("FindByID", 1).Return(&models.User{ID: 1, Username: "testuser"}, nil)
+	postRepo.On("FindByID", 5).Return(&models.Post{ID: 5, UserID: 1}, nil)
+	store.On("Put", mock.AnythingOfType("string"), int64(len(content)), "image/png").Return(nil)
+	attachmentRepo.On("Create", mock.AnythingOfType("*models.Attachment")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Attachment).ID = 9
+	})
+
+	// ACT
+	attachment, err := attachmentService.Upload(context.Background(), 1, 5, `C:\fotos\foto.png`, int64(len(content)), bytes.NewReader(content))
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", attachment.ContentType)
+	assert.Equal(t, "foto.png", attachment.Filename)
+	assert.Equal(t, 5, *attachment.PostID)
+	assert.Equal(t, "/api/attachments/9", attachment.URL)
+	assert.True(t, strings.HasPrefix(attachment.StorageKey, "attachments/"))
+	assert.True(t, strings.HasSuffix(attachment.StorageKey, ".png"))
+	assert.Equal(t, content, store.Stored[attachment.StorageKey])
+	assert.Equal(t, 40, attachment.Width)
+	assert.Equal(t, 30, attachment.Height)
+	assert.Equal(t, models.AttachmentProcessingPending, attachment.ProcessingStatus)
+	attachmentRepo.AssertExpectations(t)
+	store.AssertExpectations(t)
+}
+
+// TestUpload_RejectsDisallowedType: el tipo se detecta por contenido, no por la extensión
+func TestUpload_RejectsDisallowedType(t *testing.T) {
+	// ARRANGE
+	attachmentService, attachmentRepo, _, userRepo, store := newAttachmentServiceWithMocks()
+	userRepo.On("FindByID", 1).Return(&models.User{ID: 1}, nil)
+	content := []byte("<html><script>alert(1)</script></html>")
+
+	// ACT
+	attachment, err := attachmentService.Upload(context.Background(), 1, 0, "foto.png", int64(len(content)), bytes.NewReader(content))
+
+	// ASSERT
+	assert.Error(t, err)
+	assert.Nil(t, attachment)
+	assert.Contains(t, err.Error(), "tipo de archivo no permitido")
+	store.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything)
+	attachmentRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// TestUpload_TooLarge: se rechaza antes de leer el contenido
+func TestUpload_TooLarge(t *testing.T) {
+	// ARRANGE
+	attachmentService, _, _, userRepo, _ := newAttachmentServiceWithMocks()
+
+	// ACT
+	attachment, err := attachmentService.Upload(context.Background(), 1, 0, "grande.png", services.MaxUploadSize+1, bytes.NewReader(testPNG(t, 1, 1)))
+
+	// ASSERT
+	assert.Nil(t, attachment)
+	assert.EqualError(t, err, services.ErrFileTooLarge)
+	userRepo.AssertNotCalled(t, "FindByID", mock.Anything)
+}
+
+// TestUpload_NotPostOwner: no se puede adjuntar a un post ajeno
+func TestUpload_NotPostOwner(t *testing.T) {
+	// ARRANGE
+	attachmentService, _, postRepo, userRepo, store := newAttachmentServiceWithMocks()
+	userRepo.On("FindByID", 2).Return(&models.User{ID: 2}, nil)
+	postRepo.On("FindByID", 5).Return(&models.Post{ID: 5, UserID: 1}, nil)
+	content := testPNG(t, 1, 1)
+
+	// ACT
+	attachment, err := attachmentService.Upload(context.Background(), 2, 5, "foto.png", int64(len(content)), bytes.NewReader(content))
+
+	// ASSERT
+	assert.Error(t, err)
+	assert.Nil(t, attachment)
+	store.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestUpload_RejectsDecompressionBomb: se rechaza por las dimensiones declaradas, sin decodificar
+func TestUpload_RejectsDecompressionBomb(t *testing.T) {
+	// ARRANGE
+	attachmentService, attachmentRepo, _, userRepo, store := newAttachmentServiceWithMocks()
+	userRepo.On("FindByID", 1).Return(&models.User{ID: 1}, nil)
+	content := bombPNG()
+
+	// ACT
+	attachment, err := attachmentService.Upload(context.Background(), 1, 0, "bomba.png", int64(len(content)), bytes.NewReader(content))
+
+	// ASSERT
+	assert.Nil(t, attachment)
+	assert.EqualError(t, err, imaging.ErrTooManyPixels.Error())
+	store.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything)
+	attachmentRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// TestOpen_DraftPostHiddenFromOthers: los adjuntos siguen la visibilidad del post
+func TestOpen_DraftPostHiddenFromOthers(t *testing.T) {
+	// ARRANGE
+	attachmentService, attachmentRepo, postRepo, _, store := newAttachmentServiceWithMocks()
+	postID := 5
+	attachmentRepo.On("FindByID", 9).Return(&models.Attachment{ID: 9, PostID: &postID, UserID: 1, StorageKey: "attachments/x.png"}, nil)
+	postRepo.On("FindByID", 5).Return(&models.Post{ID: 5, UserID: 1, Status: models.PostStatusDraft}, nil)
+
+	// ACT
+	attachment, content, err := attachmentService.Open(context.Background(), 9, 2)
+
+	// ASSERT
+	assert.EqualError(t, err, services.ErrAttachmentNotFound)
+	assert.Nil(t, attachment)
+	assert.Nil(t, content)
+	store.AssertNotCalled(t, "Open", mock.Anything)
+}
+
+// TestDeleteAttachment_RemovesBlob: se borra el registro y el archivo
+func TestDeleteAttachment_RemovesBlob(t *testing.T) {
+	// ARRANGE
+	attachmentService, attachmentRepo, _, _, store := newAttachmentServiceWithMocks()
+	attachmentRepo.On("FindByID", 9).Return(&models.Attachment{ID: 9, UserID: 1, StorageKey: "attachments/x.png"}, nil)
+	attachmentRepo.On("Delete", 9).Return(nil)
+	store.On("Delete", "attachments/x.png").Return(nil)
+
+	// ACT
+	err := attachmentService.Delete(context.Background(), 9, 1)
+
+	// ASSERT
+	assert.NoError(t, err)
+	attachmentRepo.AssertExpectations(t)
+	store.AssertExpectations(t)
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"testing"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+	"tp06-testing/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestBlockService_Block: bloquear devuelve el estado nuevo; no se puede
+// bloquear a uno mismo ni a un usuario inexistente
+func TestBlockService_Block(t *testing.T) {
+	// ARRANGE
+	blockRepo := new(mocks.MockBlockRepository)
+	userRepo := new(mocks.MockUserRepository)
+	blockService := services.NewBlockService(blockRepo, userRepo)
+
+	userRepo.On("FindByID", 2).Return(&models.User{ID: 2, Username: "beto"}, nil)
+	userRepo.On("FindByID", 99).Return(nil, nil)
+	blockRepo.On("Block", 1, 2).Return(nil).Once()
+	blockRepo.On("FindStatus", 1, 2).Return(&models.BlockStatus{UserID: 2, Blocked: true}, nil)
+
+	// ACT
+	status, err := blockService.Block(1, 2)
+	_, selfErr := blockService.Block(1, 1)
+	_, missingErr := blockService.Mute(1, 99)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.True(t, status.Blocked)
+	assert.EqualError(t, selfErr, services.ErrCannotBlockSelf)
+	assert.EqualError(t, missingErr, services.ErrUserNotFound)
+	blockRepo.AssertExpectations(t)
+	blockRepo.AssertNotCalled(t, "Mute", mock.Anything, mock.Anything)
+}
+
+// TestCreateComment_BlockedByAuthor: un usuario bloqueado no puede
+// comentar los posts de quien lo bloqueó
+func TestCreateComment_BlockedByAuthor(t *testing.T) {
+	// ARRANGE
+	postRepo := new(mocks.MockPostRepository)
+	userRepo := new(mocks.MockUserRepository)
+	blockRepo := new(mocks.MockBlockRepository)
+	postService := services.NewPostService(postRepo, userRepo)
+	postService.SetBlockRepository(blockRepo)
+
+	postRepo.On("FindByID", 1).Return(&models.Post{ID: 1, UserID: 1, Status: models.PostStatusPublished}, nil)
+	userRepo.On("FindByID", 2).Return(&models.User{ID: 2, Username: "beto"}, nil)
+	blockRepo.On("FindBlockers", []int{1}, 2).Return(map[int]bool{1: true}, nil)
+
+	// ACT
+	comment, err := postService.CreateComment(1, &models.CreateCommentRequest{Content: "Hola"}, 2)
+
+	// ASSERT
+	assert.Nil(t, comment)
+	assert.EqualError(t, err, services.ErrBlockedByAuthor)
+	postRepo.AssertNotCalled(t, "CreateComment", mock.Anything)
+}
+
+// TestCreateComment_MentionOfBlockerDropped: mencionar a quien bloqueó al
+// autor no crea la mención ni la notificación
+func TestCreateComment_MentionOfBlockerDropped(t *testing.T) {
+	// ARRANGE
+	f := newMentionFixture()
+	blockRepo := new(mocks.MockBlockRepository)
+	f.postService.SetBlockRepository(blockRepo)
+
+	// beto (2) comenta en un post de ana (1); carla (3) lo bloqueó
+	f.userRepo.On("FindByID", 2).Return(&models.User{ID: 2, Username: "beto"}, nil)
+	f.postRepo.On("FindByID", 10).Return(&models.Post{ID: 10, UserID: 1, Status: models.PostStatusPublished}, nil)
+	f.postRepo.On("CreateComment", mock.AnythingOfType("*models.Comment")).Return(nil)
+	f.postRepo.On("FindCommenterIDs", 10).Return([]int{}, nil)
+	f.mentionRepo.On("SaveCommentMentions", 10, mock.Anything, mock.Anything).Return(nil)
+	blockRepo.On("FindBlockers", []int{1}, 2).Return(map[int]bool{}, nil)
+	blockRepo.On("FindBlockers", []int{3, 1}, 2).Return(map[int]bool{3: true}, nil)
+
+	// ACT
+	comment, err := f.postService.CreateComment(10, &models.CreateCommentRequest{Content: "@carla @ana mirá esto"}, 2)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Mention{{UserID: 1, Username: "ana", Offset: 7, Length: 4}}, comment.Mentions)
+	assert.Nil(t, notificationFor(f.notificationRepo, 3))
+}
+
+// TestGetCommentsByPostID_FiltersForViewer: los comentarios se piden para
+// el viewer, así el repositorio omite a sus bloqueados y silenciados
+func TestGetCommentsByPostID_FiltersForViewer(t *testing.T) {
+	// ARRANGE
+	postRepo := new(mocks.MockPostRepository)
+	postService := services.NewPostService(postRepo, new(mocks.MockUserRepository))
+
+	postRepo.On("FindByID", 1).Return(&models.Post{ID: 1, UserID: 1, Status: models.PostStatusPublished}, nil)
+	postRepo.On("FindCommentsByPostID", 1, 7).Return([]*models.Comment{{ID: 3, PostID: 1, UserID: 2}}, nil)
+
+	// ACT
+	comments, err := postService.GetCommentsByPostID(1, 7, services.SortNew)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Len(t, comments, 1)
+	postRepo.AssertExpectations(t)
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+	"tp06-testing/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newPostServiceWithBookmarks() (*services.PostService, *mocks.MockPostRepository, *mocks.MockUserRepository, *mocks.MockBookmarkRepository) {
+	postRepo := new(mocks.MockPostRepository)
+	userRepo := new(mocks.MockUserRepository)
+	bookmarkRepo := new(mocks.MockBookmarkRepository)
+	postService := services.NewPostService(postRepo, userRepo)
+	postService.SetBookmarkRepository(bookmarkRepo)
+	return postService, postRepo, userRepo, bookmarkRepo
+}
+
+// TestBookmarkPost_Success: guarda un post publicado
+func TestBookmarkPost_Success(t *testing.T) {
+	// ARRANGE
+	postService, postRepo, userRepo, bookmarkRepo := newPostServiceWithBookmarks()
+	userRepo.On("FindByID", 2).Return(&models.User{ID: 2}, nil)
+	postRepo.On("FindByID", 1).Return(&models.Post{ID: 1, UserID: 1, Status: models.PostStatusPublished}, nil)
+	bookmarkRepo.On("Add", 2, 1).Return(nil)
+
+	// ACT
+	err := postService.BookmarkPost(1, 2)
+
+	// ASSERT
+	assert.NoError(t, err)
+	bookmarkRepo.AssertExpectations(t)
+}
+
+// TestBookmarkPost_DraftOfAnotherUser: no se pueden guardar posts que no se ven
+func TestBookmarkPost_DraftOfAnotherUser(t *testing.T) {
+	// ARRANGE
+	postService, postRepo, userRepo, bookmarkRepo := newPostServiceWithBookmarks()
+	userRepo.On("FindByID", 2).Return(&models.User{ID: 2}, nil)
+	postRepo.On("FindByID", 1).Return(&models.Post{ID: 1, UserID: 1, Status: models.PostStatusDraft}, nil)
+
+	// ACT
+	err := postService.BookmarkPost(1, 2)
+
+	// ASSERT
+	assert.EqualError(t, err, services.ErrPostNotFound)
+	bookmarkRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+}
+
+// TestGetBookmarks_Pagination: pide uno de más y arma el cursor con el último incluido
+func TestGetBookmarks_Pagination(t *testing.T) {
+	// ARRANGE
+	postService, _, _, bookmarkRepo := newPostServiceWithBookmarks()
+	at := func(minute int) *time.Time {
+		t := time.Date(2025, 10, 1, 12, minute, 0, 0, time.UTC)
+		return &t
+	}
+	bookmarkRepo.On("FindPostsByUser", 2, (*models.Cursor)(nil), 3).Return([]*models.Post{
+		{ID: 9, Title: "A", Bookmarked: true, BookmarkedAt: at(30)},
+		{ID: 4, Title: "B", Bookmarked: true, BookmarkedAt: at(20)},
+		{ID: 7, Title: "C", Bookmarked: true, BookmarkedAt: at(10)},
+	}, nil)
+
+	// ACT
+	page, err := postService.GetBookmarks(2, "", 2)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Len(t, page.Posts, 2)
+	next, err := models.ParseCursor(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, next.ID)
+	assert.True(t, next.Time.Equal(*at(20)))
+}
+
+// TestGetBookmarks_LastPage: sin posts de más no hay cursor siguiente
+func TestGetBookmarks_LastPage(t *testing.T) {
+	// ARRANGE
+	postService, _, _, bookmarkRepo := newPostServiceWithBookmarks()
+	cursor := models.Cursor{Time: time.Date(2025, 10, 1, 12, 20, 0, 0, time.UTC), ID: 4}
+	bookmarkRepo.On("FindPostsByUser", 2, &cursor, 21).Return(nil, nil)
+
+	// ACT
+	page, err := postService.GetBookmarks(2, cursor.String(), 0)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.NotNil(t, page.Posts)
+	assert.Empty(t, page.Posts)
+	assert.Empty(t, page.NextCursor)
+}
+
+// TestGetBookmarks_InvalidParams: cursor o limit inválidos
+func TestGetBookmarks_InvalidParams(t *testing.T) {
+	// ARRANGE
+	postService, _, _, bookmarkRepo := newPostServiceWithBookmarks()
+
+	// ACT
+	_, cursorErr := postService.GetBookmarks(2, "no-es-un-cursor", 10)
+	_, limitErr := postService.GetBookmarks(2, "", 500)
+
+	// ASSERT
+	assert.EqualError(t, cursorErr, models.ErrInvalidCursor)
+	assert.EqualError(t, limitErr, services.ErrInvalidLimit)
+	bookmarkRepo.AssertNotCalled(t, "FindPostsByUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestGetAllPosts_MarksBookmarked: el listado indica qué posts guardó el viewer
+func TestGetAllPosts_MarksBookmarked(t *testing.T) {
+	// ARRANGE
+	postService, postRepo, _, bookmarkRepo := newPostServiceWithBookmarks()
+	postRepo.On("FindAll", 2).Return([]*models.Post{
+		{ID: 1, Title: "Uno", Status: models.PostStatusPublished},
+		{ID: 2, Title: "Dos", Status: models.PostStatusPublished},
+	}, nil)
+	bookmarkRepo.On("BookmarkedPostIDs", 2, []int{1, 2}).Return(map[int]bool{2: true}, nil)
+
+	// ACT
+	posts, err := postService.GetAllPosts(2, services.SortNew)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.False(t, posts[0].Bookmarked)
+	assert.True(t, posts[1].Bookmarked)
+}
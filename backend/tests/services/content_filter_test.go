@@ -0,0 +1,247 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"tp06-testing/internal/contentfilter"
+	"tp06-testing/internal/events"
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// verdictFilter decide siempre lo mismo
+type verdictFilter struct {
+	verdict string
+}
+
+func (f *verdictFilter) Name() string {
+	return "test"
+}
+
+func (f *verdictFilter) Check(content *contentfilter.Content) (*contentfilter.Decision, error) {
+	return &contentfilter.Decision{Verdict: f.verdict, Reason: "motivo"}, nil
+}
+
+// fakeTrainer guarda los ejemplos con los que se entrenó
+type fakeTrainer struct {
+	texts []string
+	spam  []bool
+}
+
+func (t *fakeTrainer) Train(text string, spam bool) error {
+	t.texts = append(t.texts, text)
+	t.spam = append(t.spam, spam)
+	return nil
+}
+
+// TestCreatePost_HeldByFilter: el post retenido se guarda oculto y entra
+// a la cola de moderación con un reporte del filtro, sin usuario
+func TestCreatePost_HeldByFilter(t *testing.T) {
+	// ARRANGE
+	f := newModerationFixture()
+	postService := services.NewPostService(f.postRepo, f.userRepo)
+	postService.SetContentFilter(&verdictFilter{verdict: contentfilter.Hold})
+	postService.SetModerationService(f.moderationService)
+	f.postRepo.On("SlugExists", "oferta").Return(false, nil)
+	f.postRepo.On("Create", mock.AnythingOfType("*models.Post")).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Post).ID = 8
+	})
+	f.reportRepo.On("SetHidden", 8, 0, true).Return(nil)
+	f.reportRepo.On("Create", mock.AnythingOfType("*models.Report")).Return(true, nil)
+	f.reportRepo.On("AddAction", mock.AnythingOfType("*models.ReportAction")).Return(nil)
+
+	// ACT
+	post, err := postService.CreatePost(&models.CreatePostRequest{Title: "Oferta", Content: "Compra ya"}, 2)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.True(t, post.IsHidden())
+	assert.NotNil(t, f.postRepo.Calls[len(f.postRepo.Calls)-1].Arguments.Get(0).(*models.Post).HiddenAt)
+
+	var report *models.Report
+	for _, call := range f.reportRepo.Calls {
+		if call.Method == "Create" {
+			report = call.Arguments.Get(0).(*models.Report)
+		}
+	}
+	assert.Equal(t, 0, report.ReporterID)
+	assert.Equal(t, 2, report.AuthorID)
+	assert.Equal(t, models.ReportFilter, report.Reason)
+	assert.Equal(t, "test: motivo", report.Details)
+	assert.Equal(t, "Oferta\n\nCompra ya", report.Content)
+}
+
+// recordingFilter guarda lo último que revisó y decide siempre lo mismo
+type recordingFilter struct {
+	verdictFilter
+	checked *contentfilter.Content
+}
+
+func (f *recordingFilter) Check(content *contentfilter.Content) (*contentfilter.Decision, error) {
+	f.checked = content
+	return f.verdictFilter.Check(content)
+}
+
+// TestUpdatePost_HeldByFilterWithoutModeration: la edición retenida se
+// guarda oculta en la misma escritura aunque no haya cola de moderación
+func TestUpdatePost_HeldByFilterWithoutModeration(t *testing.T) {
+	// ARRANGE
+	f := newModerationFixture()
+	postService := services.NewPostService(f.postRepo, f.userRepo)
+	postService.SetContentFilter(&verdictFilter{verdict: contentfilter.Hold})
+	f.postRepo.On("Update", mock.AnythingOfType("*models.Post"), "").Return(nil)
+
+	// ACT
+	post, err := postService.UpdatePost(1, &models.UpdatePostRequest{Title: "Hola", Content: "Compra seguidores"}, 1)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.True(t, post.IsHidden())
+	saved := f.postRepo.Calls[len(f.postRepo.Calls)-1].Arguments.Get(0).(*models.Post)
+	assert.NotNil(t, saved.HiddenAt)
+}
+
+// TestUpdatePost_HoldForReviewError: si no se puede mandar a la cola de
+// moderación se devuelve el error (el post ya quedó oculto)
+func TestUpdatePost_HoldForReviewError(t *testing.T) {
+	// ARRANGE
+	f := newModerationFixture()
+	postService := services.NewPostService(f.postRepo, f.userRepo)
+	postService.SetContentFilter(&verdictFilter{verdict: contentfilter.Hold})
+	postService.SetModerationService(f.moderationService)
+	f.postRepo.On("Update", mock.AnythingOfType("*models.Post"), "").Return(nil)
+	f.reportRepo.On("SetHidden", 1, 0, true).Return(errors.New("database is locked"))
+
+	// ACT
+	post, err := postService.UpdatePost(1, &models.UpdatePostRequest{Title: "Hola", Content: "Compra seguidores"}, 1)
+
+	// ASSERT
+	assert.Nil(t, post)
+	assert.EqualError(t, err, "database is locked")
+	f.reportRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// TestCreateComment_FilterGetsPostID: el filtro recibe el post en el que
+// se comenta
+func TestCreateComment_FilterGetsPostID(t *testing.T) {
+	// ARRANGE
+	f := newModerationFixture()
+	postService := services.NewPostService(f.postRepo, f.userRepo)
+	filter := &recordingFilter{verdictFilter: verdictFilter{verdict: contentfilter.Reject}}
+	postService.SetContentFilter(filter)
+
+	// ACT
+	_, err := postService.CreateComment(1, &models.CreateCommentRequest{Content: "Compra seguidores"}, 2)
+
+	// ASSERT
+	assert.EqualError(t, err, services.ErrContentRejected)
+	assert.Equal(t, contentfilter.KindComment, filter.checked.Kind)
+	assert.Equal(t, 1, filter.checked.PostID)
+}
+
+// TestCreateComment_RejectedByFilter: el comentario rechazado no se guarda
+func TestCreateComment_RejectedByFilter(t *testing.T) {
+	// ARRANGE
+	f := newModerationFixture()
+	postService := services.NewPostService(f.postRepo, f.userRepo)
+	postService.SetContentFilter(&verdictFilter{verdict: contentfilter.Reject})
+
+	// ACT
+	comment, err := postService.CreateComment(1, &models.CreateCommentRequest{Content: "Compra seguidores"}, 2)
+
+	// ASSERT
+	assert.Nil(t, comment)
+	assert.EqualError(t, err, services.ErrContentRejected)
+	f.postRepo.AssertNotCalled(t, "CreateComment", mock.Anything)
+}
+
+// TestModeration_TrainsSpamClassifier: actuar sobre un reporte del filtro
+// entrena como spam, descartar uno de spam entrena como contenido
+// legítimo y los demás motivos no entrenan
+func TestModeration_TrainsSpamClassifier(t *testing.T) {
+	// ARRANGE
+	f := newModerationFixture()
+	trainer := &fakeTrainer{}
+	f.moderationService.SetSpamTrainer(trainer)
+	resolvedAt := time.Now()
+	f.reportRepo.On("FindByID", 5).Return(&models.Report{ID: 5, PostID: 1, AuthorID: 1, Reason: models.ReportFilter, Content: "casino gratis", Status: models.ReportOpen, TargetStatus: models.ReportTargetHidden}, nil).Once()
+	f.reportRepo.On("FindByID", 6).Return(&models.Report{ID: 6, PostID: 1, CommentID: 3, AuthorID: 1, Reason: models.ReportSpam, Content: "receta de pan", Status: models.ReportOpen, TargetStatus: models.ReportTargetVisible}, nil).Once()
+	f.reportRepo.On("FindByID", 7).Return(&models.Report{ID: 7, PostID: 1, CommentID: 4, AuthorID: 1, Reason: models.ReportHarassment, Content: "sos un tonto", Status: models.ReportOpen, TargetStatus: models.ReportTargetVisible}, nil).Once()
+	f.reportRepo.On("FindByID", mock.Anything).Return(&models.Report{ID: 5, Status: models.ReportResolved, ResolvedAt: &resolvedAt}, nil)
+	f.reportRepo.On("Close", mock.Anything, mock.Anything, mock.AnythingOfType("*models.ReportAction")).Return(nil)
+	f.reportRepo.On("SetHidden", 1, 4, true).Return(nil)
+	f.postRepo.On("Delete", 1, 9).Return(nil)
+	f.reportRepo.On("FindActions", mock.Anything).Return([]*models.ReportAction{}, nil)
+
+	// ACT
+	_, actErr := f.moderationService.Act(9, 5, &models.ModerateReportRequest{Action: models.ReportActionDelete})
+	_, dismissErr := f.moderationService.Dismiss(9, 6, &models.ModerateReportRequest{})
+	_, hideErr := f.moderationService.Act(9, 7, &models.ModerateReportRequest{Action: models.ReportActionHide})
+
+	// ASSERT
+	assert.NoError(t, actErr)
+	assert.NoError(t, dismissErr)
+	assert.NoError(t, hideErr)
+	assert.Equal(t, []string{"casino gratis", "receta de pan"}, trainer.texts)
+	assert.Equal(t, []bool{true, false}, trainer.spam)
+}
+
+// TestPublishPost_HeldDraftEmitsNothing: publicar un borrador retenido por
+// los filtros no lo anuncia ni avisa a los mencionados hasta que moderación
+// lo apruebe
+func TestPublishPost_HeldDraftEmitsNothing(t *testing.T) {
+	// ARRANGE
+	f := newMentionFixture()
+	broker := events.NewBroker(10, 10)
+	f.postService.SetEventBroker(broker)
+	sub, _, _ := broker.Subscribe(nil, 0)
+	hiddenAt := time.Now()
+	f.postRepo.On("FindByID", 5).Return(&models.Post{ID: 5, UserID: 1, Title: "Oferta", Content: "Hola @beto", Status: models.PostStatusDraft, HiddenAt: &hiddenAt}, nil)
+	f.postRepo.On("UpdateStatus", 5, models.PostStatusPublished, mock.Anything).Return(nil)
+	f.mentionRepo.On("PostMentions", []int{5}).Return(map[int][]models.Mention{
+		5: {{UserID: 2, Username: "beto", Offset: 5, Length: 5}},
+	}, nil)
+
+	// ACT
+	post, err := f.postService.PublishPost(5, nil, 1)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.True(t, post.IsPublished())
+	assert.False(t, post.IsPublic())
+	assert.Len(t, sub.C, 0)
+	f.notificationRepo.AssertNotCalled(t, "CreateOrCoalesce", mock.Anything)
+}
+
+// TestHiddenPost_ChangesEmitNothing: archivar o borrar un post oculto y que
+// su autor lo comente no llega a los clientes conectados ni notifica
+func TestHiddenPost_ChangesEmitNothing(t *testing.T) {
+	// ARRANGE
+	f := newMentionFixture()
+	broker := events.NewBroker(10, 10)
+	f.postService.SetEventBroker(broker)
+	sub, _, _ := broker.Subscribe(nil, 0)
+	hiddenAt := time.Now()
+	f.postRepo.On("FindByID", 5).Return(&models.Post{ID: 5, UserID: 1, Title: "Oferta", Content: "texto", Status: models.PostStatusPublished, HiddenAt: &hiddenAt}, nil)
+	f.postRepo.On("CreateComment", mock.AnythingOfType("*models.Comment")).Return(nil)
+	f.mentionRepo.On("SaveCommentMentions", 5, mock.Anything, mock.Anything).Return(nil)
+	f.postRepo.On("UpdateStatus", 5, models.PostStatusArchived, mock.Anything).Return(nil)
+	f.postRepo.On("Delete", 5, 1).Return(nil)
+
+	// ACT
+	_, errComment := f.postService.CreateComment(5, &models.CreateCommentRequest{Content: "Mirá @beto"}, 1)
+	_, errArchive := f.postService.ArchivePost(5, 1)
+	errDelete := f.postService.DeletePost(5, 1)
+
+	// ASSERT
+	assert.NoError(t, errComment)
+	assert.NoError(t, errArchive)
+	assert.NoError(t, errDelete)
+	assert.Len(t, sub.C, 0)
+	f.notificationRepo.AssertNotCalled(t, "CreateOrCoalesce", mock.Anything)
+}
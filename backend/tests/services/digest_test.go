@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tp06-testing/internal/mail"
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+	"tp06-testing/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// sentEmail es un email leído de la bandeja de salida del FileMailer
+type sentEmail struct {
+	header netmail.Header
+	text   string
+	html   string
+}
+
+// readOutbox lee y decodifica los emails que dejó el FileMailer en dir
+func readOutbox(t *testing.T, dir string) []sentEmail {
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+
+	var sent []sentEmail
+	for _, file := range files {
+		f, err := os.Open(file)
+		require.NoError(t, err)
+		defer f.Close()
+
+		msg, err := netmail.ReadMessage(f)
+		require.NoError(t, err)
+		_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+		require.NoError(t, err)
+
+		email := sentEmail{header: msg.Header}
+		parts := multipart.NewReader(msg.Body, params["boundary"])
+		for {
+			part, err := parts.NextPart()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			body, err := io.ReadAll(quotedprintable.NewReader(part))
+			require.NoError(t, err)
+
+			mediaType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+			if mediaType == "text/html" {
+				email.html = string(body)
+			} else {
+				email.text = string(body)
+			}
+		}
+		sent = append(sent, email)
+	}
+	return sent
+}
+
+func newDigestSender(t *testing.T) (*services.DigestSender, *services.DigestService, *mocks.MockDigestRepository, *mocks.MockUserRepository, *mocks.FakeClock, string) {
+	digestRepo := new(mocks.MockDigestRepository)
+	userRepo := new(mocks.MockUserRepository)
+	clock := &mocks.FakeClock{Current: time.Date(2025, 10, 8, 9, 0, 0, 0, time.UTC)}
+	outbox := t.TempDir()
+	mailer, err := mail.NewFileMailer(outbox, "Blog <no-reply@blog.test>")
+	require.NoError(t, err)
+
+	digestService := services.NewDigestService(digestRepo, clock, "s3cr3t", "https://api.blog.test")
+	sender := services.NewDigestSender(digestService, digestRepo, userRepo, mailer, clock, time.Minute, "https://blog.test")
+	return sender, digestService, digestRepo, userRepo, clock, outbox
+}
+
+// TestDigestService_UpdateSettings_StartsFromNow: al activar el resumen el
+// primero cubre desde ese momento; una frecuencia desconocida se rechaza
+func TestDigestService_UpdateSettings_StartsFromNow(t *testing.T) {
+	// ARRANGE
+	_, digestService, digestRepo, _, clock, _ := newDigestSender(t)
+	digestRepo.On("FindSettings", 5).Return(&models.DigestSettings{UserID: 5, Frequency: models.DigestOff}, nil)
+	digestRepo.On("SaveSettings", mock.Anything).Return(nil)
+
+	// ACT
+	settings, err := digestService.UpdateSettings(5, &models.UpdateDigestSettingsRequest{Frequency: models.DigestWeekly})
+	_, invalidErr := digestService.UpdateSettings(5, &models.UpdateDigestSettingsRequest{Frequency: "hourly"})
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, models.DigestWeekly, settings.Frequency)
+	require.NotNil(t, settings.LastDigestAt)
+	assert.Equal(t, clock.Current, *settings.LastDigestAt)
+	assert.EqualError(t, invalidErr, services.ErrInvalidDigestFrequency)
+	digestRepo.AssertNumberOfCalls(t, "SaveSettings", 1)
+}
+
+// TestDigestService_Unsubscribe_RequiresValidToken: el token firmado
+// desuscribe a su usuario y uno alterado se rechaza
+func TestDigestService_Unsubscribe_RequiresValidToken(t *testing.T) {
+	// ARRANGE
+	_, digestService, digestRepo, _, _, _ := newDigestSender(t)
+	lastDigest := time.Date(2025, 10, 1, 9, 0, 0, 0, time.UTC)
+	digestRepo.On("FindSettings", 5).Return(&models.DigestSettings{UserID: 5, Frequency: models.DigestDaily, LastDigestAt: &lastDigest}, nil)
+	digestRepo.On("SaveSettings", mock.MatchedBy(func(s *models.DigestSettings) bool {
+		return s.UserID == 5 && s.Frequency == models.DigestOff
+	})).Return(nil).Once()
+	token := digestService.UnsubscribeToken(5)
+
+	// ACT
+	forgedErr := digestService.Unsubscribe("6" + token[1:])
+	err := digestService.Unsubscribe(token)
+
+	// ASSERT
+	assert.EqualError(t, forgedErr, services.ErrInvalidUnsubscribeToken)
+	assert.NoError(t, err)
+	assert.Contains(t, digestService.UnsubscribeURL(5), "https://api.blog.test/api/digest/unsubscribe?token=")
+	digestRepo.AssertExpectations(t)
+}
+
+// TestDigestSender_SendsDigestToOutbox: el resumen incluye los posts nuevos
+// y las respuestas desde el anterior, en texto y HTML, con el link para
+// desuscribirse con un clic
+func TestDigestSender_SendsDigestToOutbox(t *testing.T) {
+	// ARRANGE
+	sender, digestService, digestRepo, userRepo, clock, outbox := newDigestSender(t)
+	lastDigest := clock.Current.Add(-25 * time.Hour)
+	digestRepo.On("FindDue", clock.Current, mock.Anything).Return([]*models.DigestSettings{
+		{UserID: 5, Frequency: models.DigestDaily, LastDigestAt: &lastDigest},
+	}, nil)
+	userRepo.On("FindByID", 5).Return(&models.User{ID: 5, Username: "ana", Email: "ana@example.com"}, nil)
+	digestRepo.On("FindNewPosts", 5, lastDigest, clock.Current, mock.Anything).Return([]*models.Post{
+		{ID: 9, Title: "Go & SQLite", Content: "Un post **nuevo**.", Slug: "go-sqlite", Username: "beto"},
+	}, nil)
+	digestRepo.On("FindReplies", 5, lastDigest, clock.Current, mock.Anything).Return([]*models.DigestReply{
+		{CommentID: 4, PostID: 2, PostTitle: "Mi post", PostSlug: "mi-post", Username: "carla", Content: "¡Muy bueno!"},
+	}, nil)
+	digestRepo.On("MarkDigested", 5, clock.Current).Return(nil)
+
+	// ACT
+	count, err := sender.RunOnce(context.Background())
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	digestRepo.AssertExpectations(t)
+
+	sent := readOutbox(t, outbox)
+	require.Len(t, sent, 1)
+	email := sent[0]
+	subject, _ := new(mime.WordDecoder).DecodeHeader(email.header.Get("Subject"))
+	assert.Equal(t, "ana@example.com", email.header.Get("To"))
+	assert.Equal(t, "Tu resumen diario: 1 post nuevo y 1 respuesta", subject)
+	assert.Equal(t, "<"+digestService.UnsubscribeURL(5)+">", email.header.Get("List-Unsubscribe"))
+	assert.Equal(t, "List-Unsubscribe=One-Click", email.header.Get("List-Unsubscribe-Post"))
+
+	assert.Contains(t, email.text, "* Go & SQLite (de beto)")
+	assert.Contains(t, email.text, "https://blog.test/posts/go-sqlite")
+	assert.Contains(t, email.text, "https://blog.test/posts/mi-post#comment-4")
+	assert.Contains(t, email.text, digestService.UnsubscribeURL(5))
+	assert.Contains(t, email.html, "Go &amp; SQLite")
+	assert.Contains(t, email.html, "Un post nuevo.")
+}
+
+// TestDigestSender_EmptyPeriod_SkipsEmail: si no pasó nada no se envía
+// email, pero el período se da por cubierto
+func TestDigestSender_EmptyPeriod_SkipsEmail(t *testing.T) {
+	// ARRANGE
+	sender, _, digestRepo, userRepo, clock, outbox := newDigestSender(t)
+	lastDigest := clock.Current.Add(-8 * 24 * time.Hour)
+	digestRepo.On("FindDue", clock.Current, mock.Anything).Return([]*models.DigestSettings{
+		{UserID: 5, Frequency: models.DigestWeekly, LastDigestAt: &lastDigest},
+	}, nil)
+	userRepo.On("FindByID", 5).Return(&models.User{ID: 5, Username: "ana", Email: "ana@example.com"}, nil)
+	digestRepo.On("FindNewPosts", 5, lastDigest, clock.Current, mock.Anything).Return(nil, nil)
+	digestRepo.On("FindReplies", 5, lastDigest, clock.Current, mock.Anything).Return(nil, nil)
+	digestRepo.On("MarkDigested", 5, clock.Current).Return(nil)
+
+	// ACT
+	count, err := sender.RunOnce(context.Background())
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Empty(t, readOutbox(t, outbox))
+	digestRepo.AssertExpectations(t)
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"testing"
+
+	"tp06-testing/internal/events"
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+	"tp06-testing/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newPostServiceWithBroker() (*services.PostService, *mocks.MockPostRepository, *mocks.MockUserRepository, *events.Subscription) {
+	postRepo := new(mocks.MockPostRepository)
+	userRepo := new(mocks.MockUserRepository)
+	postService := services.NewPostService(postRepo, userRepo)
+	broker := events.NewBroker(10, 10)
+	postService.SetEventBroker(broker)
+	sub, _, _ := broker.Subscribe(nil, 0)
+	return postService, postRepo, userRepo, sub
+}
+
+// TestCreatePost_PublishesEvent: un post publicado se envía a los clientes conectados
+func TestCreatePost_PublishesEvent(t *testing.T) {
+	// ARRANGE
+	postService, postRepo, userRepo, sub := newPostServiceWithBroker()
+	userRepo.On("FindByID", 1).Return(&models.User{ID: 1, Username: "ana"}, nil)
+	postRepo.On("SlugExists", "hola").Return(false, nil)
+	postRepo.On("Create", mock.AnythingOfType("*models.Post")).Return(nil)
+
+	// ACT
+	_, err := postService.CreatePost(&models.CreatePostRequest{Title: "Hola", Content: "Contenido"}, 1)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Len(t, sub.C, 1)
+	event := <-sub.C
+	assert.Equal(t, events.PostCreated, event.Type)
+	assert.Contains(t, string(event.Data), `"title":"Hola"`)
+}
+
+// TestCreatePost_DraftDoesNotPublishEvent: los borradores no se anuncian
+func TestCreatePost_DraftDoesNotPublishEvent(t *testing.T) {
+	// ARRANGE
+	postService, postRepo, userRepo, sub := newPostServiceWithBroker()
+	userRepo.On("FindByID", 1).Return(&models.User{ID: 1, Username: "ana"}, nil)
+	postRepo.On("SlugExists", "hola").Return(false, nil)
+	postRepo.On("Create", mock.AnythingOfType("*models.Post")).Return(nil)
+
+	// ACT
+	_, err := postService.CreatePost(&models.CreatePostRequest{Title: "Hola", Content: "Contenido", Status: models.PostStatusDraft}, 1)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Len(t, sub.C, 0)
+}
+
+// TestDeleteComment_PublishesEvent: se avisa el borrado con el ID del post para filtrar
+func TestDeleteComment_PublishesEvent(t *testing.T) {
+	// ARRANGE
+	postService, postRepo, userRepo, sub := newPostServiceWithBroker()
+	postRepo.On("FindByID", 3).Return(&models.Post{ID: 3, UserID: 1, Status: models.PostStatusPublished}, nil)
+	userRepo.On("FindByID", 2).Return(&models.User{ID: 2}, nil)
+	postRepo.On("DeleteComment", 3, 7, 2, 2).Return(nil)
+
+	// ACT
+	err := postService.DeleteComment(3, 7, 2)
+
+	// ASSERT
+	assert.NoError(t, err)
+	event := <-sub.C
+	assert.Equal(t, events.CommentDeleted, event.Type)
+	assert.Equal(t, 3, event.PostID)
+	assert.JSONEq(t, `{"id": 7, "post_id": 3}`, string(event.Data))
+}
+
+// roomRecorder guarda los eventos enviados a las salas en vivo
+type roomRecorder struct {
+	events []string
+}
+
+func (r *roomRecorder) Publish(eventType string, postID int, data interface{}) error {
+	r.events = append(r.events, eventType)
+	return nil
+}
+
+// TestDeleteComment_NonPublicPostGoesOnlyToRoom: los comentarios de un post
+// de seguidores llegan a su sala pero no al broker
+func TestDeleteComment_NonPublicPostGoesOnlyToRoom(t *testing.T) {
+	// ARRANGE
+	postService, postRepo, userRepo, sub := newPostServiceWithBroker()
+	rooms := &roomRecorder{}
+	postService.SetRoomPublisher(rooms)
+	postRepo.On("FindByID", 3).Return(&models.Post{ID: 3, UserID: 1, Status: models.PostStatusPublished, Visibility: models.PostVisibilityFollowers}, nil)
+	userRepo.On("FindByID", 2).Return(&models.User{ID: 2}, nil)
+	postRepo.On("DeleteComment", 3, 7, 2, 2).Return(nil)
+
+	// ACT
+	err := postService.DeleteComment(3, 7, 2)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, []string{events.CommentDeleted}, rooms.events)
+	assert.Len(t, sub.C, 0)
+}
+
+// TestCreateComment_UnlistedPostGoesOnlyToRoom: un comentario en un post
+// no listado se envía a su sala, no al stream
+func TestCreateComment_UnlistedPostGoesOnlyToRoom(t *testing.T) {
+	// ARRANGE
+	postService, postRepo, userRepo, sub := newPostServiceWithBroker()
+	rooms := &roomRecorder{}
+	postService.SetRoomPublisher(rooms)
+	postRepo.On("FindByID", 3).Return(&models.Post{ID: 3, UserID: 1, Status: models.PostStatusPublished, Visibility: models.PostVisibilityUnlisted}, nil)
+	userRepo.On("FindByID", 2).Return(&models.User{ID: 2, Username: "beto"}, nil)
+	postRepo.On("CreateComment", mock.AnythingOfType("*models.Comment")).Return(nil)
+
+	// ACT
+	_, err := postService.CreateComment(3, &models.CreateCommentRequest{Content: "Hola"}, 2)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, []string{events.CommentCreated}, rooms.events)
+	assert.Len(t, sub.C, 0)
+}
@@ -0,0 +1,459 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tp06-testing/internal/activitypub"
+	"tp06-testing/internal/events"
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+	"tp06-testing/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const federationBaseURL = "https://blog.example.com"
+
+// remoteInstance es un servidor de ActivityPub de prueba (como si fuera
+// Mastodon) con un único usuario, alice, que firma lo que envía y guarda
+// lo que recibe en sus inboxes
+type remoteInstance struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	publicPEM string
+	received  chan receivedActivity
+	fetches   atomic.Int32 // GETs recibidos del documento del actor
+}
+
+// receivedActivity es un request que llegó a un inbox de la instancia remota
+type receivedActivity struct {
+	request *http.Request
+	body    []byte
+}
+
+func newRemoteInstance(t *testing.T) *remoteInstance {
+	privatePEM, publicPEM, err := activitypub.GenerateKey()
+	require.NoError(t, err)
+	key, err := activitypub.ParsePrivateKey(privatePEM)
+	require.NoError(t, err)
+
+	remote := &remoteInstance{key: key, publicPEM: publicPEM, received: make(chan receivedActivity, 10)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/alice", func(w http.ResponseWriter, r *http.Request) {
+		remote.fetches.Add(1)
+		w.Header().Set("Content-Type", activitypub.ContentType)
+		json.NewEncoder(w).Encode(activitypub.Actor{
+			ID:                remote.actorURI(),
+			Type:              activitypub.TypePerson,
+			PreferredUsername: "alice",
+			Inbox:             remote.server.URL + "/users/alice/inbox",
+			Endpoints:         &activitypub.Endpoints{SharedInbox: remote.server.URL + "/inbox"},
+			PublicKey:         activitypub.PublicKey{ID: remote.keyID(), Owner: remote.actorURI(), PublicKeyPem: publicPEM},
+		})
+	})
+	inbox := func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		remote.received <- receivedActivity{request: r, body: body}
+		w.WriteHeader(http.StatusAccepted)
+	}
+	mux.HandleFunc("POST /inbox", inbox)
+	mux.HandleFunc("POST /users/alice/inbox", inbox)
+
+	remote.server = httptest.NewTLSServer(mux)
+	t.Cleanup(remote.server.Close)
+	return remote
+}
+
+func (ri *remoteInstance) actorURI() string { return ri.server.URL + "/users/alice" }
+func (ri *remoteInstance) keyID() string    { return ri.actorURI() + "#main-key" }
+
+// cachedActor es alice tal como queda guardada después del primer contacto
+func (ri *remoteInstance) cachedActor() *models.RemoteActor {
+	return &models.RemoteActor{
+		UserID:       50,
+		URI:          ri.actorURI(),
+		Username:     "alice@" + ri.server.Listener.Addr().String(),
+		KeyID:        ri.keyID(),
+		PublicKeyPEM: ri.publicPEM,
+		Inbox:        ri.server.URL + "/users/alice/inbox",
+		SharedInbox:  ri.server.URL + "/inbox",
+	}
+}
+
+// signedRequest arma el POST firmado por alice al inbox de un usuario local
+func (ri *remoteInstance) signedRequest(t *testing.T, userInbox string, activity interface{}, now time.Time) (*http.Request, []byte) {
+	body, err := json.Marshal(activity)
+	require.NoError(t, err)
+
+	out, err := http.NewRequest(http.MethodPost, userInbox, bytes.NewReader(body))
+	require.NoError(t, err)
+	require.NoError(t, activitypub.SignRequest(out, ri.keyID(), ri.key, body, now))
+
+	in := httptest.NewRequest(http.MethodPost, userInbox, bytes.NewReader(body))
+	in.Header = out.Header.Clone()
+	return in, body
+}
+
+// next espera la próxima actividad recibida por la instancia remota
+func (ri *remoteInstance) next(t *testing.T) receivedActivity {
+	select {
+	case received := <-ri.received:
+		return received
+	case <-time.After(2 * time.Second):
+		t.Fatal("la instancia remota no recibió ninguna actividad")
+		return receivedActivity{}
+	}
+}
+
+type federationFixture struct {
+	service    *services.FederationService
+	fedRepo    *mocks.MockFederationRepository
+	userRepo   *mocks.MockUserRepository
+	postRepo   *mocks.MockPostRepository
+	followRepo *mocks.MockFollowRepository
+	clock      *mocks.FakeClock
+	localKey   *models.ActorKey
+}
+
+// newFederationFixture arma el servicio para ana (usuario 1), que ya tiene
+// sus claves generadas
+func newFederationFixture(t *testing.T) *federationFixture {
+	privatePEM, publicPEM, err := activitypub.GenerateKey()
+	require.NoError(t, err)
+
+	f := &federationFixture{
+		fedRepo:    new(mocks.MockFederationRepository),
+		userRepo:   new(mocks.MockUserRepository),
+		postRepo:   new(mocks.MockPostRepository),
+		followRepo: new(mocks.MockFollowRepository),
+		clock:      &mocks.FakeClock{Current: time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)},
+		localKey:   &models.ActorKey{UserID: 1, PrivateKeyPEM: privatePEM, PublicKeyPEM: publicPEM},
+	}
+	postService := services.NewPostService(f.postRepo, f.userRepo)
+	followService := services.NewFollowService(f.followRepo, f.userRepo)
+	// Las instancias remotas de prueba usan https con un certificado propio
+	client := &http.Client{Timeout: 2 * time.Second, Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	f.service = services.NewFederationService(f.fedRepo, f.userRepo, postService, followService,
+		client, federationBaseURL, "https://www.blog.example.com")
+	f.service.SetClock(f.clock)
+
+	f.userRepo.On("FindByID", 1).Return(&models.User{ID: 1, Username: "ana", CreatedAt: f.clock.Current}, nil)
+	f.fedRepo.On("FindRemoteActorByUser", 1).Return(nil, nil)
+	f.fedRepo.On("FindKey", 1).Return(f.localKey, nil).Maybe()
+	return f
+}
+
+// TestFederation_WebFingerAndActor: acct:ana@dominio lleva al actor, que publica su clave
+func TestFederation_WebFingerAndActor(t *testing.T) {
+	// ARRANGE
+	f := newFederationFixture(t)
+	f.userRepo.On("FindByUsername", "ana").Return(&models.User{ID: 1, Username: "ana"}, nil)
+
+	// ACT
+	jrd, err := f.service.WebFinger("acct:ana@blog.example.com")
+	actor, actorErr := f.service.GetActor(1)
+	_, otherErr := f.service.WebFinger("acct:ana@otro.example")
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, "acct:ana@blog.example.com", jrd.Subject)
+	assert.Equal(t, federationBaseURL+"/ap/users/1", jrd.Links[0].Href)
+	assert.Equal(t, activitypub.ContentType, jrd.Links[0].Type)
+
+	assert.NoError(t, actorErr)
+	assert.Equal(t, "ana", actor.PreferredUsername)
+	assert.Equal(t, federationBaseURL+"/ap/users/1/inbox", actor.Inbox)
+	assert.Equal(t, federationBaseURL+"/ap/users/1#main-key", actor.PublicKey.ID)
+	assert.Equal(t, f.localKey.PublicKeyPEM, actor.PublicKey.PublicKeyPem)
+
+	assert.EqualError(t, otherErr, services.ErrActorNotFound)
+}
+
+// TestFederation_FollowIsAccepted: un Follow firmado por un actor nuevo lo
+// registra como seguidor y recibe un Accept firmado con la clave de ana
+func TestFederation_FollowIsAccepted(t *testing.T) {
+	// ARRANGE
+	f := newFederationFixture(t)
+	remote := newRemoteInstance(t)
+	f.fedRepo.On("FindRemoteActor", remote.actorURI()).Return(nil, nil)
+	f.fedRepo.On("SaveRemoteActor", mock.MatchedBy(func(actor *models.RemoteActor) bool {
+		return actor.URI == remote.actorURI() && actor.KeyID == remote.keyID() && actor.SharedInbox == remote.server.URL+"/inbox"
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.RemoteActor).UserID = 50
+	}).Return(nil)
+	f.userRepo.On("FindByID", 50).Return(&models.User{ID: 50, Username: "alice@remote"}, nil)
+	f.followRepo.On("Follow", 50, 1).Return(true, nil)
+	f.followRepo.On("FindProfile", 1, 50).Return(&models.UserProfile{ID: 1, FollowerCount: 1}, nil)
+
+	follow := map[string]string{
+		"@context": activitypub.ContextActivityStreams,
+		"id":       remote.server.URL + "/follows/1",
+		"type":     activitypub.TypeFollow,
+		"actor":    remote.actorURI(),
+		"object":   federationBaseURL + "/ap/users/1",
+	}
+	req, body := remote.signedRequest(t, federationBaseURL+"/ap/users/1/inbox", follow, f.clock.Now())
+
+	// ACT
+	err := f.service.HandleInbox(1, req, body)
+
+	// ASSERT
+	require.NoError(t, err)
+	f.followRepo.AssertExpectations(t)
+
+	received := remote.next(t)
+	assert.Equal(t, "/users/alice/inbox", received.request.URL.Path)
+
+	sig, err := activitypub.ParseSignature(received.request)
+	require.NoError(t, err)
+	assert.Equal(t, federationBaseURL+"/ap/users/1#main-key", sig.KeyID)
+	localPublic, _ := activitypub.ParsePublicKey(f.localKey.PublicKeyPEM)
+	assert.NoError(t, sig.Verify(received.request, localPublic, received.body, f.clock.Now()))
+
+	var accept struct {
+		Type   string `json:"type"`
+		Object struct {
+			ID   string `json:"id"`
+			Type string `json:"type"`
+		} `json:"object"`
+	}
+	require.NoError(t, json.Unmarshal(received.body, &accept))
+	assert.Equal(t, activitypub.TypeAccept, accept.Type)
+	assert.Equal(t, follow["id"], accept.Object.ID)
+}
+
+// TestFederation_RejectsInvalidSignature: si el cuerpo no es el firmado no se procesa
+func TestFederation_RejectsInvalidSignature(t *testing.T) {
+	// ARRANGE
+	f := newFederationFixture(t)
+	remote := newRemoteInstance(t)
+	f.fedRepo.On("FindRemoteActor", remote.actorURI()).Return(remote.cachedActor(), nil)
+
+	follow := map[string]string{
+		"id":     remote.server.URL + "/follows/1",
+		"type":   activitypub.TypeFollow,
+		"actor":  remote.actorURI(),
+		"object": federationBaseURL + "/ap/users/1",
+	}
+	req, _ := remote.signedRequest(t, federationBaseURL+"/ap/users/1/inbox", follow, f.clock.Now())
+	follow["object"] = federationBaseURL + "/ap/users/2"
+	tampered, _ := json.Marshal(follow)
+
+	// ACT
+	err := f.service.HandleInbox(1, req, tampered)
+
+	// ASSERT
+	assert.EqualError(t, err, services.ErrInvalidSignature)
+	f.fedRepo.AssertNotCalled(t, "SaveRemoteActor", mock.Anything)
+	f.followRepo.AssertNotCalled(t, "Follow", mock.Anything, mock.Anything)
+}
+
+// TestFederation_RejectsUnsafeActorWithoutFetching: un actor que no es
+// https o que no está en el servidor de la clave se rechaza antes de
+// hacer cualquier request (evita usar el inbox para llegar a la red interna)
+func TestFederation_RejectsUnsafeActorWithoutFetching(t *testing.T) {
+	// ARRANGE
+	f := newFederationFixture(t)
+	remote := newRemoteInstance(t)
+	plainActor := strings.Replace(remote.actorURI(), "https://", "http://", 1)
+	f.fedRepo.On("FindRemoteActor", mock.AnythingOfType("string")).Return(nil, nil)
+
+	follow := func(actor string) map[string]string {
+		return map[string]string{
+			"id":     remote.server.URL + "/follows/1",
+			"type":   activitypub.TypeFollow,
+			"actor":  actor,
+			"object": federationBaseURL + "/ap/users/1",
+		}
+	}
+	plainReq, plainBody := remote.signedRequest(t, federationBaseURL+"/ap/users/1/inbox", follow(plainActor), f.clock.Now())
+
+	// Firmado con una clave de otro servidor
+	otherBody, err := json.Marshal(follow(remote.actorURI()))
+	require.NoError(t, err)
+	out, err := http.NewRequest(http.MethodPost, federationBaseURL+"/ap/users/1/inbox", bytes.NewReader(otherBody))
+	require.NoError(t, err)
+	require.NoError(t, activitypub.SignRequest(out, "https://otro.example/users/alice#main-key", remote.key, otherBody, f.clock.Now()))
+	otherReq := httptest.NewRequest(http.MethodPost, federationBaseURL+"/ap/users/1/inbox", bytes.NewReader(otherBody))
+	otherReq.Header = out.Header.Clone()
+
+	// ACT
+	errPlain := f.service.HandleInbox(1, plainReq, plainBody)
+	errOther := f.service.HandleInbox(1, otherReq, otherBody)
+
+	// ASSERT
+	assert.EqualError(t, errPlain, services.ErrInvalidSignature)
+	assert.EqualError(t, errOther, services.ErrInvalidSignature)
+	assert.Equal(t, int32(0), remote.fetches.Load())
+	f.fedRepo.AssertNotCalled(t, "SaveRemoteActor", mock.Anything)
+}
+
+// TestPublicHTTPClient_RefusesPrivateAddresses: el cliente de federación y
+// webhooks no se conecta a loopback aunque la URL sea válida
+func TestPublicHTTPClient_RefusesPrivateAddresses(t *testing.T) {
+	// ARRANGE
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer server.Close()
+	client := services.NewPublicHTTPClient(time.Second)
+
+	// ACT
+	_, err := client.Get(server.URL)
+	_, errLocalhost := client.Get(strings.Replace(server.URL, "127.0.0.1", "localhost", 1))
+
+	// ASSERT
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), services.ErrPrivateAddress)
+	require.Error(t, errLocalhost)
+	assert.Contains(t, errLocalhost.Error(), services.ErrPrivateAddress)
+	assert.Equal(t, int32(0), hits.Load())
+}
+
+// TestFederation_RemoteReplyBecomesComment: una Note en respuesta a un post
+// se importa como comentario del usuario que representa al actor
+func TestFederation_RemoteReplyBecomesComment(t *testing.T) {
+	// ARRANGE
+	f := newFederationFixture(t)
+	remote := newRemoteInstance(t)
+	noteID := remote.server.URL + "/users/alice/statuses/9"
+	f.fedRepo.On("FindRemoteActor", remote.actorURI()).Return(remote.cachedActor(), nil)
+	f.fedRepo.On("FindRemoteComment", noteID).Return(nil, nil)
+	f.postRepo.On("FindByID", 7).Return(&models.Post{ID: 7, UserID: 1, Status: models.PostStatusPublished}, nil)
+	f.userRepo.On("FindByID", 50).Return(&models.User{ID: 50, Username: "alice@remote"}, nil)
+	f.postRepo.On("CreateComment", mock.MatchedBy(func(comment *models.Comment) bool {
+		return comment.PostID == 7 && comment.UserID == 50 && comment.Content == "¡Muy bueno! Gracias"
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Comment).ID = 33
+	}).Return(nil)
+	f.fedRepo.On("SaveRemoteComment", &models.RemoteComment{ObjectURI: noteID, CommentID: 33, PostID: 7}).Return(nil)
+
+	create := map[string]interface{}{
+		"id":    noteID + "/activity",
+		"type":  activitypub.TypeCreate,
+		"actor": remote.actorURI(),
+		"object": map[string]interface{}{
+			"id":           noteID,
+			"type":         activitypub.TypeNote,
+			"attributedTo": remote.actorURI(),
+			"inReplyTo":    federationBaseURL + "/ap/posts/7",
+			"content":      "<p>¡Muy bueno!<br>Gracias</p>",
+			"tag":          []interface{}{},
+		},
+	}
+	req, body := remote.signedRequest(t, federationBaseURL+"/ap/users/1/inbox", create, f.clock.Now())
+
+	// ACT
+	err := f.service.HandleInbox(1, req, body)
+
+	// ASSERT
+	assert.NoError(t, err)
+	f.postRepo.AssertExpectations(t)
+	f.fedRepo.AssertExpectations(t)
+}
+
+// TestFederation_DeliversPostsToFollowers: los posts nuevos y borrados se
+// envían una sola vez a cada servidor con seguidores
+func TestFederation_DeliversPostsToFollowers(t *testing.T) {
+	// ARRANGE
+	f := newFederationFixture(t)
+	remote := newRemoteInstance(t)
+	bob := remote.cachedActor()
+	bob.UserID, bob.URI = 51, remote.server.URL+"/users/bob"
+	f.fedRepo.On("FindFollowerActors", 1).Return([]*models.RemoteActor{remote.cachedActor(), bob}, nil)
+	published := f.clock.Now()
+	f.postRepo.On("FindByID", 7).Return(&models.Post{
+		ID: 7, UserID: 1, Title: "Hola", Content: "Hola **mundo**", Slug: "hola",
+		Status: models.PostStatusPublished, PublishedAt: &published,
+	}, nil)
+
+	broker := events.NewBroker(10, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go f.service.Listen(ctx, broker)
+	require.Eventually(t, func() bool { return broker.SubscriberCount() == 1 }, time.Second, 10*time.Millisecond)
+
+	// ACT
+	require.NoError(t, broker.Publish(events.PostCreated, 7, map[string]int{"id": 7}))
+	created := remote.next(t)
+	require.NoError(t, broker.Publish(events.PostDeleted, 7, map[string]int{"id": 7, "user_id": 1}))
+	deleted := remote.next(t)
+
+	// ASSERT
+	var create struct {
+		Type   string             `json:"type"`
+		Object activitypub.Object `json:"object"`
+	}
+	require.NoError(t, json.Unmarshal(created.body, &create))
+	assert.Equal(t, "/inbox", created.request.URL.Path)
+	assert.Equal(t, activitypub.TypeCreate, create.Type)
+	assert.Equal(t, activitypub.TypeArticle, create.Object.Type)
+	assert.Equal(t, federationBaseURL+"/ap/posts/7", create.Object.ID)
+	assert.Equal(t, "https://www.blog.example.com/posts/hola", create.Object.URL)
+	assert.Contains(t, create.Object.Content, "<strong>mundo</strong>")
+
+	var del struct {
+		Type   string             `json:"type"`
+		Object activitypub.Object `json:"object"`
+	}
+	require.NoError(t, json.Unmarshal(deleted.body, &del))
+	assert.Equal(t, activitypub.TypeDelete, del.Type)
+	assert.Equal(t, federationBaseURL+"/ap/posts/7", del.Object.ID)
+
+	// Bob comparte el inbox de alice: no hay una segunda copia
+	select {
+	case extra := <-remote.received:
+		t.Fatalf("entrega duplicada: %s", extra.body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// TestFederation_OutboxPagesOnlyUserPosts: el outbox cuenta y pagina los
+// posts públicos de ana en la base en lugar de leer todos los posts
+func TestFederation_OutboxPagesOnlyUserPosts(t *testing.T) {
+	// ARRANGE
+	f := newFederationFixture(t)
+	published := f.clock.Now()
+	var page []*models.Post
+	for id := 30; id >= 10; id-- { // 21 posts: uno más que la página
+		page = append(page, &models.Post{ID: id, UserID: 1, Title: "Post", Content: "texto", Slug: "post", Status: models.PostStatusPublished, PublishedAt: &published})
+	}
+	f.postRepo.On("CountPublicByUser", 1).Return(45, nil)
+	f.postRepo.On("FindPublicByUser", 1, 20, 21).Return(page, nil)
+	f.postRepo.On("FindPublicByUser", 1, 40, 21).Return(page[:5], nil)
+
+	// ACT
+	outbox, err := f.service.GetOutbox(1)
+	second, errSecond := f.service.GetOutboxPage(1, 2)
+	last, errLast := f.service.GetOutboxPage(1, 3)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, 45, outbox.TotalItems)
+	assert.Equal(t, federationBaseURL+"/ap/users/1/outbox?page=1", outbox.First)
+
+	assert.NoError(t, errSecond)
+	assert.Len(t, second.OrderedItems, 20)
+	assert.Equal(t, federationBaseURL+"/ap/posts/30", second.OrderedItems[0].(*activitypub.Activity).Object.(*activitypub.Object).ID)
+	assert.Equal(t, federationBaseURL+"/ap/users/1/outbox?page=3", second.Next)
+	assert.Equal(t, federationBaseURL+"/ap/users/1/outbox?page=1", second.Prev)
+
+	assert.NoError(t, errLast)
+	assert.Len(t, last.OrderedItems, 5)
+	assert.Empty(t, last.Next)
+	f.postRepo.AssertNotCalled(t, "FindAll", mock.Anything)
+}
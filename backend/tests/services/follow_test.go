@@ -0,0 +1,130 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+	"tp06-testing/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newFollowService() (*services.FollowService, *mocks.MockFollowRepository, *mocks.MockUserRepository) {
+	followRepo := new(mocks.MockFollowRepository)
+	userRepo := new(mocks.MockUserRepository)
+	return services.NewFollowService(followRepo, userRepo), followRepo, userRepo
+}
+
+// TestFollow_Success: sigue al usuario y devuelve su perfil actualizado
+func TestFollow_Success(t *testing.T) {
+	// ARRANGE
+	followService, followRepo, userRepo := newFollowService()
+	userRepo.On("FindByID", 1).Return(&models.User{ID: 1}, nil)
+	userRepo.On("FindByID", 2).Return(&models.User{ID: 2}, nil)
+	followRepo.On("Follow", 1, 2).Return(true, nil)
+	followRepo.On("FindProfile", 2, 1).Return(&models.UserProfile{ID: 2, FollowerCount: 1, FollowedByMe: true}, nil)
+
+	// ACT
+	profile, err := followService.Follow(1, 2)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.True(t, profile.FollowedByMe)
+	assert.Equal(t, 1, profile.FollowerCount)
+	followRepo.AssertExpectations(t)
+}
+
+// TestFollow_Self: no se puede seguir a uno mismo
+func TestFollow_Self(t *testing.T) {
+	// ARRANGE
+	followService, followRepo, _ := newFollowService()
+
+	// ACT
+	profile, err := followService.Follow(1, 1)
+
+	// ASSERT
+	assert.Nil(t, profile)
+	assert.EqualError(t, err, services.ErrCannotFollowSelf)
+	followRepo.AssertNotCalled(t, "Follow", mock.Anything, mock.Anything)
+}
+
+// TestFollow_UnknownUser: el usuario a seguir tiene que existir
+func TestFollow_UnknownUser(t *testing.T) {
+	// ARRANGE
+	followService, followRepo, userRepo := newFollowService()
+	userRepo.On("FindByID", 1).Return(&models.User{ID: 1}, nil)
+	userRepo.On("FindByID", 99).Return(nil, nil)
+
+	// ACT
+	_, err := followService.Follow(1, 99)
+
+	// ASSERT
+	assert.EqualError(t, err, services.ErrUserNotFound)
+	followRepo.AssertNotCalled(t, "Follow", mock.Anything, mock.Anything)
+}
+
+// TestGetFollowers_Pagination: el cursor usa la fecha del follow del último incluido
+func TestGetFollowers_Pagination(t *testing.T) {
+	// ARRANGE
+	followService, followRepo, _ := newFollowService()
+	at := func(minute int) *time.Time {
+		t := time.Date(2025, 10, 1, 12, minute, 0, 0, time.UTC)
+		return &t
+	}
+	followRepo.On("FindProfile", 1, 0).Return(&models.UserProfile{ID: 1}, nil)
+	followRepo.On("FindFollowers", 1, 0, (*models.Cursor)(nil), 3).Return([]*models.UserProfile{
+		{ID: 5, FollowedAt: at(30)},
+		{ID: 3, FollowedAt: at(20)},
+		{ID: 8, FollowedAt: at(10)},
+	}, nil)
+
+	// ACT
+	page, err := followService.GetFollowers(1, 0, "", 2)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Len(t, page.Users, 2)
+	next, err := models.ParseCursor(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, next.ID)
+	assert.True(t, next.Time.Equal(*at(20)))
+}
+
+// TestGetFeed_Pagination: el feed pagina por fecha de publicación
+func TestGetFeed_Pagination(t *testing.T) {
+	// ARRANGE
+	postRepo := new(mocks.MockPostRepository)
+	postService := services.NewPostService(postRepo, new(mocks.MockUserRepository))
+	published := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	after := &models.Cursor{Time: published.Add(time.Hour), ID: 50}
+	postRepo.On("FindFeed", 1, after, 2).Return([]*models.Post{
+		{ID: 7, Title: "A", Status: models.PostStatusPublished, PublishedAt: &published},
+	}, nil)
+
+	// ACT
+	page, err := postService.GetFeed(1, after.String(), 1)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Len(t, page.Posts, 1)
+	assert.Empty(t, page.NextCursor)
+	postRepo.AssertExpectations(t)
+}
+
+// TestGetFeed_InvalidCursor: un cursor inválido es un error del cliente
+func TestGetFeed_InvalidCursor(t *testing.T) {
+	// ARRANGE
+	postRepo := new(mocks.MockPostRepository)
+	postService := services.NewPostService(postRepo, new(mocks.MockUserRepository))
+
+	// ACT
+	page, err := postService.GetFeed(1, "no-es-un-cursor", 10)
+
+	// ASSERT
+	assert.Nil(t, page)
+	assert.EqualError(t, err, models.ErrInvalidCursor)
+	postRepo.AssertNotCalled(t, "FindFeed", mock.Anything, mock.Anything, mock.Anything)
+}
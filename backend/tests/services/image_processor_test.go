@@ -0,0 +1,75 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+	"tp06-testing/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// readSeekNopCloser adapta un bytes.Reader al io.ReadSeekCloser del BlobStore
+type readSeekNopCloser struct{ *bytes.Reader }
+
+func (readSeekNopCloser) Close() error { return nil }
+
+func blob(data []byte) io.ReadSeekCloser {
+	return readSeekNopCloser{bytes.NewReader(data)}
+}
+
+// TestImageProcessor_GeneratesVariants: genera thumbnail y medium sin agrandar
+func TestImageProcessor_GeneratesVariants(t *testing.T) {
+	// ARRANGE
+	attachmentRepo := new(mocks.MockAttachmentRepository)
+	store := new(mocks.MockBlobStore)
+	processor := services.NewImageProcessor(attachmentRepo, store, 0)
+
+	pending := &models.Attachment{ID: 7, StorageKey: "attachments/2025/10/abc.png", ContentType: "image/png"}
+	attachmentRepo.On("FindPendingImages", 10).Return([]*models.Attachment{pending}, nil)
+	store.On("Open", "attachments/2025/10/abc.png").Return(blob(testPNG(t, 800, 400)), nil)
+	store.On("Put", "attachments/2025/10/abc_thumbnail.png", mock.Anything, "image/png").Return(nil)
+	store.On("Put", "attachments/2025/10/abc_medium.png", mock.Anything, "image/png").Return(nil)
+	attachmentRepo.On("SaveVariants", 7, mock.MatchedBy(func(variants []*models.AttachmentVariant) bool {
+		return len(variants) == 2 &&
+			variants[0].Name == models.VariantThumbnail && variants[0].Width == 320 && variants[0].Height == 160 &&
+			variants[1].Name == models.VariantMedium && variants[1].Width == 800 && variants[1].Height == 400
+	})).Return(nil)
+
+	// ACT
+	n, err := processor.RunOnce(context.Background())
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	attachmentRepo.AssertExpectations(t)
+	store.AssertExpectations(t)
+}
+
+// TestImageProcessor_MarksInvalidImageAsFailed: una imagen dañada no se reintenta
+func TestImageProcessor_MarksInvalidImageAsFailed(t *testing.T) {
+	// ARRANGE
+	attachmentRepo := new(mocks.MockAttachmentRepository)
+	store := new(mocks.MockBlobStore)
+	processor := services.NewImageProcessor(attachmentRepo, store, 0)
+
+	pending := &models.Attachment{ID: 8, StorageKey: "attachments/2025/10/bad.png", ContentType: "image/png"}
+	attachmentRepo.On("FindPendingImages", 10).Return([]*models.Attachment{pending}, nil)
+	store.On("Open", "attachments/2025/10/bad.png").Return(blob([]byte("\x89PNG\r\n\x1a\nroto")), nil)
+	attachmentRepo.On("UpdateProcessingStatus", 8, models.AttachmentProcessingFailed).Return(nil)
+
+	// ACT
+	n, err := processor.RunOnce(context.Background())
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	attachmentRepo.AssertExpectations(t)
+	attachmentRepo.AssertNotCalled(t, "SaveVariants", mock.Anything, mock.Anything)
+	store.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"testing"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+	"tp06-testing/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mentionFixture struct {
+	postService      *services.PostService
+	postRepo         *mocks.MockPostRepository
+	userRepo         *mocks.MockUserRepository
+	mentionRepo      *mocks.MockMentionRepository
+	notificationRepo *mocks.MockNotificationRepository
+}
+
+func newMentionFixture() *mentionFixture {
+	f := &mentionFixture{
+		postRepo:         new(mocks.MockPostRepository),
+		userRepo:         new(mocks.MockUserRepository),
+		mentionRepo:      new(mocks.MockMentionRepository),
+		notificationRepo: new(mocks.MockNotificationRepository),
+	}
+	f.postService = services.NewPostService(f.postRepo, f.userRepo)
+	f.postService.SetMentionRepository(f.mentionRepo)
+	f.postService.SetNotificationService(services.NewNotificationService(f.notificationRepo, f.postRepo))
+
+	f.userRepo.On("FindByID", 1).Return(&models.User{ID: 1, Username: "ana"}, nil)
+	f.userRepo.On("FindByUsername", "ana").Return(&models.User{ID: 1, Username: "ana"}, nil)
+	f.userRepo.On("FindByUsername", "beto").Return(&models.User{ID: 2, Username: "beto"}, nil)
+	f.userRepo.On("FindByUsername", "carla").Return(&models.User{ID: 3, Username: "Carla"}, nil)
+	f.userRepo.On("FindByUsername", "nadie").Return(nil, nil)
+	f.notificationRepo.On("FindPreferences", mock.Anything).Return(models.DefaultNotificationPreferences(0), nil)
+	f.notificationRepo.On("CreateOrCoalesce", mock.Anything).Return(nil)
+	return f
+}
+
+// TestCreatePost_StoresMentions: resuelve los @usuario existentes y avisa a los mencionados
+func TestCreatePost_StoresMentions(t *testing.T) {
+	// ARRANGE
+	f := newMentionFixture()
+	f.postRepo.On("SlugExists", "hola").Return(false, nil)
+	f.postRepo.On("Create", mock.AnythingOfType("*models.Post")).Return(nil)
+	f.mentionRepo.On("ReplacePostMentions", mock.Anything, mock.Anything).Return(nil)
+
+	// ACT
+	post, err := f.postService.CreatePost(&models.CreatePostRequest{
+		Title:   "Hola",
+		Content: "Gracias @CARLA, @nadie y @ana. Otra vez @carla",
+	}, 1)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Mention{
+		{UserID: 3, Username: "Carla", Offset: 8, Length: 6},
+		{UserID: 1, Username: "ana", Offset: 25, Length: 4},
+		{UserID: 3, Username: "Carla", Offset: 40, Length: 6},
+	}, post.Mentions)
+	f.mentionRepo.AssertCalled(t, "ReplacePostMentions", post.ID, post.Mentions)
+	// Solo carla: el autor no se notifica a sí mismo y carla recibe una sola
+	f.notificationRepo.AssertNumberOfCalls(t, "CreateOrCoalesce", 1)
+	assert.Equal(t, models.NotificationMention, notificationFor(f.notificationRepo, 3).Type)
+}
+
+// TestCreatePost_DraftMentionsNotNotified: las menciones de un borrador no se avisan
+func TestCreatePost_DraftMentionsNotNotified(t *testing.T) {
+	// ARRANGE
+	f := newMentionFixture()
+	f.postRepo.On("SlugExists", "borrador").Return(false, nil)
+	f.postRepo.On("Create", mock.AnythingOfType("*models.Post")).Return(nil)
+	f.mentionRepo.On("ReplacePostMentions", mock.Anything, mock.Anything).Return(nil)
+
+	// ACT
+	post, err := f.postService.CreatePost(&models.CreatePostRequest{
+		Title:   "Borrador",
+		Content: "Para @beto",
+		Status:  models.PostStatusDraft,
+	}, 1)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Len(t, post.Mentions, 1)
+	f.notificationRepo.AssertNotCalled(t, "CreateOrCoalesce", mock.Anything)
+}
+
+// TestUpdatePost_NotifiesOnlyNewMentions: al editar solo se avisa a los recién mencionados
+func TestUpdatePost_NotifiesOnlyNewMentions(t *testing.T) {
+	// ARRANGE
+	f := newMentionFixture()
+	f.postRepo.On("FindByID", 10).Return(&models.Post{ID: 10, Title: "Hola", Slug: "hola", UserID: 1, Status: models.PostStatusPublished}, nil)
+	f.postRepo.On("Update", mock.AnythingOfType("*models.Post"), "hola").Return(nil)
+	f.mentionRepo.On("PostMentions", []int{10}).Return(map[int][]models.Mention{
+		10: {{UserID: 2, Username: "beto", Offset: 0, Length: 5}},
+	}, nil)
+	f.mentionRepo.On("ReplacePostMentions", 10, mock.Anything).Return(nil)
+
+	// ACT
+	post, err := f.postService.UpdatePost(10, &models.UpdatePostRequest{Title: "Hola", Content: "@beto y ahora @carla"}, 1)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Len(t, post.Mentions, 2)
+	f.notificationRepo.AssertNumberOfCalls(t, "CreateOrCoalesce", 1)
+	assert.NotNil(t, notificationFor(f.notificationRepo, 3))
+}
+
+// TestCreateComment_StoresMentions: las menciones del comentario se guardan con el comentario
+func TestCreateComment_StoresMentions(t *testing.T) {
+	// ARRANGE
+	f := newMentionFixture()
+	f.postRepo.On("FindByID", 10).Return(&models.Post{ID: 10, UserID: 1, Status: models.PostStatusPublished}, nil)
+	f.userRepo.On("FindByID", 2).Return(&models.User{ID: 2, Username: "beto"}, nil)
+	f.postRepo.On("CreateComment", mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Comment).ID = 50
+	}).Return(nil)
+	f.postRepo.On("FindCommenterIDs", 10).Return([]int{2}, nil)
+	f.mentionRepo.On("SaveCommentMentions", 10, 50, mock.Anything).Return(nil)
+
+	// ACT
+	comment, err := f.postService.CreateComment(10, &models.CreateCommentRequest{Content: "`@nadie` ojo @carla"}, 2)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Mention{{UserID: 3, Username: "Carla", Offset: 13, Length: 6}}, comment.Mentions)
+	f.mentionRepo.AssertExpectations(t)
+	f.userRepo.AssertNotCalled(t, "FindByUsername", "nadie")
+}
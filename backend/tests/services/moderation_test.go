@@ -0,0 +1,172 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+	"tp06-testing/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// moderationFixture arma un ModerationService con umbral 2 sobre el post 1
+// de ana (1); beto (2) y carla (3) reportan y mod (9) es moderador
+type moderationFixture struct {
+	reportRepo        *mocks.MockReportRepository
+	suspensionRepo    *mocks.MockSuspensionRepository
+	postRepo          *mocks.MockPostRepository
+	userRepo          *mocks.MockUserRepository
+	moderationService *services.ModerationService
+}
+
+func newModerationFixture() *moderationFixture {
+	f := &moderationFixture{
+		reportRepo:     new(mocks.MockReportRepository),
+		suspensionRepo: new(mocks.MockSuspensionRepository),
+		postRepo:       new(mocks.MockPostRepository),
+		userRepo:       new(mocks.MockUserRepository),
+	}
+	f.moderationService = services.NewModerationService(f.reportRepo, f.suspensionRepo, f.postRepo, f.userRepo, 2)
+
+	f.postRepo.On("FindByID", 1).Return(&models.Post{ID: 1, UserID: 1, Title: "Hola", Content: "Compra ya", Status: models.PostStatusPublished}, nil)
+	f.userRepo.On("FindByID", 1).Return(&models.User{ID: 1, Username: "ana", Role: models.RoleUser}, nil)
+	f.userRepo.On("FindByID", 2).Return(&models.User{ID: 2, Username: "beto", Role: models.RoleUser}, nil)
+	f.userRepo.On("FindByID", 3).Return(&models.User{ID: 3, Username: "carla", Role: models.RoleUser}, nil)
+	f.userRepo.On("FindByID", 9).Return(&models.User{ID: 9, Username: "mod", Role: models.RoleModerator}, nil)
+	return f
+}
+
+// TestReportPost_HidesAtThreshold: el segundo reporte de un usuario
+// distinto oculta el post y deja la acción registrada sin moderador
+func TestReportPost_HidesAtThreshold(t *testing.T) {
+	// ARRANGE
+	f := newModerationFixture()
+	f.reportRepo.On("Create", mock.AnythingOfType("*models.Report")).Return(true, nil).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Report).ID = 7
+	})
+	f.reportRepo.On("CountOpen", 1, 0).Return(2, nil)
+	f.reportRepo.On("SetHidden", 1, 0, true).Return(nil)
+	f.reportRepo.On("AddAction", mock.AnythingOfType("*models.ReportAction")).Return(nil)
+
+	// ACT
+	report, err := f.moderationService.ReportPost(1, &models.CreateReportRequest{Reason: " Spam "}, 3)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReportSpam, report.Reason)
+	assert.Equal(t, 1, report.AuthorID)
+	assert.Equal(t, "Hola\n\nCompra ya", report.Content)
+	f.reportRepo.AssertCalled(t, "SetHidden", 1, 0, true)
+
+	action := f.reportRepo.Calls[len(f.reportRepo.Calls)-1].Arguments.Get(0).(*models.ReportAction)
+	assert.Equal(t, 7, action.ReportID)
+	assert.Equal(t, models.ReportActionHide, action.Action)
+	assert.Nil(t, action.ModeratorID)
+}
+
+// TestReportPost_Validation: no se puede reportar lo propio, repetir un
+// reporte abierto ni usar "other" sin detalle
+func TestReportPost_Validation(t *testing.T) {
+	// ARRANGE
+	f := newModerationFixture()
+	f.reportRepo.On("Create", mock.AnythingOfType("*models.Report")).Return(false, nil)
+
+	// ACT
+	_, ownErr := f.moderationService.ReportPost(1, &models.CreateReportRequest{Reason: models.ReportSpam}, 1)
+	_, otherErr := f.moderationService.ReportPost(1, &models.CreateReportRequest{Reason: models.ReportOther}, 2)
+	_, reasonErr := f.moderationService.ReportPost(1, &models.CreateReportRequest{Reason: "aburrido"}, 2)
+	_, repeatedErr := f.moderationService.ReportPost(1, &models.CreateReportRequest{Reason: models.ReportSpam}, 2)
+
+	// ASSERT
+	assert.EqualError(t, ownErr, services.ErrCannotReportOwn)
+	assert.EqualError(t, otherErr, services.ErrReportDetailsRequired)
+	assert.EqualError(t, reasonErr, services.ErrInvalidReportReason)
+	assert.EqualError(t, repeatedErr, services.ErrAlreadyReported)
+	f.reportRepo.AssertNotCalled(t, "CountOpen", mock.Anything, mock.Anything)
+}
+
+// TestAct_Ban: expulsar al autor lo suspende de forma permanente, también
+// oculta el contenido y cierra el reporte con la acción del moderador; un
+// usuario común no puede hacerlo
+func TestAct_Ban(t *testing.T) {
+	// ARRANGE
+	f := newModerationFixture()
+	report := &models.Report{ID: 5, PostID: 1, CommentID: 4, AuthorID: 2, Status: models.ReportOpen, TargetStatus: models.ReportTargetVisible}
+	f.reportRepo.On("FindByID", 5).Return(report, nil)
+	f.suspensionRepo.On("Create", mock.AnythingOfType("*models.Suspension")).Return(nil)
+	f.reportRepo.On("SetHidden", 1, 4, true).Return(nil)
+	f.reportRepo.On("Close", report, models.ReportResolved, mock.AnythingOfType("*models.ReportAction")).Return(nil)
+	f.reportRepo.On("FindActions", 5).Return([]*models.ReportAction{}, nil)
+
+	// ACT
+	_, forbiddenErr := f.moderationService.Act(3, 5, &models.ModerateReportRequest{Action: models.ReportActionBan})
+	_, err := f.moderationService.Act(9, 5, &models.ModerateReportRequest{Action: "ban", Note: " reincidente "})
+
+	// ASSERT
+	assert.EqualError(t, forbiddenErr, services.ErrNotModerator)
+	assert.NoError(t, err)
+	f.suspensionRepo.AssertNumberOfCalls(t, "Create", 1)
+	suspension := f.suspensionRepo.Calls[0].Arguments.Get(0).(*models.Suspension)
+	assert.Equal(t, 2, suspension.UserID)
+	assert.Equal(t, 9, *suspension.ModeratorID)
+	assert.Equal(t, "reincidente", suspension.Reason)
+	assert.Nil(t, suspension.ExpiresAt)
+	f.reportRepo.AssertCalled(t, "SetHidden", 1, 4, true)
+
+	var action *models.ReportAction
+	for _, call := range f.reportRepo.Calls {
+		if call.Method == "Close" {
+			action = call.Arguments.Get(2).(*models.ReportAction)
+		}
+	}
+	assert.Equal(t, models.ReportActionBan, action.Action)
+	assert.Equal(t, 9, *action.ModeratorID)
+	assert.Equal(t, "reincidente", action.Note)
+}
+
+// TestDismiss_UnhidesContent: descartar un reporte sobre contenido oculto
+// lo vuelve a mostrar y no se puede decidir dos veces
+func TestDismiss_UnhidesContent(t *testing.T) {
+	// ARRANGE
+	f := newModerationFixture()
+	resolvedAt := time.Now()
+	f.reportRepo.On("FindByID", 5).Return(&models.Report{ID: 5, PostID: 1, AuthorID: 1, Status: models.ReportOpen, TargetStatus: models.ReportTargetHidden}, nil).Once()
+	f.reportRepo.On("FindByID", 5).Return(&models.Report{ID: 5, PostID: 1, AuthorID: 1, Status: models.ReportDismissed, ResolvedAt: &resolvedAt, TargetStatus: models.ReportTargetVisible}, nil)
+	f.reportRepo.On("SetHidden", 1, 0, false).Return(nil)
+	f.reportRepo.On("AddAction", mock.AnythingOfType("*models.ReportAction")).Return(nil)
+	f.reportRepo.On("Close", mock.Anything, models.ReportDismissed, mock.AnythingOfType("*models.ReportAction")).Return(nil)
+	f.reportRepo.On("FindActions", 5).Return([]*models.ReportAction{}, nil)
+
+	// ACT
+	report, err := f.moderationService.Dismiss(9, 5, &models.ModerateReportRequest{})
+	_, againErr := f.moderationService.Dismiss(9, 5, &models.ModerateReportRequest{})
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReportTargetVisible, report.TargetStatus)
+	f.reportRepo.AssertCalled(t, "SetHidden", 1, 0, false)
+	assert.EqualError(t, againErr, services.ErrReportClosed)
+}
+
+// TestCreatePost_BannedUser: un usuario expulsado no puede publicar
+func TestCreatePost_BannedUser(t *testing.T) {
+	// ARRANGE
+	postRepo := new(mocks.MockPostRepository)
+	userRepo := new(mocks.MockUserRepository)
+	suspensionRepo := new(mocks.MockSuspensionRepository)
+	postService := services.NewPostService(postRepo, userRepo)
+	postService.SetSuspensionRepository(suspensionRepo)
+	userRepo.On("FindByID", 2).Return(&models.User{ID: 2, Username: "beto"}, nil)
+	suspensionRepo.On("FindActive", 2, mock.AnythingOfType("time.Time")).Return(&models.Suspension{UserID: 2, Reason: "spam"}, nil)
+
+	// ACT
+	post, err := postService.CreatePost(&models.CreatePostRequest{Title: "Hola", Content: "Compra ya"}, 2)
+
+	// ASSERT
+	assert.Nil(t, post)
+	assert.EqualError(t, err, services.ErrUserSuspended)
+	postRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
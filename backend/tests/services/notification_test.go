@@ -0,0 +1,153 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+	"tp06-testing/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newNotificationService() (*services.NotificationService, *mocks.MockNotificationRepository, *mocks.MockPostRepository) {
+	notificationRepo := new(mocks.MockNotificationRepository)
+	postRepo := new(mocks.MockPostRepository)
+	return services.NewNotificationService(notificationRepo, postRepo), notificationRepo, postRepo
+}
+
+// notificationFor busca entre las llamadas a CreateOrCoalesce la del destinatario
+func notificationFor(repo *mocks.MockNotificationRepository, userID int) *models.Notification {
+	for _, call := range repo.Calls {
+		if call.Method != "CreateOrCoalesce" {
+			continue
+		}
+		if n := call.Arguments.Get(0).(*models.Notification); n.UserID == userID {
+			return n
+		}
+	}
+	return nil
+}
+
+// TestNotifyComment_Recipients: autor, participantes y mencionados reciben
+// una sola notificación cada uno y el que comentó ninguna
+func TestNotifyComment_Recipients(t *testing.T) {
+	// ARRANGE
+	notificationService, notificationRepo, postRepo := newNotificationService()
+	post := &models.Post{ID: 10, UserID: 1}
+	comment := &models.Comment{ID: 50, PostID: 10, UserID: 2}
+	postRepo.On("FindCommenterIDs", 10).Return([]int{2, 3, 4}, nil)
+	notificationRepo.On("FindPreferences", mock.Anything).Return(models.DefaultNotificationPreferences(0), nil)
+	notificationRepo.On("CreateOrCoalesce", mock.Anything).Return(nil)
+
+	// ACT
+	err := notificationService.NotifyComment(post, comment, []int{4, 2})
+
+	// ASSERT
+	assert.NoError(t, err)
+	notificationRepo.AssertNumberOfCalls(t, "CreateOrCoalesce", 3)
+	assert.Equal(t, models.NotificationComment, notificationFor(notificationRepo, 1).Type)
+	assert.Equal(t, models.NotificationReply, notificationFor(notificationRepo, 3).Type)
+	assert.Equal(t, models.NotificationMention, notificationFor(notificationRepo, 4).Type)
+	assert.Nil(t, notificationFor(notificationRepo, 2))
+	assert.Equal(t, 50, *notificationFor(notificationRepo, 1).CommentID)
+}
+
+// TestNotifyComment_RespectsPreferences: no se crea si el usuario desactivó ese tipo
+func TestNotifyComment_RespectsPreferences(t *testing.T) {
+	// ARRANGE
+	notificationService, notificationRepo, postRepo := newNotificationService()
+	prefs := models.DefaultNotificationPreferences(1)
+	prefs.Comments = false
+	postRepo.On("FindCommenterIDs", 10).Return([]int{}, nil)
+	notificationRepo.On("FindPreferences", 1).Return(prefs, nil)
+
+	// ACT
+	err := notificationService.NotifyComment(&models.Post{ID: 10, UserID: 1}, &models.Comment{ID: 50, UserID: 2}, nil)
+
+	// ASSERT
+	assert.NoError(t, err)
+	notificationRepo.AssertNotCalled(t, "CreateOrCoalesce", mock.Anything)
+}
+
+// TestGetNotifications_CoalescedMessage: las agrupadas dicen cuántos eventos reúnen
+func TestGetNotifications_CoalescedMessage(t *testing.T) {
+	// ARRANGE
+	notificationService, notificationRepo, _ := newNotificationService()
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	notificationRepo.On("FindByUser", 1, false, (*models.Cursor)(nil), 21).Return([]*models.Notification{
+		{ID: 2, Type: models.NotificationComment, ActorUsername: "ana", PostTitle: "Hola", Count: 5, UpdatedAt: now},
+		{ID: 1, Type: models.NotificationFollow, ActorUsername: "beto", Count: 1, UpdatedAt: now},
+	}, nil)
+	notificationRepo.On("CountUnread", 1).Return(2, nil)
+
+	// ACT
+	page, err := notificationService.GetNotifications(1, false, "", 0)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, 2, page.UnreadCount)
+	assert.Equal(t, `5 comentarios nuevos en "Hola"`, page.Notifications[0].Message)
+	assert.Equal(t, "beto empezó a seguirte", page.Notifications[1].Message)
+	assert.Empty(t, page.NextCursor)
+}
+
+// TestMarkRead_NotFound: no se puede marcar una notificación ajena
+func TestMarkRead_NotFound(t *testing.T) {
+	// ARRANGE
+	notificationService, notificationRepo, _ := newNotificationService()
+	notificationRepo.On("MarkRead", 1, 99).Return(false, nil)
+
+	// ACT
+	err := notificationService.MarkRead(1, 99)
+
+	// ASSERT
+	assert.EqualError(t, err, services.ErrNotificationNotFound)
+}
+
+// TestUpdatePreferences_Partial: solo cambia los campos que vienen
+func TestUpdatePreferences_Partial(t *testing.T) {
+	// ARRANGE
+	notificationService, notificationRepo, _ := newNotificationService()
+	off := false
+	notificationRepo.On("FindPreferences", 1).Return(models.DefaultNotificationPreferences(1), nil)
+	notificationRepo.On("SavePreferences", mock.Anything).Return(nil)
+
+	// ACT
+	prefs, err := notificationService.UpdatePreferences(1, &models.UpdateNotificationPreferencesRequest{Follows: &off})
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.False(t, prefs.Follows)
+	assert.True(t, prefs.Comments)
+	notificationRepo.AssertCalled(t, "SavePreferences", prefs)
+}
+
+// TestCreateComment_NotifiesMentions: el comentario resuelve los @usuario y avisa
+func TestCreateComment_NotifiesMentions(t *testing.T) {
+	// ARRANGE
+	notificationService, notificationRepo, postRepo := newNotificationService()
+	userRepo := new(mocks.MockUserRepository)
+	postService := services.NewPostService(postRepo, userRepo)
+	postService.SetNotificationService(notificationService)
+
+	postRepo.On("FindByID", 10).Return(&models.Post{ID: 10, UserID: 1, Status: models.PostStatusPublished}, nil)
+	userRepo.On("FindByID", 2).Return(&models.User{ID: 2, Username: "beto"}, nil)
+	userRepo.On("FindByUsername", "carla").Return(&models.User{ID: 3, Username: "carla"}, nil)
+	userRepo.On("FindByUsername", "nadie").Return(nil, nil)
+	postRepo.On("CreateComment", mock.Anything).Return(nil)
+	postRepo.On("FindCommenterIDs", 10).Return([]int{2}, nil)
+	notificationRepo.On("FindPreferences", mock.Anything).Return(models.DefaultNotificationPreferences(0), nil)
+	notificationRepo.On("CreateOrCoalesce", mock.Anything).Return(nil)
+
+	// ACT
+	_, err := postService.CreateComment(10, &models.CreateCommentRequest{Content: "Hola @carla y @nadie (mail: x@carla.com)"}, 2)
+
+	// ASSERT
+	assert.NoError(t, err)
+	notificationRepo.AssertNumberOfCalls(t, "CreateOrCoalesce", 2)
+	assert.Equal(t, models.NotificationMention, notificationFor(notificationRepo, 3).Type)
+	assert.Equal(t, models.NotificationComment, notificationFor(notificationRepo, 1).Type)
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"tp06-testing/internal/events"
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+	"tp06-testing/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPostScheduler_RunOnce_UsesClock: el scheduler publica según la hora del reloj
+func TestPostScheduler_RunOnce_UsesClock(t *testing.T) {
+	// ARRANGE
+	mockPostRepo := new(mocks.MockPostRepository)
+	clock := &mocks.FakeClock{Current: time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)}
+	postService := services.NewPostService(mockPostRepo, new(mocks.MockUserRepository))
+	scheduler := services.NewPostScheduler(postService, clock, time.Minute)
+
+	mockPostRepo.On("PublishDue", clock.Current).Return(nil, nil).Once()
+	mockPostRepo.On("PublishDue", clock.Current.Add(time.Hour)).Return([]int{3, 7}, nil).Once()
+	mockPostRepo.On("FindByID", 3).Return(&models.Post{ID: 3, UserID: 1, Status: models.PostStatusPublished}, nil)
+	mockPostRepo.On("FindByID", 7).Return(&models.Post{ID: 7, UserID: 1, Status: models.PostStatusPublished}, nil)
+
+	// ACT
+	first, err1 := scheduler.RunOnce()
+	clock.Advance(time.Hour)
+	second, err2 := scheduler.RunOnce()
+
+	// ASSERT
+	assert.NoError(t, err1)
+	assert.Empty(t, first)
+	assert.NoError(t, err2)
+	assert.Len(t, second, 2)
+	assert.Equal(t, 3, second[0].ID)
+	assert.Equal(t, 7, second[1].ID)
+	mockPostRepo.AssertExpectations(t)
+}
+
+// TestPostScheduler_RunOnce_AnnouncesPosts: un post programado se anuncia
+// al publicarse igual que con PublishPost (menciones y post.created); uno
+// de solo seguidores avisa a los mencionados pero no sale en el stream
+func TestPostScheduler_RunOnce_AnnouncesPosts(t *testing.T) {
+	// ARRANGE
+	f := newMentionFixture()
+	broker := events.NewBroker(10, 10)
+	f.postService.SetEventBroker(broker)
+	sub, _, _ := broker.Subscribe(nil, 0)
+	clock := &mocks.FakeClock{Current: time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)}
+	scheduler := services.NewPostScheduler(f.postService, clock, time.Minute)
+
+	f.postRepo.On("PublishDue", clock.Current).Return([]int{3, 4}, nil)
+	f.postRepo.On("FindByID", 3).Return(&models.Post{ID: 3, UserID: 1, Title: "Programado", Content: "Hola @beto", Status: models.PostStatusPublished}, nil)
+	f.postRepo.On("FindByID", 4).Return(&models.Post{ID: 4, UserID: 1, Title: "Solo seguidores", Content: "texto", Status: models.PostStatusPublished, Visibility: models.PostVisibilityFollowers}, nil)
+	f.mentionRepo.On("PostMentions", []int{3, 4}).Return(map[int][]models.Mention{
+		3: {{UserID: 2, Username: "beto", Offset: 5, Length: 5}},
+	}, nil)
+
+	// ACT
+	posts, err := scheduler.RunOnce()
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Len(t, posts, 2)
+	assert.Len(t, sub.C, 1)
+	event := <-sub.C
+	assert.Equal(t, events.PostCreated, event.Type)
+	assert.Equal(t, 3, event.PostID)
+	f.notificationRepo.AssertNumberOfCalls(t, "CreateOrCoalesce", 1)
+	assert.Equal(t, models.NotificationMention, notificationFor(f.notificationRepo, 2).Type)
+}
@@ -2,9 +2,12 @@ package services
 
 import (
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"tp06-testing/internal/models"
+	"tp06-testing/internal/repository"
 	"tp06-testing/internal/services"
 	"tp06-testing/tests/mocks"
 
@@ -28,7 +31,8 @@ func TestCreatePost_Success(t *testing.T) {
 	mockUserRepo.On("FindByID", 1).Return(existingUser, nil)
 	// ← FIN
 
-	// Configurar mock: Create debe ejecutarse correctamente
+	// Configurar mock: el slug está libre y Create debe ejecutarse correctamente
+	mockRepo.On("SlugExists", "test-post").Return(false, nil)
 	mockRepo.On("Create", mock.AnythingOfType("*models.Post")).Return(nil)
 
 	req := &models.CreatePostRequest{
@@ -44,6 +48,7 @@ func TestCreatePost_Success(t *testing.T) {
 	assert.NotNil(t, post)
 	assert.Equal(t, "Test Post", post.Title)
 	assert.Equal(t, "This is a test post", post.Content)
+	assert.Equal(t, "test-post", post.Slug)
 
 	// Verificar que se llamaron los métodos del mock
 	mockRepo.AssertExpectations(t)
@@ -89,6 +94,7 @@ func TestCreatePost_RepoError(t *testing.T) {
 	mockUserRepo.On("FindByID", 1).Return(existingUser, nil)
 
 	// El repo Create falla
+	mockRepo.On("SlugExists", "test-post").Return(false, nil)
 	mockRepo.On("Create", mock.AnythingOfType("*models.Post")).Return(errors.New("db error"))
 
 	req := &models.CreatePostRequest{
@@ -156,6 +162,28 @@ func TestCreatePost_ContentVacio(t *testing.T) {
 	mockUserRepo.AssertNotCalled(t, "FindByID")
 }
 
+// TestCreatePost_ContentTooLong: el largo máximo se cuenta en caracteres,
+// no en bytes
+func TestCreatePost_ContentTooLong(t *testing.T) {
+	// ARRANGE
+	mockRepo := new(mocks.MockPostRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	postService := services.NewPostService(mockRepo, mockUserRepo)
+	mockUserRepo.On("FindByID", 1).Return(&models.User{ID: 1}, nil)
+	mockRepo.On("SlugExists", "test-post").Return(false, nil)
+	mockRepo.On("Create", mock.AnythingOfType("*models.Post")).Return(nil)
+
+	// ACT
+	_, tooLong := postService.CreatePost(&models.CreatePostRequest{Title: "Test Post", Content: strings.Repeat("a", services.MaxContentLength+1)}, 1)
+	post, err := postService.CreatePost(&models.CreatePostRequest{Title: "Test Post", Content: strings.Repeat("á", services.MaxContentLength)}, 1)
+
+	// ASSERT
+	assert.EqualError(t, tooLong, services.ErrContentTooLong)
+	assert.NoError(t, err)
+	assert.NotNil(t, post)
+	mockRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
 // TestDeletePost_Success prueba eliminación exitosa por el autor
 func TestDeletePost_Success(t *testing.T) {
 	// ARRANGE
@@ -173,7 +201,7 @@ func TestDeletePost_Success(t *testing.T) {
 
 	// Configurar mocks
 	mockRepo.On("FindByID", 1).Return(existingPost, nil)
-	mockRepo.On("Delete", 1).Return(nil)
+	mockRepo.On("Delete", 1, 1).Return(nil)
 
 	// ACT: El usuario 1 elimina su propio post
 	err := postService.DeletePost(1, 1)
@@ -257,7 +285,7 @@ func TestDeleteComment_Success(t *testing.T) {
 	// Configurar mocks
 	mockRepo.On("FindByID", 1).Return(existingPost, nil)
 	mockUserRepo.On("FindByID", 1).Return(existingUser, nil)
-	mockRepo.On("DeleteComment", 1, 10, 1).Return(nil)
+	mockRepo.On("DeleteComment", 1, 10, 1, 1).Return(nil)
 
 	// ACT: El usuario 1 elimina su propio comentario
 	err := postService.DeleteComment(1, 10, 1)
@@ -339,7 +367,7 @@ func TestDeleteComment_NoEsAutor(t *testing.T) {
 	mockUserRepo.On("FindByID", 2).Return(existingUser, nil)
 
 	// Usuario 2 intenta eliminar comentario del usuario 1
-	mockRepo.On("DeleteComment", 1, 10, 2).Return(errors.New("no tienes permiso para eliminar este comentario o no existe"))
+	mockRepo.On("DeleteComment", 1, 10, 2, 2).Return(errors.New("no tienes permiso para eliminar este comentario o no existe"))
 
 	// ACT
 	err := postService.DeleteComment(1, 10, 2)
@@ -361,10 +389,10 @@ func TestGetAllPosts_Success(t *testing.T) {
 		{ID: 1, Title: "Post 1", Content: "Content 1", UserID: 1},
 		{ID: 2, Title: "Post 2", Content: "Content 2", UserID: 2},
 	}
-	mockPostRepo.On("FindAll").Return(mockPosts, nil)
+	mockPostRepo.On("FindAll", 0).Return(mockPosts, nil)
 
 	// ACT
-	posts, err := postService.GetAllPosts()
+	posts, err := postService.GetAllPosts(0, services.SortNew)
 
 	// ASSERT
 	assert.NoError(t, err)
@@ -383,12 +411,13 @@ func TestGetPostByID_Success(t *testing.T) {
 		ID:      1,
 		Title:   "Test Post",
 		Content: "Test Content",
+		Status:  models.PostStatusPublished,
 		UserID:  1,
 	}
 	mockPostRepo.On("FindByID", 1).Return(mockPost, nil)
 
 	// ACT
-	post, err := postService.GetPostByID(1)
+	post, err := postService.GetPostByID(1, 0)
 
 	// ASSERT
 	assert.NoError(t, err)
@@ -404,7 +433,7 @@ func TestCreateComment_Success(t *testing.T) {
 	mockUserRepo := new(mocks.MockUserRepository)
 	postService := services.NewPostService(mockPostRepo, mockUserRepo)
 
-	mockPost := &models.Post{ID: 1, Title: "Post", UserID: 1}
+	mockPost := &models.Post{ID: 1, Title: "Post", Status: models.PostStatusPublished, UserID: 1}
 	mockUser := &models.User{ID: 2, Username: "commenter"}
 
 	mockPostRepo.On("FindByID", 1).Return(mockPost, nil)
@@ -435,17 +464,17 @@ func TestGetCommentsByPostID_Success(t *testing.T) {
 	mockUserRepo := new(mocks.MockUserRepository)
 	postService := services.NewPostService(mockPostRepo, mockUserRepo)
 
-	mockPost := &models.Post{ID: 1, Title: "Post", UserID: 1}
+	mockPost := &models.Post{ID: 1, Title: "Post", UserID: 1, Status: models.PostStatusPublished}
 	mockComments := []*models.Comment{
 		{ID: 1, PostID: 1, UserID: 1, Content: "Comment 1"},
 		{ID: 2, PostID: 1, UserID: 2, Content: "Comment 2"},
 	}
 
 	mockPostRepo.On("FindByID", 1).Return(mockPost, nil)
-	mockPostRepo.On("FindCommentsByPostID", 1).Return(mockComments, nil)
+	mockPostRepo.On("FindCommentsByPostID", 1, 0).Return(mockComments, nil)
 
 	// ACT
-	comments, err := postService.GetCommentsByPostID(1)
+	comments, err := postService.GetCommentsByPostID(1, 0, services.SortNew)
 
 	// ASSERT
 	assert.NoError(t, err)
@@ -462,10 +491,10 @@ func TestGetAllPosts_Empty(t *testing.T) {
 	mockUserRepo := new(mocks.MockUserRepository)
 	postService := services.NewPostService(mockPostRepo, mockUserRepo)
 
-	mockPostRepo.On("FindAll").Return(nil, nil)
+	mockPostRepo.On("FindAll", 0).Return(nil, nil)
 
 	// ACT
-	posts, err := postService.GetAllPosts()
+	posts, err := postService.GetAllPosts(0, services.SortNew)
 
 	// ASSERT
 	assert.NoError(t, err)
@@ -484,7 +513,7 @@ func TestGetPostByID_InvalidID(t *testing.T) {
 	postService := services.NewPostService(mockPostRepo, mockUserRepo)
 
 	// ACT
-	post, err := postService.GetPostByID(0)
+	post, err := postService.GetPostByID(0, 0)
 
 	// ASSERT
 	assert.Error(t, err)
@@ -503,7 +532,7 @@ func TestGetPostByID_NotFound(t *testing.T) {
 	mockPostRepo.On("FindByID", 999).Return(nil, nil)
 
 	// ACT
-	post, err := postService.GetPostByID(999)
+	post, err := postService.GetPostByID(999, 0)
 
 	// ASSERT
 	assert.Error(t, err)
@@ -536,6 +565,27 @@ func TestCreateComment_EmptyContent(t *testing.T) {
 	mockPostRepo.AssertNotCalled(t, "CreateComment")
 }
 
+// TestCreateComment_ContentTooLong: un comentario que supera el largo
+// máximo se rechaza antes de buscar el post
+func TestCreateComment_ContentTooLong(t *testing.T) {
+	// ARRANGE
+	mockPostRepo := new(mocks.MockPostRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	postService := services.NewPostService(mockPostRepo, mockUserRepo)
+
+	req := &models.CreateCommentRequest{
+		Content: strings.Repeat("á", services.MaxContentLength+1),
+	}
+
+	// ACT
+	comment, err := postService.CreateComment(1, req, 1)
+
+	// ASSERT
+	assert.Nil(t, comment)
+	assert.EqualError(t, err, services.ErrContentTooLong)
+	mockPostRepo.AssertNotCalled(t, "FindByID", mock.Anything)
+}
+
 // TestCreateComment_PostNotFound prueba comentar en post inexistente
 func TestCreateComment_PostNotFound(t *testing.T) {
 	// ARRANGE
@@ -567,7 +617,7 @@ func TestCreateComment_UserNotFound(t *testing.T) {
 	mockUserRepo := new(mocks.MockUserRepository)
 	postService := services.NewPostService(mockPostRepo, mockUserRepo)
 
-	mockPost := &models.Post{ID: 1, Title: "Post", UserID: 1}
+	mockPost := &models.Post{ID: 1, Title: "Post", Status: models.PostStatusPublished, UserID: 1}
 	mockPostRepo.On("FindByID", 1).Return(mockPost, nil)
 	mockUserRepo.On("FindByID", 999).Return(nil, nil)
 
@@ -599,7 +649,7 @@ func TestGetCommentsByPostID_PostNotFound(t *testing.T) {
 	mockPostRepo.On("FindByID", 999).Return(nil, nil)
 
 	// ACT
-	comments, err := postService.GetCommentsByPostID(999)
+	comments, err := postService.GetCommentsByPostID(999, 0, services.SortNew)
 
 	// ASSERT
 	assert.Error(t, err)
@@ -616,12 +666,12 @@ func TestGetCommentsByPostID_Empty(t *testing.T) {
 	mockUserRepo := new(mocks.MockUserRepository)
 	postService := services.NewPostService(mockPostRepo, mockUserRepo)
 
-	mockPost := &models.Post{ID: 1, Title: "Post", UserID: 1}
+	mockPost := &models.Post{ID: 1, Title: "Post", UserID: 1, Status: models.PostStatusPublished}
 	mockPostRepo.On("FindByID", 1).Return(mockPost, nil)
-	mockPostRepo.On("FindCommentsByPostID", 1).Return(nil, nil)
+	mockPostRepo.On("FindCommentsByPostID", 1, 0).Return(nil, nil)
 
 	// ACT
-	comments, err := postService.GetCommentsByPostID(1)
+	comments, err := postService.GetCommentsByPostID(1, 0, services.SortNew)
 
 	// ASSERT
 	assert.NoError(t, err)
@@ -629,3 +679,334 @@ func TestGetCommentsByPostID_Empty(t *testing.T) {
 	assert.Len(t, comments, 0)
 	mockPostRepo.AssertExpectations(t)
 }
+
+// TestSlugify prueba la transliteración de acentos y la limpieza de símbolos
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "que-es-el-diseno", services.Slugify("¿Qué es el Diseño?"))
+	assert.Equal(t, "pinguino-y-ciguena", services.Slugify("  Pingüino y cigüeña!! "))
+	assert.Equal(t, "go-1-24-novedades", services.Slugify("Go 1.24: novedades"))
+	assert.Equal(t, "post", services.Slugify("¡¿!?"))
+}
+
+// TestCreatePost_SlugCollision: si el slug ya existe se agrega un sufijo numérico
+func TestCreatePost_SlugCollision(t *testing.T) {
+	// ARRANGE
+	mockRepo := new(mocks.MockPostRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	postService := services.NewPostService(mockRepo, mockUserRepo)
+
+	mockUserRepo.On("FindByID", 1).Return(&models.User{ID: 1, Username: "u"}, nil)
+	mockRepo.On("SlugExists", "mi-post").Return(true, nil)
+	mockRepo.On("SlugExists", "mi-post-2").Return(true, nil)
+	mockRepo.On("SlugExists", "mi-post-3").Return(false, nil)
+	mockRepo.On("Create", mock.AnythingOfType("*models.Post")).Return(nil)
+
+	// ACT
+	post, err := postService.CreatePost(&models.CreatePostRequest{Title: "Mi post", Content: "Contenido"}, 1)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, "mi-post-3", post.Slug)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestCreatePost_SlugTakenConcurrently: si otro post toma el slug entre la
+// verificación y el INSERT, se reintenta con el siguiente sufijo libre
+func TestCreatePost_SlugTakenConcurrently(t *testing.T) {
+	// ARRANGE
+	mockRepo := new(mocks.MockPostRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	postService := services.NewPostService(mockRepo, mockUserRepo)
+
+	mockUserRepo.On("FindByID", 1).Return(&models.User{ID: 1, Username: "u"}, nil)
+	mockRepo.On("SlugExists", "mi-post").Return(false, nil).Once()
+	mockRepo.On("SlugExists", "mi-post").Return(true, nil)
+	mockRepo.On("SlugExists", "mi-post-2").Return(false, nil)
+	mockRepo.On("Create", mock.AnythingOfType("*models.Post")).Return(errors.New(repository.ErrSlugTaken)).Once()
+	mockRepo.On("Create", mock.AnythingOfType("*models.Post")).Return(nil)
+
+	// ACT
+	post, err := postService.CreatePost(&models.CreatePostRequest{Title: "Mi post", Content: "Contenido"}, 1)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, "mi-post-2", post.Slug)
+	mockRepo.AssertNumberOfCalls(t, "Create", 2)
+}
+
+// TestCreatePost_SlugTakenTooManyTimes: si el slug sigue en conflicto
+// después de varios intentos se devuelve un error de conflicto
+func TestCreatePost_SlugTakenTooManyTimes(t *testing.T) {
+	// ARRANGE
+	mockRepo := new(mocks.MockPostRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	postService := services.NewPostService(mockRepo, mockUserRepo)
+
+	mockUserRepo.On("FindByID", 1).Return(&models.User{ID: 1, Username: "u"}, nil)
+	mockRepo.On("SlugExists", mock.AnythingOfType("string")).Return(false, nil)
+	mockRepo.On("Create", mock.AnythingOfType("*models.Post")).Return(errors.New(repository.ErrSlugTaken))
+
+	// ACT
+	post, err := postService.CreatePost(&models.CreatePostRequest{Title: "Mi post", Content: "Contenido"}, 1)
+
+	// ASSERT
+	assert.Nil(t, post)
+	assert.EqualError(t, err, services.ErrSlugConflict)
+	mockRepo.AssertNumberOfCalls(t, "Create", 5)
+}
+
+// TestGetPostBySlug_Success prueba obtener un post por su slug
+func TestGetPostBySlug_Success(t *testing.T) {
+	// ARRANGE
+	mockPostRepo := new(mocks.MockPostRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	postService := services.NewPostService(mockPostRepo, mockUserRepo)
+
+	mockPost := &models.Post{ID: 1, Title: "Post", Slug: "post-nuevo", Status: models.PostStatusPublished, UserID: 1}
+	mockPostRepo.On("FindBySlug", "post-viejo").Return(mockPost, nil)
+
+	// ACT
+	post, err := postService.GetPostBySlug("post-viejo", 0)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, "post-nuevo", post.Slug)
+	mockPostRepo.AssertExpectations(t)
+}
+
+// TestGetPostBySlug_NotFound prueba un slug inexistente
+func TestGetPostBySlug_NotFound(t *testing.T) {
+	// ARRANGE
+	mockPostRepo := new(mocks.MockPostRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	postService := services.NewPostService(mockPostRepo, mockUserRepo)
+
+	mockPostRepo.On("FindBySlug", "no-existe").Return(nil, nil)
+
+	// ACT
+	post, err := postService.GetPostBySlug("no-existe", 0)
+
+	// ASSERT
+	assert.Error(t, err)
+	assert.Nil(t, post)
+	assert.Equal(t, "post no encontrado", err.Error())
+}
+
+// TestUpdatePost_TitleChangesSlug: al cambiar el título se genera un slug nuevo
+// y el anterior se pasa al repositorio para guardarlo como redirección
+func TestUpdatePost_TitleChangesSlug(t *testing.T) {
+	// ARRANGE
+	mockPostRepo := new(mocks.MockPostRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	postService := services.NewPostService(mockPostRepo, mockUserRepo)
+
+	existingPost := &models.Post{ID: 1, Title: "Título viejo", Slug: "titulo-viejo", UserID: 1}
+	mockPostRepo.On("FindByID", 1).Return(existingPost, nil)
+	mockPostRepo.On("SlugExists", "titulo-nuevo").Return(false, nil)
+	mockPostRepo.On("Update", mock.AnythingOfType("*models.Post"), "titulo-viejo").Return(nil)
+
+	// ACT
+	post, err := postService.UpdatePost(1, &models.UpdatePostRequest{Title: "Título nuevo", Content: "Contenido"}, 1)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, "titulo-nuevo", post.Slug)
+	mockPostRepo.AssertExpectations(t)
+}
+
+// TestUpdatePost_NoEsAutor: solo el autor puede editar
+func TestUpdatePost_NoEsAutor(t *testing.T) {
+	// ARRANGE
+	mockPostRepo := new(mocks.MockPostRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	postService := services.NewPostService(mockPostRepo, mockUserRepo)
+
+	existingPost := &models.Post{ID: 1, Title: "Post", Slug: "post", UserID: 1}
+	mockPostRepo.On("FindByID", 1).Return(existingPost, nil)
+
+	// ACT
+	post, err := postService.UpdatePost(1, &models.UpdatePostRequest{Title: "Otro título", Content: "Contenido"}, 2)
+
+	// ASSERT
+	assert.Error(t, err)
+	assert.Nil(t, post)
+	assert.Equal(t, "no tienes permiso para editar este post", err.Error())
+	mockPostRepo.AssertNotCalled(t, "Update")
+}
+
+// TestCreatePost_Draft: un borrador se crea sin fecha de publicación
+func TestCreatePost_Draft(t *testing.T) {
+	// ARRANGE
+	mockRepo := new(mocks.MockPostRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	postService := services.NewPostService(mockRepo, mockUserRepo)
+
+	mockUserRepo.On("FindByID", 1).Return(&models.User{ID: 1, Username: "u"}, nil)
+	mockRepo.On("SlugExists", "borrador").Return(false, nil)
+	mockRepo.On("Create", mock.AnythingOfType("*models.Post")).Return(nil)
+
+	req := &models.CreatePostRequest{Title: "Borrador", Content: "Contenido", Status: models.PostStatusDraft}
+
+	// ACT
+	post, err := postService.CreatePost(req, 1)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, models.PostStatusDraft, post.Status)
+	assert.Nil(t, post.PublishedAt)
+}
+
+// TestCreatePost_ScheduledInThePast: programar con fecha pasada -> error
+func TestCreatePost_ScheduledInThePast(t *testing.T) {
+	// ARRANGE
+	mockRepo := new(mocks.MockPostRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	postService := services.NewPostService(mockRepo, mockUserRepo)
+
+	clock := &mocks.FakeClock{Current: time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)}
+	postService.SetClock(clock)
+
+	past := clock.Current.Add(-time.Hour)
+	req := &models.CreatePostRequest{Title: "Programado", Content: "Contenido", Status: models.PostStatusScheduled, PublishAt: &past}
+
+	// ACT
+	post, err := postService.CreatePost(req, 1)
+
+	// ASSERT
+	assert.Error(t, err)
+	assert.Nil(t, post)
+	assert.Equal(t, "la fecha de publicación debe ser futura", err.Error())
+	mockRepo.AssertNotCalled(t, "Create")
+}
+
+// TestGetPostByID_DraftOfAnotherUser: un borrador ajeno se informa como inexistente
+func TestGetPostByID_DraftOfAnotherUser(t *testing.T) {
+	// ARRANGE
+	mockPostRepo := new(mocks.MockPostRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	postService := services.NewPostService(mockPostRepo, mockUserRepo)
+
+	draft := &models.Post{ID: 1, Title: "Borrador", Status: models.PostStatusDraft, UserID: 1}
+	mockPostRepo.On("FindByID", 1).Return(draft, nil)
+
+	// ACT
+	asOther, errOther := postService.GetPostByID(1, 2)
+	asOwner, errOwner := postService.GetPostByID(1, 1)
+
+	// ASSERT
+	assert.Error(t, errOther)
+	assert.Nil(t, asOther)
+	assert.NoError(t, errOwner)
+	assert.Equal(t, draft, asOwner)
+}
+
+// TestPublishPost_FutureDateSchedules: publicar con fecha futura deja el post programado
+func TestPublishPost_FutureDateSchedules(t *testing.T) {
+	// ARRANGE
+	mockPostRepo := new(mocks.MockPostRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	postService := services.NewPostService(mockPostRepo, mockUserRepo)
+
+	clock := &mocks.FakeClock{Current: time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)}
+	postService.SetClock(clock)
+
+	draft := &models.Post{ID: 1, Title: "Borrador", Status: models.PostStatusDraft, UserID: 1}
+	mockPostRepo.On("FindByID", 1).Return(draft, nil)
+	mockPostRepo.On("UpdateStatus", 1, models.PostStatusScheduled, mock.AnythingOfType("*time.Time")).Return(nil)
+
+	future := clock.Current.Add(24 * time.Hour)
+
+	// ACT
+	post, err := postService.PublishPost(1, &models.PublishPostRequest{PublishAt: &future}, 1)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, models.PostStatusScheduled, post.Status)
+	assert.True(t, post.PublishedAt.Equal(future))
+	mockPostRepo.AssertExpectations(t)
+}
+
+// TestPublishPost_Now: publicar sin fecha usa la hora del reloj
+func TestPublishPost_Now(t *testing.T) {
+	// ARRANGE
+	mockPostRepo := new(mocks.MockPostRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	postService := services.NewPostService(mockPostRepo, mockUserRepo)
+
+	clock := &mocks.FakeClock{Current: time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)}
+	postService.SetClock(clock)
+
+	draft := &models.Post{ID: 1, Title: "Borrador", Status: models.PostStatusDraft, UserID: 1}
+	mockPostRepo.On("FindByID", 1).Return(draft, nil)
+	mockPostRepo.On("UpdateStatus", 1, models.PostStatusPublished, mock.AnythingOfType("*time.Time")).Return(nil)
+
+	// ACT
+	post, err := postService.PublishPost(1, &models.PublishPostRequest{}, 1)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.True(t, post.IsPublished())
+	assert.True(t, post.PublishedAt.Equal(clock.Current))
+}
+
+// TestUnpublishPost_NoEsAutor: solo el autor puede despublicar
+func TestUnpublishPost_NoEsAutor(t *testing.T) {
+	// ARRANGE
+	mockPostRepo := new(mocks.MockPostRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	postService := services.NewPostService(mockPostRepo, mockUserRepo)
+
+	published := &models.Post{ID: 1, Title: "Post", Status: models.PostStatusPublished, UserID: 1}
+	mockPostRepo.On("FindByID", 1).Return(published, nil)
+
+	// ACT
+	post, err := postService.UnpublishPost(1, 2)
+
+	// ASSERT
+	assert.Error(t, err)
+	assert.Nil(t, post)
+	mockPostRepo.AssertNotCalled(t, "UpdateStatus")
+}
+
+// TestGetAllPosts_RendersContentHTML: los posts se devuelven con su HTML sanitizado
+func TestGetAllPosts_RendersContentHTML(t *testing.T) {
+	// ARRANGE
+	mockPostRepo := new(mocks.MockPostRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	postService := services.NewPostService(mockPostRepo, mockUserRepo)
+
+	mockPosts := []*models.Post{
+		{ID: 1, Title: "Post", Content: "**hola** <script>x</script>", Status: models.PostStatusPublished, UserID: 1},
+	}
+	mockPostRepo.On("FindAll", 0).Return(mockPosts, nil)
+
+	// ACT
+	posts, err := postService.GetAllPosts(0, services.SortNew)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, "<p><strong>hola</strong> &lt;script&gt;x&lt;/script&gt;</p>", posts[0].ContentHTML)
+	assert.Equal(t, "**hola** <script>x</script>", posts[0].Content)
+}
+
+// TestGetPostByID_ReadingStats: el post trae resumen, palabras y tiempo de lectura
+func TestGetPostByID_ReadingStats(t *testing.T) {
+	// ARRANGE
+	mockPostRepo := new(mocks.MockPostRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	postService := services.NewPostService(mockPostRepo, mockUserRepo)
+
+	content := strings.Repeat("palabra ", 450) + "fin."
+	mockPost := &models.Post{ID: 1, Title: "Post", Content: content, Status: models.PostStatusPublished, UserID: 1}
+	mockPostRepo.On("FindByID", 1).Return(mockPost, nil)
+
+	// ACT
+	post, err := postService.GetPostByID(1, 0)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, 451, post.WordCount)
+	assert.Equal(t, 3, post.ReadingTime)
+	assert.True(t, strings.HasSuffix(post.Excerpt, "…"))
+	assert.Equal(t, content, post.Content)
+}
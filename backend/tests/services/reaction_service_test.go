@@ -0,0 +1,140 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+	"tp06-testing/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newReactionServiceWithMocks() (*services.ReactionService, *mocks.MockReactionRepository, *mocks.MockPostRepository, *mocks.MockUserRepository) {
+	reactionRepo := new(mocks.MockReactionRepository)
+	postRepo := new(mocks.MockPostRepository)
+	userRepo := new(mocks.MockUserRepository)
+	return services.NewReactionService(reactionRepo, postRepo, userRepo), reactionRepo, postRepo, userRepo
+}
+
+// TestReactToPost_Success: agrega la reacción y devuelve el resumen actualizado
+func TestReactToPost_Success(t *testing.T) {
+	// ARRANGE
+	reactionService, reactionRepo, postRepo, userRepo := newReactionServiceWithMocks()
+	userRepo.On("FindByID", 2).Return(&models.User{ID: 2}, nil)
+	postRepo.On("FindByID", 1).Return(&models.Post{ID: 1, UserID: 1, Status: models.PostStatusPublished}, nil)
+	reactionRepo.On("AddPostReaction", 1, 2, models.ReactionLove).Return(nil)
+	reactionRepo.On("PostReactions", []int{1}, 2).Return(map[int]*models.ReactionSummary{
+		1: {Counts: map[string]int{models.ReactionLove: 3}, Total: 3, Mine: []string{models.ReactionLove}},
+	}, nil)
+
+	// ACT
+	summary, err := reactionService.ReactToPost(1, 2, models.ReactionLove)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, 3, summary.Counts[models.ReactionLove])
+	assert.Equal(t, []string{models.ReactionLove}, summary.Mine)
+	reactionRepo.AssertExpectations(t)
+}
+
+// TestReactToPost_InvalidType: solo se aceptan los tipos del conjunto fijo
+func TestReactToPost_InvalidType(t *testing.T) {
+	// ARRANGE
+	reactionService, reactionRepo, postRepo, _ := newReactionServiceWithMocks()
+
+	// ACT
+	summary, err := reactionService.ReactToPost(1, 2, "dislike")
+
+	// ASSERT
+	assert.Nil(t, summary)
+	assert.EqualError(t, err, services.ErrInvalidReaction)
+	postRepo.AssertNotCalled(t, "FindByID", mock.Anything)
+	reactionRepo.AssertNotCalled(t, "AddPostReaction", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestReactToPost_DraftOfAnotherUser: no se puede reaccionar a lo que no se ve
+func TestReactToPost_DraftOfAnotherUser(t *testing.T) {
+	// ARRANGE
+	reactionService, reactionRepo, postRepo, userRepo := newReactionServiceWithMocks()
+	userRepo.On("FindByID", 2).Return(&models.User{ID: 2}, nil)
+	postRepo.On("FindByID", 1).Return(&models.Post{ID: 1, UserID: 1, Status: models.PostStatusDraft}, nil)
+
+	// ACT
+	summary, err := reactionService.ReactToPost(1, 2, models.ReactionLike)
+
+	// ASSERT
+	assert.Nil(t, summary)
+	assert.EqualError(t, err, services.ErrPostNotFound)
+	reactionRepo.AssertNotCalled(t, "AddPostReaction", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestReactToComment_CommentFromAnotherPost: el comentario debe ser del post de la ruta
+func TestReactToComment_CommentFromAnotherPost(t *testing.T) {
+	// ARRANGE
+	reactionService, reactionRepo, postRepo, userRepo := newReactionServiceWithMocks()
+	userRepo.On("FindByID", 2).Return(&models.User{ID: 2}, nil)
+	postRepo.On("FindByID", 1).Return(&models.Post{ID: 1, UserID: 1, Status: models.PostStatusPublished}, nil)
+	postRepo.On("FindCommentByID", 7).Return(&models.Comment{ID: 7, PostID: 5}, nil)
+
+	// ACT
+	summary, err := reactionService.ReactToComment(1, 7, 2, models.ReactionLike)
+
+	// ASSERT
+	assert.Nil(t, summary)
+	assert.EqualError(t, err, services.ErrCommentNotFound)
+	reactionRepo.AssertNotCalled(t, "AddCommentReaction", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestGetAllPosts_TopSortDecaysOverTime: un post reciente con pocas
+// reacciones supera a uno viejo con muchas
+func TestGetAllPosts_TopSortDecaysOverTime(t *testing.T) {
+	// ARRANGE
+	postRepo := new(mocks.MockPostRepository)
+	reactionRepo := new(mocks.MockReactionRepository)
+	postService := services.NewPostService(postRepo, new(mocks.MockUserRepository))
+	postService.SetReactionRepository(reactionRepo)
+	clock := &mocks.FakeClock{Current: time.Date(2025, 10, 10, 12, 0, 0, 0, time.UTC)}
+	postService.SetClock(clock)
+
+	hoursAgo := func(h int) *time.Time {
+		at := clock.Current.Add(-time.Duration(h) * time.Hour)
+		return &at
+	}
+	posts := []*models.Post{
+		{ID: 3, Title: "Nuevo sin reacciones", Status: models.PostStatusPublished, PublishedAt: hoursAgo(0)},
+		{ID: 2, Title: "Reciente", Status: models.PostStatusPublished, PublishedAt: hoursAgo(1)},
+		{ID: 1, Title: "Viejo", Status: models.PostStatusPublished, PublishedAt: hoursAgo(72)},
+	}
+	postRepo.On("FindAll", 5).Return(posts, nil)
+	reactionRepo.On("PostReactions", []int{3, 2, 1}, 5).Return(map[int]*models.ReactionSummary{
+		2: {Counts: map[string]int{models.ReactionLike: 4}, Total: 4, Mine: []string{models.ReactionLike}},
+		1: {Counts: map[string]int{models.ReactionLike: 50}, Total: 50, Mine: []string{}},
+	}, nil)
+
+	// ACT
+	result, err := postService.GetAllPosts(5, services.SortTop)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 1, 3}, []int{result[0].ID, result[1].ID, result[2].ID})
+	assert.Equal(t, []string{models.ReactionLike}, result[0].MyReactions)
+	assert.Equal(t, map[string]int{}, result[2].Reactions) // sin reacciones: objeto vacío, no null
+}
+
+// TestGetAllPosts_InvalidSort: un orden desconocido es un error
+func TestGetAllPosts_InvalidSort(t *testing.T) {
+	// ARRANGE
+	postRepo := new(mocks.MockPostRepository)
+	postService := services.NewPostService(postRepo, new(mocks.MockUserRepository))
+
+	// ACT
+	posts, err := postService.GetAllPosts(0, "random")
+
+	// ASSERT
+	assert.Nil(t, posts)
+	assert.EqualError(t, err, services.ErrInvalidSort)
+	postRepo.AssertNotCalled(t, "FindAll", mock.Anything)
+}
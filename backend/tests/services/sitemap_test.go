@@ -0,0 +1,83 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+	"tp06-testing/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestSitemap_PageCount: un sitemap hasta el límite y un índice cuando se supera
+func TestSitemap_PageCount(t *testing.T) {
+	// ARRANGE
+	emptyRepo := new(mocks.MockPostRepository)
+	emptyRepo.On("CountPublished").Return(0, nil)
+	fullRepo := new(mocks.MockPostRepository)
+	fullRepo.On("CountPublished").Return(2001, nil)
+
+	emptyService := services.NewSitemapService(emptyRepo)
+	fullService := services.NewSitemapService(fullRepo)
+	fullService.SetPageSize(1000)
+
+	// ACT
+	emptyPages, emptyErr := emptyService.PageCount()
+	fullPages, fullErr := fullService.PageCount()
+
+	// ASSERT
+	assert.NoError(t, emptyErr)
+	assert.Equal(t, 1, emptyPages)
+	assert.NoError(t, fullErr)
+	assert.Equal(t, 3, fullPages)
+}
+
+// TestSitemap_EachEntryStreamsPage: cada página pide al repositorio solo su tramo
+func TestSitemap_EachEntryStreamsPage(t *testing.T) {
+	// ARRANGE
+	postRepo := new(mocks.MockPostRepository)
+	postRepo.On("CountPublished").Return(25, nil)
+	entries := []*models.SitemapEntry{
+		{Slug: "post-21", LastModified: time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)},
+		{Slug: "post-22", LastModified: time.Date(2025, 10, 2, 12, 0, 0, 0, time.UTC)},
+	}
+	postRepo.On("EachPublished", 20, 10, mock.Anything).Return(entries, nil)
+
+	sitemapService := services.NewSitemapService(postRepo)
+	sitemapService.SetPageSize(10)
+
+	// ACT
+	var slugs []string
+	err := sitemapService.EachEntry(3, func(entry *models.SitemapEntry) error {
+		slugs = append(slugs, entry.Slug)
+		return nil
+	})
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"post-21", "post-22"}, slugs)
+	postRepo.AssertExpectations(t)
+}
+
+// TestSitemap_PageOutOfRange: pedir una página que no existe devuelve error
+func TestSitemap_PageOutOfRange(t *testing.T) {
+	// ARRANGE
+	postRepo := new(mocks.MockPostRepository)
+	postRepo.On("CountPublished").Return(25, nil)
+
+	sitemapService := services.NewSitemapService(postRepo)
+	sitemapService.SetPageSize(10)
+	noop := func(entry *models.SitemapEntry) error { return nil }
+
+	// ACT
+	errZero := sitemapService.EachEntry(0, noop)
+	errAfter := sitemapService.EachEntry(4, noop)
+
+	// ASSERT
+	assert.EqualError(t, errZero, services.ErrSitemapNotFound)
+	assert.EqualError(t, errAfter, services.ErrSitemapNotFound)
+	postRepo.AssertNotCalled(t, "EachPublished", mock.Anything, mock.Anything, mock.Anything)
+}
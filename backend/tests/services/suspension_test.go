@@ -0,0 +1,154 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+	"tp06-testing/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestSuspendUser_Validation: hace falta un motivo y una fecha futura, y
+// no se puede suspender a un moderador
+func TestSuspendUser_Validation(t *testing.T) {
+	// ARRANGE
+	f := newModerationFixture()
+	clock := &mocks.FakeClock{Current: time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)}
+	f.moderationService.SetClock(clock)
+	past := clock.Current.Add(-time.Hour)
+
+	// ACT
+	_, reasonErr := f.moderationService.SuspendUser(9, 2, &models.SuspendUserRequest{Reason: "  "})
+	_, pastErr := f.moderationService.SuspendUser(9, 2, &models.SuspendUserRequest{Reason: "spam", ExpiresAt: &past})
+	_, forbiddenErr := f.moderationService.SuspendUser(3, 2, &models.SuspendUserRequest{Reason: "spam"})
+	_, moderatorErr := f.moderationService.SuspendUser(9, 9, &models.SuspendUserRequest{Reason: "spam"})
+
+	// ASSERT
+	assert.EqualError(t, reasonErr, services.ErrSuspensionReasonRequired)
+	assert.EqualError(t, pastErr, services.ErrSuspensionExpiresInPast)
+	assert.EqualError(t, forbiddenErr, services.ErrNotModerator)
+	assert.EqualError(t, moderatorErr, services.ErrCannotSuspendModerator)
+	f.suspensionRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// TestSuspendUser_Success: la suspensión queda registrada con moderador,
+// motivo y vencimiento
+func TestSuspendUser_Success(t *testing.T) {
+	// ARRANGE
+	f := newModerationFixture()
+	clock := &mocks.FakeClock{Current: time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)}
+	f.moderationService.SetClock(clock)
+	expiresAt := clock.Current.Add(72 * time.Hour)
+	f.suspensionRepo.On("Create", mock.AnythingOfType("*models.Suspension")).Return(nil)
+
+	// ACT
+	suspension, err := f.moderationService.SuspendUser(9, 2, &models.SuspendUserRequest{Reason: " insultos ", ExpiresAt: &expiresAt})
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, 2, suspension.UserID)
+	assert.Equal(t, 9, *suspension.ModeratorID)
+	assert.Equal(t, "insultos", suspension.Reason)
+	assert.Equal(t, expiresAt, *suspension.ExpiresAt)
+}
+
+// sessionRecorder guarda los usuarios cuyas conexiones se cerraron
+type sessionRecorder struct {
+	disconnected []int
+}
+
+func (r *sessionRecorder) Disconnect(userID int) {
+	r.disconnected = append(r.disconnected, userID)
+}
+
+// TestSuspendUser_DisconnectsLiveSessions: el suspendido sale de las salas en vivo
+func TestSuspendUser_DisconnectsLiveSessions(t *testing.T) {
+	// ARRANGE
+	f := newModerationFixture()
+	sessions := &sessionRecorder{}
+	f.moderationService.SetSessionCloser(sessions)
+	f.suspensionRepo.On("Create", mock.AnythingOfType("*models.Suspension")).Return(nil)
+
+	// ACT
+	_, err := f.moderationService.SuspendUser(9, 2, &models.SuspendUserRequest{Reason: "spam"})
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, sessions.disconnected)
+}
+
+// TestLiftSuspension_NotSuspended: no se puede levantar lo que no existe
+func TestLiftSuspension_NotSuspended(t *testing.T) {
+	// ARRANGE
+	f := newModerationFixture()
+	f.suspensionRepo.On("Lift", 2, 9, mock.AnythingOfType("time.Time")).Return(false, nil)
+
+	// ACT
+	suspensions, err := f.moderationService.LiftSuspension(9, 2)
+
+	// ASSERT
+	assert.Nil(t, suspensions)
+	assert.EqualError(t, err, services.ErrUserNotSuspended)
+}
+
+// TestLogin_Suspended: un usuario suspendido no puede iniciar sesión y el
+// error le explica el motivo y hasta cuándo
+func TestLogin_Suspended(t *testing.T) {
+	// ARRANGE
+	userRepo := new(mocks.MockUserRepository)
+	suspensionRepo := new(mocks.MockSuspensionRepository)
+	authService := services.NewAuthService(userRepo)
+	authService.SetSuspensionRepository(suspensionRepo)
+	expiresAt := time.Date(2024, 5, 13, 12, 0, 0, 0, time.UTC)
+	userRepo.On("FindByEmail", "beto@example.com").Return(&models.User{ID: 2, Email: "beto@example.com", Password: "secreto"}, nil)
+	suspensionRepo.On("FindActive", 2, mock.AnythingOfType("time.Time")).Return(&models.Suspension{UserID: 2, Reason: "spam reiterado", ExpiresAt: &expiresAt}, nil)
+
+	// ACT
+	user, err := authService.Login(&models.Credentials{Email: "beto@example.com", Password: "secreto"})
+
+	// ASSERT
+	assert.Nil(t, user)
+	var suspended *services.SuspendedError
+	assert.True(t, errors.As(err, &suspended))
+	assert.Equal(t, "tu cuenta está suspendida hasta el 13/05/2024 12:00 (UTC). Motivo: spam reiterado", err.Error())
+}
+
+// TestCheckSession_NotSuspended: sin suspensión vigente la sesión sigue
+func TestCheckSession_NotSuspended(t *testing.T) {
+	// ARRANGE
+	suspensionRepo := new(mocks.MockSuspensionRepository)
+	authService := services.NewAuthService(new(mocks.MockUserRepository))
+	authService.SetSuspensionRepository(suspensionRepo)
+	clock := &mocks.FakeClock{Current: time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)}
+	authService.SetClock(clock)
+	suspensionRepo.On("FindActive", 2, clock.Current).Return(nil, nil)
+
+	// ACT
+	err := authService.CheckSession(2)
+
+	// ASSERT
+	assert.NoError(t, err)
+	suspensionRepo.AssertExpectations(t)
+}
+
+// TestDeletePost_Suspended: un usuario suspendido tampoco puede borrar
+func TestDeletePost_Suspended(t *testing.T) {
+	// ARRANGE
+	postRepo := new(mocks.MockPostRepository)
+	suspensionRepo := new(mocks.MockSuspensionRepository)
+	postService := services.NewPostService(postRepo, new(mocks.MockUserRepository))
+	postService.SetSuspensionRepository(suspensionRepo)
+	suspensionRepo.On("FindActive", 1, mock.AnythingOfType("time.Time")).Return(&models.Suspension{UserID: 1, Reason: "spam"}, nil)
+
+	// ACT
+	err := postService.DeletePost(5, 1)
+
+	// ASSERT
+	assert.EqualError(t, err, services.ErrUserSuspended)
+	postRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
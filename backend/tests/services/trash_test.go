@@ -0,0 +1,154 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+	"tp06-testing/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestGetTrash_PurgeDates: la papelera busca lo borrado dentro del plazo
+// e informa cuándo se elimina cada cosa
+func TestGetTrash_PurgeDates(t *testing.T) {
+	// ARRANGE
+	mockRepo := new(mocks.MockPostRepository)
+	postService := services.NewPostService(mockRepo, new(mocks.MockUserRepository))
+	clock := &mocks.FakeClock{Current: time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)}
+	postService.SetClock(clock)
+	postService.SetTrashRetention(7 * 24 * time.Hour)
+
+	since := clock.Current.Add(-7 * 24 * time.Hour)
+	deletedAt := clock.Current.Add(-2 * 24 * time.Hour)
+	mockRepo.On("FindDeletedPosts", 1, since).Return([]*models.Post{{ID: 5, Title: "Borrador", Content: "**hola**", UserID: 1, DeletedAt: &deletedAt}}, nil)
+	mockRepo.On("FindDeletedComments", 1, since).Return(nil, nil)
+
+	// ACT
+	trash, err := postService.GetTrash(1)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, 7, trash.RetentionDays)
+	assert.Len(t, trash.Posts, 1)
+	assert.Equal(t, deletedAt.Add(7*24*time.Hour), *trash.Posts[0].PurgeAt)
+	assert.Contains(t, trash.Posts[0].ContentHTML, "<strong>hola</strong>")
+	assert.NotNil(t, trash.Comments)
+	assert.Empty(t, trash.Comments)
+}
+
+// TestRestorePost_Success: el post vuelve de la papelera y se devuelve
+// con su contenido
+func TestRestorePost_Success(t *testing.T) {
+	// ARRANGE
+	mockRepo := new(mocks.MockPostRepository)
+	postService := services.NewPostService(mockRepo, new(mocks.MockUserRepository))
+	clock := &mocks.FakeClock{Current: time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)}
+	postService.SetClock(clock)
+
+	since := clock.Current.Add(-services.DefaultTrashRetention)
+	mockRepo.On("Restore", 5, 1, since).Return(true, nil)
+	mockRepo.On("FindByID", 5).Return(&models.Post{ID: 5, Title: "Vuelve", Content: "texto", Status: models.PostStatusPublished, UserID: 1}, nil)
+
+	// ACT
+	post, err := postService.RestorePost(5, 1)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, 5, post.ID)
+	assert.Equal(t, "<p>texto</p>", post.ContentHTML)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestRestorePost_NotInTrash: no se restaura lo que no está en la papelera
+// del usuario, lo que venció o lo que borró moderación
+func TestRestorePost_NotInTrash(t *testing.T) {
+	// ARRANGE
+	mockRepo := new(mocks.MockPostRepository)
+	postService := services.NewPostService(mockRepo, new(mocks.MockUserRepository))
+	mockRepo.On("Restore", 5, 2, mock.AnythingOfType("time.Time")).Return(false, nil)
+
+	// ACT
+	post, err := postService.RestorePost(5, 2)
+
+	// ASSERT
+	assert.Nil(t, post)
+	assert.EqualError(t, err, services.ErrPostNotInTrash)
+	mockRepo.AssertNotCalled(t, "FindByID", mock.Anything)
+}
+
+// TestRestoreComment_Success: el comentario vuelve a su post
+func TestRestoreComment_Success(t *testing.T) {
+	// ARRANGE
+	mockRepo := new(mocks.MockPostRepository)
+	postService := services.NewPostService(mockRepo, new(mocks.MockUserRepository))
+	mockRepo.On("RestoreComment", 5, 10, 1, mock.AnythingOfType("time.Time")).Return(true, nil)
+	mockRepo.On("FindCommentByID", 10).Return(&models.Comment{ID: 10, PostID: 5, UserID: 1, Content: "perdón"}, nil)
+	mockRepo.On("FindByID", 5).Return(&models.Post{ID: 5, Status: models.PostStatusPublished, UserID: 2}, nil)
+
+	// ACT
+	comment, err := postService.RestoreComment(5, 10, 1)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, 10, comment.ID)
+	assert.Equal(t, "<p>perdón</p>", comment.ContentHTML)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestRestoreComment_NotInTrash: un comentario ajeno, vencido o de un post
+// borrado no se restaura
+func TestRestoreComment_NotInTrash(t *testing.T) {
+	// ARRANGE
+	mockRepo := new(mocks.MockPostRepository)
+	postService := services.NewPostService(mockRepo, new(mocks.MockUserRepository))
+	mockRepo.On("RestoreComment", 5, 10, 2, mock.AnythingOfType("time.Time")).Return(false, nil)
+
+	// ACT
+	comment, err := postService.RestoreComment(5, 10, 2)
+
+	// ASSERT
+	assert.Nil(t, comment)
+	assert.EqualError(t, err, services.ErrCommentNotInTrash)
+}
+
+// TestModeration_DeleteRecordsModerator: lo que borra un moderador queda
+// registrado a su nombre, así el autor no puede restaurarlo
+func TestModeration_DeleteRecordsModerator(t *testing.T) {
+	// ARRANGE
+	f := newModerationFixture()
+	f.reportRepo.On("FindByID", 6).Return(&models.Report{ID: 6, PostID: 1, CommentID: 3, AuthorID: 2, Reason: models.ReportHarassment, Status: models.ReportOpen, TargetStatus: models.ReportTargetVisible}, nil).Once()
+	f.reportRepo.On("FindByID", 6).Return(&models.Report{ID: 6, Status: models.ReportResolved}, nil)
+	f.reportRepo.On("Close", mock.Anything, mock.Anything, mock.AnythingOfType("*models.ReportAction")).Return(nil)
+	f.reportRepo.On("FindActions", 6).Return([]*models.ReportAction{}, nil)
+	f.postRepo.On("DeleteComment", 1, 3, 2, 9).Return(nil)
+
+	// ACT
+	_, err := f.moderationService.Act(9, 6, &models.ModerateReportRequest{Action: models.ReportActionDelete})
+
+	// ASSERT
+	assert.NoError(t, err)
+	f.postRepo.AssertCalled(t, "DeleteComment", 1, 3, 2, 9)
+}
+
+// TestTrashPurger_RunOnce_UsesRetention: se elimina lo borrado antes del
+// plazo de retención según el reloj
+func TestTrashPurger_RunOnce_UsesRetention(t *testing.T) {
+	// ARRANGE
+	mockRepo := new(mocks.MockPostRepository)
+	clock := &mocks.FakeClock{Current: time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)}
+	purger := services.NewTrashPurger(mockRepo, clock, 30*24*time.Hour, time.Hour)
+	mockRepo.On("PurgeDeleted", time.Date(2024, 4, 10, 12, 0, 0, 0, time.UTC)).Return(2, 5, nil)
+
+	// ACT
+	posts, comments, err := purger.RunOnce()
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, 2, posts)
+	assert.Equal(t, 5, comments)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"testing"
+
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+	"tp06-testing/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newVisibilityService arma un PostService con el repositorio de follows
+// configurado y el post 5 del usuario 1 con la visibilidad indicada
+func newVisibilityService(visibility string) (*services.PostService, *mocks.MockPostRepository, *mocks.MockFollowRepository) {
+	mockRepo := new(mocks.MockPostRepository)
+	followRepo := new(mocks.MockFollowRepository)
+	postService := services.NewPostService(mockRepo, new(mocks.MockUserRepository))
+	postService.SetFollowRepository(followRepo)
+
+	mockRepo.On("FindByID", 5).Return(&models.Post{
+		ID:         5,
+		Title:      "Post",
+		Content:    "texto",
+		Status:     models.PostStatusPublished,
+		Visibility: visibility,
+		UserID:     1,
+	}, nil)
+
+	return postService, mockRepo, followRepo
+}
+
+// TestCreatePost_DefaultVisibility: sin visibilidad el post es público
+func TestCreatePost_DefaultVisibility(t *testing.T) {
+	// ARRANGE
+	mockRepo := new(mocks.MockPostRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	postService := services.NewPostService(mockRepo, mockUserRepo)
+	mockUserRepo.On("FindByID", 1).Return(&models.User{ID: 1, Username: "ana"}, nil)
+	mockRepo.On("SlugExists", "hola").Return(false, nil)
+	mockRepo.On("Create", mock.AnythingOfType("*models.Post")).Return(nil)
+
+	// ACT
+	post, err := postService.CreatePost(&models.CreatePostRequest{Title: "Hola", Content: "texto"}, 1)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, models.PostVisibilityPublic, post.Visibility)
+}
+
+// TestCreatePost_InvalidVisibility: una visibilidad desconocida se rechaza
+// sin crear el post
+func TestCreatePost_InvalidVisibility(t *testing.T) {
+	// ARRANGE
+	mockRepo := new(mocks.MockPostRepository)
+	mockUserRepo := new(mocks.MockUserRepository)
+	postService := services.NewPostService(mockRepo, mockUserRepo)
+	mockUserRepo.On("FindByID", 1).Return(&models.User{ID: 1, Username: "ana"}, nil)
+
+	// ACT
+	post, err := postService.CreatePost(&models.CreatePostRequest{Title: "Hola", Content: "texto", Visibility: "amigos"}, 1)
+
+	// ASSERT
+	assert.Nil(t, post)
+	assert.EqualError(t, err, services.ErrInvalidVisibility)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// TestGetPostByID_Unlisted: un post no listado se puede abrir con el link
+// aunque no se esté logueado
+func TestGetPostByID_Unlisted(t *testing.T) {
+	// ARRANGE
+	postService, _, _ := newVisibilityService(models.PostVisibilityUnlisted)
+
+	// ACT
+	post, err := postService.GetPostByID(5, 0)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, 5, post.ID)
+}
+
+// TestGetPostByID_Private: un post privado solo lo ve su autor
+func TestGetPostByID_Private(t *testing.T) {
+	// ARRANGE
+	postService, _, followRepo := newVisibilityService(models.PostVisibilityPrivate)
+
+	// ACT
+	other, otherErr := postService.GetPostByID(5, 2)
+	own, ownErr := postService.GetPostByID(5, 1)
+
+	// ASSERT
+	assert.Nil(t, other)
+	assert.EqualError(t, otherErr, services.ErrPostNotFound)
+	assert.NoError(t, ownErr)
+	assert.Equal(t, 5, own.ID)
+	followRepo.AssertNotCalled(t, "IsFollowing", mock.Anything, mock.Anything)
+}
+
+// TestGetPostByID_FollowersOnly: un post para seguidores lo ve quien
+// sigue al autor y no el resto
+func TestGetPostByID_FollowersOnly(t *testing.T) {
+	// ARRANGE
+	postService, _, followRepo := newVisibilityService(models.PostVisibilityFollowers)
+	followRepo.On("IsFollowing", 2, 1).Return(true, nil)
+	followRepo.On("IsFollowing", 3, 1).Return(false, nil)
+
+	// ACT
+	follower, followerErr := postService.GetPostByID(5, 2)
+	stranger, strangerErr := postService.GetPostByID(5, 3)
+	anonymous, anonymousErr := postService.GetPostByID(5, 0)
+
+	// ASSERT
+	assert.NoError(t, followerErr)
+	assert.Equal(t, 5, follower.ID)
+	assert.Nil(t, stranger)
+	assert.EqualError(t, strangerErr, services.ErrPostNotFound)
+	assert.Nil(t, anonymous)
+	assert.EqualError(t, anonymousErr, services.ErrPostNotFound)
+	followRepo.AssertNotCalled(t, "IsFollowing", 0, 1)
+}
+
+// TestGetCommentsByPostID_Private: los comentarios de un post que no se
+// puede ver tampoco se pueden leer
+func TestGetCommentsByPostID_Private(t *testing.T) {
+	// ARRANGE
+	postService, mockRepo, _ := newVisibilityService(models.PostVisibilityPrivate)
+
+	// ACT
+	comments, err := postService.GetCommentsByPostID(5, 2, "")
+
+	// ASSERT
+	assert.Nil(t, comments)
+	assert.EqualError(t, err, services.ErrPostNotFound)
+	mockRepo.AssertNotCalled(t, "FindCommentsByPostID", mock.Anything, mock.Anything)
+}
+
+// TestUpdatePost_KeepsVisibility: si no se manda visibilidad el post
+// conserva la que tenía
+func TestUpdatePost_KeepsVisibility(t *testing.T) {
+	// ARRANGE
+	postService, mockRepo, _ := newVisibilityService(models.PostVisibilityFollowers)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Post"), "").Return(nil)
+
+	// ACT
+	post, err := postService.UpdatePost(5, &models.UpdatePostRequest{Title: "Post", Content: "otro texto"}, 1)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, models.PostVisibilityFollowers, post.Visibility)
+}
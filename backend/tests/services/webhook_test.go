@@ -0,0 +1,337 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"tp06-testing/internal/events"
+	"tp06-testing/internal/models"
+	"tp06-testing/internal/services"
+	"tp06-testing/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// receivedWebhook es lo que recibió el servidor de prueba
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+// newWebhookReceiver levanta un receptor que responde status y guarda lo recibido
+func newWebhookReceiver(t *testing.T, status int) (*httptest.Server, chan receivedWebhook) {
+	received := make(chan receivedWebhook, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- receivedWebhook{header: r.Header, body: body}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func newWebhookDispatcher() (*services.WebhookDispatcher, *mocks.MockWebhookRepository, *mocks.FakeClock) {
+	webhookRepo := new(mocks.MockWebhookRepository)
+	clock := &mocks.FakeClock{Current: time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)}
+	dispatcher := services.NewWebhookDispatcher(webhookRepo, clock, &http.Client{Timeout: time.Second}, time.Minute)
+	return dispatcher, webhookRepo, clock
+}
+
+// TestWebhookDispatcher_DeliversSignedPayload: el receptor recibe el
+// evento con una firma que puede verificar con su secreto
+func TestWebhookDispatcher_DeliversSignedPayload(t *testing.T) {
+	// ARRANGE
+	dispatcher, webhookRepo, clock := newWebhookDispatcher()
+	server, received := newWebhookReceiver(t, http.StatusNoContent)
+	delivery := &models.WebhookDelivery{
+		ID:        7,
+		WebhookID: 1,
+		EventType: events.CommentCreated,
+		Payload:   `{"event":"comment.created","data":{"id":3}}`,
+		Status:    models.DeliveryPending,
+		URL:       server.URL,
+		Secret:    "s3cr3t",
+	}
+	webhookRepo.On("FindDueDeliveries", clock.Current, mock.Anything).Return([]*models.WebhookDelivery{delivery}, nil)
+	webhookRepo.On("UpdateDelivery", delivery).Return(nil)
+
+	// ACT
+	count, err := dispatcher.RunOnce(context.Background())
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	got := <-received
+	timestamp := strconv.FormatInt(clock.Current.Unix(), 10)
+	assert.Equal(t, delivery.Payload, string(got.body))
+	assert.Equal(t, events.CommentCreated, got.header.Get(services.HeaderWebhookEvent))
+	assert.Equal(t, "7", got.header.Get(services.HeaderWebhookDelivery))
+	assert.Equal(t, timestamp, got.header.Get(services.HeaderWebhookTimestamp))
+	assert.Equal(t, services.SignWebhookPayload("s3cr3t", clock.Current.Unix(), got.body), got.header.Get(services.HeaderWebhookSignature))
+	assert.NotEqual(t, services.SignWebhookPayload("otro", clock.Current.Unix(), got.body), got.header.Get(services.HeaderWebhookSignature))
+
+	assert.Equal(t, models.DeliveryDelivered, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusNoContent, *delivery.ResponseStatus)
+	assert.Nil(t, delivery.NextAttemptAt)
+	assert.Equal(t, clock.Current, *delivery.DeliveredAt)
+}
+
+// TestWebhookDispatcher_RetriesWithBackoff: cada fallo duplica la espera
+func TestWebhookDispatcher_RetriesWithBackoff(t *testing.T) {
+	// ARRANGE
+	dispatcher, webhookRepo, clock := newWebhookDispatcher()
+	server, _ := newWebhookReceiver(t, http.StatusInternalServerError)
+	delivery := &models.WebhookDelivery{ID: 7, EventType: events.PostCreated, Payload: `{}`, Status: models.DeliveryPending, URL: server.URL}
+	webhookRepo.On("FindDueDeliveries", mock.Anything, mock.Anything).Return([]*models.WebhookDelivery{delivery}, nil)
+	webhookRepo.On("UpdateDelivery", delivery).Return(nil)
+
+	// ACT
+	_, err1 := dispatcher.RunOnce(context.Background())
+	firstRetry := *delivery.NextAttemptAt
+	_, err2 := dispatcher.RunOnce(context.Background())
+	secondRetry := *delivery.NextAttemptAt
+
+	// ASSERT
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, *delivery.ResponseStatus)
+	assert.Contains(t, delivery.LastError, "500")
+	assert.Equal(t, clock.Current.Add(30*time.Second), firstRetry)
+	assert.Equal(t, clock.Current.Add(time.Minute), secondRetry)
+}
+
+// TestWebhookDispatcher_DeadLettersAfterMaxAttempts: al agotar los
+// reintentos la entrega queda como "dead" y no se vuelve a programar
+func TestWebhookDispatcher_DeadLettersAfterMaxAttempts(t *testing.T) {
+	// ARRANGE
+	dispatcher, webhookRepo, _ := newWebhookDispatcher()
+	server, _ := newWebhookReceiver(t, http.StatusBadGateway)
+	delivery := &models.WebhookDelivery{ID: 7, EventType: events.PostCreated, Payload: `{}`, Status: models.DeliveryPending, Attempts: 7, URL: server.URL}
+	webhookRepo.On("FindDueDeliveries", mock.Anything, mock.Anything).Return([]*models.WebhookDelivery{delivery}, nil)
+	webhookRepo.On("UpdateDelivery", delivery).Return(nil)
+
+	// ACT
+	_, err := dispatcher.RunOnce(context.Background())
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, models.DeliveryDead, delivery.Status)
+	assert.Equal(t, 8, delivery.Attempts)
+	assert.Nil(t, delivery.NextAttemptAt)
+}
+
+// TestWebhookDispatcher_HidesTransportErrors: el registro que ve el dueño
+// del webhook no muestra el error de red tal cual (serviría para sondear
+// puertos y direcciones)
+func TestWebhookDispatcher_HidesTransportErrors(t *testing.T) {
+	// ARRANGE
+	dispatcher, webhookRepo, _ := newWebhookDispatcher()
+	server, _ := newWebhookReceiver(t, http.StatusNoContent)
+	closedURL := server.URL
+	server.Close()
+	delivery := &models.WebhookDelivery{ID: 7, EventType: events.PostCreated, Payload: `{}`, Status: models.DeliveryPending, URL: closedURL}
+	webhookRepo.On("FindDueDeliveries", mock.Anything, mock.Anything).Return([]*models.WebhookDelivery{delivery}, nil)
+	webhookRepo.On("UpdateDelivery", delivery).Return(nil)
+
+	// ACT
+	_, err := dispatcher.RunOnce(context.Background())
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+	assert.Nil(t, delivery.ResponseStatus)
+	assert.Equal(t, "no se pudo conectar con el receptor", delivery.LastError)
+}
+
+// TestWebhookDispatcher_SlowReceiverDoesNotBlockOthers: mientras un
+// receptor tarda en responder, las entregas de otros webhooks salen igual;
+// las siguientes del webhook que falló quedan para la próxima vuelta
+func TestWebhookDispatcher_SlowReceiverDoesNotBlockOthers(t *testing.T) {
+	// ARRANGE
+	dispatcher, webhookRepo, _ := newWebhookDispatcher()
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(slow.Close)
+	t.Cleanup(func() {
+		select {
+		case <-release:
+		default:
+			close(release)
+		}
+	})
+	fast, received := newWebhookReceiver(t, http.StatusNoContent)
+
+	slowFirst := &models.WebhookDelivery{ID: 1, WebhookID: 1, EventType: events.PostCreated, Payload: `{}`, Status: models.DeliveryPending, URL: slow.URL}
+	slowSecond := &models.WebhookDelivery{ID: 2, WebhookID: 1, EventType: events.PostCreated, Payload: `{}`, Status: models.DeliveryPending, URL: slow.URL}
+	fastOne := &models.WebhookDelivery{ID: 3, WebhookID: 2, EventType: events.PostCreated, Payload: `{}`, Status: models.DeliveryPending, URL: fast.URL}
+	webhookRepo.On("FindDueDeliveries", mock.Anything, mock.Anything).Return([]*models.WebhookDelivery{slowFirst, slowSecond, fastOne}, nil)
+	webhookRepo.On("UpdateDelivery", mock.AnythingOfType("*models.WebhookDelivery")).Return(nil)
+
+	// ACT
+	done := make(chan int)
+	go func() {
+		count, _ := dispatcher.RunOnce(context.Background())
+		done <- count
+	}()
+
+	// ASSERT
+	select {
+	case <-received:
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("la entrega al receptor rápido esperó al lento")
+	}
+	close(release)
+	assert.Equal(t, 2, <-done)
+	assert.Equal(t, models.DeliveryDelivered, fastOne.Status)
+	assert.Equal(t, 1, slowFirst.Attempts)
+	assert.Equal(t, 0, slowSecond.Attempts)
+	webhookRepo.AssertNumberOfCalls(t, "UpdateDelivery", 2)
+}
+
+// TestWebhookDispatcher_EnqueueForSubscribers: se encola una entrega por webhook suscripto
+func TestWebhookDispatcher_EnqueueForSubscribers(t *testing.T) {
+	// ARRANGE
+	dispatcher, webhookRepo, clock := newWebhookDispatcher()
+	webhookRepo.On("FindSubscribed", events.PostCreated).Return([]*models.Webhook{{ID: 1}, {ID: 2}}, nil)
+	webhookRepo.On("CreateDelivery", mock.Anything).Return(nil)
+	event := &events.Event{ID: 99, Type: events.PostCreated, PostID: 5, Data: []byte(`{"id":5}`)}
+
+	// ACT
+	count, err := dispatcher.Enqueue(event)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	delivery := webhookRepo.Calls[1].Arguments.Get(0).(*models.WebhookDelivery)
+	assert.Equal(t, 1, delivery.WebhookID)
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+	assert.Equal(t, clock.Current, *delivery.NextAttemptAt)
+
+	var payload map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(delivery.Payload), &payload))
+	assert.Equal(t, float64(99), payload["id"])
+	assert.Equal(t, events.PostCreated, payload["event"])
+	assert.Equal(t, map[string]interface{}{"id": float64(5)}, payload["data"])
+}
+
+// TestCreateWebhook_Validation: la URL tiene que ser http(s) y los eventos conocidos
+func TestCreateWebhook_Validation(t *testing.T) {
+	// ARRANGE
+	webhookRepo := new(mocks.MockWebhookRepository)
+	webhookService := services.NewWebhookService(webhookRepo, services.RealClock{})
+
+	// ACT
+	_, errURL := webhookService.CreateWebhook(&models.CreateWebhookRequest{URL: "ftp://example.com", Events: []string{events.PostCreated}}, 1)
+	_, errEvent := webhookService.CreateWebhook(&models.CreateWebhookRequest{URL: "https://example.com", Events: []string{"post.liked"}}, 1)
+	_, errEmpty := webhookService.CreateWebhook(&models.CreateWebhookRequest{URL: "https://example.com"}, 1)
+
+	// ASSERT
+	assert.EqualError(t, errURL, services.ErrInvalidWebhookURL)
+	assert.EqualError(t, errEvent, services.ErrInvalidWebhookEvents)
+	assert.EqualError(t, errEmpty, services.ErrInvalidWebhookEvents)
+	webhookRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// TestCreateWebhook_RejectsPrivateAddresses: no se pueden registrar
+// destinos locales, de redes privadas ni la metadata de la nube
+func TestCreateWebhook_RejectsPrivateAddresses(t *testing.T) {
+	// ARRANGE
+	webhookRepo := new(mocks.MockWebhookRepository)
+	webhookService := services.NewWebhookService(webhookRepo, services.RealClock{})
+	urls := []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://10.0.0.5/hook",
+		"http://192.168.1.10/hook",
+		"http://[::1]/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+		"http://0.0.0.0/hook",
+	}
+
+	for _, url := range urls {
+		// ACT
+		_, err := webhookService.CreateWebhook(&models.CreateWebhookRequest{URL: url, Events: []string{events.PostCreated}}, 1)
+
+		// ASSERT
+		assert.EqualError(t, err, services.ErrPrivateAddress, url)
+	}
+	webhookRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// TestCreateWebhook_GeneratesSecret: sin secreto se genera uno y se devuelve una sola vez
+func TestCreateWebhook_GeneratesSecret(t *testing.T) {
+	// ARRANGE
+	webhookRepo := new(mocks.MockWebhookRepository)
+	webhookService := services.NewWebhookService(webhookRepo, services.RealClock{})
+	webhookRepo.On("Create", mock.Anything).Return(nil)
+	webhookRepo.On("FindByUser", 1).Return([]*models.Webhook{{ID: 1, UserID: 1, Secret: "guardado"}}, nil)
+	req := &models.CreateWebhookRequest{
+		URL:    "https://ci.example.com/hook",
+		Events: []string{events.CommentCreated, events.CommentCreated},
+	}
+
+	// ACT
+	webhook, err := webhookService.CreateWebhook(req, 1)
+	listed, listErr := webhookService.GetWebhooks(1)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Len(t, webhook.Secret, 64)
+	assert.Equal(t, []string{events.CommentCreated}, webhook.Events)
+	assert.NoError(t, listErr)
+	assert.Empty(t, listed[0].Secret)
+}
+
+// TestRedeliver_CreatesNewDelivery: el reenvío es una entrega nueva con el mismo payload
+func TestRedeliver_CreatesNewDelivery(t *testing.T) {
+	// ARRANGE
+	webhookRepo := new(mocks.MockWebhookRepository)
+	clock := &mocks.FakeClock{Current: time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)}
+	webhookService := services.NewWebhookService(webhookRepo, clock)
+	original := &models.WebhookDelivery{ID: 7, WebhookID: 1, EventType: events.PostDeleted, Payload: `{"id":1}`, Status: models.DeliveryDead, Attempts: 8}
+	webhookRepo.On("FindByID", 1).Return(&models.Webhook{ID: 1, UserID: 3}, nil)
+	webhookRepo.On("FindDelivery", 1, 7).Return(original, nil)
+	webhookRepo.On("CreateDelivery", mock.Anything).Return(nil)
+
+	// ACT
+	delivery, err := webhookService.Redeliver(1, 7, 3)
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, models.DeliveryPending, delivery.Status)
+	assert.Equal(t, 0, delivery.Attempts)
+	assert.Equal(t, original.Payload, delivery.Payload)
+	assert.Equal(t, 7, *delivery.RedeliveryOf)
+	assert.Equal(t, clock.Current, *delivery.NextAttemptAt)
+	assert.Equal(t, models.DeliveryDead, original.Status)
+}
+
+// TestRedeliver_OtherUsersWebhook: un webhook ajeno se informa como inexistente
+func TestRedeliver_OtherUsersWebhook(t *testing.T) {
+	// ARRANGE
+	webhookRepo := new(mocks.MockWebhookRepository)
+	webhookService := services.NewWebhookService(webhookRepo, services.RealClock{})
+	webhookRepo.On("FindByID", 1).Return(&models.Webhook{ID: 1, UserID: 3}, nil)
+
+	// ACT
+	_, err := webhookService.Redeliver(1, 7, 4)
+
+	// ASSERT
+	assert.EqualError(t, err, services.ErrWebhookNotFound)
+	webhookRepo.AssertNotCalled(t, "CreateDelivery", mock.Anything)
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"tp06-testing/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 simula un bucket S3 en memoria (PUT, HEAD, GET con Range, DELETE)
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = data
+	case http.MethodHead, http.MethodGet:
+		data, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		start := 0
+		if rng := r.Header.Get("Range"); rng != "" {
+			start, _ = strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rng, "bytes="), "-"))
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		}
+		if r.Method == http.MethodGet {
+			w.Write(data[start:])
+		}
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// TestS3Store_PutOpenSeekDelete prueba el ciclo completo contra un S3 falso
+func TestS3Store_PutOpenSeekDelete(t *testing.T) {
+	// ARRANGE
+	fake := &fakeS3{objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	store, err := storage.NewS3Store(storage.S3Config{
+		Endpoint:  server.URL,
+		Bucket:    "uploads",
+		AccessKey: "AKID",
+		SecretKey: "secret",
+	}, server.Client())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// ACT
+	err = store.Put(ctx, "attachments/2025/10/a.txt", strings.NewReader("hola mundo"), 10, "text/plain")
+	require.NoError(t, err)
+
+	object, err := store.Open(ctx, "attachments/2025/10/a.txt")
+	require.NoError(t, err)
+	_, err = object.Seek(5, io.SeekStart)
+	require.NoError(t, err)
+	rest, err := io.ReadAll(object)
+	object.Close()
+
+	// ASSERT
+	assert.NoError(t, err)
+	assert.Equal(t, "mundo", string(rest))
+	assert.Contains(t, fake.objects, "/uploads/attachments/2025/10/a.txt")
+
+	assert.NoError(t, store.Delete(ctx, "attachments/2025/10/a.txt"))
+	_, err = store.Open(ctx, "attachments/2025/10/a.txt")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+// TestLocalStore_RejectsPathTraversal: las claves no pueden salir del directorio
+func TestLocalStore_RejectsPathTraversal(t *testing.T) {
+	// ARRANGE
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	// ACT
+	err = store.Put(context.Background(), "../fuera.txt", strings.NewReader("x"), 1, "text/plain")
+
+	// ASSERT
+	assert.Error(t, err)
+}
@@ -70,7 +70,7 @@ export const PostList: React.FC<PostListProps> = ({ currentUserId, onRefresh, on
                         <h3>{post.title}</h3>
                         <span className="post-author">por @{post.username}</span>
                     </div>
-                    <p className="post-content">{post.content}</p>
+                    <p className="post-content">{post.excerpt ?? post.content}</p>
                     <div className="post-footer">
             <span className="post-date">
               {new Date(post.created_at).toLocaleDateString()}
@@ -10,7 +10,11 @@ export interface User {
   export interface Post {
     id: number;
     title: string;
-    content: string;
+    content?: string; // GET /api/posts no lo envía salvo que se pida con ?fields=
+    content_html?: string;
+    excerpt?: string;
+    word_count?: number;
+    reading_time_minutes?: number;
     user_id: number;
     username: string;
     created_at: string;